@@ -4,6 +4,7 @@ import (
 	"context"
 	"hotel-guide/internal/db"
 	"hotel-guide/internal/hotel"
+	"hotel-guide/internal/storage"
 	"log"
 	"net/http"
 	"os"
@@ -23,16 +24,66 @@ func main() {
 
 	defer db.CloseDB(dbInstance)
 
+	// Remove rows the foreign keys added by the migrations would reject
+	if err := hotel.DeleteOrphans(dbInstance); err != nil {
+		log.Fatalf("Error deleting orphaned rows: %v", err)
+	}
+
 	// Run migrations
-	if err := dbInstance.AutoMigrate(&hotel.Hotel{}, &hotel.ContactInfo{}); err != nil {
+	if err := dbInstance.AutoMigrate(&hotel.Hotel{}, &hotel.ContactInfo{}, &hotel.Location{}, &hotel.ContactType{}, &hotel.AuditEntry{}, &hotel.ImportJob{},
+		&hotel.HotelOfficial{}, &hotel.OfficialContact{}, &hotel.RoomType{}, &hotel.Reservation{}, &hotel.Review{}, &hotel.Tag{}, &hotel.HotelTag{}, &hotel.Media{}, &hotel.HotelTranslation{},
+		&hotel.RatePlan{}, &hotel.ExchangeRate{}, &hotel.Chain{}); err != nil {
 		log.Fatalf("Error running migrations: %v", err)
 	}
 
+	// Move free-text location contacts into structured locations
+	if err := hotel.MigrateLocationContacts(dbInstance); err != nil {
+		log.Fatalf("Error migrating location contacts: %v", err)
+	}
+
+	// Record the owners of existing hotels as their owner officials
+	if err := hotel.MigrateOwnerOfficials(dbInstance); err != nil {
+		log.Fatalf("Error migrating hotel owners to officials: %v", err)
+	}
+
+	// Register the built-in contact types
+	if err := hotel.SeedContactTypes(dbInstance); err != nil {
+		log.Fatalf("Error seeding contact types: %v", err)
+	}
+	if err := hotel.SeedTags(dbInstance); err != nil {
+		log.Fatalf("Error seeding tags: %v", err)
+	}
+
 	// Initialize hotel repository
 	hotelRepo := hotel.NewRepository(dbInstance)
 
+	// Keep uploaded media files below MEDIA_DIR
+	mediaDir := os.Getenv("MEDIA_DIR")
+	if mediaDir == "" {
+		mediaDir = "media"
+	}
+	blobStore, err := storage.NewLocalStore(mediaDir)
+	if err != nil {
+		log.Fatalf("Failed to initialize media storage: %v", err)
+	}
+
 	// Initialize hotel service
-	hotelService := hotel.NewService(hotelRepo)
+	hotelService := hotel.NewService(hotelRepo, blobStore)
+
+	// Import jobs run in this process, so the ones a previous run left in
+	// progress will never finish
+	interrupted, err := hotelService.FailInterruptedImportJobs()
+	if err != nil {
+		log.Fatalf("Error failing interrupted import jobs: %v", err)
+	}
+	if interrupted > 0 {
+		log.Printf("Marked %d interrupted import jobs as failed", interrupted)
+	}
+
+	// Permanently remove hotels soft-deleted longer than the retention period
+	retention := durationFromEnv("HOTEL_RETENTION", 30*24*time.Hour)
+	purgeInterval := durationFromEnv("HOTEL_PURGE_INTERVAL", time.Hour)
+	hotelService.StartPurgeWorker(retention, purgeInterval)
 
 	// Initialize hotel handler
 	hotelHandler := hotel.NewHandler(hotelService)
@@ -73,3 +124,16 @@ func main() {
 	}
 	log.Println("Hotel service stopped gracefully")
 }
+
+// durationFromEnv reads a duration such as "720h" from the environment.
+func durationFromEnv(name string, fallback time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		log.Fatalf("Invalid %s %q: must be a positive duration such as 720h", name, value)
+	}
+	return duration
+}
@@ -0,0 +1,217 @@
+package hotel
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hotel-guide/internal/pagination"
+	"reflect"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Audited entities.
+const (
+	AuditEntityHotel       = "hotel"
+	AuditEntityContact     = "contact"
+	AuditEntityLocation    = "location"
+	AuditEntityOfficial    = "official"
+	AuditEntityRoomType    = "room_type"
+	AuditEntityReservation = "reservation"
+	AuditEntityReview      = "review"
+	AuditEntityMedia       = "media"
+	AuditEntityTranslation = "translation"
+	AuditEntityRatePlan    = "rate_plan"
+)
+
+// Audited actions.
+const (
+	AuditActionCreate  = "create"
+	AuditActionUpdate  = "update"
+	AuditActionDelete  = "delete"
+	AuditActionRestore = "restore"
+)
+
+// SystemActor is recorded for changes made without a known actor.
+const SystemActor = "system"
+
+var ErrInvalidAuditFilter = errors.New("audit filter requires since to be before until")
+
+// AuditEntry records one change made through the HotelService. Entries are
+// only ever appended and outlive the hotels they describe.
+type AuditEntry struct {
+	ID        uuid.UUID    `gorm:"type:uuid;primary_key" json:"id"`
+	HotelID   uuid.UUID    `gorm:"type:uuid;not null;index" json:"hotel_id"`
+	Entity    string       `gorm:"not null" json:"entity"`
+	EntityID  uuid.UUID    `gorm:"type:uuid;not null" json:"entity_id"`
+	Action    string       `gorm:"not null" json:"action"`
+	Actor     string       `gorm:"not null;index" json:"actor"`
+	RequestID string       `json:"request_id,omitempty"`
+	Changes   AuditChanges `gorm:"type:text" json:"changes"`
+	CreatedAt time.Time    `gorm:"not null;index" json:"created_at"`
+}
+
+// AuditChange is the value of a field before and after a change. Before is nil
+// for created and restored entities and After is nil for deleted ones.
+type AuditChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// AuditChanges maps field names to their changes. It is stored as JSON.
+type AuditChanges map[string]AuditChange
+
+func (c AuditChanges) Value() (driver.Value, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (c *AuditChanges) Scan(value interface{}) error {
+	switch data := value.(type) {
+	case nil:
+		*c = nil
+		return nil
+	case string:
+		return json.Unmarshal([]byte(data), c)
+	case []byte:
+		return json.Unmarshal(data, c)
+	}
+	return fmt.Errorf("cannot scan %T into audit changes", value)
+}
+
+// diffSnapshots compares the JSON fields of two snapshots of an entity. Nested
+// objects and lists are left out; they are audited as entities of their own.
+func diffSnapshots(before, after interface{}) (AuditChanges, error) {
+	beforeFields, err := snapshotFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := snapshotFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(AuditChanges)
+	for _, fields := range []map[string]interface{}{beforeFields, afterFields} {
+		for name := range fields {
+			if _, done := changes[name]; done || name == "id" || name == "hotel_id" {
+				continue
+			}
+			from, to := beforeFields[name], afterFields[name]
+			if isNested(from) || isNested(to) || reflect.DeepEqual(from, to) {
+				continue
+			}
+			changes[name] = AuditChange{Before: from, After: to}
+		}
+	}
+	return changes, nil
+}
+
+func snapshotFields(snapshot interface{}) (map[string]interface{}, error) {
+	if snapshot == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	err = json.Unmarshal(data, &fields)
+	return fields, err
+}
+
+func isNested(value interface{}) bool {
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+		return true
+	}
+	return false
+}
+
+type auditContextKey struct{}
+
+type auditInfo struct {
+	actor     string
+	requestID string
+}
+
+// WithAuditInfo returns a context attributing the changes made with it to the
+// actor and the request.
+func WithAuditInfo(ctx context.Context, actor, requestID string) context.Context {
+	return context.WithValue(ctx, auditContextKey{}, auditInfo{actor: actor, requestID: requestID})
+}
+
+func auditInfoFrom(ctx context.Context) auditInfo {
+	info, _ := ctx.Value(auditContextKey{}).(auditInfo)
+	if info.actor == "" {
+		info.actor = SystemActor
+	}
+	return info
+}
+
+// AuditFilter selects the audit entries ListAuditEntries returns, newest first.
+// Zero fields match every entry.
+type AuditFilter struct {
+	HotelID uuid.UUID
+	Entity  string
+	Action  string
+	Actor   string
+	Since   time.Time
+	Until   time.Time
+
+	Limit int
+	// Cursor continues a listing after the last entry of a previous page.
+	Cursor string
+
+	after *pagination.Cursor
+}
+
+// AuditPage is one page of audit entries.
+type AuditPage struct {
+	Items      []AuditEntry `json:"items"`
+	Limit      int          `json:"limit"`
+	NextCursor string       `json:"next_cursor,omitempty"`
+}
+
+const auditCursorSort = "-created_at"
+
+// normalize applies defaults and validates the filter.
+func (f *AuditFilter) normalize() error {
+	if f.Limit <= 0 {
+		f.Limit = DefaultPageLimit
+	}
+	if f.Limit > MaxPageLimit {
+		f.Limit = MaxPageLimit
+	}
+	if !f.Since.IsZero() && !f.Until.IsZero() && !f.Since.Before(f.Until) {
+		return ErrInvalidAuditFilter
+	}
+
+	f.after = nil
+	if f.Cursor != "" {
+		cursor, err := pagination.Decode(f.Cursor, auditCursorSort)
+		if err != nil {
+			return err
+		}
+		value, ok := cursor.Value.(string)
+		if !ok {
+			return ErrInvalidCursor
+		}
+		if cursor.Value, err = time.Parse(time.RFC3339Nano, value); err != nil {
+			return ErrInvalidCursor
+		}
+		f.after = cursor
+	}
+	return nil
+}
+
+// nextCursor returns the cursor that continues the listing after entry.
+func (f *AuditFilter) nextCursor(entry AuditEntry) string {
+	return pagination.Encode(pagination.Cursor{Sort: auditCursorSort, Value: entry.CreatedAt, ID: entry.ID})
+}
@@ -0,0 +1,58 @@
+package hotel
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	before := &Hotel{ID: uuid.New(), OwnerName: "John", OwnerSurname: "Doe", CompanyTitle: "Doe Ltd.", Version: 1,
+		ContactInfos: []ContactInfo{{InfoType: ContactTypePhone, InfoContent: "+902125550100"}}}
+	after := *before
+	after.CompanyTitle = "Doe Hotels Ltd."
+	after.Version = 2
+	after.ContactInfos = nil
+
+	// Only changed scalar fields are recorded; contacts are audited on their own
+	changes, err := diffSnapshots(before, &after)
+	assert.NoError(t, err)
+	assert.Equal(t, AuditChanges{
+		"company_title": {Before: "Doe Ltd.", After: "Doe Hotels Ltd."},
+		"version":       {Before: float64(1), After: float64(2)},
+	}, changes)
+
+	// Created entities have no before values, deleted ones no after values
+	contact := &ContactInfo{ID: uuid.New(), InfoType: ContactTypeEmail, InfoContent: "desk@example.com"}
+	changes, err = diffSnapshots(nil, contact)
+	assert.NoError(t, err)
+	assert.Equal(t, AuditChange{Before: nil, After: "desk@example.com"}, changes["info_content"])
+	assert.NotContains(t, changes, "id")
+
+	changes, err = diffSnapshots(contact, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, AuditChange{Before: ContactTypeEmail, After: nil}, changes["info_type"])
+}
+
+func TestAuditChanges_ValueAndScan(t *testing.T) {
+	changes := AuditChanges{"owner_name": {Before: "John", After: "Jane"}}
+
+	value, err := changes.Value()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"owner_name":{"before":"John","after":"Jane"}}`, value.(string))
+
+	var scanned AuditChanges
+	assert.NoError(t, scanned.Scan([]byte(value.(string))))
+	assert.Equal(t, changes, scanned)
+	assert.Error(t, scanned.Scan(42))
+}
+
+func TestAuditInfoFrom(t *testing.T) {
+	info := auditInfoFrom(context.Background())
+	assert.Equal(t, SystemActor, info.actor)
+
+	info = auditInfoFrom(WithAuditInfo(context.Background(), "alice", "req-1"))
+	assert.Equal(t, auditInfo{actor: "alice", requestID: "req-1"}, info)
+}
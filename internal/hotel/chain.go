@@ -0,0 +1,115 @@
+package hotel
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Kinds of chains. A group owns brands and a brand owns hotels; hotels may
+// also belong to a group directly.
+const (
+	ChainKindGroup = "group"
+	ChainKindBrand = "brand"
+)
+
+var (
+	ErrChainNotFound = errors.New("chain not found")
+	ErrChainExists   = errors.New("chain already exists")
+	ErrChainInUse    = errors.New("chain has brands or hotels")
+)
+
+// Chain is a hotel group or a brand. Brands may belong to a group, groups
+// belong to no other chain.
+type Chain struct {
+	ID       uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	ParentID *uuid.UUID `gorm:"type:uuid;index" json:"parent_id,omitempty"`
+	Name     string     `gorm:"not null;uniqueIndex" json:"name"`
+	Kind     string     `gorm:"not null" json:"kind"`
+	// Brands are the brands of a group. They are only loaded by GetChain.
+	Brands    []Chain   `gorm:"foreignKey:ParentID;references:ID" json:"brands,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ChainStats summarizes the hotels of a chain, including the hotels of the
+// brands of a group.
+type ChainStats struct {
+	ChainID    uuid.UUID `json:"chain_id"`
+	HotelCount int64     `json:"hotel_count"`
+	// Locations count the hotels per country and city, most hotels first.
+	// UnlocatedCount is the number of hotels without a location.
+	Locations      []LocationCount `json:"locations"`
+	UnlocatedCount int64           `json:"unlocated_count"`
+	// ContactCoverage tells for every contact type how many of the hotels can
+	// be reached through it.
+	ContactCoverage []ContactCoverage `json:"contact_coverage"`
+}
+
+// LocationCount is the number of hotels in a city.
+type LocationCount struct {
+	Country    string `json:"country"`
+	City       string `json:"city"`
+	HotelCount int64  `json:"hotel_count"`
+}
+
+// ContactCoverage is the number of hotels with at least one contact of a type,
+// and their share of all hotels between 0 and 1.
+type ContactCoverage struct {
+	InfoType   string  `json:"info_type"`
+	HotelCount int64   `json:"hotel_count"`
+	Share      float64 `json:"share"`
+}
+
+// validateChain normalizes a chain and returns a *ValidationError listing
+// every invalid field. Whether the parent of a brand is a group is checked by
+// the service.
+func validateChain(chain *Chain) error {
+	chain.Name = strings.TrimSpace(chain.Name)
+	chain.Kind = strings.ToLower(strings.TrimSpace(chain.Kind))
+
+	var fields []FieldError
+	if chain.Name == "" || len([]rune(chain.Name)) > maxTitleLength {
+		fields = append(fields, FieldError{"name", "is required and must be at most 255 characters"})
+	}
+	switch chain.Kind {
+	case ChainKindGroup:
+		if chain.ParentID != nil {
+			fields = append(fields, FieldError{"parent_id", "a group cannot belong to another chain"})
+		}
+	case ChainKindBrand:
+		if chain.ParentID != nil && *chain.ParentID == chain.ID {
+			fields = append(fields, FieldError{"parent_id", "a brand cannot belong to itself"})
+		}
+	default:
+		fields = append(fields, FieldError{"kind", "must be group or brand"})
+	}
+
+	if len(fields) > 0 {
+		return &ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+// contactCoverage returns the coverage of every contact type of the registry
+// but the legacy location type, ordered by name, from the number of hotels
+// with a contact of each type.
+func contactCoverage(registry contactRegistry, hotelsByType map[string]int64, hotelCount int64) []ContactCoverage {
+	coverage := make([]ContactCoverage, 0, len(registry))
+	for name := range registry {
+		if name == ContactTypeLocation {
+			continue
+		}
+		entry := ContactCoverage{InfoType: name, HotelCount: hotelsByType[name]}
+		if hotelCount > 0 {
+			entry.Share = math.Round(float64(entry.HotelCount)/float64(hotelCount)*1000) / 1000
+		}
+		coverage = append(coverage, entry)
+	}
+	sort.Slice(coverage, func(i, j int) bool { return coverage[i].InfoType < coverage[j].InfoType })
+	return coverage
+}
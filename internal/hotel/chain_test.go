@@ -0,0 +1,49 @@
+package hotel
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateChain(t *testing.T) {
+	chain := Chain{Name: " Hilton ", Kind: "Group"}
+	assert.NoError(t, validateChain(&chain))
+	assert.Equal(t, "Hilton", chain.Name)
+	assert.Equal(t, ChainKindGroup, chain.Kind)
+
+	groupID := uuid.New()
+	assert.NoError(t, validateChain(&Chain{Name: "Hampton", Kind: ChainKindBrand, ParentID: &groupID}))
+
+	var validationErr *ValidationError
+	if assert.ErrorAs(t, validateChain(&Chain{Name: "Hilton", Kind: ChainKindGroup, ParentID: &groupID}), &validationErr) {
+		assert.Equal(t, "parent_id", validationErr.Fields[0].Field)
+	}
+	if assert.ErrorAs(t, validateChain(&Chain{ID: groupID, Name: "Hampton", Kind: ChainKindBrand, ParentID: &groupID}), &validationErr) {
+		assert.Equal(t, "parent_id", validationErr.Fields[0].Field)
+	}
+	if assert.ErrorAs(t, validateChain(&Chain{Name: strings.Repeat("x", maxTitleLength+1), Kind: "hotel"}), &validationErr) {
+		assert.Equal(t, []FieldError{
+			{Field: "name", Message: "is required and must be at most 255 characters"},
+			{Field: "kind", Message: "must be group or brand"},
+		}, validationErr.Fields)
+	}
+}
+
+func TestContactCoverage(t *testing.T) {
+	registry := newContactRegistry(DefaultContactTypes)
+	coverage := contactCoverage(registry, map[string]int64{ContactTypePhone: 3, ContactTypeEmail: 1, ContactTypeLocation: 2}, 4)
+	assert.Equal(t, []ContactCoverage{
+		{InfoType: ContactTypeEmail, HotelCount: 1, Share: 0.25},
+		{InfoType: ContactTypeFax, HotelCount: 0, Share: 0},
+		{InfoType: ContactTypePhone, HotelCount: 3, Share: 0.75},
+		{InfoType: ContactTypeWebsite, HotelCount: 0, Share: 0},
+	}, coverage)
+
+	// Chains without hotels cover nothing
+	for _, entry := range contactCoverage(registry, map[string]int64{}, 0) {
+		assert.Zero(t, entry.Share)
+	}
+}
@@ -0,0 +1,324 @@
+package hotel
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Kinds of contact content. Each contact type validates and normalizes its
+// contents according to its kind.
+const (
+	ContactKindPhone = "phone"
+	ContactKindEmail = "email"
+	ContactKindURL   = "url"
+	ContactKindText  = "text"
+)
+
+// ContactType is an entry of the contact type registry.
+type ContactType struct {
+	// Name is the value of ContactInfo.InfoType, e.g. "whatsapp".
+	Name        string `gorm:"primaryKey" json:"name"`
+	DisplayName string `gorm:"not null" json:"display_name"`
+	Kind        string `gorm:"not null" json:"kind"`
+	// Pattern is an optional regular expression the normalized content must match.
+	Pattern        string `json:"pattern,omitempty"`
+	UniquePerHotel bool   `gorm:"not null;default:false" json:"unique_per_hotel"`
+	// CountsInStats makes contacts of this type count toward the phone count of
+	// the location stats. It only applies to types of the phone kind.
+	CountsInStats bool `gorm:"not null;default:false" json:"counts_in_stats"`
+}
+
+// DefaultContactTypes are the contact types every registry starts with.
+var DefaultContactTypes = []ContactType{
+	{Name: ContactTypePhone, DisplayName: "Phone", Kind: ContactKindPhone, CountsInStats: true},
+	{Name: ContactTypeEmail, DisplayName: "Email", Kind: ContactKindEmail},
+	{Name: ContactTypeFax, DisplayName: "Fax", Kind: ContactKindPhone},
+	{Name: ContactTypeWebsite, DisplayName: "Website", Kind: ContactKindURL, UniquePerHotel: true},
+	{Name: ContactTypeLocation, DisplayName: "Location", Kind: ContactKindText},
+}
+
+var contactTypeName = regexp.MustCompile(`^[a-z][a-z0-9_]{0,31}$`)
+
+// validateContactType checks a registry entry before it is stored.
+func validateContactType(contactType *ContactType) error {
+	contactType.Name = strings.TrimSpace(contactType.Name)
+	contactType.DisplayName = strings.TrimSpace(contactType.DisplayName)
+	if !contactTypeName.MatchString(contactType.Name) || contactType.DisplayName == "" {
+		return ErrInvalidContactType
+	}
+	switch contactType.Kind {
+	case ContactKindPhone, ContactKindEmail, ContactKindURL, ContactKindText:
+	default:
+		return ErrInvalidContactType
+	}
+	if _, err := regexp.Compile(contactType.Pattern); err != nil {
+		return ErrInvalidContactType
+	}
+	return nil
+}
+
+// contactRegistry indexes contact types by name.
+type contactRegistry map[string]ContactType
+
+func newContactRegistry(types []ContactType) contactRegistry {
+	registry := make(contactRegistry, len(types))
+	for _, contactType := range types {
+		registry[contactType.Name] = contactType
+	}
+	return registry
+}
+
+// countedInStats returns the names of the types that count toward the stats.
+func (r contactRegistry) countedInStats() map[string]bool {
+	counted := make(map[string]bool)
+	for name, contactType := range r {
+		if contactType.CountsInStats {
+			counted[name] = true
+		}
+	}
+	return counted
+}
+
+// phoneTypes returns the sorted names of the phone types that count toward
+// the stats, the types of the contacts the stats count as phones.
+func (r contactRegistry) phoneTypes() []string {
+	names := make([]string, 0)
+	for name, contactType := range r {
+		if contactType.Kind == ContactKindPhone && contactType.CountsInStats {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DefaultPhoneCountry is assumed for phone and fax numbers given in national
+// format when the hotel has no location to infer the country from.
+const DefaultPhoneCountry = "TR"
+
+// callingCode is the international dialing prefix of a country and the trunk
+// prefix dropped from national numbers when dialing from abroad.
+type callingCode struct {
+	code  string
+	trunk string
+}
+
+// callingCodes maps ISO 3166 codes and common country names, lower-cased, to
+// calling codes. Countries missing here need numbers in international format.
+var callingCodes = map[string]callingCode{}
+
+func init() {
+	for _, country := range []struct {
+		names []string
+		callingCode
+	}{
+		{[]string{"tr", "turkey", "türkiye", "turkiye"}, callingCode{"90", "0"}},
+		{[]string{"us", "usa", "united states", "united states of america"}, callingCode{"1", "1"}},
+		{[]string{"ca", "canada"}, callingCode{"1", "1"}},
+		{[]string{"gb", "uk", "united kingdom", "england"}, callingCode{"44", "0"}},
+		{[]string{"de", "germany", "deutschland"}, callingCode{"49", "0"}},
+		{[]string{"fr", "france"}, callingCode{"33", "0"}},
+		{[]string{"it", "italy", "italia"}, callingCode{"39", ""}},
+		{[]string{"es", "spain", "españa", "espana"}, callingCode{"34", ""}},
+		{[]string{"nl", "netherlands"}, callingCode{"31", "0"}},
+		{[]string{"gr", "greece"}, callingCode{"30", ""}},
+		{[]string{"cy", "cyprus"}, callingCode{"357", ""}},
+		{[]string{"bg", "bulgaria"}, callingCode{"359", "0"}},
+		{[]string{"az", "azerbaijan"}, callingCode{"994", "0"}},
+		{[]string{"ru", "russia"}, callingCode{"7", "8"}},
+		{[]string{"ae", "uae", "united arab emirates"}, callingCode{"971", "0"}},
+		{[]string{"jp", "japan"}, callingCode{"81", "0"}},
+		{[]string{"cn", "china"}, callingCode{"86", "0"}},
+	} {
+		for _, name := range country.names {
+			callingCodes[name] = country.callingCode
+		}
+	}
+}
+
+// validateContacts normalizes the contacts of a new hotel and returns a
+// *ValidationError listing every invalid field.
+func (r contactRegistry) validateContacts(contacts []ContactInfo, country string) error {
+	var fields []FieldError
+	seen := make(map[string]bool)
+	for i := range contacts {
+		prefix := fmt.Sprintf("contacts[%d].", i)
+		fields = append(fields, r.normalizeContact(&contacts[i], country, prefix)...)
+
+		if contactType, ok := r[contacts[i].InfoType]; ok && contactType.UniquePerHotel {
+			if seen[contactType.Name] {
+				fields = append(fields, FieldError{prefix + "info_type", fmt.Sprintf("a hotel can have only one %s", contactType.DisplayName)})
+			}
+			seen[contactType.Name] = true
+		}
+	}
+	if len(fields) > 0 {
+		return invalidContact(fields)
+	}
+	return nil
+}
+
+// invalidContact returns a *ValidationError for the fields that also matches
+// ErrInvalidContact with errors.Is.
+func invalidContact(fields []FieldError) error {
+	return fmt.Errorf("%w: %w", ErrInvalidContact, &ValidationError{Fields: fields})
+}
+
+// validateContact normalizes a contact added to or changed on the hotel. Types
+// that are unique per hotel must not be used by another contact of the hotel.
+func (r contactRegistry) validateContact(contact *ContactInfo, hotel *Hotel) error {
+	fields := r.normalizeContact(contact, phoneCountry(hotel), "")
+	if contactType, ok := r[contact.InfoType]; ok && contactType.UniquePerHotel {
+		for _, other := range hotel.ContactInfos {
+			if other.InfoType == contact.InfoType && other.ID != contact.ID {
+				fields = append(fields, FieldError{"info_type", fmt.Sprintf("the hotel already has a %s", contactType.DisplayName)})
+				break
+			}
+		}
+	}
+	if len(fields) > 0 {
+		return invalidContact(fields)
+	}
+	return nil
+}
+
+// normalizeContact checks the contact against its registered type and rewrites
+// the content in canonical form for the kind of the type: E.164 for phones,
+// lower case for emails. Numbers in national format are assumed to belong to
+// country. Field names are prefixed with prefix.
+func (r contactRegistry) normalizeContact(contact *ContactInfo, country, prefix string) []FieldError {
+	contact.InfoType = strings.ToLower(strings.TrimSpace(contact.InfoType))
+	contact.InfoContent = strings.TrimSpace(contact.InfoContent)
+
+	var fields []FieldError
+	contactType, known := r[contact.InfoType]
+	switch {
+	case contact.InfoType == "":
+		fields = append(fields, FieldError{prefix + "info_type", "is required"})
+	case !known:
+		fields = append(fields, FieldError{prefix + "info_type", fmt.Sprintf("must be one of %s", r.names())})
+	}
+
+	if contact.InfoContent == "" {
+		return append(fields, FieldError{prefix + "info_content", "is required"})
+	}
+	if !known {
+		return fields
+	}
+
+	content, err := normalizeContent(contactType.Kind, contact.InfoContent, country)
+	if err != nil {
+		return append(fields, FieldError{prefix + "info_content", err.Error()})
+	}
+	if contactType.Pattern != "" {
+		if matched, err := regexp.MatchString(contactType.Pattern, content); err != nil || !matched {
+			return append(fields, FieldError{prefix + "info_content", fmt.Sprintf("is not a valid %s", contactType.DisplayName)})
+		}
+	}
+	contact.InfoContent = content
+	return fields
+}
+
+// names lists the registered type names in alphabetical order.
+func (r contactRegistry) names() string {
+	names := make([]string, 0, len(r))
+	for name := range r {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+func normalizeContent(kind, content, country string) (string, error) {
+	switch kind {
+	case ContactKindPhone:
+		return normalizePhone(content, country)
+	case ContactKindEmail:
+		return normalizeEmail(content)
+	case ContactKindURL:
+		return normalizeURL(content)
+	}
+	return content, nil
+}
+
+// normalizePhone converts a phone number to E.164. Numbers starting with + or
+// 00 are international; others are national numbers of country.
+func normalizePhone(raw, country string) (string, error) {
+	var digits strings.Builder
+	for i, r := range raw {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == '+' && i == 0:
+		case strings.ContainsRune(" -.()/", r):
+		default:
+			return "", fmt.Errorf("must be a phone number, found %q", r)
+		}
+	}
+
+	number := digits.String()
+	switch {
+	case strings.HasPrefix(raw, "+"):
+	case strings.HasPrefix(number, "00"):
+		number = number[2:]
+	default:
+		code, ok := callingCodes[strings.ToLower(strings.TrimSpace(country))]
+		if !ok {
+			return "", fmt.Errorf("must be in international format, e.g. +90 212 555 0100, as the country %q is unknown", country)
+		}
+		if code.trunk != "" {
+			number = strings.TrimPrefix(number, code.trunk)
+		}
+		number = code.code + number
+	}
+
+	// E.164 numbers have at most 15 digits and never start with 0.
+	if len(number) < 8 || len(number) > 15 || number[0] == '0' {
+		return "", fmt.Errorf("must have between 8 and 15 digits including the country code")
+	}
+	return "+" + number, nil
+}
+
+// normalizeEmail lower-cases an email address and checks its syntax. Display
+// names such as "Desk <desk@example.com>" are rejected.
+func normalizeEmail(raw string) (string, error) {
+	email := strings.ToLower(raw)
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email || address.Name != "" {
+		return "", fmt.Errorf("must be an email address")
+	}
+	at := strings.LastIndex(email, "@")
+	if domain := email[at+1:]; !strings.Contains(domain, ".") || strings.HasPrefix(domain, ".") || strings.HasSuffix(domain, ".") {
+		return "", fmt.Errorf("must be an email address with a valid domain")
+	}
+	return email, nil
+}
+
+// normalizeURL checks that the content is a web address and lower-cases its
+// scheme and host. Addresses without a scheme are assumed to use https.
+func normalizeURL(raw string) (string, error) {
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	address, err := url.Parse(raw)
+	if err != nil || (address.Scheme != "http" && address.Scheme != "https") ||
+		address.User != nil || !strings.Contains(address.Hostname(), ".") || strings.Contains(address.Host, " ") {
+		return "", fmt.Errorf("must be a web address")
+	}
+	address.Host = strings.ToLower(address.Host)
+	return address.String(), nil
+}
+
+// phoneCountry returns the country national phone numbers of the hotel belong to.
+func phoneCountry(hotel *Hotel) string {
+	if hotel != nil && hotel.Location != nil {
+		if _, ok := callingCodes[strings.ToLower(strings.TrimSpace(hotel.Location.Country))]; ok {
+			return hotel.Location.Country
+		}
+	}
+	return DefaultPhoneCountry
+}
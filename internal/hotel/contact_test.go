@@ -0,0 +1,151 @@
+package hotel
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizePhone(t *testing.T) {
+	tests := []struct {
+		raw, country, want string
+	}{
+		{"+90 (212) 555 01 00", "TR", "+902125550100"},
+		{"0212 555 01 00", "TR", "+902125550100"},
+		{"0049 30 5550100", "TR", "+49305550100"},
+		{"030 555 0100", "Germany", "+49305550100"},
+		{"06 1234 5678", "it", "+390612345678"},
+		{"(212) 555-0100", "United States", "+12125550100"},
+		{"1-212-555-0100", "US", "+12125550100"},
+	}
+	for _, tt := range tests {
+		got, err := normalizePhone(tt.raw, tt.country)
+		assert.NoError(t, err, tt.raw)
+		assert.Equal(t, tt.want, got, tt.raw)
+	}
+
+	for _, raw := range []string{"555-CALL-NOW", "+90 12", "+1234567890123456", "12+34"} {
+		_, err := normalizePhone(raw, "TR")
+		assert.Error(t, err, raw)
+	}
+
+	// National numbers of unknown countries cannot be completed
+	_, err := normalizePhone("0212 555 01 00", "Atlantis")
+	assert.Error(t, err)
+}
+
+func TestNormalizeEmail(t *testing.T) {
+	email, err := normalizeEmail("Reservations@Grand-Hotel.COM")
+	assert.NoError(t, err)
+	assert.Equal(t, "reservations@grand-hotel.com", email)
+
+	for _, raw := range []string{"not-an-email", "desk@localhost", "Desk <desk@example.com>", "a b@example.com", "desk@example."} {
+		_, err := normalizeEmail(raw)
+		assert.Error(t, err, raw)
+	}
+}
+
+func TestValidateContact(t *testing.T) {
+	registry := newContactRegistry(DefaultContactTypes)
+	contact := ContactInfo{InfoType: " Phone ", InfoContent: " 0532 123 45 67 "}
+	assert.NoError(t, registry.validateContact(&contact, &Hotel{}))
+	assert.Equal(t, ContactTypePhone, contact.InfoType)
+	assert.Equal(t, "+905321234567", contact.InfoContent)
+
+	// Location contacts are free text
+	contact = ContactInfo{InfoType: ContactTypeLocation, InfoContent: "Beyoğlu, Istanbul"}
+	assert.NoError(t, registry.validateContact(&contact, &Hotel{}))
+
+	err := registry.validateContact(&ContactInfo{}, &Hotel{})
+	var validationErr *ValidationError
+	if assert.ErrorAs(t, err, &validationErr) {
+		assert.Equal(t, []FieldError{
+			{Field: "info_type", Message: "is required"},
+			{Field: "info_content", Message: "is required"},
+		}, validationErr.Fields)
+	}
+}
+
+func TestPhoneCountry(t *testing.T) {
+	assert.Equal(t, DefaultPhoneCountry, phoneCountry(&Hotel{}))
+	assert.Equal(t, "Germany", phoneCountry(&Hotel{Location: &Location{Country: "Germany"}}))
+	assert.Equal(t, DefaultPhoneCountry, phoneCountry(&Hotel{Location: &Location{Country: "Atlantis"}}))
+}
+
+func TestContactRegistry_CustomTypes(t *testing.T) {
+	registry := newContactRegistry(append([]ContactType{
+		{Name: "website", DisplayName: "Website", Kind: ContactKindURL, UniquePerHotel: true},
+		{Name: "instagram", DisplayName: "Instagram handle", Kind: ContactKindText, Pattern: `^@[a-z0-9._]{1,30}$`},
+		{Name: "whatsapp", DisplayName: "WhatsApp", Kind: ContactKindPhone, CountsInStats: true},
+	}, DefaultContactTypes...))
+
+	// URLs get a scheme and a lower-case host
+	contact := ContactInfo{InfoType: "website", InfoContent: "Grand-Hotel.example/Rooms"}
+	assert.NoError(t, registry.validateContact(&contact, &Hotel{}))
+	assert.Equal(t, "https://grand-hotel.example/Rooms", contact.InfoContent)
+
+	// Unique types cannot be added twice to the same hotel
+	existing := ContactInfo{ID: uuid.New(), InfoType: "website", InfoContent: "https://grand-hotel.example"}
+	contact = ContactInfo{InfoType: "website", InfoContent: "https://other.example"}
+	err := registry.validateContact(&contact, &Hotel{ContactInfos: []ContactInfo{existing}})
+	assert.ErrorIs(t, err, ErrInvalidContact)
+
+	// ... but the existing contact itself can be changed
+	existing.InfoContent = "https://grand-hotel.example/en"
+	assert.NoError(t, registry.validateContact(&existing, &Hotel{ContactInfos: []ContactInfo{existing}}))
+
+	// Patterns are checked after normalization
+	contact = ContactInfo{InfoType: "instagram", InfoContent: "grand hotel"}
+	err = registry.validateContact(&contact, &Hotel{})
+	var validationErr *ValidationError
+	if assert.ErrorAs(t, err, &validationErr) {
+		assert.Equal(t, "is not a valid Instagram handle", validationErr.Fields[0].Message)
+	}
+
+	err = registry.validateContacts([]ContactInfo{
+		{InfoType: "website", InfoContent: "a.example"},
+		{InfoType: "website", InfoContent: "b.example"},
+	}, DefaultPhoneCountry)
+	if assert.ErrorAs(t, err, &validationErr) {
+		assert.Equal(t, "contacts[1].info_type", validationErr.Fields[0].Field)
+	}
+
+	assert.Equal(t, map[string]bool{ContactTypePhone: true, "whatsapp": true}, registry.countedInStats())
+}
+
+func TestValidateContactType(t *testing.T) {
+	valid := ContactType{Name: "emergency_line", DisplayName: " Emergency line ", Kind: ContactKindPhone}
+	assert.NoError(t, validateContactType(&valid))
+	assert.Equal(t, "Emergency line", valid.DisplayName)
+
+	for _, contactType := range []ContactType{
+		{Name: "Emergency Line", DisplayName: "Emergency line", Kind: ContactKindPhone},
+		{Name: "emergency", Kind: ContactKindPhone},
+		{Name: "emergency", DisplayName: "Emergency line", Kind: "pager"},
+		{Name: "emergency", DisplayName: "Emergency line", Kind: ContactKindText, Pattern: "(["},
+	} {
+		assert.ErrorIs(t, validateContactType(&contactType), ErrInvalidContactType, contactType.Name)
+	}
+}
+
+func TestNormalizeURL(t *testing.T) {
+	address, err := normalizeURL("HTTP://Example.COM/Path?q=1")
+	assert.NoError(t, err)
+	assert.Equal(t, "http://example.com/Path?q=1", address)
+
+	for _, raw := range []string{"ftp://example.com", "localhost", "user@example.com", "exa mple.com"} {
+		_, err := normalizeURL(raw)
+		assert.Error(t, err, raw)
+	}
+}
+
+func TestContactRegistry_PhoneTypes(t *testing.T) {
+	registry := newContactRegistry(append([]ContactType{
+		{Name: "whatsapp", DisplayName: "WhatsApp", Kind: ContactKindPhone, CountsInStats: true},
+		{Name: "booking_page", DisplayName: "Booking page", Kind: ContactKindURL, CountsInStats: true},
+	}, DefaultContactTypes...))
+
+	// Only phone types that count toward the stats are counted as phones
+	assert.Equal(t, []string{ContactTypePhone, "whatsapp"}, registry.phoneTypes())
+}
@@ -0,0 +1,158 @@
+package hotel
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// Formats of the hotel export.
+const (
+	ExportFormatCSV    = "csv"
+	ExportFormatNDJSON = "ndjson"
+	ExportFormatJSON   = "json"
+)
+
+var ErrInvalidExportFormat = errors.New("export format must be csv, ndjson or json")
+
+// exportWriter writes exported hotels one at a time.
+type exportWriter interface {
+	write(hotel *Hotel) error
+	close() error
+}
+
+// newExportWriter returns a writer for the format. contactColumns gives the
+// number of CSV columns of each contact type; other formats ignore it.
+func newExportWriter(w io.Writer, format string, contactColumns map[string]int) (exportWriter, error) {
+	switch format {
+	case ExportFormatCSV:
+		return newCSVExportWriter(w, contactColumns)
+	case ExportFormatNDJSON:
+		return &ndjsonExportWriter{encoder: json.NewEncoder(w)}, nil
+	case ExportFormatJSON:
+		return &jsonExportWriter{w: w}, nil
+	}
+	return nil, ErrInvalidExportFormat
+}
+
+// csvExportColumns are the hotel and location columns of a CSV export. The
+// contact columns follow, in the "contact:<type>" form read by ParseImport.
+var csvExportColumns = []string{
+	"id", "owner_name", "owner_surname", "company_title", "version",
+	"country", "city", "district", "postal_code", "street", "latitude", "longitude",
+}
+
+type csvExportWriter struct {
+	writer         *csv.Writer
+	contactColumns map[string]int
+	// firstColumn maps a contact type to the index of its first column.
+	firstColumn map[string]int
+	columns     int
+}
+
+func newCSVExportWriter(w io.Writer, contactColumns map[string]int) (*csvExportWriter, error) {
+	contactTypes := make([]string, 0, len(contactColumns))
+	for infoType := range contactColumns {
+		contactTypes = append(contactTypes, infoType)
+	}
+	sort.Strings(contactTypes)
+
+	header := append([]string{}, csvExportColumns...)
+	firstColumn := make(map[string]int)
+	for _, infoType := range contactTypes {
+		firstColumn[infoType] = len(header)
+		for i := 0; i < contactColumns[infoType]; i++ {
+			header = append(header, csvContactPrefix+infoType)
+		}
+	}
+
+	writer := &csvExportWriter{
+		writer:         csv.NewWriter(w),
+		contactColumns: contactColumns,
+		firstColumn:    firstColumn,
+		columns:        len(header),
+	}
+	return writer, writer.writer.Write(header)
+}
+
+func (c *csvExportWriter) write(hotel *Hotel) error {
+	record := make([]string, c.columns)
+	copy(record, []string{
+		hotel.ID.String(), hotel.OwnerName, hotel.OwnerSurname, hotel.CompanyTitle, strconv.Itoa(hotel.Version),
+	})
+	if location := hotel.Location; location != nil {
+		copy(record[5:], []string{
+			location.Country, location.City, location.District, location.PostalCode, location.Street,
+			formatCoordinate(location.Latitude), formatCoordinate(location.Longitude),
+		})
+	}
+
+	// Contacts fill the columns of their type in order. Contacts added after
+	// the header was written may find no column left and are left out.
+	used := make(map[string]int)
+	for _, contact := range hotel.ContactInfos {
+		if used[contact.InfoType] < c.contactColumns[contact.InfoType] {
+			record[c.firstColumn[contact.InfoType]+used[contact.InfoType]] = contact.InfoContent
+			used[contact.InfoType]++
+		}
+	}
+	return c.writer.Write(record)
+}
+
+func (c *csvExportWriter) close() error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+func formatCoordinate(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*value, 'f', -1, 64)
+}
+
+type ndjsonExportWriter struct {
+	encoder *json.Encoder
+}
+
+func (n *ndjsonExportWriter) write(hotel *Hotel) error {
+	return n.encoder.Encode(hotel)
+}
+
+func (n *ndjsonExportWriter) close() error {
+	return nil
+}
+
+// jsonExportWriter writes the hotels as a single JSON array.
+type jsonExportWriter struct {
+	w       io.Writer
+	started bool
+}
+
+func (j *jsonExportWriter) write(hotel *Hotel) error {
+	separator := ","
+	if !j.started {
+		separator, j.started = "[", true
+	}
+	data, err := json.Marshal(hotel)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(j.w, separator); err != nil {
+		return err
+	}
+	_, err = j.w.Write(data)
+	return err
+}
+
+func (j *jsonExportWriter) close() error {
+	if !j.started {
+		_, err := io.WriteString(j.w, "[]\n")
+		return err
+	}
+	_, err := io.WriteString(j.w, "]\n")
+	return err
+}
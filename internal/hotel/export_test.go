@@ -0,0 +1,80 @@
+package hotel
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func exportTestHotels() []Hotel {
+	latitude, longitude := 41.04, 29.0
+	return []Hotel{
+		{
+			ID: uuid.New(), OwnerName: "John", OwnerSurname: "Doe", CompanyTitle: "Doe Ltd.", Version: 2,
+			Location: &Location{Country: "Turkey", City: "Istanbul", Latitude: &latitude, Longitude: &longitude},
+			ContactInfos: []ContactInfo{
+				{InfoType: ContactTypePhone, InfoContent: "+902125550100"},
+				{InfoType: ContactTypeEmail, InfoContent: "desk@doe.example"},
+				{InfoType: ContactTypePhone, InfoContent: "+902125550101"},
+			},
+		},
+		{ID: uuid.New(), OwnerName: "Jane", OwnerSurname: "Smith", CompanyTitle: "Smith Ltd.", Version: 1},
+	}
+}
+
+func writeExport(t *testing.T, format string, contactColumns map[string]int, hotels []Hotel) string {
+	var buf bytes.Buffer
+	writer, err := newExportWriter(&buf, format, contactColumns)
+	assert.NoError(t, err)
+	for i := range hotels {
+		assert.NoError(t, writer.write(&hotels[i]))
+	}
+	assert.NoError(t, writer.close())
+	return buf.String()
+}
+
+func TestExportWriter_CSV(t *testing.T) {
+	hotels := exportTestHotels()
+	file := writeExport(t, ExportFormatCSV, map[string]int{ContactTypePhone: 2, ContactTypeEmail: 1}, hotels)
+
+	lines := strings.Split(strings.TrimSpace(file), "\n")
+	if assert.Len(t, lines, 3) {
+		assert.Equal(t, "id,owner_name,owner_surname,company_title,version,country,city,district,postal_code,street,latitude,longitude,"+
+			"contact:email,contact:phone,contact:phone", lines[0])
+		assert.Equal(t, hotels[0].ID.String()+",John,Doe,Doe Ltd.,2,Turkey,Istanbul,,,,41.04,29,desk@doe.example,+902125550100,+902125550101", lines[1])
+		assert.Equal(t, hotels[1].ID.String()+",Jane,Smith,Smith Ltd.,1,,,,,,,,,,", lines[2])
+	}
+
+	// The export can be imported again
+	rows, err := ParseImport(strings.NewReader(file), ImportFormatCSV)
+	assert.NoError(t, err)
+	if assert.Len(t, rows, 2) {
+		assert.Equal(t, "Doe Ltd.", rows[0].CompanyTitle)
+		assert.Len(t, rows[0].Contacts, 3)
+	}
+}
+
+func TestExportWriter_JSON(t *testing.T) {
+	hotels := exportTestHotels()
+
+	var exported []Hotel
+	assert.NoError(t, json.Unmarshal([]byte(writeExport(t, ExportFormatJSON, nil, hotels)), &exported))
+	assert.Len(t, exported, 2)
+
+	// An empty export is an empty array
+	assert.Equal(t, "[]\n", writeExport(t, ExportFormatJSON, nil, nil))
+
+	lines := strings.Split(strings.TrimSpace(writeExport(t, ExportFormatNDJSON, nil, hotels)), "\n")
+	if assert.Len(t, lines, 2) {
+		var hotel Hotel
+		assert.NoError(t, json.Unmarshal([]byte(lines[1]), &hotel))
+		assert.Equal(t, hotels[1].ID, hotel.ID)
+	}
+
+	_, err := newExportWriter(&bytes.Buffer{}, "xml", nil)
+	assert.ErrorIs(t, err, ErrInvalidExportFormat)
+}
@@ -0,0 +1,108 @@
+package hotel
+
+import "math"
+
+const earthRadiusKm = 6371.0
+
+// MaxRadiusKm is the largest radius of a nearby search, and MaxBoxSpan the most
+// degrees of latitude or longitude a bounding box may span. They bound the
+// hotels a geo search loads. A geo search returns DefaultGeoLimit hotels
+// unless asked for more, and at most MaxGeoLimit.
+const (
+	MaxRadiusKm     = 500.0
+	MaxBoxSpan      = 10.0
+	DefaultGeoLimit = 20
+	MaxGeoLimit     = 100
+)
+
+// BoundingBox is a latitude/longitude rectangle. MinLng is greater than
+// MaxLng when the box crosses the antimeridian.
+type BoundingBox struct {
+	MinLat float64
+	MinLng float64
+	MaxLat float64
+	MaxLng float64
+}
+
+// HotelDistance is a hotel found by a geo search together with its distance
+// from the search origin.
+type HotelDistance struct {
+	Hotel
+	DistanceKm float64 `json:"distance_km"`
+}
+
+// Valid reports whether the box corners are valid coordinates.
+func (b BoundingBox) Valid() bool {
+	return validCoordinates(b.MinLat, b.MinLng) && validCoordinates(b.MaxLat, b.MaxLng) && b.MinLat <= b.MaxLat
+}
+
+// Spans returns the degrees of latitude and longitude the box covers.
+func (b BoundingBox) Spans() (float64, float64) {
+	lngSpan := b.MaxLng - b.MinLng
+	if b.MinLng > b.MaxLng {
+		lngSpan += 360
+	}
+	return b.MaxLat - b.MinLat, lngSpan
+}
+
+// Center returns the middle of the box, taking antimeridian crossing into account.
+func (b BoundingBox) Center() (float64, float64) {
+	lat := (b.MinLat + b.MaxLat) / 2
+	maxLng := b.MaxLng
+	if b.MinLng > b.MaxLng {
+		maxLng += 360
+	}
+	lng := (b.MinLng + maxLng) / 2
+	if lng > 180 {
+		lng -= 360
+	}
+	return lat, lng
+}
+
+// boundingBoxAround returns the smallest box containing every point within
+// radiusKm of the origin. It is used as a cheap SQL prefilter before the exact
+// distance check.
+func boundingBoxAround(lat, lng, radiusKm float64) BoundingBox {
+	deltaLat := radiusKm / earthRadiusKm * 180 / math.Pi
+	box := BoundingBox{MinLat: lat - deltaLat, MaxLat: lat + deltaLat, MinLng: -180, MaxLng: 180}
+	if box.MinLat <= -90 || box.MaxLat >= 90 {
+		// The circle contains a pole, so every longitude is in range
+		box.MinLat = math.Max(box.MinLat, -90)
+		box.MaxLat = math.Min(box.MaxLat, 90)
+		return box
+	}
+
+	ratio := math.Sin(math.Min(radiusKm/earthRadiusKm, math.Pi/2)) / math.Cos(lat*math.Pi/180)
+	if ratio >= 1 {
+		return box
+	}
+
+	deltaLng := math.Asin(ratio) * 180 / math.Pi
+	box.MinLng = normalizeLongitude(lng - deltaLng)
+	box.MaxLng = normalizeLongitude(lng + deltaLng)
+	return box
+}
+
+// haversineKm returns the great-circle distance between two points in kilometers.
+func haversineKm(lat1, lng1, lat2, lng2 float64) float64 {
+	toRad := math.Pi / 180
+	dLat := (lat2 - lat1) * toRad
+	dLng := (lng2 - lng1) * toRad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*toRad)*math.Cos(lat2*toRad)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+func normalizeLongitude(lng float64) float64 {
+	for lng > 180 {
+		lng -= 360
+	}
+	for lng < -180 {
+		lng += 360
+	}
+	return lng
+}
+
+func validCoordinates(lat, lng float64) bool {
+	return lat >= -90 && lat <= 90 && lng >= -180 && lng <= 180
+}
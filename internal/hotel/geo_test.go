@@ -0,0 +1,46 @@
+package hotel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHaversineKm(t *testing.T) {
+	// Istanbul to Ankara is roughly 350 km as the crow flies
+	distance := haversineKm(41.0082, 28.9784, 39.9334, 32.8597)
+	assert.InDelta(t, 350, distance, 5)
+
+	// The distance from a point to itself is zero
+	assert.Equal(t, 0.0, haversineKm(52.52, 13.405, 52.52, 13.405))
+}
+
+func TestBoundingBoxAround(t *testing.T) {
+	box := boundingBoxAround(41.0, 29.0, 10)
+	assert.True(t, box.Valid())
+	assert.Less(t, box.MinLat, 41.0)
+	assert.Greater(t, box.MaxLat, 41.0)
+	assert.Less(t, box.MinLng, 29.0)
+	assert.Greater(t, box.MaxLng, 29.0)
+
+	// Every corner of the box is at least the radius away from the origin
+	assert.GreaterOrEqual(t, haversineKm(41.0, 29.0, box.MaxLat, 29.0), 9.99)
+	assert.GreaterOrEqual(t, haversineKm(41.0, 29.0, 41.0, box.MaxLng), 9.99)
+}
+
+func TestBoundingBoxAround_Antimeridian(t *testing.T) {
+	box := boundingBoxAround(-17.7, 179.9, 50)
+	assert.True(t, box.Valid())
+	assert.Greater(t, box.MinLng, box.MaxLng)
+
+	lat, lng := box.Center()
+	assert.InDelta(t, -17.7, lat, 0.001)
+	assert.InDelta(t, 179.9, lng, 0.001)
+}
+
+func TestBoundingBoxAround_Pole(t *testing.T) {
+	box := boundingBoxAround(89.9, 0, 50)
+	assert.Equal(t, 90.0, box.MaxLat)
+	assert.Equal(t, -180.0, box.MinLng)
+	assert.Equal(t, 180.0, box.MaxLng)
+}
@@ -2,8 +2,18 @@ package hotel
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"hotel-guide/internal/money"
+	"io"
+	"log"
+	"mime"
 	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
@@ -21,14 +31,86 @@ func NewHandler(service HotelService) *Handler {
 
 // RegisterRoutes registers report-related routes
 func (h *Handler) RegisterRoutes(r *mux.Router) {
+	r.Use(auditMiddleware)
 	r.HandleFunc("/hotels/stats", h.GetHotelStats).Methods("GET")
+	r.HandleFunc("/hotels/stats:batch", h.BatchHotelStats).Methods("POST")
+	r.HandleFunc("/hotels/stats/locations", h.ListLocationStats).Methods("GET")
 	r.HandleFunc("/hotels", h.CreateHotel).Methods("POST")
+	r.HandleFunc("/hotels/import", h.ImportHotels).Methods("POST")
+	r.HandleFunc("/hotels/import/{jobID}", h.GetImportJob).Methods("GET")
+	r.HandleFunc("/hotels/export", h.ExportHotels).Methods("GET")
 	r.HandleFunc("/hotels/{id}", h.DeleteHotel).Methods("DELETE")
+	r.HandleFunc("/hotels/{id}/restore", h.RestoreHotel).Methods("POST")
+	r.HandleFunc("/hotels/{id}", h.ReplaceHotel).Methods("PUT")
+	r.HandleFunc("/hotels/{id}", h.PatchHotel).Methods("PATCH")
 	r.HandleFunc("/hotels", h.ListHotels).Methods("GET")
 	r.HandleFunc("/hotels/{hotelID}/contacts", h.AddContactInfo).Methods("POST")
 	r.HandleFunc("/hotels/{hotelID}/contacts/{contactID}", h.RemoveContactInfo).Methods("DELETE")
+	r.HandleFunc("/hotels/{hotelID}/contacts/{contactID}", h.PatchContactInfo).Methods("PATCH")
+	r.HandleFunc("/hotels/{hotelID}/location", h.SetLocation).Methods("PUT")
+	r.HandleFunc("/hotels/{hotelID}/location", h.DeleteLocation).Methods("DELETE")
+	r.HandleFunc("/hotels/{hotelID}/tags", h.SetHotelTags).Methods("PUT")
+	r.HandleFunc("/hotels/{hotelID}/chain", h.SetHotelChain).Methods("PUT")
+	r.HandleFunc("/hotels/{hotelID}/chain", h.RemoveHotelChain).Methods("DELETE")
+	r.HandleFunc("/hotels/{hotelID}/translations", h.ListTranslations).Methods("GET")
+	r.HandleFunc("/hotels/{hotelID}/translations/{language}", h.SaveTranslation).Methods("PUT")
+	r.HandleFunc("/hotels/{hotelID}/translations/{language}", h.RemoveTranslation).Methods("DELETE")
+	r.HandleFunc("/hotels/{hotelID}/media", h.ListMedia).Methods("GET")
+	r.HandleFunc("/hotels/{hotelID}/media", h.AddMedia).Methods("POST")
+	r.HandleFunc("/hotels/{hotelID}/media/order", h.ReorderMedia).Methods("PUT")
+	r.HandleFunc("/hotels/{hotelID}/media/{mediaID}/content", h.GetMediaContent).Methods("GET")
+	r.HandleFunc("/hotels/{hotelID}/media/{mediaID}/thumbnail", h.GetMediaThumbnail).Methods("GET")
+	r.HandleFunc("/hotels/{hotelID}/media/{mediaID}", h.RemoveMedia).Methods("DELETE")
+	r.HandleFunc("/hotels/{hotelID}/officials", h.AddOfficial).Methods("POST")
+	r.HandleFunc("/hotels/{hotelID}/rooms", h.ListRoomTypes).Methods("GET")
+	r.HandleFunc("/hotels/{hotelID}/rooms", h.AddRoomType).Methods("POST")
+	r.HandleFunc("/hotels/{hotelID}/rooms/{roomTypeID}", h.GetRoomType).Methods("GET")
+	r.HandleFunc("/hotels/{hotelID}/rooms/{roomTypeID}", h.UpdateRoomType).Methods("PUT")
+	r.HandleFunc("/hotels/{hotelID}/rooms/{roomTypeID}", h.RemoveRoomType).Methods("DELETE")
+	r.HandleFunc("/hotels/{hotelID}/availability", h.GetAvailability).Methods("GET")
+	r.HandleFunc("/hotels/{hotelID}/rate-plans", h.ListRatePlans).Methods("GET")
+	r.HandleFunc("/hotels/{hotelID}/rate-plans", h.AddRatePlan).Methods("POST")
+	r.HandleFunc("/hotels/{hotelID}/rate-plans/{ratePlanID}", h.GetRatePlan).Methods("GET")
+	r.HandleFunc("/hotels/{hotelID}/rate-plans/{ratePlanID}", h.UpdateRatePlan).Methods("PUT")
+	r.HandleFunc("/hotels/{hotelID}/rate-plans/{ratePlanID}", h.RemoveRatePlan).Methods("DELETE")
+	r.HandleFunc("/hotels/{hotelID}/quote", h.QuoteStay).Methods("GET")
+	r.HandleFunc("/hotels/{hotelID}/reviews", h.ListReviews).Methods("GET")
+	r.HandleFunc("/hotels/{hotelID}/reviews", h.AddReview).Methods("POST")
+	r.HandleFunc("/hotels/{hotelID}/reviews/{reviewID}", h.ModerateReview).Methods("PATCH")
+	r.HandleFunc("/hotels/{hotelID}/reviews/{reviewID}", h.RemoveReview).Methods("DELETE")
+	r.HandleFunc("/hotels/{hotelID}/reservations", h.CreateReservation).Methods("POST")
+	r.HandleFunc("/hotels/{hotelID}/reservations/{reservationID}", h.GetReservation).Methods("GET")
+	r.HandleFunc("/hotels/{hotelID}/reservations/{reservationID}/confirm", h.ConfirmReservation).Methods("POST")
+	r.HandleFunc("/hotels/{hotelID}/reservations/{reservationID}", h.CancelReservation).Methods("DELETE")
+	r.HandleFunc("/hotels/{hotelID}/officials/{officialID}", h.UpdateOfficial).Methods("PUT")
+	r.HandleFunc("/hotels/{hotelID}/officials/{officialID}", h.RemoveOfficial).Methods("DELETE")
 	r.HandleFunc("/hotels/officials", h.ListHotelOfficials).Methods("GET")
+	r.HandleFunc("/hotels/translations/missing", h.ListMissingTranslations).Methods("GET")
+	r.HandleFunc("/hotels/nearby", h.ListNearbyHotels).Methods("GET")
+	r.HandleFunc("/hotels/search", h.SearchHotels).Methods("GET")
 	r.HandleFunc("/hotels/{hotelID}", h.GetHotelDetails).Methods("GET")
+	r.HandleFunc("/hotels/{hotelID}/history", h.GetHotelHistory).Methods("GET")
+	r.HandleFunc("/audit", h.ListAuditEntries).Methods("GET")
+	r.HandleFunc("/contact-types", h.ListContactTypes).Methods("GET")
+	r.HandleFunc("/contact-types", h.CreateContactType).Methods("POST")
+	r.HandleFunc("/contact-types/{name}", h.GetContactType).Methods("GET")
+	r.HandleFunc("/contact-types/{name}", h.UpdateContactType).Methods("PUT")
+	r.HandleFunc("/contact-types/{name}", h.DeleteContactType).Methods("DELETE")
+	r.HandleFunc("/tags", h.ListTags).Methods("GET")
+	r.HandleFunc("/tags", h.CreateTag).Methods("POST")
+	r.HandleFunc("/tags/{name}", h.GetTag).Methods("GET")
+	r.HandleFunc("/tags/{name}", h.UpdateTag).Methods("PUT")
+	r.HandleFunc("/tags/{name}", h.DeleteTag).Methods("DELETE")
+	r.HandleFunc("/chains", h.ListChains).Methods("GET")
+	r.HandleFunc("/chains", h.CreateChain).Methods("POST")
+	r.HandleFunc("/chains/{chainID}", h.GetChain).Methods("GET")
+	r.HandleFunc("/chains/{chainID}", h.UpdateChain).Methods("PUT")
+	r.HandleFunc("/chains/{chainID}", h.DeleteChain).Methods("DELETE")
+	r.HandleFunc("/chains/{chainID}/hotels", h.ListChainHotels).Methods("GET")
+	r.HandleFunc("/chains/{chainID}/stats", h.GetChainStats).Methods("GET")
+	r.HandleFunc("/exchange-rates", h.ListExchangeRates).Methods("GET")
+	r.HandleFunc("/exchange-rates/{base}/{quote}/{date}", h.SaveExchangeRate).Methods("PUT")
+	r.HandleFunc("/exchange-rates/{base}/{quote}/{date}", h.RemoveExchangeRate).Methods("DELETE")
 }
 
 func (h *Handler) CreateHotel(w http.ResponseWriter, r *http.Request) {
@@ -44,9 +126,9 @@ func (h *Handler) CreateHotel(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	hotel, err := h.hotelService.CreateHotel(request.OwnerName, request.OwnerSurname, request.CompanyTitle, request.Contacts)
+	hotel, err := h.hotelService.CreateHotel(r.Context(), request.OwnerName, request.OwnerSurname, request.CompanyTitle, request.Contacts)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, r, err)
 		return
 	}
 
@@ -54,6 +136,166 @@ func (h *Handler) CreateHotel(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(hotel)
 }
 
+// ImportHotels creates the hotels of a CSV or NDJSON file. Files with more than
+// SyncImportLimit rows, or any file with async=true, are imported by a job
+// that is polled at the URL in the Location header.
+func (h *Handler) ImportHotels(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	var opts ImportOptions
+	var async bool
+	for name, target := range map[string]*bool{"dry_run": &opts.DryRun, "async": &async} {
+		if value := query.Get(name); value != "" {
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("%s parameter must be true or false", name), http.StatusBadRequest)
+				return
+			}
+			*target = parsed
+		}
+	}
+	if value := query.Get("batch_size"); value != "" {
+		batchSize, err := strconv.Atoi(value)
+		if err != nil || batchSize < 0 {
+			http.Error(w, "batch_size parameter must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+		opts.BatchSize = batchSize
+	}
+
+	// The file is spooled to disk, so that a job reads it one batch at a time.
+	file, err := SpoolImport(http.MaxBytesReader(w, r.Body, MaxImportSize), importFormat(r))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, fmt.Sprintf("import file must not exceed %d bytes", MaxImportSize), http.StatusRequestEntityTooLarge)
+			return
+		}
+		writeServiceError(w, r, err)
+		return
+	}
+
+	if async || file.Len() > SyncImportLimit {
+		job, err := h.hotelService.StartImportJob(r.Context(), file, opts)
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Location", "/hotels/import/"+job.ID.String())
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(job)
+		return
+	}
+
+	defer file.Close()
+	rows, err := file.Rows()
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	result, err := h.hotelService.ImportHotels(r.Context(), rows, opts)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	status := http.StatusOK
+	if opts.BatchSize == 0 && result.Failed > 0 {
+		// Nothing was stored because of the invalid rows.
+		status = http.StatusUnprocessableEntity
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(result)
+}
+
+// importFormat returns the format given by the format parameter or, without
+// one, by the Content-Type header. CSV is assumed when neither names a format.
+func importFormat(r *http.Request) string {
+	if format := strings.ToLower(r.URL.Query().Get("format")); format != "" {
+		return format
+	}
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/x-ndjson", "application/ndjson", "application/jsonl":
+		return ImportFormatNDJSON
+	}
+	return ImportFormatCSV
+}
+
+func (h *Handler) GetImportJob(w http.ResponseWriter, r *http.Request) {
+	jobID, err := uuid.Parse(mux.Vars(r)["jobID"])
+	if err != nil {
+		http.Error(w, "Invalid import job ID", http.StatusBadRequest)
+		return
+	}
+
+	job, err := h.hotelService.GetImportJob(jobID)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job)
+}
+
+// exportContentTypes maps the export formats to their media types.
+var exportContentTypes = map[string]string{
+	ExportFormatCSV:    "text/csv; charset=utf-8",
+	ExportFormatNDJSON: "application/x-ndjson",
+	ExportFormatJSON:   "application/json",
+}
+
+// ExportHotels streams every hotel matching the filters of GET /hotels as CSV,
+// NDJSON or a JSON array. Pagination parameters are ignored.
+func (h *Handler) ExportHotels(w http.ResponseWriter, r *http.Request) {
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	if format == "" {
+		format = ExportFormatCSV
+	}
+	contentType, ok := exportContentTypes[format]
+	if !ok {
+		http.Error(w, ErrInvalidExportFormat.Error(), http.StatusBadRequest)
+		return
+	}
+
+	opts, err := parseListOptions(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	export := &exportResponseWriter{ResponseWriter: w, contentType: contentType, filename: "hotels." + format}
+	if err := h.hotelService.ExportHotels(export, format, opts); err != nil {
+		if !export.started {
+			writeServiceError(w, r, err)
+			return
+		}
+		// The status has been sent; the client is left with a truncated file.
+		log.Printf("Export of hotels failed: %v", err)
+	}
+}
+
+// exportResponseWriter sends the export headers with the first write, so that
+// errors occurring before any hotel was exported still get an error response.
+type exportResponseWriter struct {
+	http.ResponseWriter
+	contentType string
+	filename    string
+	started     bool
+}
+
+func (e *exportResponseWriter) Write(data []byte) (int, error) {
+	if !e.started {
+		e.started = true
+		e.Header().Set("Content-Type", e.contentType)
+		e.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", e.filename))
+		e.WriteHeader(http.StatusOK)
+	}
+	return e.ResponseWriter.Write(data)
+}
+
 func (h *Handler) DeleteHotel(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	hotelID, err := uuid.Parse(vars["id"])
@@ -62,14 +304,111 @@ func (h *Handler) DeleteHotel(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.hotelService.DeleteHotel(hotelID); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	version, err := parseIfMatch(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.hotelService.DeleteHotel(r.Context(), hotelID, version); err != nil {
+		writeServiceError(w, r, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// RestoreHotel undoes the soft delete of a hotel that has not been purged yet.
+func (h *Handler) RestoreHotel(w http.ResponseWriter, r *http.Request) {
+	hotelID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid hotel ID", http.StatusBadRequest)
+		return
+	}
+
+	hotel, err := h.hotelService.RestoreHotel(r.Context(), hotelID)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", hotelETag(hotel, nil))
+	json.NewEncoder(w).Encode(hotel)
+}
+
+// ReplaceHotel handles PUT requests; every editable field must be present.
+func (h *Handler) ReplaceHotel(w http.ResponseWriter, r *http.Request) {
+	hotelID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid hotel ID", http.StatusBadRequest)
+		return
+	}
+
+	version, err := parseIfMatch(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var request struct {
+		OwnerName    string `json:"owner_name"`
+		OwnerSurname string `json:"owner_surname"`
+		CompanyTitle string `json:"company_title"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	update := HotelUpdate{
+		OwnerName:    &request.OwnerName,
+		OwnerSurname: &request.OwnerSurname,
+		CompanyTitle: &request.CompanyTitle,
+	}
+	h.updateHotel(w, r, hotelID, update, version)
+}
+
+// PatchHotel handles JSON Merge Patch requests for a hotel.
+func (h *Handler) PatchHotel(w http.ResponseWriter, r *http.Request) {
+	hotelID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid hotel ID", http.StatusBadRequest)
+		return
+	}
+
+	version, err := parseIfMatch(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var update HotelUpdate
+	err = decodeMergePatch(r.Body, map[string]**string{
+		"owner_name":    &update.OwnerName,
+		"owner_surname": &update.OwnerSurname,
+		"company_title": &update.CompanyTitle,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.updateHotel(w, r, hotelID, update, version)
+}
+
+func (h *Handler) updateHotel(w http.ResponseWriter, r *http.Request, hotelID uuid.UUID, update HotelUpdate, version int) {
+	hotel, err := h.hotelService.UpdateHotel(r.Context(), hotelID, update, version)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", hotelETag(hotel, nil))
+	json.NewEncoder(w).Encode(hotel)
+}
+
 func (h *Handler) AddContactInfo(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	hotelID, err := uuid.Parse(vars["hotelID"])
@@ -78,14 +417,20 @@ func (h *Handler) AddContactInfo(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	version, err := parseIfMatch(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	var contact ContactInfo
 	if err := json.NewDecoder(r.Body).Decode(&contact); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	if err := h.hotelService.AddContactInfo(hotelID, &contact); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := h.hotelService.AddContactInfo(r.Context(), hotelID, &contact, version); err != nil {
+		writeServiceError(w, r, err)
 		return
 	}
 
@@ -107,82 +452,2232 @@ func (h *Handler) RemoveContactInfo(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.hotelService.RemoveContactInfo(hotelID, contactID); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	version, err := parseIfMatch(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.hotelService.RemoveContactInfo(r.Context(), hotelID, contactID, version); err != nil {
+		writeServiceError(w, r, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
-func (h *Handler) ListHotels(w http.ResponseWriter, r *http.Request) {
-	hotels, err := h.hotelService.ListHotels()
+// PatchContactInfo handles JSON Merge Patch requests for a hotel contact info.
+func (h *Handler) PatchContactInfo(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	hotelID, err := uuid.Parse(vars["hotelID"])
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, "Invalid hotel ID", http.StatusBadRequest)
+		return
+	}
+
+	contactID, err := uuid.Parse(vars["contactID"])
+	if err != nil {
+		http.Error(w, "Invalid contact ID", http.StatusBadRequest)
+		return
+	}
+
+	version, err := parseIfMatch(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var update ContactInfoUpdate
+	err = decodeMergePatch(r.Body, map[string]**string{
+		"info_type":    &update.InfoType,
+		"info_content": &update.InfoContent,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	contact, err := h.hotelService.UpdateContactInfo(r.Context(), hotelID, contactID, update, version)
+	if err != nil {
+		writeServiceError(w, r, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(hotels)
+	json.NewEncoder(w).Encode(contact)
 }
 
-func (h *Handler) ListHotelOfficials(w http.ResponseWriter, r *http.Request) {
-	officials, err := h.hotelService.ListHotelOfficials()
+// SetLocation creates or replaces the structured location of a hotel.
+func (h *Handler) SetLocation(w http.ResponseWriter, r *http.Request) {
+	hotelID, err := uuid.Parse(mux.Vars(r)["hotelID"])
 	if err != nil {
-		http.Error(w, "Error retrieving hotel officials", http.StatusInternalServerError)
+		http.Error(w, "Invalid hotel ID", http.StatusBadRequest)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(officials); err != nil {
-		http.Error(w, "Error encoding response", http.StatusInternalServerError)
+	version, err := parseIfMatch(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var location Location
+	if err := json.NewDecoder(r.Body).Decode(&location); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.hotelService.SetLocation(r.Context(), hotelID, &location, version); err != nil {
+		writeServiceError(w, r, err)
+		return
 	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(location)
 }
 
-func (h *Handler) GetHotelDetails(w http.ResponseWriter, r *http.Request) {
-	hotelID := mux.Vars(r)["hotelID"]
-	hotelUUID, err := uuid.Parse(hotelID)
+// SetHotelTags replaces the tags of a hotel with the tags in the body.
+func (h *Handler) SetHotelTags(w http.ResponseWriter, r *http.Request) {
+	hotelID, err := uuid.Parse(mux.Vars(r)["hotelID"])
 	if err != nil {
 		http.Error(w, "Invalid hotel ID", http.StatusBadRequest)
 		return
 	}
 
-	hotelDetails, err := h.hotelService.GetHotelDetails(hotelUUID)
-	if err != nil || hotelDetails == nil {
-		http.Error(w, "Hotel not found", http.StatusNotFound)
+	version, err := parseIfMatch(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		Tags []string `json:"tags"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tags, err := h.hotelService.SetHotelTags(r.Context(), hotelID, body.Tags, version)
+	if err != nil {
+		writeServiceError(w, r, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(hotelDetails); err != nil {
-		http.Error(w, "Error encoding response", http.StatusInternalServerError)
+	json.NewEncoder(w).Encode(struct {
+		Tags []string `json:"tags"`
+	}{tags})
+}
+
+// SetHotelChain assigns a hotel to the brand or group of the request body.
+func (h *Handler) SetHotelChain(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		ChainID uuid.UUID `json:"chain_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if body.ChainID == uuid.Nil {
+		http.Error(w, "chain_id is required", http.StatusBadRequest)
+		return
+	}
+	if !h.setHotelChain(w, r, &body.ChainID) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(body)
+}
+
+// RemoveHotelChain takes a hotel out of its chain.
+func (h *Handler) RemoveHotelChain(w http.ResponseWriter, r *http.Request) {
+	if h.setHotelChain(w, r, nil) {
+		w.WriteHeader(http.StatusNoContent)
 	}
 }
 
-func (h *Handler) GetHotelStats(w http.ResponseWriter, r *http.Request) {
-	location := r.URL.Query().Get("location")
-	if location == "" {
-		http.Error(w, "location parameter is required", http.StatusBadRequest)
+// setHotelChain sets the chain of the hotel of the path and reports whether it
+// succeeded. Failures have been written to w.
+func (h *Handler) setHotelChain(w http.ResponseWriter, r *http.Request, chainID *uuid.UUID) bool {
+	hotelID, err := uuid.Parse(mux.Vars(r)["hotelID"])
+	if err != nil {
+		http.Error(w, "Invalid hotel ID", http.StatusBadRequest)
+		return false
+	}
+
+	version, err := parseIfMatch(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+
+	if err := h.hotelService.SetHotelChain(r.Context(), hotelID, chainID, version); err != nil {
+		writeServiceError(w, r, err)
+		return false
+	}
+	return true
+}
+
+// DeleteLocation removes the structured location of a hotel.
+func (h *Handler) DeleteLocation(w http.ResponseWriter, r *http.Request) {
+	hotelID, err := uuid.Parse(mux.Vars(r)["hotelID"])
+	if err != nil {
+		http.Error(w, "Invalid hotel ID", http.StatusBadRequest)
 		return
 	}
 
-	hotelCount, phoneCount, err := h.hotelService.FetchLocationStats(location)
+	version, err := parseIfMatch(r)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("error fetching stats: %v", err), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.hotelService.DeleteLocation(r.Context(), hotelID, version); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) ListHotels(w http.ResponseWriter, r *http.Request) {
+	if bbox := r.URL.Query().Get("bbox"); bbox != "" {
+		h.listHotelsInBoundingBox(w, r, bbox)
+		return
+	}
+
+	opts, err := parseListOptions(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := h.hotelService.ListHotels(opts)
+	if err != nil {
+		writeServiceError(w, r, err)
 		return
 	}
 
-	response := struct {
-		HotelCount int `json:"hotel_count"`
-		PhoneCount int `json:"phone_count"`
-	}{
-		HotelCount: hotelCount,
-		PhoneCount: phoneCount,
+	chain := languageChain(w, r)
+	for i := range page.Items {
+		localizeHotel(&page.Items[i], chain)
+	}
+
+	page.Links.Self = r.URL.RequestURI()
+	if page.NextCursor != "" {
+		next := *r.URL
+		query := next.Query()
+		query.Del("offset")
+		query.Set("cursor", page.NextCursor)
+		next.RawQuery = query.Encode()
+		page.Links.Next = next.RequestURI()
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(page)
+}
+
+// parseListOptions reads the pagination, sorting and filter parameters of GET /hotels.
+// A sort field prefixed with "-" sorts in descending order.
+func parseListOptions(r *http.Request) (ListOptions, error) {
+	query := r.URL.Query()
+	opts := ListOptions{
+		Cursor:       query.Get("cursor"),
+		CompanyTitle: query.Get("company_title"),
+		OwnerName:    query.Get("owner_name"),
+		ContactType:  query.Get("contact_type"),
+	}
+
+	var err error
+	if opts.Location, err = parseLocationFilter(query); err != nil {
+		return opts, err
+	}
+
+	for name, target := range map[string]*int{"limit": &opts.Limit, "offset": &opts.Offset} {
+		if value := query.Get(name); value != "" {
+			number, err := strconv.Atoi(value)
+			if err != nil || number < 0 {
+				return opts, fmt.Errorf("%s parameter must be a non-negative integer", name)
+			}
+			*target = number
+		}
+	}
+
+	if value := query.Get("min_rating"); value != "" {
+		rating, err := strconv.ParseFloat(value, 64)
+		if err != nil || rating < 0 || rating > MaxReviewScore {
+			return opts, fmt.Errorf("min_rating parameter must be a number between 0 and 10")
+		}
+		opts.MinRating = rating
+	}
+
+	if value := query.Get("tags"); value != "" {
+		opts.Tags = strings.Split(value, ",")
+	}
+
+	if value := query.Get("facets"); value != "" {
+		facets, err := strconv.ParseBool(value)
+		if err != nil {
+			return opts, fmt.Errorf("facets parameter must be true or false")
+		}
+		opts.Facets = facets
+	}
+
+	if value := query.Get("include_deleted"); value != "" {
+		includeDeleted, err := strconv.ParseBool(value)
+		if err != nil {
+			return opts, fmt.Errorf("include_deleted parameter must be true or false")
+		}
+		opts.IncludeDeleted = includeDeleted
+	}
+
+	opts.SortBy = query.Get("sort")
+	if strings.HasPrefix(opts.SortBy, "-") {
+		opts.SortBy, opts.SortDesc = opts.SortBy[1:], true
+	}
+	return opts, nil
+}
+
+// parseLocationFilter reads the location, country, city, district and chain_id
+// query parameters.
+func parseLocationFilter(query url.Values) (LocationFilter, error) {
+	filter := LocationFilter{
+		Name:     query.Get("location"),
+		Country:  query.Get("country"),
+		City:     query.Get("city"),
+		District: query.Get("district"),
+	}
+	if value := query.Get("chain_id"); value != "" {
+		chainID, err := uuid.Parse(value)
+		if err != nil {
+			return filter, fmt.Errorf("chain_id parameter must be a chain ID")
+		}
+		filter.ChainID = chainID
+	}
+	return filter, nil
+}
+
+// parseLimit reads the limit query parameter, 0 when it is not given.
+func parseLimit(query url.Values) (int, error) {
+	value := query.Get("limit")
+	if value == "" {
+		return 0, nil
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 0 {
+		return 0, fmt.Errorf("limit parameter must be a non-negative integer")
+	}
+	return limit, nil
+}
+
+// listHotelsInBoundingBox serves GET /hotels?bbox=minLng,minLat,maxLng,maxLat.
+// The results are not a page of the hotel list, so bbox only goes with limit.
+func (h *Handler) listHotelsInBoundingBox(w http.ResponseWriter, r *http.Request, bbox string) {
+	query := r.URL.Query()
+	names := make([]string, 0, len(query))
+	for name := range query {
+		if name != "bbox" && name != "limit" {
+			names = append(names, name)
+		}
+	}
+	if len(names) > 0 {
+		sort.Strings(names)
+		http.Error(w, fmt.Sprintf("bbox cannot be combined with the %s parameter", names[0]), http.StatusBadRequest)
+		return
+	}
+	limit, err := parseLimit(query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	parts := strings.Split(bbox, ",")
+	if len(parts) != 4 {
+		http.Error(w, "bbox must be minLng,minLat,maxLng,maxLat", http.StatusBadRequest)
+		return
+	}
+
+	var values [4]float64
+	for i, part := range parts {
+		value, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			http.Error(w, "bbox must be minLng,minLat,maxLng,maxLat", http.StatusBadRequest)
+			return
+		}
+		values[i] = value
+	}
+
+	box := BoundingBox{MinLng: values[0], MinLat: values[1], MaxLng: values[2], MaxLat: values[3]}
+	hotels, err := h.hotelService.FindHotelsInBoundingBox(box, limit)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	chain := languageChain(w, r)
+	for i := range hotels {
+		localizeHotel(&hotels[i].Hotel, chain)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(hotels)
+}
+
+// ListNearbyHotels serves GET /hotels/nearby?lat=&lng=&radius_km=&limit=.
+func (h *Handler) ListNearbyHotels(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	var coordinates [3]float64
+	for i, name := range []string{"lat", "lng", "radius_km"} {
+		value, err := strconv.ParseFloat(query.Get(name), 64)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("%s parameter must be a number", name), http.StatusBadRequest)
+			return
+		}
+		coordinates[i] = value
+	}
+	limit, err := parseLimit(query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	hotels, err := h.hotelService.FindNearbyHotels(coordinates[0], coordinates[1], coordinates[2], limit)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	chain := languageChain(w, r)
+	for i := range hotels {
+		localizeHotel(&hotels[i].Hotel, chain)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(hotels)
+}
+
+// SearchHotels handles typo-tolerant search over company titles, owners and contacts.
+func (h *Handler) SearchHotels(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if strings.TrimSpace(query.Get("q")) == "" {
+		http.Error(w, "q parameter is required", http.StatusBadRequest)
+		return
+	}
+
+	limit := 0
+	if value := query.Get("limit"); value != "" {
+		number, err := strconv.Atoi(value)
+		if err != nil || number < 0 {
+			http.Error(w, "limit parameter must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+		limit = number
+	}
+
+	results, err := h.hotelService.SearchHotels(query.Get("q"), limit)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	// Search results carry the company title only, so only it is localized
+	ids := make([]uuid.UUID, len(results))
+	for i, result := range results {
+		ids[i] = result.ID
+	}
+	translations, err := h.hotelService.FindTranslations(ids)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	chain := languageChain(w, r)
+	for i := range results {
+		hotel := Hotel{CompanyTitle: results[i].CompanyTitle}
+		for _, translation := range translations {
+			if translation.HotelID == results[i].ID {
+				hotel.Translations = append(hotel.Translations, translation)
+			}
+		}
+		localizeHotel(&hotel, chain)
+		results[i].CompanyTitle = hotel.CompanyTitle
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+}
+
+// ListHotelOfficials serves GET /hotels/officials?hotel_id=&role=&active=.
+func (h *Handler) ListHotelOfficials(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := OfficialFilter{Role: query.Get("role")}
+	if value := query.Get("hotel_id"); value != "" {
+		hotelID, err := uuid.Parse(value)
+		if err != nil {
+			http.Error(w, "Invalid hotel ID", http.StatusBadRequest)
+			return
+		}
+		filter.HotelID = hotelID
+	}
+	if value := query.Get("active"); value != "" {
+		active, err := strconv.ParseBool(value)
+		if err != nil {
+			http.Error(w, "active parameter must be true or false", http.StatusBadRequest)
+			return
+		}
+		if active {
+			filter.ActiveAt = time.Now()
+		}
+	}
+
+	officials, err := h.hotelService.ListHotelOfficials(filter)
+	if err != nil {
+		http.Error(w, "Error retrieving hotel officials", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(officials); err != nil {
+		http.Error(w, "Error encoding response", http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) AddOfficial(w http.ResponseWriter, r *http.Request) {
+	hotelID, err := uuid.Parse(mux.Vars(r)["hotelID"])
+	if err != nil {
+		http.Error(w, "Invalid hotel ID", http.StatusBadRequest)
+		return
+	}
+
+	version, err := parseIfMatch(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	official, err := decodeOfficial(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.hotelService.AddOfficial(r.Context(), hotelID, official, version); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(official)
+}
+
+// UpdateOfficial replaces an official. Contacts missing from the request are removed.
+func (h *Handler) UpdateOfficial(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	hotelID, err := uuid.Parse(vars["hotelID"])
+	if err != nil {
+		http.Error(w, "Invalid hotel ID", http.StatusBadRequest)
+		return
+	}
+
+	officialID, err := uuid.Parse(vars["officialID"])
+	if err != nil {
+		http.Error(w, "Invalid official ID", http.StatusBadRequest)
+		return
+	}
+
+	version, err := parseIfMatch(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	official, err := decodeOfficial(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.hotelService.UpdateOfficial(r.Context(), hotelID, officialID, official, version); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(official)
+}
+
+func (h *Handler) RemoveOfficial(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	hotelID, err := uuid.Parse(vars["hotelID"])
+	if err != nil {
+		http.Error(w, "Invalid hotel ID", http.StatusBadRequest)
+		return
+	}
+
+	officialID, err := uuid.Parse(vars["officialID"])
+	if err != nil {
+		http.Error(w, "Invalid official ID", http.StatusBadRequest)
+		return
+	}
+
+	version, err := parseIfMatch(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.hotelService.RemoveOfficial(r.Context(), hotelID, officialID, version); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) ListRoomTypes(w http.ResponseWriter, r *http.Request) {
+	hotelID, err := uuid.Parse(mux.Vars(r)["hotelID"])
+	if err != nil {
+		http.Error(w, "Invalid hotel ID", http.StatusBadRequest)
+		return
+	}
+
+	roomTypes, err := h.hotelService.ListRoomTypes(hotelID)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(roomTypes)
+}
+
+func (h *Handler) GetRoomType(w http.ResponseWriter, r *http.Request) {
+	hotelID, roomTypeID, ok := parseRoomTypeIDs(w, r)
+	if !ok {
+		return
+	}
+
+	roomType, err := h.hotelService.GetRoomType(hotelID, roomTypeID)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(roomType)
+}
+
+func (h *Handler) AddRoomType(w http.ResponseWriter, r *http.Request) {
+	hotelID, err := uuid.Parse(mux.Vars(r)["hotelID"])
+	if err != nil {
+		http.Error(w, "Invalid hotel ID", http.StatusBadRequest)
+		return
+	}
+
+	version, err := parseIfMatch(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var roomType RoomType
+	if err := json.NewDecoder(r.Body).Decode(&roomType); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.hotelService.AddRoomType(r.Context(), hotelID, &roomType, version); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(roomType)
+}
+
+// UpdateRoomType replaces a room type.
+func (h *Handler) UpdateRoomType(w http.ResponseWriter, r *http.Request) {
+	hotelID, roomTypeID, ok := parseRoomTypeIDs(w, r)
+	if !ok {
+		return
+	}
+
+	version, err := parseIfMatch(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var roomType RoomType
+	if err := json.NewDecoder(r.Body).Decode(&roomType); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.hotelService.UpdateRoomType(r.Context(), hotelID, roomTypeID, &roomType, version); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(roomType)
+}
+
+func (h *Handler) RemoveRoomType(w http.ResponseWriter, r *http.Request) {
+	hotelID, roomTypeID, ok := parseRoomTypeIDs(w, r)
+	if !ok {
+		return
+	}
+
+	version, err := parseIfMatch(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.hotelService.RemoveRoomType(r.Context(), hotelID, roomTypeID, version); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// parseRoomTypeIDs reads the hotel and room type IDs of a room type URL and
+// answers 400 when either is invalid.
+func parseRoomTypeIDs(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	vars := mux.Vars(r)
+	hotelID, err := uuid.Parse(vars["hotelID"])
+	if err != nil {
+		http.Error(w, "Invalid hotel ID", http.StatusBadRequest)
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	roomTypeID, err := uuid.Parse(vars["roomTypeID"])
+	if err != nil {
+		http.Error(w, "Invalid room type ID", http.StatusBadRequest)
+		return uuid.Nil, uuid.Nil, false
+	}
+	return hotelID, roomTypeID, true
+}
+
+// ListRatePlans lists the rate plans of a hotel, or of the room type of the
+// room_type_id query parameter.
+func (h *Handler) ListRatePlans(w http.ResponseWriter, r *http.Request) {
+	hotelID, err := uuid.Parse(mux.Vars(r)["hotelID"])
+	if err != nil {
+		http.Error(w, "Invalid hotel ID", http.StatusBadRequest)
+		return
+	}
+
+	var roomTypeID uuid.UUID
+	if value := r.URL.Query().Get("room_type_id"); value != "" {
+		if roomTypeID, err = uuid.Parse(value); err != nil {
+			http.Error(w, "Invalid room type ID", http.StatusBadRequest)
+			return
+		}
+	}
+
+	plans, err := h.hotelService.ListRatePlans(hotelID, roomTypeID)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(plans)
+}
+
+func (h *Handler) GetRatePlan(w http.ResponseWriter, r *http.Request) {
+	hotelID, ratePlanID, ok := parseRatePlanIDs(w, r)
+	if !ok {
+		return
+	}
+
+	plan, err := h.hotelService.GetRatePlan(hotelID, ratePlanID)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(plan)
+}
+
+func (h *Handler) AddRatePlan(w http.ResponseWriter, r *http.Request) {
+	hotelID, err := uuid.Parse(mux.Vars(r)["hotelID"])
+	if err != nil {
+		http.Error(w, "Invalid hotel ID", http.StatusBadRequest)
+		return
+	}
+
+	version, err := parseIfMatch(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	plan, err := decodeRatePlan(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.hotelService.AddRatePlan(r.Context(), hotelID, plan, version); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(plan)
+}
+
+// UpdateRatePlan replaces a rate plan.
+func (h *Handler) UpdateRatePlan(w http.ResponseWriter, r *http.Request) {
+	hotelID, ratePlanID, ok := parseRatePlanIDs(w, r)
+	if !ok {
+		return
+	}
+
+	version, err := parseIfMatch(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	plan, err := decodeRatePlan(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.hotelService.UpdateRatePlan(r.Context(), hotelID, ratePlanID, plan, version); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(plan)
+}
+
+func (h *Handler) RemoveRatePlan(w http.ResponseWriter, r *http.Request) {
+	hotelID, ratePlanID, ok := parseRatePlanIDs(w, r)
+	if !ok {
+		return
+	}
+
+	version, err := parseIfMatch(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.hotelService.RemoveRatePlan(r.Context(), hotelID, ratePlanID, version); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// parseRatePlanIDs reads the hotel and rate plan IDs of a rate plan URL and
+// answers 400 when either is invalid.
+func parseRatePlanIDs(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	vars := mux.Vars(r)
+	hotelID, err := uuid.Parse(vars["hotelID"])
+	if err != nil {
+		http.Error(w, "Invalid hotel ID", http.StatusBadRequest)
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	ratePlanID, err := uuid.Parse(vars["ratePlanID"])
+	if err != nil {
+		http.Error(w, "Invalid rate plan ID", http.StatusBadRequest)
+		return uuid.Nil, uuid.Nil, false
+	}
+	return hotelID, ratePlanID, true
+}
+
+// QuoteStay prices the stay of the query parameters room_type_id, check_in,
+// check_out, rooms, meal_plan and currency.
+func (h *Handler) QuoteStay(w http.ResponseWriter, r *http.Request) {
+	hotelID, err := uuid.Parse(mux.Vars(r)["hotelID"])
+	if err != nil {
+		http.Error(w, "Invalid hotel ID", http.StatusBadRequest)
+		return
+	}
+
+	params := r.URL.Query()
+	request := QuoteRequest{MealPlan: params.Get("meal_plan"), Currency: params.Get("currency")}
+	if request.RoomTypeID, err = uuid.Parse(params.Get("room_type_id")); err != nil {
+		http.Error(w, "Invalid room type ID", http.StatusBadRequest)
+		return
+	}
+	if request.CheckIn, err = parseDate("check_in", params.Get("check_in")); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if request.CheckOut, err = parseDate("check_out", params.Get("check_out")); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if value := params.Get("rooms"); value != "" {
+		if request.Rooms, err = strconv.Atoi(value); err != nil {
+			http.Error(w, "rooms parameter must be an integer", http.StatusBadRequest)
+			return
+		}
+	}
+
+	quote, err := h.hotelService.QuoteStay(hotelID, request)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(quote)
+}
+
+// ListExchangeRates lists the exchange rates, optionally of the base and quote
+// currencies of the query.
+func (h *Handler) ListExchangeRates(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	rates, err := h.hotelService.ListExchangeRates(ExchangeRateFilter{
+		BaseCurrency:  query.Get("base"),
+		QuoteCurrency: query.Get("quote"),
+	})
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rates)
+}
+
+// SaveExchangeRate creates or replaces the rate of the currency pair of the
+// path from the effective date of the path on.
+func (h *Handler) SaveExchangeRate(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	effectiveDate, err := parseDate("date", vars["date"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		Rate money.Decimal `json:"rate"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rate := &ExchangeRate{BaseCurrency: vars["base"], QuoteCurrency: vars["quote"], EffectiveDate: effectiveDate, Rate: body.Rate}
+	if err := h.hotelService.SaveExchangeRate(rate); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rate)
+}
+
+func (h *Handler) RemoveExchangeRate(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	effectiveDate, err := parseDate("date", vars["date"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.hotelService.RemoveExchangeRate(vars["base"], vars["quote"], effectiveDate); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// languageChain returns the fallback chain of languages for the Accept-Language
+// header of a request, and marks the response as varying with that header.
+func languageChain(w http.ResponseWriter, r *http.Request) []string {
+	w.Header().Add("Vary", "Accept-Language")
+	return LanguageChain(r.Header.Get("Accept-Language"))
+}
+
+// ListTranslations lists every translation of a hotel, unlike the read
+// endpoints, which pick one by Accept-Language.
+func (h *Handler) ListTranslations(w http.ResponseWriter, r *http.Request) {
+	hotelID, err := uuid.Parse(mux.Vars(r)["hotelID"])
+	if err != nil {
+		http.Error(w, "Invalid hotel ID", http.StatusBadRequest)
+		return
+	}
+
+	translations, err := h.hotelService.ListTranslations(hotelID)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(translations)
+}
+
+// SaveTranslation creates or replaces the translation of a hotel into the
+// language in the path.
+func (h *Handler) SaveTranslation(w http.ResponseWriter, r *http.Request) {
+	hotelID, err := uuid.Parse(mux.Vars(r)["hotelID"])
+	if err != nil {
+		http.Error(w, "Invalid hotel ID", http.StatusBadRequest)
+		return
+	}
+
+	version, err := parseIfMatch(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var translation HotelTranslation
+	if err := json.NewDecoder(r.Body).Decode(&translation); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.hotelService.SaveTranslation(r.Context(), hotelID, mux.Vars(r)["language"], &translation, version); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(translation)
+}
+
+func (h *Handler) RemoveTranslation(w http.ResponseWriter, r *http.Request) {
+	hotelID, err := uuid.Parse(mux.Vars(r)["hotelID"])
+	if err != nil {
+		http.Error(w, "Invalid hotel ID", http.StatusBadRequest)
+		return
+	}
+
+	version, err := parseIfMatch(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.hotelService.RemoveTranslation(r.Context(), hotelID, mux.Vars(r)["language"], version); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListMissingTranslations serves GET /hotels/translations/missing?languages=&limit=&offset=.
+func (h *Handler) ListMissingTranslations(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	var filter MissingTranslationFilter
+	if value := query.Get("languages"); value != "" {
+		filter.Languages = strings.Split(value, ",")
+	}
+	for name, target := range map[string]*int{"limit": &filter.Limit, "offset": &filter.Offset} {
+		if value := query.Get(name); value != "" {
+			number, err := strconv.Atoi(value)
+			if err != nil || number < 0 {
+				http.Error(w, fmt.Sprintf("%s parameter must be a non-negative integer", name), http.StatusBadRequest)
+				return
+			}
+			*target = number
+		}
+	}
+
+	page, err := h.hotelService.ListMissingTranslations(filter)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+// mediaCacheControl lets clients and proxies keep media for a year. The file
+// of a media never changes; a new upload gets a new ID.
+const mediaCacheControl = "public, max-age=31536000, immutable"
+
+func (h *Handler) ListMedia(w http.ResponseWriter, r *http.Request) {
+	hotelID, err := uuid.Parse(mux.Vars(r)["hotelID"])
+	if err != nil {
+		http.Error(w, "Invalid hotel ID", http.StatusBadRequest)
+		return
+	}
+
+	media, err := h.hotelService.ListMedia(hotelID)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(media)
+}
+
+// AddMedia uploads the file in the "file" field of a multipart form.
+func (h *Handler) AddMedia(w http.ResponseWriter, r *http.Request) {
+	hotelID, err := uuid.Parse(mux.Vars(r)["hotelID"])
+	if err != nil {
+		http.Error(w, "Invalid hotel ID", http.StatusBadRequest)
+		return
+	}
+
+	version, err := parseIfMatch(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Leave room for the multipart headers around the file
+	r.Body = http.MaxBytesReader(w, r.Body, MaxMediaSize+1<<20)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, fmt.Sprintf("media file must not exceed %d bytes", MaxMediaSize), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "multipart form with a file field is required", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, MaxMediaSize+1))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(data) > MaxMediaSize {
+		http.Error(w, fmt.Sprintf("media file must not exceed %d bytes", MaxMediaSize), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	media, err := h.hotelService.AddMedia(r.Context(), hotelID, header.Filename, data, version)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", fmt.Sprintf("/hotels/%s/media/%s/content", hotelID, media.ID))
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(media)
+}
+
+// ReorderMedia sets the display order of the media of a hotel to the order of
+// the IDs in the body.
+func (h *Handler) ReorderMedia(w http.ResponseWriter, r *http.Request) {
+	hotelID, err := uuid.Parse(mux.Vars(r)["hotelID"])
+	if err != nil {
+		http.Error(w, "Invalid hotel ID", http.StatusBadRequest)
+		return
+	}
+
+	version, err := parseIfMatch(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		MediaIDs []uuid.UUID `json:"media_ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	media, err := h.hotelService.ReorderMedia(r.Context(), hotelID, body.MediaIDs, version)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(media)
+}
+
+func (h *Handler) GetMediaContent(w http.ResponseWriter, r *http.Request) {
+	h.serveMedia(w, r, false)
+}
+
+func (h *Handler) GetMediaThumbnail(w http.ResponseWriter, r *http.Request) {
+	h.serveMedia(w, r, true)
+}
+
+// serveMedia writes the file or the thumbnail of a media with caching headers.
+// The entity tag is derived from the checksum of the file.
+func (h *Handler) serveMedia(w http.ResponseWriter, r *http.Request, thumbnail bool) {
+	hotelID, mediaID, ok := parseMediaIDs(w, r)
+	if !ok {
+		return
+	}
+
+	media, file, err := h.hotelService.OpenMedia(hotelID, mediaID, thumbnail)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	defer file.Close()
+
+	tag, contentType := `"`+media.Checksum+`"`, media.ContentType
+	if thumbnail {
+		tag, contentType = `"`+media.Checksum+`-thumb"`, ThumbnailContentType
+	}
+	w.Header().Set("Cache-Control", mediaCacheControl)
+	w.Header().Set("ETag", tag)
+	if matchesIfNoneMatch(r.Header.Get("If-None-Match"), tag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if !thumbnail {
+		w.Header().Set("Content-Length", strconv.FormatInt(media.Size, 10))
+		if media.FileName != "" {
+			w.Header().Set("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": media.FileName}))
+		}
+	}
+	if _, err := io.Copy(w, file); err != nil {
+		log.Printf("Failed to send media %s: %v", media.ID, err)
+	}
+}
+
+func (h *Handler) RemoveMedia(w http.ResponseWriter, r *http.Request) {
+	hotelID, mediaID, ok := parseMediaIDs(w, r)
+	if !ok {
+		return
+	}
+
+	version, err := parseIfMatch(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.hotelService.RemoveMedia(r.Context(), hotelID, mediaID, version); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// parseMediaIDs reads the hotel and media IDs of a media URL and answers 400
+// when either is invalid.
+func parseMediaIDs(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	vars := mux.Vars(r)
+	hotelID, err := uuid.Parse(vars["hotelID"])
+	if err != nil {
+		http.Error(w, "Invalid hotel ID", http.StatusBadRequest)
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	mediaID, err := uuid.Parse(vars["mediaID"])
+	if err != nil {
+		http.Error(w, "Invalid media ID", http.StatusBadRequest)
+		return uuid.Nil, uuid.Nil, false
+	}
+	return hotelID, mediaID, true
+}
+
+// GetAvailability returns the availability calendar of a hotel for the nights
+// from the from query parameter up to the to parameter.
+func (h *Handler) GetAvailability(w http.ResponseWriter, r *http.Request) {
+	hotelID, err := uuid.Parse(mux.Vars(r)["hotelID"])
+	if err != nil {
+		http.Error(w, "Invalid hotel ID", http.StatusBadRequest)
+		return
+	}
+
+	var query AvailabilityQuery
+	params := r.URL.Query()
+	if query.From, err = parseDate("from", params.Get("from")); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if query.To, err = parseDate("to", params.Get("to")); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if value := params.Get("room_type_id"); value != "" {
+		if query.RoomTypeID, err = uuid.Parse(value); err != nil {
+			http.Error(w, "Invalid room type ID", http.StatusBadRequest)
+			return
+		}
+	}
+
+	calendar, err := h.hotelService.GetAvailability(hotelID, query)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(calendar)
+}
+
+func (h *Handler) CreateReservation(w http.ResponseWriter, r *http.Request) {
+	hotelID, err := uuid.Parse(mux.Vars(r)["hotelID"])
+	if err != nil {
+		http.Error(w, "Invalid hotel ID", http.StatusBadRequest)
+		return
+	}
+
+	reservation, err := decodeReservation(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.hotelService.CreateReservation(r.Context(), hotelID, reservation); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(reservation)
+}
+
+func (h *Handler) GetReservation(w http.ResponseWriter, r *http.Request) {
+	hotelID, reservationID, ok := parseReservationIDs(w, r)
+	if !ok {
+		return
+	}
+
+	reservation, err := h.hotelService.GetReservation(hotelID, reservationID)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reservation)
+}
+
+// ConfirmReservation turns a hold into a booking.
+func (h *Handler) ConfirmReservation(w http.ResponseWriter, r *http.Request) {
+	hotelID, reservationID, ok := parseReservationIDs(w, r)
+	if !ok {
+		return
+	}
+
+	reservation, err := h.hotelService.ConfirmReservation(r.Context(), hotelID, reservationID)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reservation)
+}
+
+func (h *Handler) CancelReservation(w http.ResponseWriter, r *http.Request) {
+	hotelID, reservationID, ok := parseReservationIDs(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.hotelService.CancelReservation(r.Context(), hotelID, reservationID); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListReviews returns the reviews of a hotel. The status query parameter
+// selects pending or rejected reviews instead of the approved ones.
+func (h *Handler) ListReviews(w http.ResponseWriter, r *http.Request) {
+	hotelID, err := uuid.Parse(mux.Vars(r)["hotelID"])
+	if err != nil {
+		http.Error(w, "Invalid hotel ID", http.StatusBadRequest)
+		return
+	}
+
+	filter := ReviewFilter{HotelID: hotelID, Status: r.URL.Query().Get("status")}
+	if value := r.URL.Query().Get("limit"); value != "" {
+		if filter.Limit, err = strconv.Atoi(value); err != nil || filter.Limit < 0 {
+			http.Error(w, "limit parameter must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+	}
+
+	reviews, err := h.hotelService.ListReviews(filter)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reviews)
+}
+
+func (h *Handler) AddReview(w http.ResponseWriter, r *http.Request) {
+	hotelID, err := uuid.Parse(mux.Vars(r)["hotelID"])
+	if err != nil {
+		http.Error(w, "Invalid hotel ID", http.StatusBadRequest)
+		return
+	}
+
+	var request struct {
+		Score  int    `json:"score"`
+		Text   string `json:"text"`
+		Author string `json:"author"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	review := &Review{Score: request.Score, Text: request.Text, Author: request.Author}
+	if err := h.hotelService.AddReview(r.Context(), hotelID, review); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(review)
+}
+
+// ModerateReview sets the moderation status of a review.
+func (h *Handler) ModerateReview(w http.ResponseWriter, r *http.Request) {
+	hotelID, reviewID, ok := parseReviewIDs(w, r)
+	if !ok {
+		return
+	}
+
+	var request struct {
+		Status string `json:"status"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	review, err := h.hotelService.ModerateReview(r.Context(), hotelID, reviewID, request.Status)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(review)
+}
+
+func (h *Handler) RemoveReview(w http.ResponseWriter, r *http.Request) {
+	hotelID, reviewID, ok := parseReviewIDs(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.hotelService.RemoveReview(r.Context(), hotelID, reviewID); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// parseReviewIDs reads the hotel and review IDs of a review URL and answers 400
+// when either is invalid.
+func parseReviewIDs(w http.ResponseWriter, r *http.Request) (hotelID, reviewID uuid.UUID, ok bool) {
+	vars := mux.Vars(r)
+	hotelID, err := uuid.Parse(vars["hotelID"])
+	if err != nil {
+		http.Error(w, "Invalid hotel ID", http.StatusBadRequest)
+		return uuid.Nil, uuid.Nil, false
+	}
+	reviewID, err = uuid.Parse(vars["reviewID"])
+	if err != nil {
+		http.Error(w, "Invalid review ID", http.StatusBadRequest)
+		return uuid.Nil, uuid.Nil, false
+	}
+	return hotelID, reviewID, true
+}
+
+// parseReservationIDs reads the hotel and reservation IDs of a reservation URL
+// and answers 400 when either is invalid.
+func parseReservationIDs(w http.ResponseWriter, r *http.Request) (hotelID, reservationID uuid.UUID, ok bool) {
+	vars := mux.Vars(r)
+	hotelID, err := uuid.Parse(vars["hotelID"])
+	if err != nil {
+		http.Error(w, "Invalid hotel ID", http.StatusBadRequest)
+		return uuid.Nil, uuid.Nil, false
+	}
+	reservationID, err = uuid.Parse(vars["reservationID"])
+	if err != nil {
+		http.Error(w, "Invalid reservation ID", http.StatusBadRequest)
+		return uuid.Nil, uuid.Nil, false
+	}
+	return hotelID, reservationID, true
+}
+
+// decodeReservation reads a reservation request with its days such as 2024-03-01.
+func decodeReservation(r *http.Request) (*Reservation, error) {
+	var request struct {
+		RoomTypeID uuid.UUID `json:"room_type_id"`
+		CheckIn    string    `json:"check_in"`
+		CheckOut   string    `json:"check_out"`
+		Rooms      int       `json:"rooms"`
+		GuestName  string    `json:"guest_name"`
+		Status     string    `json:"status"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return nil, err
+	}
+
+	reservation := &Reservation{
+		RoomTypeID: request.RoomTypeID,
+		Rooms:      request.Rooms,
+		GuestName:  request.GuestName,
+		Status:     request.Status,
+	}
+	var err error
+	if reservation.CheckIn, err = parseDate("check_in", request.CheckIn); err != nil {
+		return nil, err
+	}
+	if reservation.CheckOut, err = parseDate("check_out", request.CheckOut); err != nil {
+		return nil, err
+	}
+	return reservation, nil
+}
+
+// decodeOfficial reads an official from the request body. Its dates are given
+// as days, e.g. "2024-03-01", or as RFC 3339 timestamps.
+func decodeOfficial(r *http.Request) (*HotelOfficial, error) {
+	var request struct {
+		Role         string            `json:"role"`
+		Name         string            `json:"name"`
+		Surname      string            `json:"surname"`
+		StartDate    string            `json:"start_date"`
+		EndDate      string            `json:"end_date"`
+		ContactInfos []OfficialContact `json:"contact_infos"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return nil, err
+	}
+
+	official := &HotelOfficial{
+		Role:         request.Role,
+		Name:         request.Name,
+		Surname:      request.Surname,
+		ContactInfos: request.ContactInfos,
+	}
+	for _, date := range []struct {
+		name   string
+		value  string
+		target **time.Time
+	}{{"start_date", request.StartDate, &official.StartDate}, {"end_date", request.EndDate, &official.EndDate}} {
+		if date.value == "" {
+			continue
+		}
+		parsed, err := parseDate(date.name, date.value)
+		if err != nil {
+			return nil, err
+		}
+		*date.target = &parsed
+	}
+	return official, nil
+}
+
+// decodeRatePlan reads a rate plan from the request body. Its dates are given
+// as days, e.g. "2024-06-01", or as RFC 3339 timestamps.
+func decodeRatePlan(r *http.Request) (*RatePlan, error) {
+	var request struct {
+		RoomTypeID   uuid.UUID          `json:"room_type_id"`
+		Name         string             `json:"name"`
+		ValidFrom    string             `json:"valid_from"`
+		ValidTo      string             `json:"valid_to"`
+		BasePrice    money.Decimal      `json:"base_price"`
+		Currency     string             `json:"currency"`
+		MealPlan     string             `json:"meal_plan"`
+		Cancellation CancellationPolicy `json:"cancellation"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return nil, err
+	}
+
+	plan := &RatePlan{
+		RoomTypeID:   request.RoomTypeID,
+		Name:         request.Name,
+		BasePrice:    request.BasePrice,
+		Currency:     request.Currency,
+		MealPlan:     request.MealPlan,
+		Cancellation: request.Cancellation,
+	}
+	var err error
+	if plan.ValidFrom, err = parseDate("valid_from", request.ValidFrom); err != nil {
+		return nil, err
+	}
+	if plan.ValidTo, err = parseDate("valid_to", request.ValidTo); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// parseDate reads a day such as 2024-03-01. RFC 3339 times are accepted too.
+func parseDate(name, value string) (time.Time, error) {
+	parsed, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		if parsed, err = time.Parse(time.RFC3339, value); err != nil {
+			return time.Time{}, fmt.Errorf("%s must be a date such as 2024-03-01", name)
+		}
+	}
+	return parsed, nil
+}
+
+func (h *Handler) GetHotelDetails(w http.ResponseWriter, r *http.Request) {
+	hotelID := mux.Vars(r)["hotelID"]
+	hotelUUID, err := uuid.Parse(hotelID)
+	if err != nil {
+		http.Error(w, "Invalid hotel ID", http.StatusBadRequest)
+		return
+	}
+
+	hotelDetails, err := h.hotelService.GetHotelDetails(hotelUUID)
+	if err != nil || hotelDetails == nil {
+		http.Error(w, "Hotel not found", http.StatusNotFound)
+		return
+	}
+
+	languages := localizeHotel(hotelDetails, languageChain(w, r))
+	tag := hotelETag(hotelDetails, languages)
+	w.Header().Set("ETag", tag)
+	if hotelDetails.Language != "" {
+		w.Header().Set("Content-Language", hotelDetails.Language)
+	}
+	if matchesIfNoneMatch(r.Header.Get("If-None-Match"), tag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(hotelDetails); err != nil {
+		http.Error(w, "Error encoding response", http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) GetHotelStats(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter, err := parseLocationFilter(query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.IsEmpty() {
+		http.Error(w, "location, country, city, district or chain_id parameter is required", http.StatusBadRequest)
+		return
+	}
+
+	stats, err := h.hotelService.FetchLocationStats(filter, query.Get("currency"))
+	if errors.Is(err, money.ErrInvalidCurrency) {
+		writeServiceError(w, r, err)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("error fetching stats: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(stats); err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// BatchHotelStats serves POST /hotels/stats:batch, which returns the stats of
+// up to MaxStatsBatch locations in one response, in the order they are listed.
+// Every location takes the filters and currency of GET /hotels/stats.
+func (h *Handler) BatchHotelStats(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Locations []struct {
+			Location string    `json:"location"`
+			Country  string    `json:"country"`
+			City     string    `json:"city"`
+			District string    `json:"district"`
+			ChainID  uuid.UUID `json:"chain_id"`
+			Currency string    `json:"currency"`
+		} `json:"locations"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	queries := make([]LocationStatsQuery, len(request.Locations))
+	for i, location := range request.Locations {
+		queries[i] = LocationStatsQuery{
+			Filter: LocationFilter{
+				Name:     location.Location,
+				Country:  location.Country,
+				City:     location.City,
+				District: location.District,
+				ChainID:  location.ChainID,
+			},
+			Currency: location.Currency,
+		}
+	}
+
+	stats, err := h.hotelService.FetchLocationStatsBatch(queries)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(struct {
+		Items []LocationStats `json:"items"`
+	}{stats})
+}
+
+// ListLocationStats serves GET /hotels/stats/locations?level=&min_hotels=&sort=&limit=&offset=
+// with the location filters of GET /hotels/stats, which are all optional.
+func (h *Handler) ListLocationStats(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	opts := LocationStatsOptions{Level: query.Get("level")}
+
+	var err error
+	if opts.Location, err = parseLocationFilter(query); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	for name, target := range map[string]*int{"min_hotels": &opts.MinHotels, "limit": &opts.Limit, "offset": &opts.Offset} {
+		if value := query.Get(name); value != "" {
+			number, err := strconv.Atoi(value)
+			if err != nil || number < 0 {
+				http.Error(w, fmt.Sprintf("%s parameter must be a non-negative integer", name), http.StatusBadRequest)
+				return
+			}
+			*target = number
+		}
+	}
+	opts.SortBy = query.Get("sort")
+	if strings.HasPrefix(opts.SortBy, "-") {
+		opts.SortBy, opts.SortDesc = opts.SortBy[1:], true
+	}
+
+	page, err := h.hotelService.ListLocationStats(opts)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+// decodeMergePatch reads a JSON Merge Patch (RFC 7396) document into the given
+// string fields. A null member clears the field; unknown members are rejected.
+func decodeMergePatch(body io.Reader, fields map[string]**string) error {
+	var patch map[string]json.RawMessage
+	if err := json.NewDecoder(body).Decode(&patch); err != nil {
+		return err
+	}
+
+	for key, raw := range patch {
+		target, ok := fields[key]
+		if !ok {
+			return fmt.Errorf("unknown field %q", key)
+		}
+
+		value := ""
+		if string(raw) != "null" {
+			if err := json.Unmarshal(raw, &value); err != nil {
+				return fmt.Errorf("invalid value for %q: %w", key, err)
+			}
+		}
+		*target = &value
+	}
+	return nil
+}
+
+// hotelETag is the strong entity tag of a hotel representation. Review
+// moderation changes the rating without a new version, so the tag adds the
+// review count and score total to the version that If-Match compares, followed
+// by the languages a localized representation was translated from.
+func hotelETag(hotel *Hotel, languages []string) string {
+	tag := fmt.Sprintf("%d-%d-%d", hotel.Version, hotel.ReviewCount, hotel.ScoreTotal)
+	if len(languages) > 0 {
+		tag += "-" + strings.Join(languages, "+")
+	}
+	return `"` + tag + `"`
+}
+
+// noVersion is required of the hotel when no tag of the If-Match header can
+// match, so that the write fails its precondition. Versions start at 1.
+const noVersion = -1
+
+// parseIfMatch returns the hotel version required by the If-Match header, or
+// zero when the header is absent or "*". The header lists versions or tags of
+// hotel representations, separated by commas. If-Match uses the strong
+// comparison, so weak tags never match. Versions only grow, and every version
+// a client has been given is at most the current one, so of the listed
+// versions only the highest can still match; it is the one required.
+func parseIfMatch(r *http.Request) (int, error) {
+	value := strings.TrimSpace(r.Header.Get("If-Match"))
+	if value == "" || value == "*" {
+		return 0, nil
+	}
+
+	required := noVersion
+	for _, tag := range strings.Split(value, ",") {
+		tag = strings.TrimSpace(tag)
+		if strings.HasPrefix(tag, "W/") {
+			continue
+		}
+		if len(tag) < 2 || !strings.HasPrefix(tag, `"`) || !strings.HasSuffix(tag, `"`) {
+			return 0, fmt.Errorf("invalid If-Match header %q", value)
+		}
+
+		prefix, _, _ := strings.Cut(tag[1:len(tag)-1], "-")
+		version, err := strconv.Atoi(prefix)
+		if err != nil || version <= 0 {
+			return 0, fmt.Errorf("invalid If-Match header %q", value)
+		}
+		if version > required {
+			required = version
+		}
+	}
+	return required, nil
+}
+
+// matchesIfNoneMatch reports whether the If-None-Match header matches the
+// current entity tag, using the weak comparison required for GET.
+func matchesIfNoneMatch(header, current string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == current {
+			return true
+		}
+	}
+	return false
+}
+
+// Headers identifying who made a change and the request it was made in.
+const (
+	ActorHeader     = "X-Actor"
+	RequestIDHeader = "X-Request-ID"
+)
+
+// auditMiddleware attributes the changes made by a request to the actor named
+// in its X-Actor header. Requests without an X-Request-ID are given one, which
+// is echoed in the response.
+//
+// The service does not authenticate clients, so X-Actor is trusted as sent. It
+// must be set by the authenticating gateway in front of the service, which
+// also strips any X-Actor header sent by the client.
+func auditMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(RequestIDHeader)
+		if requestID == "" {
+			requestID = uuid.NewString()
+		}
+		w.Header().Set(RequestIDHeader, requestID)
+
+		ctx := WithAuditInfo(r.Context(), strings.TrimSpace(r.Header.Get(ActorHeader)), requestID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// GetHotelHistory serves the audit entries of one hotel, newest first.
+func (h *Handler) GetHotelHistory(w http.ResponseWriter, r *http.Request) {
+	hotelID, err := uuid.Parse(mux.Vars(r)["hotelID"])
+	if err != nil {
+		http.Error(w, "Invalid hotel ID", http.StatusBadRequest)
+		return
+	}
+
+	filter, err := parseAuditFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	filter.HotelID = hotelID
+	h.writeAuditPage(w, r, filter)
+}
+
+// ListAuditEntries serves the audit log of all hotels, newest first.
+func (h *Handler) ListAuditEntries(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseAuditFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if value := r.URL.Query().Get("hotel_id"); value != "" {
+		if filter.HotelID, err = uuid.Parse(value); err != nil {
+			http.Error(w, "Invalid hotel ID", http.StatusBadRequest)
+			return
+		}
+	}
+	h.writeAuditPage(w, r, filter)
+}
+
+func (h *Handler) writeAuditPage(w http.ResponseWriter, r *http.Request, filter AuditFilter) {
+	page, err := h.hotelService.ListAuditEntries(filter)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+// parseAuditFilter reads the filter and pagination parameters of the audit log.
+// Times are given in RFC 3339 format.
+func parseAuditFilter(r *http.Request) (AuditFilter, error) {
+	query := r.URL.Query()
+	filter := AuditFilter{
+		Entity: query.Get("entity"),
+		Action: query.Get("action"),
+		Actor:  query.Get("actor"),
+		Cursor: query.Get("cursor"),
+	}
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 0 {
+			return filter, fmt.Errorf("limit parameter must be a non-negative integer")
+		}
+		filter.Limit = limit
+	}
+
+	for name, target := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+		if value := query.Get(name); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return filter, fmt.Errorf("%s parameter must be an RFC 3339 time", name)
+			}
+			*target = parsed
+		}
+	}
+	return filter, nil
+}
+
+func (h *Handler) ListContactTypes(w http.ResponseWriter, r *http.Request) {
+	contactTypes, err := h.hotelService.ListContactTypes()
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(contactTypes)
+}
+
+func (h *Handler) GetContactType(w http.ResponseWriter, r *http.Request) {
+	contactType, err := h.hotelService.GetContactType(mux.Vars(r)["name"])
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(contactType)
+}
+
+func (h *Handler) CreateContactType(w http.ResponseWriter, r *http.Request) {
+	var contactType ContactType
+	if err := json.NewDecoder(r.Body).Decode(&contactType); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.hotelService.CreateContactType(&contactType); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(contactType)
+}
+
+// UpdateContactType replaces a contact type. The name in the path wins over
+// any name in the body.
+func (h *Handler) UpdateContactType(w http.ResponseWriter, r *http.Request) {
+	var contactType ContactType
+	if err := json.NewDecoder(r.Body).Decode(&contactType); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.hotelService.UpdateContactType(mux.Vars(r)["name"], &contactType); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(contactType)
+}
+
+func (h *Handler) DeleteContactType(w http.ResponseWriter, r *http.Request) {
+	if err := h.hotelService.DeleteContactType(mux.Vars(r)["name"]); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) ListTags(w http.ResponseWriter, r *http.Request) {
+	tags, err := h.hotelService.ListTags()
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tags)
+}
+
+func (h *Handler) GetTag(w http.ResponseWriter, r *http.Request) {
+	tag, err := h.hotelService.GetTag(mux.Vars(r)["name"])
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tag)
+}
+
+func (h *Handler) CreateTag(w http.ResponseWriter, r *http.Request) {
+	var tag Tag
+	if err := json.NewDecoder(r.Body).Decode(&tag); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.hotelService.CreateTag(&tag); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(tag)
+}
+
+// UpdateTag replaces a tag. The name in the path wins over any name in the
+// body.
+func (h *Handler) UpdateTag(w http.ResponseWriter, r *http.Request) {
+	var tag Tag
+	if err := json.NewDecoder(r.Body).Decode(&tag); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.hotelService.UpdateTag(mux.Vars(r)["name"], &tag); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tag)
+}
+
+func (h *Handler) DeleteTag(w http.ResponseWriter, r *http.Request) {
+	if err := h.hotelService.DeleteTag(mux.Vars(r)["name"]); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListChains lists the chains, optionally only those of the kind of the query.
+func (h *Handler) ListChains(w http.ResponseWriter, r *http.Request) {
+	chains, err := h.hotelService.ListChains(r.URL.Query().Get("kind"))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(chains)
+}
+
+func (h *Handler) GetChain(w http.ResponseWriter, r *http.Request) {
+	chainID, err := uuid.Parse(mux.Vars(r)["chainID"])
+	if err != nil {
+		http.Error(w, "Invalid chain ID", http.StatusBadRequest)
+		return
+	}
+
+	chain, err := h.hotelService.GetChain(chainID)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(chain)
+}
+
+func (h *Handler) CreateChain(w http.ResponseWriter, r *http.Request) {
+	var chain Chain
+	if err := json.NewDecoder(r.Body).Decode(&chain); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.hotelService.CreateChain(&chain); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(chain)
+}
+
+// UpdateChain replaces the name and parent of a chain.
+func (h *Handler) UpdateChain(w http.ResponseWriter, r *http.Request) {
+	chainID, err := uuid.Parse(mux.Vars(r)["chainID"])
+	if err != nil {
+		http.Error(w, "Invalid chain ID", http.StatusBadRequest)
+		return
+	}
+
+	var chain Chain
+	if err := json.NewDecoder(r.Body).Decode(&chain); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.hotelService.UpdateChain(chainID, &chain); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(chain)
+}
+
+func (h *Handler) DeleteChain(w http.ResponseWriter, r *http.Request) {
+	chainID, err := uuid.Parse(mux.Vars(r)["chainID"])
+	if err != nil {
+		http.Error(w, "Invalid chain ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.hotelService.DeleteChain(chainID); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListChainHotels lists the hotels of a chain with the query parameters of
+// GET /hotels.
+func (h *Handler) ListChainHotels(w http.ResponseWriter, r *http.Request) {
+	chainID, err := uuid.Parse(mux.Vars(r)["chainID"])
+	if err != nil {
+		http.Error(w, "Invalid chain ID", http.StatusBadRequest)
+		return
+	}
+
+	opts, err := parseListOptions(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := h.hotelService.ListChainHotels(chainID, opts)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	chain := languageChain(w, r)
+	for i := range page.Items {
+		localizeHotel(&page.Items[i], chain)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+func (h *Handler) GetChainStats(w http.ResponseWriter, r *http.Request) {
+	chainID, err := uuid.Parse(mux.Vars(r)["chainID"])
+	if err != nil {
+		http.Error(w, "Invalid chain ID", http.StatusBadRequest)
+		return
+	}
+
+	stats, err := h.hotelService.FetchChainStats(chainID)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
+
+// writeServiceError maps errors returned by the hotel service onto HTTP status codes.
+func writeServiceError(w http.ResponseWriter, r *http.Request, err error) {
+	var validationErr *ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(struct {
+			Error  string       `json:"error"`
+			Fields []FieldError `json:"fields"`
+		}{"validation failed", validationErr.Fields})
+	case errors.Is(err, ErrVersionConflict) && r.Header.Get("If-Match") != "":
+		http.Error(w, err.Error(), http.StatusPreconditionFailed)
+	case errors.Is(err, ErrVersionConflict):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, ErrHotelNotFound), errors.Is(err, ErrContactNotFound), errors.Is(err, ErrLocationNotFound),
+		errors.Is(err, ErrContactTypeNotFound), errors.Is(err, ErrImportJobNotFound), errors.Is(err, ErrOfficialNotFound),
+		errors.Is(err, ErrRoomTypeNotFound), errors.Is(err, ErrReservationNotFound),
+		errors.Is(err, ErrReviewNotFound), errors.Is(err, ErrTagNotFound), errors.Is(err, ErrMediaNotFound), errors.Is(err, ErrThumbnailNotFound),
+		errors.Is(err, ErrTranslationNotFound), errors.Is(err, ErrRatePlanNotFound), errors.Is(err, ErrExchangeRateNotFound),
+		errors.Is(err, ErrChainNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, ErrContactTypeExists), errors.Is(err, ErrContactTypeInUse), errors.Is(err, ErrHotelNotDeleted),
+		errors.Is(err, ErrNotAvailable), errors.Is(err, ErrHoldExpired), errors.Is(err, ErrNotHold),
+		errors.Is(err, ErrTagExists), errors.Is(err, ErrTagInUse), errors.Is(err, ErrRatePlanOverlap),
+		errors.Is(err, ErrChainExists), errors.Is(err, ErrChainInUse), errors.Is(err, ErrRoomTypeInUse),
+		errors.Is(err, ErrRoomsReserved):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, ErrInvalidHotel), errors.Is(err, ErrInvalidContact), errors.Is(err, ErrInvalidLocation),
+		errors.Is(err, ErrInvalidCoordinates), errors.Is(err, ErrSearchAreaTooLarge), errors.Is(err, ErrInvalidCursor), errors.Is(err, ErrInvalidSort),
+		errors.Is(err, ErrInvalidSearchQuery), errors.Is(err, ErrInvalidContactType), errors.Is(err, ErrInvalidAuditFilter),
+		errors.Is(err, ErrInvalidImport), errors.Is(err, ErrInvalidExportFormat), errors.Is(err, ErrInvalidDateRange),
+		errors.Is(err, ErrInvalidReviewStatus), errors.Is(err, ErrInvalidTag), errors.Is(err, ErrInvalidMediaOrder),
+		errors.Is(err, ErrInvalidMediaUpload), errors.Is(err, ErrInvalidLanguage), errors.Is(err, ErrInvalidExchangeRate),
+		errors.Is(err, ErrInvalidLocationLevel), errors.Is(err, money.ErrInvalidCurrency):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrUnsupportedMedia):
+		http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+	case errors.Is(err, ErrNoRatePlan), errors.Is(err, ErrNoExchangeRate):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
 }
@@ -2,11 +2,16 @@ package hotel
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"hotel-guide/internal/money"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
@@ -18,19 +23,124 @@ type MockHotelService struct {
 	mock.Mock
 }
 
-func (m *MockHotelService) CreateHotel(ownerName, ownerSurname, companyTitle string, contacts []ContactInfo) (*Hotel, error) {
+func (m *MockHotelService) CreateHotel(_ context.Context, ownerName, ownerSurname, companyTitle string, contacts []ContactInfo) (*Hotel, error) {
 	args := m.Called(ownerName, ownerSurname, companyTitle, contacts)
 	return args.Get(0).(*Hotel), args.Error(1)
 }
 
-func (m *MockHotelService) DeleteHotel(id uuid.UUID) error {
-	args := m.Called(id)
+func (m *MockHotelService) DeleteHotel(_ context.Context, id uuid.UUID, version int) error {
+	args := m.Called(id, version)
 	return args.Error(0)
 }
 
-func (m *MockHotelService) ListHotels() ([]Hotel, error) {
+func (m *MockHotelService) RestoreHotel(_ context.Context, id uuid.UUID) (*Hotel, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Hotel), args.Error(1)
+}
+
+func (m *MockHotelService) PurgeDeletedHotels(retention time.Duration) (int64, error) {
+	args := m.Called(retention)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockHotelService) FailInterruptedImportJobs() (int64, error) {
+	args := m.Called()
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockHotelService) StartPurgeWorker(retention, interval time.Duration) {
+	m.Called(retention, interval)
+}
+
+func (m *MockHotelService) UpdateHotel(_ context.Context, id uuid.UUID, update HotelUpdate, version int) (*Hotel, error) {
+	args := m.Called(id, update, version)
+	return args.Get(0).(*Hotel), args.Error(1)
+}
+
+func (m *MockHotelService) UpdateContactInfo(_ context.Context, hotelID, contactID uuid.UUID, update ContactInfoUpdate, version int) (*ContactInfo, error) {
+	args := m.Called(hotelID, contactID, update, version)
+	return args.Get(0).(*ContactInfo), args.Error(1)
+}
+
+func (m *MockHotelService) ListHotels(opts ListOptions) (*HotelPage, error) {
+	args := m.Called(opts)
+	return args.Get(0).(*HotelPage), args.Error(1)
+}
+
+// ExportHotels writes the body given to the mock before returning its error.
+func (m *MockHotelService) ExportHotels(w io.Writer, format string, opts ListOptions) error {
+	args := m.Called(format, opts)
+	if body := args.String(0); body != "" {
+		io.WriteString(w, body)
+	}
+	return args.Error(1)
+}
+
+func (m *MockHotelService) SearchHotels(query string, limit int) ([]SearchResult, error) {
+	args := m.Called(query, limit)
+	return args.Get(0).([]SearchResult), args.Error(1)
+}
+
+func (m *MockHotelService) ListContactTypes() ([]ContactType, error) {
 	args := m.Called()
-	return args.Get(0).([]Hotel), args.Error(1)
+	return args.Get(0).([]ContactType), args.Error(1)
+}
+
+func (m *MockHotelService) GetContactType(name string) (*ContactType, error) {
+	args := m.Called(name)
+	return args.Get(0).(*ContactType), args.Error(1)
+}
+
+func (m *MockHotelService) CreateContactType(contactType *ContactType) error {
+	args := m.Called(contactType)
+	return args.Error(0)
+}
+
+func (m *MockHotelService) UpdateContactType(name string, contactType *ContactType) error {
+	args := m.Called(name, contactType)
+	return args.Error(0)
+}
+
+func (m *MockHotelService) DeleteContactType(name string) error {
+	args := m.Called(name)
+	return args.Error(0)
+}
+
+func (m *MockHotelService) ImportHotels(_ context.Context, rows []ImportRow, opts ImportOptions) (*ImportResult, error) {
+	args := m.Called(rows, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ImportResult), args.Error(1)
+}
+
+func (m *MockHotelService) StartImportJob(_ context.Context, source ImportSource, opts ImportOptions) (*ImportJob, error) {
+	// The job takes over the source
+	defer source.Close()
+	args := m.Called(source, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ImportJob), args.Error(1)
+}
+
+func (m *MockHotelService) GetImportJob(id uuid.UUID) (*ImportJob, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ImportJob), args.Error(1)
+}
+
+func (m *MockHotelService) ListAuditEntries(filter AuditFilter) (*AuditPage, error) {
+	args := m.Called(filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*AuditPage), args.Error(1)
 }
 
 func (m *MockHotelService) GetHotelDetails(id uuid.UUID) (*Hotel, error) {
@@ -38,23 +148,360 @@ func (m *MockHotelService) GetHotelDetails(id uuid.UUID) (*Hotel, error) {
 	return args.Get(0).(*Hotel), args.Error(1)
 }
 
-func (m *MockHotelService) AddContactInfo(hotelID uuid.UUID, contact *ContactInfo) error {
-	args := m.Called(hotelID, contact)
+func (m *MockHotelService) AddContactInfo(_ context.Context, hotelID uuid.UUID, contact *ContactInfo, version int) error {
+	args := m.Called(hotelID, contact, version)
+	return args.Error(0)
+}
+
+func (m *MockHotelService) SetLocation(_ context.Context, hotelID uuid.UUID, location *Location, version int) error {
+	args := m.Called(hotelID, location, version)
+	return args.Error(0)
+}
+
+func (m *MockHotelService) DeleteLocation(_ context.Context, hotelID uuid.UUID, version int) error {
+	args := m.Called(hotelID, version)
+	return args.Error(0)
+}
+
+func (m *MockHotelService) FetchLocationStats(filter LocationFilter, currency string) (*LocationStats, error) {
+	args := m.Called(filter, currency)
+	return args.Get(0).(*LocationStats), args.Error(1)
+}
+
+func (m *MockHotelService) FetchLocationStatsBatch(queries []LocationStatsQuery) ([]LocationStats, error) {
+	args := m.Called(queries)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]LocationStats), args.Error(1)
+}
+
+func (m *MockHotelService) ListLocationStats(opts LocationStatsOptions) (*LocationStatsPage, error) {
+	args := m.Called(opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*LocationStatsPage), args.Error(1)
+}
+
+func (m *MockHotelService) ListRoomTypes(hotelID uuid.UUID) ([]RoomType, error) {
+	args := m.Called(hotelID)
+	return args.Get(0).([]RoomType), args.Error(1)
+}
+
+func (m *MockHotelService) GetRoomType(hotelID, roomTypeID uuid.UUID) (*RoomType, error) {
+	args := m.Called(hotelID, roomTypeID)
+	return args.Get(0).(*RoomType), args.Error(1)
+}
+
+func (m *MockHotelService) AddRoomType(_ context.Context, hotelID uuid.UUID, roomType *RoomType, version int) error {
+	args := m.Called(hotelID, roomType, version)
+	return args.Error(0)
+}
+
+func (m *MockHotelService) UpdateRoomType(_ context.Context, hotelID, roomTypeID uuid.UUID, roomType *RoomType, version int) error {
+	args := m.Called(hotelID, roomTypeID, roomType, version)
+	return args.Error(0)
+}
+
+func (m *MockHotelService) RemoveRoomType(_ context.Context, hotelID, roomTypeID uuid.UUID, version int) error {
+	args := m.Called(hotelID, roomTypeID, version)
+	return args.Error(0)
+}
+
+func (m *MockHotelService) GetAvailability(hotelID uuid.UUID, query AvailabilityQuery) ([]RoomAvailability, error) {
+	args := m.Called(hotelID, query)
+	return args.Get(0).([]RoomAvailability), args.Error(1)
+}
+
+func (m *MockHotelService) GetReservation(hotelID, reservationID uuid.UUID) (*Reservation, error) {
+	args := m.Called(hotelID, reservationID)
+	return args.Get(0).(*Reservation), args.Error(1)
+}
+
+func (m *MockHotelService) CreateReservation(_ context.Context, hotelID uuid.UUID, reservation *Reservation) error {
+	args := m.Called(hotelID, reservation)
+	return args.Error(0)
+}
+
+func (m *MockHotelService) ConfirmReservation(_ context.Context, hotelID, reservationID uuid.UUID) (*Reservation, error) {
+	args := m.Called(hotelID, reservationID)
+	return args.Get(0).(*Reservation), args.Error(1)
+}
+
+func (m *MockHotelService) CancelReservation(_ context.Context, hotelID, reservationID uuid.UUID) error {
+	args := m.Called(hotelID, reservationID)
 	return args.Error(0)
 }
 
-func (m *MockHotelService) FetchLocationStats(location string) (int, int, error) {
-	args := m.Called(location)
-	return args.Int(0), args.Int(1), args.Error(2)
+func (m *MockHotelService) ListReviews(filter ReviewFilter) ([]Review, error) {
+	args := m.Called(filter)
+	return args.Get(0).([]Review), args.Error(1)
+}
+
+func (m *MockHotelService) AddReview(_ context.Context, hotelID uuid.UUID, review *Review) error {
+	args := m.Called(hotelID, review)
+	return args.Error(0)
+}
+
+func (m *MockHotelService) ModerateReview(_ context.Context, hotelID, reviewID uuid.UUID, status string) (*Review, error) {
+	args := m.Called(hotelID, reviewID, status)
+	return args.Get(0).(*Review), args.Error(1)
+}
+
+func (m *MockHotelService) RemoveReview(_ context.Context, hotelID, reviewID uuid.UUID) error {
+	args := m.Called(hotelID, reviewID)
+	return args.Error(0)
 }
 
-func (m *MockHotelService) ListHotelOfficials() ([]HotelOfficial, error) {
+func (m *MockHotelService) ListTags() ([]Tag, error) {
 	args := m.Called()
+	return args.Get(0).([]Tag), args.Error(1)
+}
+
+func (m *MockHotelService) GetTag(name string) (*Tag, error) {
+	args := m.Called(name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Tag), args.Error(1)
+}
+
+func (m *MockHotelService) CreateTag(tag *Tag) error {
+	args := m.Called(tag)
+	return args.Error(0)
+}
+
+func (m *MockHotelService) UpdateTag(name string, tag *Tag) error {
+	args := m.Called(name, tag)
+	return args.Error(0)
+}
+
+func (m *MockHotelService) DeleteTag(name string) error {
+	args := m.Called(name)
+	return args.Error(0)
+}
+
+func (m *MockHotelService) SetHotelTags(_ context.Context, hotelID uuid.UUID, tags []string, version int) ([]string, error) {
+	args := m.Called(hotelID, tags, version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockHotelService) ListMedia(hotelID uuid.UUID) ([]Media, error) {
+	args := m.Called(hotelID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]Media), args.Error(1)
+}
+
+func (m *MockHotelService) AddMedia(_ context.Context, hotelID uuid.UUID, fileName string, data []byte, version int) (*Media, error) {
+	args := m.Called(hotelID, fileName, data, version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Media), args.Error(1)
+}
+
+func (m *MockHotelService) OpenMedia(hotelID, mediaID uuid.UUID, thumbnail bool) (*Media, io.ReadCloser, error) {
+	args := m.Called(hotelID, mediaID, thumbnail)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).(*Media), args.Get(1).(io.ReadCloser), args.Error(2)
+}
+
+func (m *MockHotelService) ReorderMedia(_ context.Context, hotelID uuid.UUID, mediaIDs []uuid.UUID, version int) ([]Media, error) {
+	args := m.Called(hotelID, mediaIDs, version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]Media), args.Error(1)
+}
+
+func (m *MockHotelService) RemoveMedia(_ context.Context, hotelID, mediaID uuid.UUID, version int) error {
+	args := m.Called(hotelID, mediaID, version)
+	return args.Error(0)
+}
+
+func (m *MockHotelService) ListTranslations(hotelID uuid.UUID) ([]HotelTranslation, error) {
+	args := m.Called(hotelID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]HotelTranslation), args.Error(1)
+}
+
+func (m *MockHotelService) FindTranslations(hotelIDs []uuid.UUID) ([]HotelTranslation, error) {
+	args := m.Called(hotelIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]HotelTranslation), args.Error(1)
+}
+
+func (m *MockHotelService) SaveTranslation(_ context.Context, hotelID uuid.UUID, language string, translation *HotelTranslation, version int) error {
+	args := m.Called(hotelID, language, translation, version)
+	return args.Error(0)
+}
+
+func (m *MockHotelService) RemoveTranslation(_ context.Context, hotelID uuid.UUID, language string, version int) error {
+	args := m.Called(hotelID, language, version)
+	return args.Error(0)
+}
+
+func (m *MockHotelService) ListMissingTranslations(filter MissingTranslationFilter) (*MissingTranslationPage, error) {
+	args := m.Called(filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*MissingTranslationPage), args.Error(1)
+}
+
+func (m *MockHotelService) ListRatePlans(hotelID, roomTypeID uuid.UUID) ([]RatePlan, error) {
+	args := m.Called(hotelID, roomTypeID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]RatePlan), args.Error(1)
+}
+
+func (m *MockHotelService) GetRatePlan(hotelID, ratePlanID uuid.UUID) (*RatePlan, error) {
+	args := m.Called(hotelID, ratePlanID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*RatePlan), args.Error(1)
+}
+
+func (m *MockHotelService) AddRatePlan(_ context.Context, hotelID uuid.UUID, plan *RatePlan, version int) error {
+	args := m.Called(hotelID, plan, version)
+	return args.Error(0)
+}
+
+func (m *MockHotelService) UpdateRatePlan(_ context.Context, hotelID, ratePlanID uuid.UUID, plan *RatePlan, version int) error {
+	args := m.Called(hotelID, ratePlanID, plan, version)
+	return args.Error(0)
+}
+
+func (m *MockHotelService) RemoveRatePlan(_ context.Context, hotelID, ratePlanID uuid.UUID, version int) error {
+	args := m.Called(hotelID, ratePlanID, version)
+	return args.Error(0)
+}
+
+func (m *MockHotelService) QuoteStay(hotelID uuid.UUID, request QuoteRequest) (*Quote, error) {
+	args := m.Called(hotelID, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Quote), args.Error(1)
+}
+
+func (m *MockHotelService) ListExchangeRates(filter ExchangeRateFilter) ([]ExchangeRate, error) {
+	args := m.Called(filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]ExchangeRate), args.Error(1)
+}
+
+func (m *MockHotelService) SaveExchangeRate(rate *ExchangeRate) error {
+	args := m.Called(rate)
+	return args.Error(0)
+}
+
+func (m *MockHotelService) RemoveExchangeRate(base, quote string, effectiveDate time.Time) error {
+	args := m.Called(base, quote, effectiveDate)
+	return args.Error(0)
+}
+
+func (m *MockHotelService) ListChains(kind string) ([]Chain, error) {
+	args := m.Called(kind)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]Chain), args.Error(1)
+}
+
+func (m *MockHotelService) GetChain(id uuid.UUID) (*Chain, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Chain), args.Error(1)
+}
+
+func (m *MockHotelService) CreateChain(chain *Chain) error {
+	args := m.Called(chain)
+	return args.Error(0)
+}
+
+func (m *MockHotelService) UpdateChain(id uuid.UUID, chain *Chain) error {
+	args := m.Called(id, chain)
+	return args.Error(0)
+}
+
+func (m *MockHotelService) DeleteChain(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockHotelService) SetHotelChain(_ context.Context, hotelID uuid.UUID, chainID *uuid.UUID, version int) error {
+	args := m.Called(hotelID, chainID, version)
+	return args.Error(0)
+}
+
+func (m *MockHotelService) ListChainHotels(chainID uuid.UUID, opts ListOptions) (*HotelPage, error) {
+	args := m.Called(chainID, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*HotelPage), args.Error(1)
+}
+
+func (m *MockHotelService) FetchChainStats(chainID uuid.UUID) (*ChainStats, error) {
+	args := m.Called(chainID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ChainStats), args.Error(1)
+}
+
+func (m *MockHotelService) FindNearbyHotels(lat, lng, radiusKm float64, limit int) ([]HotelDistance, error) {
+	args := m.Called(lat, lng, radiusKm, limit)
+	return args.Get(0).([]HotelDistance), args.Error(1)
+}
+
+func (m *MockHotelService) FindHotelsInBoundingBox(box BoundingBox, limit int) ([]HotelDistance, error) {
+	args := m.Called(box, limit)
+	return args.Get(0).([]HotelDistance), args.Error(1)
+}
+
+func (m *MockHotelService) ListHotelOfficials(filter OfficialFilter) ([]HotelOfficial, error) {
+	args := m.Called(filter)
 	return args.Get(0).([]HotelOfficial), args.Error(1)
 }
 
-func (m *MockHotelService) RemoveContactInfo(hotelID uuid.UUID, contactUUID uuid.UUID) error {
-	args := m.Called(hotelID, contactUUID)
+func (m *MockHotelService) AddOfficial(_ context.Context, hotelID uuid.UUID, official *HotelOfficial, version int) error {
+	args := m.Called(hotelID, official, version)
+	return args.Error(0)
+}
+
+func (m *MockHotelService) UpdateOfficial(_ context.Context, hotelID, officialID uuid.UUID, official *HotelOfficial, version int) error {
+	args := m.Called(hotelID, officialID, official, version)
+	return args.Error(0)
+}
+
+func (m *MockHotelService) RemoveOfficial(_ context.Context, hotelID, officialID uuid.UUID, version int) error {
+	args := m.Called(hotelID, officialID, version)
+	return args.Error(0)
+}
+
+func (m *MockHotelService) RemoveContactInfo(_ context.Context, hotelID uuid.UUID, contactUUID uuid.UUID, version int) error {
+	args := m.Called(hotelID, contactUUID, version)
 	return args.Error(0)
 }
 
@@ -104,7 +551,7 @@ func TestDeleteHotel_Handler(t *testing.T) {
 	// Test data
 	hotelID := uuid.New()
 
-	mockService.On("DeleteHotel", hotelID).Return(nil)
+	mockService.On("DeleteHotel", hotelID, 0).Return(nil)
 
 	// Prepare the request with valid hotel ID
 	req := httptest.NewRequest(http.MethodDelete, "/hotels/"+hotelID.String(), nil)
@@ -149,7 +596,8 @@ func TestListHotels_Handler(t *testing.T) {
 		CompanyTitle: "Alice's Inns",
 	}
 
-	mockService.On("ListHotels").Return([]Hotel{hotel}, nil)
+	page := &HotelPage{Items: []Hotel{hotel}, Total: 1, Limit: DefaultPageLimit}
+	mockService.On("ListHotels", ListOptions{}).Return(page, nil)
 
 	// Prepare the request
 	req := httptest.NewRequest(http.MethodGet, "/hotels", nil)
@@ -162,11 +610,69 @@ func TestListHotels_Handler(t *testing.T) {
 
 	// Assert status code and response body
 	assert.Equal(t, http.StatusOK, rr.Code)
-	var hotels []Hotel
-	err := json.NewDecoder(rr.Body).Decode(&hotels)
+	var response HotelPage
+	err := json.NewDecoder(rr.Body).Decode(&response)
+	assert.NoError(t, err)
+	assert.Len(t, response.Items, 1)
+	assert.Equal(t, "Alice's Inns", response.Items[0].CompanyTitle)
+	assert.Equal(t, int64(1), response.Total)
+	assert.Equal(t, "/hotels", response.Links.Self)
+	assert.Empty(t, response.Links.Next)
+	mockService.AssertExpectations(t)
+}
+
+func TestListHotels_Handler_FiltersAndNextLink(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	// Test data
+	opts := ListOptions{
+		Limit:        1,
+		Offset:       2,
+		SortBy:       "company_title",
+		SortDesc:     true,
+		CompanyTitle: "inn",
+		ContactType:  ContactTypeEmail,
+		Location:     LocationFilter{City: "Istanbul"},
+	}
+	page := &HotelPage{Items: []Hotel{{ID: uuid.New(), CompanyTitle: "Bosphorus Inn"}}, Total: 5, Limit: 1, Offset: 2, NextCursor: "abc"}
+	mockService.On("ListHotels", opts).Return(page, nil)
+
+	// Prepare the request
+	req := httptest.NewRequest(http.MethodGet, "/hotels?limit=1&offset=2&sort=-company_title&company_title=inn&contact_type=email&city=Istanbul", nil)
+	rr := httptest.NewRecorder()
+
+	// Register routes and handle request
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+	r.ServeHTTP(rr, req)
+
+	// Assert status code and response body
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var response HotelPage
+	err := json.NewDecoder(rr.Body).Decode(&response)
 	assert.NoError(t, err)
-	assert.Len(t, hotels, 1)
-	assert.Equal(t, "Alice's Inns", hotels[0].CompanyTitle)
+	assert.Equal(t, "abc", response.NextCursor)
+	assert.Contains(t, response.Links.Next, "cursor=abc")
+	assert.NotContains(t, response.Links.Next, "offset=")
+	mockService.AssertExpectations(t)
+}
+
+func TestListHotels_Handler_InvalidParams(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	mockService.On("ListHotels", ListOptions{SortBy: "popularity"}).Return((*HotelPage)(nil), ErrInvalidSort)
+
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+
+	for _, target := range []string{"/hotels?limit=abc", "/hotels?offset=-1", "/hotels?sort=popularity"} {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, target)
+	}
 	mockService.AssertExpectations(t)
 }
 
@@ -239,7 +745,7 @@ func TestAddContactInfo_Handler(t *testing.T) {
 		InfoContent: "987654321", // Add info content
 	}
 
-	mockService.On("AddContactInfo", hotelID, &contact).Return(nil)
+	mockService.On("AddContactInfo", hotelID, &contact, 0).Return(nil)
 
 	// Prepare the request
 	requestBody := `{"info_type": "phone", "info_content": "987654321"}`
@@ -285,7 +791,7 @@ func TestRemoveContactInfo_Handler(t *testing.T) {
 	hotelID := uuid.New()
 	contactID := uuid.New()
 
-	mockService.On("RemoveContactInfo", hotelID, contactID).Return(nil)
+	mockService.On("RemoveContactInfo", hotelID, contactID, 0).Return(nil)
 
 	// Prepare the request
 	req := httptest.NewRequest(http.MethodDelete, "/hotels/"+hotelID.String()+"/contacts/"+contactID.String(), nil)
@@ -348,7 +854,8 @@ func TestGetHotelStats_Handler(t *testing.T) {
 	hotelCount := 10
 	phoneCount := 5
 
-	mockService.On("FetchLocationStats", location).Return(hotelCount, phoneCount, nil)
+	mockService.On("FetchLocationStats", LocationFilter{Name: location}, "").
+		Return(&LocationStats{HotelCount: hotelCount, PhoneCount: phoneCount, RoomCount: 120, BedCount: 180}, nil)
 
 	// Prepare the request with valid location query
 	req := httptest.NewRequest(http.MethodGet, "/hotels/stats?location="+location, nil)
@@ -364,11 +871,15 @@ func TestGetHotelStats_Handler(t *testing.T) {
 	var response struct {
 		HotelCount int `json:"hotel_count"`
 		PhoneCount int `json:"phone_count"`
+		RoomCount  int `json:"room_count"`
+		BedCount   int `json:"bed_count"`
 	}
 	err := json.NewDecoder(rr.Body).Decode(&response)
 	assert.NoError(t, err)
 	assert.Equal(t, hotelCount, response.HotelCount)
 	assert.Equal(t, phoneCount, response.PhoneCount)
+	assert.Equal(t, 120, response.RoomCount)
+	assert.Equal(t, 180, response.BedCount)
 	mockService.AssertExpectations(t)
 }
 
@@ -396,7 +907,7 @@ func TestGetHotelStats_InternalError(t *testing.T) {
 	// Test data
 	location := "Paris"
 
-	mockService.On("FetchLocationStats", location).Return(0, 0, fmt.Errorf("internal error"))
+	mockService.On("FetchLocationStats", LocationFilter{Name: location}, "").Return((*LocationStats)(nil), fmt.Errorf("internal error"))
 
 	// Prepare the request with valid location query
 	req := httptest.NewRequest(http.MethodGet, "/hotels/stats?location="+location, nil)
@@ -410,3 +921,1891 @@ func TestGetHotelStats_InternalError(t *testing.T) {
 	// Assert status code
 	assert.Equal(t, http.StatusInternalServerError, rr.Code)
 }
+
+func TestGetHotelStats_Currency(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	median := money.MustParse("3549.65")
+	mockService.On("FetchLocationStats", LocationFilter{City: "Istanbul"}, "TRY").
+		Return(&LocationStats{HotelCount: 3, MedianNightlyPrice: &median, Currency: "TRY"}, nil)
+	mockService.On("FetchLocationStats", LocationFilter{City: "Istanbul"}, "lira").Return((*LocationStats)(nil), money.ErrInvalidCurrency)
+
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+
+	req := httptest.NewRequest(http.MethodGet, "/hotels/stats?city=Istanbul&currency=TRY", nil)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"median_nightly_price":"3549.65","currency":"TRY"`)
+
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/hotels/stats?city=Istanbul&currency=lira", nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestReplaceHotel_Handler(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	// Test data
+	hotelID := uuid.New()
+	hotel := &Hotel{
+		ID:           hotelID,
+		OwnerName:    "Jane",
+		OwnerSurname: "Doe",
+		CompanyTitle: "JD Suites",
+	}
+
+	mockService.On("UpdateHotel", hotelID, mock.MatchedBy(func(u HotelUpdate) bool {
+		return *u.OwnerName == "Jane" && *u.OwnerSurname == "Doe" && *u.CompanyTitle == "JD Suites"
+	}), 0).Return(hotel, nil)
+
+	// Prepare the request
+	requestBody := `{"owner_name": "Jane", "owner_surname": "Doe", "company_title": "JD Suites"}`
+	req := httptest.NewRequest(http.MethodPut, "/hotels/"+hotelID.String(), bytes.NewBufferString(requestBody))
+	rr := httptest.NewRecorder()
+
+	// Register routes and handle request
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+	r.ServeHTTP(rr, req)
+
+	// Assert status code and response body
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var response Hotel
+	err := json.NewDecoder(rr.Body).Decode(&response)
+	assert.NoError(t, err)
+	assert.Equal(t, "JD Suites", response.CompanyTitle)
+	mockService.AssertExpectations(t)
+}
+
+func TestPatchHotel_Handler(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	// Test data
+	hotelID := uuid.New()
+	hotel := &Hotel{
+		ID:           hotelID,
+		OwnerName:    "John",
+		OwnerSurname: "Doe",
+		CompanyTitle: "JD Grand Hotels",
+	}
+
+	// Only the patched member should be set on the update
+	mockService.On("UpdateHotel", hotelID, mock.MatchedBy(func(u HotelUpdate) bool {
+		return u.OwnerName == nil && u.OwnerSurname == nil && *u.CompanyTitle == "JD Grand Hotels"
+	}), 0).Return(hotel, nil)
+
+	// Prepare the request
+	requestBody := `{"company_title": "JD Grand Hotels"}`
+	req := httptest.NewRequest(http.MethodPatch, "/hotels/"+hotelID.String(), bytes.NewBufferString(requestBody))
+	rr := httptest.NewRecorder()
+
+	// Register routes and handle request
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+	r.ServeHTTP(rr, req)
+
+	// Assert status code
+	assert.Equal(t, http.StatusOK, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestPatchHotel_NotFound(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	// Test data
+	hotelID := uuid.New()
+	var hotel *Hotel
+
+	mockService.On("UpdateHotel", hotelID, mock.Anything, 0).Return(hotel, fmt.Errorf("failed to update hotel: %w", ErrHotelNotFound))
+
+	// Prepare the request
+	req := httptest.NewRequest(http.MethodPatch, "/hotels/"+hotelID.String(), bytes.NewBufferString(`{"owner_name": "Jane"}`))
+	rr := httptest.NewRecorder()
+
+	// Register routes and handle request
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+	r.ServeHTTP(rr, req)
+
+	// Assert status code
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestPatchHotel_UnknownField(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	// Prepare the request with a member that cannot be patched
+	hotelID := uuid.New()
+	req := httptest.NewRequest(http.MethodPatch, "/hotels/"+hotelID.String(), bytes.NewBufferString(`{"id": "abc"}`))
+	rr := httptest.NewRecorder()
+
+	// Register routes and handle request
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+	r.ServeHTTP(rr, req)
+
+	// Assert status code
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestPatchContactInfo_Handler(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	// Test data
+	hotelID := uuid.New()
+	contactID := uuid.New()
+	contact := &ContactInfo{
+		ID:          contactID,
+		HotelID:     hotelID,
+		InfoType:    ContactTypePhone,
+		InfoContent: "5551234",
+	}
+
+	mockService.On("UpdateContactInfo", hotelID, contactID, mock.MatchedBy(func(u ContactInfoUpdate) bool {
+		return u.InfoType == nil && *u.InfoContent == "5551234"
+	}), 0).Return(contact, nil)
+
+	// Prepare the request
+	req := httptest.NewRequest(http.MethodPatch, "/hotels/"+hotelID.String()+"/contacts/"+contactID.String(), bytes.NewBufferString(`{"info_content": "5551234"}`))
+	rr := httptest.NewRecorder()
+
+	// Register routes and handle request
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+	r.ServeHTTP(rr, req)
+
+	// Assert status code and response body
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var response ContactInfo
+	err := json.NewDecoder(rr.Body).Decode(&response)
+	assert.NoError(t, err)
+	assert.Equal(t, "5551234", response.InfoContent)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetHotelDetails_ETag(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	// Test data
+	hotelID := uuid.New()
+	hotel := &Hotel{ID: hotelID, CompanyTitle: "Eve's Resorts", Version: 3}
+
+	mockService.On("GetHotelDetails", hotelID).Return(hotel, nil)
+
+	// Register routes
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+
+	// The version is returned as the entity tag
+	req := httptest.NewRequest(http.MethodGet, "/hotels/"+hotelID.String(), nil)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `"3-0-0"`, rr.Header().Get("ETag"))
+
+	// A matching If-None-Match yields 304 without a body
+	req = httptest.NewRequest(http.MethodGet, "/hotels/"+hotelID.String(), nil)
+	req.Header.Set("If-None-Match", `"3-0-0"`)
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNotModified, rr.Code)
+	assert.Empty(t, rr.Body.String())
+
+	// A stale If-None-Match returns the full representation
+	req = httptest.NewRequest(http.MethodGet, "/hotels/"+hotelID.String(), nil)
+	req.Header.Set("If-None-Match", `"2-0-0"`)
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetHotelDetails_ETagAfterReviewApproval(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	// Test data
+	hotelID, reviewID := uuid.New(), uuid.New()
+	before := &Hotel{ID: hotelID, CompanyTitle: "Eve's Resorts", Version: 3}
+	after := &Hotel{ID: hotelID, CompanyTitle: "Eve's Resorts", Version: 3, ReviewCount: 1, ScoreTotal: 8, AverageScore: 8}
+
+	mockService.On("GetHotelDetails", hotelID).Return(before, nil).Once()
+	mockService.On("ModerateReview", hotelID, reviewID, ReviewApproved).Return(&Review{ID: reviewID, Status: ReviewApproved}, nil)
+	mockService.On("GetHotelDetails", hotelID).Return(after, nil).Once()
+	mockService.On("UpdateHotel", hotelID, mock.Anything, 3).Return(after, nil)
+
+	// Register routes
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+
+	req := httptest.NewRequest(http.MethodGet, "/hotels/"+hotelID.String(), nil)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	cached := rr.Header().Get("ETag")
+
+	// Approving a review changes the rating but not the version
+	req = httptest.NewRequest(http.MethodPatch, "/hotels/"+hotelID.String()+"/reviews/"+reviewID.String(), bytes.NewBufferString(`{"status":"approved"}`))
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	// The cached representation is stale, so it is sent again
+	req = httptest.NewRequest(http.MethodGet, "/hotels/"+hotelID.String(), nil)
+	req.Header.Set("If-None-Match", cached)
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `"3-1-8"`, rr.Header().Get("ETag"))
+	var response Hotel
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	assert.Equal(t, 1, response.ReviewCount)
+
+	// The tag still carries the version for If-Match
+	req = httptest.NewRequest(http.MethodPatch, "/hotels/"+hotelID.String(), bytes.NewBufferString(`{"owner_name": "Eve"}`))
+	req.Header.Set("If-Match", rr.Header().Get("ETag"))
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetHotelDetails_ETagPerLanguage(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	// Test data
+	hotelID := uuid.New()
+	hotel := func() *Hotel {
+		return &Hotel{ID: hotelID, CompanyTitle: "Pera Palace", Version: 3, Translations: []HotelTranslation{
+			{HotelID: hotelID, Language: "de", CompanyTitle: "Pera Palast"},
+		}}
+	}
+	mockService.On("GetHotelDetails", hotelID).Return(hotel(), nil).Once()
+	mockService.On("GetHotelDetails", hotelID).Return(hotel(), nil).Once()
+
+	// Register routes
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+
+	// The tag names the language the hotel was translated from
+	req := httptest.NewRequest(http.MethodGet, "/hotels/"+hotelID.String(), nil)
+	req.Header.Set("Accept-Language", "de-DE")
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `"3-0-0-de"`, rr.Header().Get("ETag"))
+
+	// A representation cached for another language does not match
+	req = httptest.NewRequest(http.MethodGet, "/hotels/"+hotelID.String(), nil)
+	req.Header.Set("Accept-Language", "tr")
+	req.Header.Set("If-None-Match", `"3-0-0-de"`)
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `"3-0-0"`, rr.Header().Get("ETag"))
+	var response Hotel
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	assert.Equal(t, "Pera Palace", response.CompanyTitle)
+	mockService.AssertExpectations(t)
+}
+
+func TestPatchHotel_IfMatch(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	// Test data
+	hotelID := uuid.New()
+	hotel := &Hotel{ID: hotelID, CompanyTitle: "JD Grand Hotels", Version: 5}
+
+	mockService.On("UpdateHotel", hotelID, mock.Anything, 4).Return(hotel, nil)
+
+	// Prepare the request with the expected version
+	req := httptest.NewRequest(http.MethodPatch, "/hotels/"+hotelID.String(), bytes.NewBufferString(`{"company_title": "JD Grand Hotels"}`))
+	req.Header.Set("If-Match", `"4"`)
+	rr := httptest.NewRecorder()
+
+	// Register routes and handle request
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+	r.ServeHTTP(rr, req)
+
+	// Assert status code and the new entity tag
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `"5-0-0"`, rr.Header().Get("ETag"))
+	mockService.AssertExpectations(t)
+}
+
+func TestPatchHotel_PreconditionFailed(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	// Test data
+	hotelID := uuid.New()
+	var hotel *Hotel
+
+	mockService.On("UpdateHotel", hotelID, mock.Anything, 2).Return(hotel, ErrVersionConflict)
+
+	// Prepare the request with a stale version
+	req := httptest.NewRequest(http.MethodPatch, "/hotels/"+hotelID.String(), bytes.NewBufferString(`{"owner_name": "Jane"}`))
+	req.Header.Set("If-Match", `"2"`)
+	rr := httptest.NewRecorder()
+
+	// Register routes and handle request
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+	r.ServeHTTP(rr, req)
+
+	// Assert status code
+	assert.Equal(t, http.StatusPreconditionFailed, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestDeleteHotel_IfMatchUnknownHotel(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	// A version precondition on a hotel that does not exist is not a conflict
+	hotelID := uuid.New()
+	mockService.On("DeleteHotel", hotelID, 3).Return(ErrHotelNotFound)
+
+	req := httptest.NewRequest(http.MethodDelete, "/hotels/"+hotelID.String(), nil)
+	req.Header.Set("If-Match", `"3"`)
+	rr := httptest.NewRecorder()
+
+	// Register routes and handle request
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+	r.ServeHTTP(rr, req)
+
+	// Assert status code
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestDeleteHotel_InvalidIfMatch(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	// Prepare the request with a malformed entity tag
+	req := httptest.NewRequest(http.MethodDelete, "/hotels/"+uuid.New().String(), nil)
+	req.Header.Set("If-Match", "abc")
+	rr := httptest.NewRecorder()
+
+	// Register routes and handle request
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+	r.ServeHTTP(rr, req)
+
+	// Assert status code
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	mockService.AssertNotCalled(t, "DeleteHotel", mock.Anything, mock.Anything)
+}
+
+func TestParseIfMatch(t *testing.T) {
+	for header, expected := range map[string]int{
+		"":                       0,
+		"*":                      0,
+		`"4"`:                    4,
+		`"4-12-97-de+en"`:        4,
+		`"3-0-0", "5-0-0" , "4"`: 5,
+		`W/"4"`:                  noVersion,
+		`W/"6-0-0", "4-0-0"`:     4,
+		`W/"any", W/"other"`:     noVersion,
+	} {
+		req := httptest.NewRequest(http.MethodDelete, "/hotels/"+uuid.New().String(), nil)
+		req.Header.Set("If-Match", header)
+		version, err := parseIfMatch(req)
+		assert.NoError(t, err, header)
+		assert.Equal(t, expected, version, header)
+	}
+
+	for _, header := range []string{"abc", `"abc"`, `"0"`, `"4", 5`, `"4",`} {
+		req := httptest.NewRequest(http.MethodDelete, "/hotels/"+uuid.New().String(), nil)
+		req.Header.Set("If-Match", header)
+		_, err := parseIfMatch(req)
+		assert.Error(t, err, header)
+	}
+}
+
+func TestDeleteHotel_WeakIfMatch(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	// A weak tag never matches, so the precondition fails
+	hotelID := uuid.New()
+	mockService.On("DeleteHotel", hotelID, noVersion).Return(ErrVersionConflict)
+
+	req := httptest.NewRequest(http.MethodDelete, "/hotels/"+hotelID.String(), nil)
+	req.Header.Set("If-Match", `W/"3-0-0"`)
+	rr := httptest.NewRecorder()
+
+	// Register routes and handle request
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+	r.ServeHTTP(rr, req)
+
+	// Assert status code
+	assert.Equal(t, http.StatusPreconditionFailed, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetHotelStats_LocationLevels(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	// Test data
+	filter := LocationFilter{Country: "Turkey", City: "Istanbul", District: "Besiktas"}
+	mockService.On("FetchLocationStats", filter, "").Return(&LocationStats{HotelCount: 4, PhoneCount: 6}, nil)
+
+	// Prepare the request targeting every location level
+	req := httptest.NewRequest(http.MethodGet, "/hotels/stats?country=Turkey&city=Istanbul&district=Besiktas", nil)
+	rr := httptest.NewRecorder()
+
+	// Register routes and handle request
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+	r.ServeHTTP(rr, req)
+
+	// Assert status code
+	assert.Equal(t, http.StatusOK, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestSetLocation_Handler(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	// Test data
+	hotelID := uuid.New()
+	mockService.On("SetLocation", hotelID, mock.MatchedBy(func(l *Location) bool {
+		return l.Country == "Turkey" && l.City == "Istanbul" && *l.Latitude == 41.0082
+	}), 0).Return(nil)
+
+	// Prepare the request
+	requestBody := `{"country": "Turkey", "city": "Istanbul", "street": "Istiklal Cd. 1", "latitude": 41.0082, "longitude": 28.9784}`
+	req := httptest.NewRequest(http.MethodPut, "/hotels/"+hotelID.String()+"/location", bytes.NewBufferString(requestBody))
+	rr := httptest.NewRecorder()
+
+	// Register routes and handle request
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+	r.ServeHTTP(rr, req)
+
+	// Assert status code
+	assert.Equal(t, http.StatusOK, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestSetLocation_Handler_Invalid(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	// Test data
+	hotelID := uuid.New()
+	mockService.On("SetLocation", hotelID, mock.Anything, 0).Return(ErrInvalidLocation)
+
+	// Prepare the request without a city
+	req := httptest.NewRequest(http.MethodPut, "/hotels/"+hotelID.String()+"/location", bytes.NewBufferString(`{"country": "Turkey"}`))
+	rr := httptest.NewRecorder()
+
+	// Register routes and handle request
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+	r.ServeHTTP(rr, req)
+
+	// Assert status code
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestListNearbyHotels_Handler(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	// Test data
+	hotel := HotelDistance{Hotel: Hotel{ID: uuid.New(), CompanyTitle: "Taksim Suites"}, DistanceKm: 1.25}
+	mockService.On("FindNearbyHotels", 41.0369, 28.985, 5.0, 0).Return([]HotelDistance{hotel}, nil)
+
+	// Prepare the request
+	req := httptest.NewRequest(http.MethodGet, "/hotels/nearby?lat=41.0369&lng=28.985&radius_km=5", nil)
+	rr := httptest.NewRecorder()
+
+	// Register routes and handle request
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+	r.ServeHTTP(rr, req)
+
+	// Assert status code and that the distance is part of each result
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var response []map[string]interface{}
+	err := json.NewDecoder(rr.Body).Decode(&response)
+	assert.NoError(t, err)
+	assert.Len(t, response, 1)
+	assert.Equal(t, "Taksim Suites", response[0]["company_title"])
+	assert.Equal(t, 1.25, response[0]["distance_km"])
+	mockService.AssertExpectations(t)
+}
+
+func TestListNearbyHotels_MissingRadius(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	// Prepare the request without a radius
+	req := httptest.NewRequest(http.MethodGet, "/hotels/nearby?lat=41.0369&lng=28.985", nil)
+	rr := httptest.NewRecorder()
+
+	// Register routes and handle request
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+	r.ServeHTTP(rr, req)
+
+	// Assert status code
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestListHotels_BoundingBox(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	// Test data
+	box := BoundingBox{MinLng: 28.9, MinLat: 40.9, MaxLng: 29.1, MaxLat: 41.1}
+	hotel := HotelDistance{Hotel: Hotel{ID: uuid.New(), CompanyTitle: "Bosphorus Inn"}, DistanceKm: 0.4}
+	mockService.On("FindHotelsInBoundingBox", box, 5).Return([]HotelDistance{hotel}, nil)
+
+	// Prepare the request
+	req := httptest.NewRequest(http.MethodGet, "/hotels?bbox=28.9,40.9,29.1,41.1&limit=5", nil)
+	rr := httptest.NewRecorder()
+
+	// Register routes and handle request
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+	r.ServeHTTP(rr, req)
+
+	// Assert status code and response body
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var response []HotelDistance
+	err := json.NewDecoder(rr.Body).Decode(&response)
+	assert.NoError(t, err)
+	assert.Len(t, response, 1)
+	assert.Equal(t, 0.4, response[0].DistanceKm)
+	mockService.AssertExpectations(t)
+}
+
+func TestListHotels_InvalidBoundingBox(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	// Prepare the request with only three coordinates
+	req := httptest.NewRequest(http.MethodGet, "/hotels?bbox=28.9,40.9,29.1", nil)
+	rr := httptest.NewRecorder()
+
+	// Register routes and handle request
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+	r.ServeHTTP(rr, req)
+
+	// Assert status code
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestListHotels_BoundingBoxWithListParameters(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	// Prepare the request with a cursor, which a bounding box search cannot page
+	req := httptest.NewRequest(http.MethodGet, "/hotels?bbox=28.9,40.9,29.1,41.1&cursor=abc", nil)
+	rr := httptest.NewRecorder()
+
+	// Register routes and handle request
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+	r.ServeHTTP(rr, req)
+
+	// Assert status code and that the parameter is named
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "cursor")
+	mockService.AssertNotCalled(t, "FindHotelsInBoundingBox", mock.Anything, mock.Anything)
+}
+
+func TestSearchHotels_Handler(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	// Test data
+	result := SearchResult{
+		ID:           uuid.New(),
+		CompanyTitle: "Pera Palace",
+		Score:        3,
+		Matches:      []SearchMatch{{Field: "company_title", Value: "Pera Palace", Highlighted: "<em>Pera</em> Palace"}},
+	}
+	mockService.On("SearchHotels", "pera", 5).Return([]SearchResult{result}, nil)
+	mockService.On("FindTranslations", []uuid.UUID{result.ID}).Return([]HotelTranslation{
+		{HotelID: result.ID, Language: "de", CompanyTitle: "Pera Palast"},
+	}, nil)
+
+	// Prepare the request
+	req := httptest.NewRequest(http.MethodGet, "/hotels/search?q=pera&limit=5", nil)
+	req.Header.Set("Accept-Language", "de-DE")
+	rr := httptest.NewRecorder()
+
+	// Register routes and handle request
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+	r.ServeHTTP(rr, req)
+
+	// Assert status code and response body
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var response []SearchResult
+	err := json.NewDecoder(rr.Body).Decode(&response)
+	assert.NoError(t, err)
+	if assert.Len(t, response, 1) {
+		assert.Equal(t, "Pera Palast", response[0].CompanyTitle)
+		assert.Equal(t, "<em>Pera</em> Palace", response[0].Matches[0].Highlighted)
+	}
+	assert.Equal(t, "Accept-Language", rr.Header().Get("Vary"))
+	mockService.AssertExpectations(t)
+}
+
+func TestSearchHotels_Handler_MissingQuery(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	// Prepare the request without q
+	req := httptest.NewRequest(http.MethodGet, "/hotels/search", nil)
+	rr := httptest.NewRecorder()
+
+	// Register routes and handle request
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+	r.ServeHTTP(rr, req)
+
+	// Assert status code
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	mockService.AssertNotCalled(t, "SearchHotels", mock.Anything, mock.Anything)
+}
+
+func TestAddContactInfo_Handler_ValidationError(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	// Test data
+	hotelID := uuid.New()
+	validationErr := &ValidationError{Fields: []FieldError{{Field: "info_content", Message: "must be an email address"}}}
+	mockService.On("AddContactInfo", hotelID, mock.Anything, 0).Return(fmt.Errorf("failed to add contact info: %w", validationErr))
+
+	// Prepare the request
+	requestBody := `{"info_type": "email", "info_content": "not-an-email"}`
+	req := httptest.NewRequest(http.MethodPost, "/hotels/"+hotelID.String()+"/contacts", bytes.NewBufferString(requestBody))
+	rr := httptest.NewRecorder()
+
+	// Register routes and handle request
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+	r.ServeHTTP(rr, req)
+
+	// Assert status code and the field errors in the body
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	var response struct {
+		Fields []FieldError `json:"fields"`
+	}
+	err := json.NewDecoder(rr.Body).Decode(&response)
+	assert.NoError(t, err)
+	assert.Equal(t, validationErr.Fields, response.Fields)
+	mockService.AssertExpectations(t)
+}
+
+func TestCreateHotel_Handler_InvalidHotel(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	mockService.On("CreateHotel", "", "Doe", "JD Hotels", mock.Anything).Return((*Hotel)(nil), ErrInvalidHotel)
+
+	// Prepare the request without an owner name
+	requestBody := `{"ownerSurname": "Doe", "companyTitle": "JD Hotels"}`
+	req := httptest.NewRequest(http.MethodPost, "/hotels", bytes.NewBufferString(requestBody))
+	rr := httptest.NewRecorder()
+
+	// Register routes and handle request
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+	r.ServeHTTP(rr, req)
+
+	// Assert status code
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestCreateContactType_Handler(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	// Test data
+	expected := &ContactType{Name: "website", DisplayName: "Website", Kind: ContactKindURL, UniquePerHotel: true}
+	mockService.On("CreateContactType", expected).Return(nil)
+
+	// Prepare the request
+	requestBody := `{"name": "website", "display_name": "Website", "kind": "url", "unique_per_hotel": true}`
+	req := httptest.NewRequest(http.MethodPost, "/contact-types", bytes.NewBufferString(requestBody))
+	rr := httptest.NewRecorder()
+
+	// Register routes and handle request
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+	r.ServeHTTP(rr, req)
+
+	// Assert status code and response body
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	var response ContactType
+	err := json.NewDecoder(rr.Body).Decode(&response)
+	assert.NoError(t, err)
+	assert.Equal(t, *expected, response)
+	mockService.AssertExpectations(t)
+}
+
+func TestContactType_Handler_Errors(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	mockService.On("GetContactType", "pager").Return((*ContactType)(nil), ErrContactTypeNotFound)
+	mockService.On("DeleteContactType", ContactTypePhone).Return(ErrContactTypeInUse)
+	mockService.On("CreateContactType", mock.Anything).Return(ErrContactTypeExists)
+
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+
+	tests := []struct {
+		method, target, body string
+		status               int
+	}{
+		{http.MethodGet, "/contact-types/pager", "", http.StatusNotFound},
+		{http.MethodDelete, "/contact-types/phone", "", http.StatusConflict},
+		{http.MethodPost, "/contact-types", `{"name": "phone", "display_name": "Phone", "kind": "phone"}`, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.target, bytes.NewBufferString(tt.body))
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		assert.Equal(t, tt.status, rr.Code, tt.method+" "+tt.target)
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestRestoreHotel_Handler(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	// Test data
+	hotelID := uuid.New()
+	mockService.On("RestoreHotel", hotelID).Return(&Hotel{ID: hotelID, CompanyTitle: "Bosphorus Inn", Version: 3}, nil)
+
+	// Prepare the request
+	req := httptest.NewRequest(http.MethodPost, "/hotels/"+hotelID.String()+"/restore", nil)
+	rr := httptest.NewRecorder()
+
+	// Register routes and handle request
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+	r.ServeHTTP(rr, req)
+
+	// Assert status code and response body
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `"3-0-0"`, rr.Header().Get("ETag"))
+	var response Hotel
+	err := json.NewDecoder(rr.Body).Decode(&response)
+	assert.NoError(t, err)
+	assert.Equal(t, "Bosphorus Inn", response.CompanyTitle)
+	mockService.AssertExpectations(t)
+}
+
+func TestRestoreHotel_Handler_Errors(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	notDeletedID, missingID := uuid.New(), uuid.New()
+	mockService.On("RestoreHotel", notDeletedID).Return(nil, fmt.Errorf("failed to restore hotel: %w", ErrHotelNotDeleted))
+	mockService.On("RestoreHotel", missingID).Return(nil, fmt.Errorf("failed to restore hotel: %w", ErrHotelNotFound))
+
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+
+	for target, status := range map[string]int{
+		"/hotels/" + notDeletedID.String() + "/restore": http.StatusConflict,
+		"/hotels/" + missingID.String() + "/restore":    http.StatusNotFound,
+		"/hotels/invalid-id/restore":                    http.StatusBadRequest,
+	} {
+		req := httptest.NewRequest(http.MethodPost, target, nil)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		assert.Equal(t, status, rr.Code, target)
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestListHotels_Handler_IncludeDeleted(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	page := &HotelPage{Items: []Hotel{}, Limit: DefaultPageLimit}
+	mockService.On("ListHotels", ListOptions{IncludeDeleted: true}).Return(page, nil)
+
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+
+	for target, status := range map[string]int{
+		"/hotels?include_deleted=true": http.StatusOK,
+		"/hotels?include_deleted=yes":  http.StatusBadRequest,
+	} {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		assert.Equal(t, status, rr.Code, target)
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestGetHotelHistory_Handler(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	// Test data
+	hotelID := uuid.New()
+	page := &AuditPage{Items: []AuditEntry{{ID: uuid.New(), HotelID: hotelID, Entity: AuditEntityHotel, Action: AuditActionCreate, Actor: "alice"}}, Limit: 10}
+	mockService.On("ListAuditEntries", AuditFilter{HotelID: hotelID, Action: AuditActionCreate, Limit: 10}).Return(page, nil)
+
+	// Prepare the request
+	req := httptest.NewRequest(http.MethodGet, "/hotels/"+hotelID.String()+"/history?action=create&limit=10", nil)
+	rr := httptest.NewRecorder()
+
+	// Register routes and handle request
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+	r.ServeHTTP(rr, req)
+
+	// Assert status code and response body
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var response AuditPage
+	err := json.NewDecoder(rr.Body).Decode(&response)
+	assert.NoError(t, err)
+	if assert.Len(t, response.Items, 1) {
+		assert.Equal(t, "alice", response.Items[0].Actor)
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestListAuditEntries_Handler(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	// Test data
+	since := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	filter := AuditFilter{Entity: AuditEntityContact, Actor: "alice", Since: since}
+	mockService.On("ListAuditEntries", filter).Return(&AuditPage{Items: []AuditEntry{}, Limit: DefaultPageLimit}, nil)
+
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+
+	for target, status := range map[string]int{
+		"/audit?entity=contact&actor=alice&since=2026-05-01T00:00:00Z": http.StatusOK,
+		"/audit?since=yesterday":     http.StatusBadRequest,
+		"/audit?hotel_id=invalid-id": http.StatusBadRequest,
+		"/audit?limit=-1":            http.StatusBadRequest,
+	} {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		assert.Equal(t, status, rr.Code, target)
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestAuditMiddleware(t *testing.T) {
+	var info auditInfo
+	handler := auditMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		info = auditInfoFrom(r.Context())
+	}))
+
+	// The actor and request ID are taken from the headers
+	req := httptest.NewRequest(http.MethodDelete, "/hotels/"+uuid.NewString(), nil)
+	req.Header.Set(ActorHeader, "alice")
+	req.Header.Set(RequestIDHeader, "req-1")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, auditInfo{actor: "alice", requestID: "req-1"}, info)
+	assert.Equal(t, "req-1", rr.Header().Get(RequestIDHeader))
+
+	// Anonymous requests are attributed to the system and given a request ID
+	req = httptest.NewRequest(http.MethodDelete, "/hotels/"+uuid.NewString(), nil)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, SystemActor, info.actor)
+	assert.NotEmpty(t, info.requestID)
+	assert.Equal(t, info.requestID, rr.Header().Get(RequestIDHeader))
+}
+
+func TestImportHotels_Handler(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	// Test data
+	rows := []ImportRow{{Row: 1, OwnerName: "John", OwnerSurname: "Doe", CompanyTitle: "Doe Ltd."}}
+	mockService.On("ImportHotels", rows, ImportOptions{DryRun: true}).Return(&ImportResult{DryRun: true, Total: 1, Valid: 1, Errors: []ImportRowError{}}, nil)
+
+	// Prepare the request
+	body := `{"ownerName":"John","ownerSurname":"Doe","companyTitle":"Doe Ltd."}` + "\n"
+	req := httptest.NewRequest(http.MethodPost, "/hotels/import?dry_run=true", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	rr := httptest.NewRecorder()
+
+	// Register routes and handle request
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+	r.ServeHTTP(rr, req)
+
+	// Assert status code and response body
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var response ImportResult
+	err := json.NewDecoder(rr.Body).Decode(&response)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, response.Valid)
+	mockService.AssertExpectations(t)
+}
+
+func TestImportHotels_Handler_Rejected(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	rows := []ImportRow{{Row: 2, OwnerName: "John", OwnerSurname: "Doe"}}
+	result := &ImportResult{Total: 1, Failed: 1, Errors: []ImportRowError{{Row: 2, Error: ErrInvalidHotel.Error()}}}
+	mockService.On("ImportHotels", rows, ImportOptions{}).Return(result, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/hotels/import", bytes.NewBufferString("owner_name,owner_surname,company_title\nJohn,Doe,\n"))
+	req.Header.Set("Content-Type", "text/csv")
+	rr := httptest.NewRecorder()
+
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+	r.ServeHTTP(rr, req)
+
+	// Nothing was imported because of the invalid row
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestImportHotels_Handler_Async(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	// Test data
+	job := &ImportJob{ID: uuid.New(), Status: ImportInProgress, Total: 1, BatchSize: 100}
+	mockService.On("StartImportJob", mock.Anything, ImportOptions{BatchSize: 100}).Return(job, nil)
+	mockService.On("GetImportJob", job.ID).Return(job, nil)
+
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+
+	// Prepare the request
+	req := httptest.NewRequest(http.MethodPost, "/hotels/import?format=csv&async=true&batch_size=100", bytes.NewBufferString("owner_name,owner_surname,company_title\nJohn,Doe,Doe Ltd.\n"))
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	// Assert status code and the location of the job
+	assert.Equal(t, http.StatusAccepted, rr.Code)
+	location := rr.Header().Get("Location")
+	assert.Equal(t, "/hotels/import/"+job.ID.String(), location)
+
+	req = httptest.NewRequest(http.MethodGet, location, nil)
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestImportHotels_Handler_InvalidParams(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+
+	for target, body := range map[string]string{
+		"/hotels/import?batch_size=-1": "owner_name,owner_surname,company_title\n",
+		"/hotels/import?dry_run=maybe": "owner_name,owner_surname,company_title\n",
+		"/hotels/import":               "name,title\n",
+		"/hotels/import?format=xml":    "<hotels/>",
+	} {
+		req := httptest.NewRequest(http.MethodPost, target, bytes.NewBufferString(body))
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, target)
+	}
+}
+
+func TestExportHotels_Handler(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	// Test data
+	body := "{\"id\":\"1\"}\n{\"id\":\"2\"}\n"
+	mockService.On("ExportHotels", ExportFormatNDJSON, ListOptions{SortBy: "company_title", ContactType: ContactTypeEmail}).Return(body, nil)
+
+	// Prepare the request
+	req := httptest.NewRequest(http.MethodGet, "/hotels/export?format=ndjson&sort=company_title&contact_type=email", nil)
+	rr := httptest.NewRecorder()
+
+	// Register routes and handle request
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+	r.ServeHTTP(rr, req)
+
+	// Assert status code and response body
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/x-ndjson", rr.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="hotels.ndjson"`, rr.Header().Get("Content-Disposition"))
+	assert.Equal(t, body, rr.Body.String())
+	mockService.AssertExpectations(t)
+}
+
+func TestExportHotels_Handler_Errors(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	// CSV is the default format
+	mockService.On("ExportHotels", ExportFormatCSV, ListOptions{SortBy: "popularity"}).Return("", ErrInvalidSort)
+	// Errors after the export started cannot change the status any more
+	mockService.On("ExportHotels", ExportFormatJSON, ListOptions{}).Return(`[{"id":"1"}`, fmt.Errorf("connection lost"))
+
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+
+	for target, status := range map[string]int{
+		"/hotels/export?format=xml":         http.StatusBadRequest,
+		"/hotels/export?limit=x":            http.StatusBadRequest,
+		"/hotels/export?sort=popularity":    http.StatusBadRequest,
+		"/hotels/export?format=json":        http.StatusOK,
+		"/hotels/export?include_deleted=no": http.StatusBadRequest,
+	} {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		assert.Equal(t, status, rr.Code, target)
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestListHotelOfficials_Handler(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	// Test data
+	hotelID := uuid.New()
+	officials := []HotelOfficial{{ID: uuid.New(), HotelID: hotelID, Role: RoleGeneralManager, Name: "Jane", Surname: "Smith", CompanyTitle: "Doe Ltd."}}
+	mockService.On("ListHotelOfficials", mock.MatchedBy(func(filter OfficialFilter) bool {
+		return filter.HotelID == hotelID && filter.Role == RoleGeneralManager && !filter.ActiveAt.IsZero()
+	})).Return(officials, nil)
+
+	// Prepare the request
+	req := httptest.NewRequest(http.MethodGet, "/hotels/officials?hotel_id="+hotelID.String()+"&role=general_manager&active=true", nil)
+	rr := httptest.NewRecorder()
+
+	// Register routes and handle request
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+	r.ServeHTTP(rr, req)
+
+	// Assert status code and response body
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var response []HotelOfficial
+	err := json.NewDecoder(rr.Body).Decode(&response)
+	assert.NoError(t, err)
+	assert.Equal(t, officials, response)
+	mockService.AssertExpectations(t)
+
+	for _, target := range []string{"/hotels/officials?hotel_id=42", "/hotels/officials?active=sometimes"} {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, target)
+	}
+}
+
+func TestAddOfficial_Handler(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	// Test data
+	hotelID := uuid.New()
+	start := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	expected := &HotelOfficial{
+		Role:         RoleSalesManager,
+		Name:         "Jane",
+		Surname:      "Smith",
+		StartDate:    &start,
+		ContactInfos: []OfficialContact{{InfoType: ContactTypeEmail, InfoContent: "jane@doe.example"}},
+	}
+	mockService.On("AddOfficial", hotelID, expected, 2).Return(nil)
+
+	// Prepare the request
+	body := `{"role":"sales_manager","name":"Jane","surname":"Smith","start_date":"2021-03-01","contact_infos":[{"info_type":"email","info_content":"jane@doe.example"}]}`
+	req := httptest.NewRequest(http.MethodPost, "/hotels/"+hotelID.String()+"/officials", bytes.NewBufferString(body))
+	req.Header.Set("If-Match", `"2"`)
+	rr := httptest.NewRecorder()
+
+	// Register routes and handle request
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+	r.ServeHTTP(rr, req)
+
+	// Assert status code
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	mockService.AssertExpectations(t)
+
+	// Dates must be days or timestamps
+	req = httptest.NewRequest(http.MethodPost, "/hotels/"+hotelID.String()+"/officials", bytes.NewBufferString(`{"role":"owner","end_date":"March 2021"}`))
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestUpdateAndRemoveOfficial_Handler(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	// Test data
+	hotelID, officialID := uuid.New(), uuid.New()
+	mockService.On("UpdateOfficial", hotelID, officialID, &HotelOfficial{Role: RoleOwner, Name: "John", Surname: "Doe"}, 0).Return(nil)
+	mockService.On("RemoveOfficial", hotelID, officialID, 4).Return(ErrVersionConflict)
+
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+
+	target := "/hotels/" + hotelID.String() + "/officials/" + officialID.String()
+	req := httptest.NewRequest(http.MethodPut, target, bytes.NewBufferString(`{"role":"owner","name":"John","surname":"Doe"}`))
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	// A stale version is rejected
+	req = httptest.NewRequest(http.MethodDelete, target, nil)
+	req.Header.Set("If-Match", `"4"`)
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusPreconditionFailed, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestAddRoomType_Handler(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	// Test data
+	hotelID := uuid.New()
+	expected := &RoomType{Name: "Deluxe Double", Capacity: 2, RoomCount: 10, Beds: BedConfiguration{{Type: BedDouble, Count: 1}}, Amenities: StringList{"balcony"}}
+	mockService.On("AddRoomType", hotelID, expected, 0).Return(nil)
+
+	// Prepare the request
+	body := `{"name":"Deluxe Double","capacity":2,"room_count":10,"beds":[{"type":"double","count":1}],"amenities":["balcony"]}`
+	req := httptest.NewRequest(http.MethodPost, "/hotels/"+hotelID.String()+"/rooms", bytes.NewBufferString(body))
+	rr := httptest.NewRecorder()
+
+	// Register routes and handle request
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+	r.ServeHTTP(rr, req)
+
+	// Assert status code
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestRoomTypes_Handler_Errors(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	// Test data
+	hotelID, roomTypeID, bookedRoomTypeID := uuid.New(), uuid.New(), uuid.New()
+	mockService.On("ListRoomTypes", hotelID).Return([]RoomType(nil), ErrHotelNotFound)
+	mockService.On("GetRoomType", hotelID, roomTypeID).Return((*RoomType)(nil), ErrRoomTypeNotFound)
+	mockService.On("RemoveRoomType", hotelID, roomTypeID, 0).Return(ErrRoomTypeNotFound)
+	mockService.On("RemoveRoomType", hotelID, bookedRoomTypeID, 0).Return(ErrRoomTypeInUse)
+	mockService.On("UpdateRoomType", hotelID, roomTypeID, &RoomType{Name: "Twin"}, 0).
+		Return(&ValidationError{Fields: []FieldError{{"capacity", "must be at least 1"}}})
+
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+
+	roomTypeURL := "/hotels/" + hotelID.String() + "/rooms/" + roomTypeID.String()
+	for _, tt := range []struct {
+		method, target, body string
+		status               int
+	}{
+		{http.MethodGet, "/hotels/" + hotelID.String() + "/rooms", "", http.StatusNotFound},
+		{http.MethodGet, roomTypeURL, "", http.StatusNotFound},
+		{http.MethodGet, "/hotels/" + hotelID.String() + "/rooms/42", "", http.StatusBadRequest},
+		{http.MethodDelete, roomTypeURL, "", http.StatusNotFound},
+		{http.MethodDelete, "/hotels/" + hotelID.String() + "/rooms/" + bookedRoomTypeID.String(), "", http.StatusConflict},
+		{http.MethodPut, roomTypeURL, `{"name":"Twin"}`, http.StatusUnprocessableEntity},
+		{http.MethodPut, roomTypeURL, `{"name":`, http.StatusBadRequest},
+	} {
+		req := httptest.NewRequest(tt.method, tt.target, bytes.NewBufferString(tt.body))
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		assert.Equal(t, tt.status, rr.Code, tt.method+" "+tt.target)
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestCreateReservation_Handler(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	// Test data
+	hotelID, roomTypeID := uuid.New(), uuid.New()
+	expected := &Reservation{
+		RoomTypeID: roomTypeID,
+		CheckIn:    time.Date(2030, time.June, 1, 0, 0, 0, 0, time.UTC),
+		CheckOut:   time.Date(2030, time.June, 3, 0, 0, 0, 0, time.UTC),
+		Rooms:      1,
+		GuestName:  "Jane Doe",
+		Status:     ReservationHold,
+	}
+	mockService.On("CreateReservation", hotelID, expected).Return(nil).Once()
+
+	// Prepare the request
+	body := `{"room_type_id":"` + roomTypeID.String() + `","check_in":"2030-06-01","check_out":"2030-06-03","rooms":1,"guest_name":"Jane Doe","status":"hold"}`
+	req := httptest.NewRequest(http.MethodPost, "/hotels/"+hotelID.String()+"/reservations", bytes.NewBufferString(body))
+	rr := httptest.NewRecorder()
+
+	// Register routes and handle request
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+	r.ServeHTTP(rr, req)
+
+	// Assert status code
+	assert.Equal(t, http.StatusCreated, rr.Code)
+
+	// Overbooking answers 409
+	mockService.On("CreateReservation", hotelID, expected).Return(ErrNotAvailable).Once()
+	req = httptest.NewRequest(http.MethodPost, "/hotels/"+hotelID.String()+"/reservations", bytes.NewBufferString(body))
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusConflict, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestGetAvailability_Handler(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	// Test data
+	hotelID, roomTypeID := uuid.New(), uuid.New()
+	query := AvailabilityQuery{
+		From:       time.Date(2030, time.June, 1, 0, 0, 0, 0, time.UTC),
+		To:         time.Date(2030, time.June, 2, 0, 0, 0, 0, time.UTC),
+		RoomTypeID: roomTypeID,
+	}
+	calendar := []RoomAvailability{{RoomTypeID: roomTypeID, Name: "Twin", RoomCount: 2, Dates: []DateAvailability{{"2030-06-01", 1}}}}
+	mockService.On("GetAvailability", hotelID, query).Return(calendar, nil)
+
+	// Prepare the request
+	req := httptest.NewRequest(http.MethodGet, "/hotels/"+hotelID.String()+"/availability?from=2030-06-01&to=2030-06-02&room_type_id="+roomTypeID.String(), nil)
+	rr := httptest.NewRecorder()
+
+	// Register routes and handle request
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+	r.ServeHTTP(rr, req)
+
+	// Assert status code and response body
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var response []RoomAvailability
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, calendar, response)
+
+	// Dates are required
+	req = httptest.NewRequest(http.MethodGet, "/hotels/"+hotelID.String()+"/availability?from=2030-06-01", nil)
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestReservations_Handler_Errors(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	// Test data
+	hotelID, reservationID := uuid.New(), uuid.New()
+	mockService.On("GetReservation", hotelID, reservationID).Return((*Reservation)(nil), ErrReservationNotFound)
+	mockService.On("ConfirmReservation", hotelID, reservationID).Return((*Reservation)(nil), ErrHoldExpired)
+	mockService.On("CancelReservation", hotelID, reservationID).Return(nil)
+
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+
+	reservationURL := "/hotels/" + hotelID.String() + "/reservations/" + reservationID.String()
+	for _, tt := range []struct {
+		method, target, body string
+		status               int
+	}{
+		{http.MethodGet, reservationURL, "", http.StatusNotFound},
+		{http.MethodPost, reservationURL + "/confirm", "", http.StatusConflict},
+		{http.MethodDelete, reservationURL, "", http.StatusNoContent},
+		{http.MethodGet, "/hotels/" + hotelID.String() + "/reservations/42", "", http.StatusBadRequest},
+		{http.MethodPost, "/hotels/" + hotelID.String() + "/reservations", `{"check_in":"June 1st"}`, http.StatusBadRequest},
+	} {
+		req := httptest.NewRequest(tt.method, tt.target, bytes.NewBufferString(tt.body))
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		assert.Equal(t, tt.status, rr.Code, tt.method+" "+tt.target)
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestReviews_Handler(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	// Test data
+	hotelID, reviewID := uuid.New(), uuid.New()
+	reviews := []Review{{ID: reviewID, HotelID: hotelID, Score: 4, Author: "Joe", Status: ReviewPending}}
+	mockService.On("ListReviews", ReviewFilter{HotelID: hotelID, Status: ReviewPending, Limit: 5}).Return(reviews, nil)
+	mockService.On("AddReview", hotelID, &Review{Score: 9, Text: "Great breakfast", Author: "Jane"}).Return(nil)
+	mockService.On("ModerateReview", hotelID, reviewID, ReviewRejected).Return(&Review{ID: reviewID, Status: ReviewRejected}, nil)
+	mockService.On("RemoveReview", hotelID, reviewID).Return(ErrReviewNotFound)
+
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+
+	reviewsURL := "/hotels/" + hotelID.String() + "/reviews"
+	for _, tt := range []struct {
+		method, target, body string
+		status               int
+	}{
+		{http.MethodGet, reviewsURL + "?status=pending&limit=5", "", http.StatusOK},
+		{http.MethodGet, reviewsURL + "?limit=many", "", http.StatusBadRequest},
+		{http.MethodPost, reviewsURL, `{"score":9,"text":"Great breakfast","author":"Jane"}`, http.StatusCreated},
+		{http.MethodPatch, reviewsURL + "/" + reviewID.String(), `{"status":"rejected"}`, http.StatusOK},
+		{http.MethodDelete, reviewsURL + "/" + reviewID.String(), "", http.StatusNotFound},
+		{http.MethodDelete, reviewsURL + "/42", "", http.StatusBadRequest},
+	} {
+		req := httptest.NewRequest(tt.method, tt.target, bytes.NewBufferString(tt.body))
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		assert.Equal(t, tt.status, rr.Code, tt.method+" "+tt.target)
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestListHotels_Handler_Rating(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	// Test data
+	mockService.On("ListHotels", ListOptions{SortBy: "rating", SortDesc: true, MinRating: 8.5}).Return(&HotelPage{Items: []Hotel{}}, nil)
+
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+
+	// Prepare the request
+	req := httptest.NewRequest(http.MethodGet, "/hotels?sort=-rating&min_rating=8.5", nil)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	// Ratings outside the score range are rejected
+	req = httptest.NewRequest(http.MethodGet, "/hotels?min_rating=11", nil)
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestTags_Handler(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	// Test data
+	hotelID := uuid.New()
+	mockService.On("SetHotelTags", hotelID, []string{"Pool", "parking"}, 4).Return([]string{"parking", "pool"}, nil)
+	mockService.On("GetTag", "casino").Return(nil, ErrTagNotFound)
+	mockService.On("DeleteTag", "pool").Return(ErrTagInUse)
+	mockService.On("CreateTag", mock.Anything).Return(ErrInvalidTag)
+
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+
+	// Prepare the request
+	req := httptest.NewRequest(http.MethodPut, "/hotels/"+hotelID.String()+"/tags", bytes.NewBufferString(`{"tags": ["Pool", "parking"]}`))
+	req.Header.Set("If-Match", `"4"`)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"tags": ["parking", "pool"]}`, rr.Body.String())
+
+	tests := []struct {
+		method, target, body string
+		status               int
+	}{
+		{http.MethodGet, "/tags/casino", "", http.StatusNotFound},
+		{http.MethodDelete, "/tags/pool", "", http.StatusConflict},
+		{http.MethodPost, "/tags", `{"name": "Casino", "display_name": "Casino", "kind": "amenity"}`, http.StatusBadRequest},
+		{http.MethodPut, "/hotels/not-a-uuid/tags", `{"tags": []}`, http.StatusBadRequest},
+		{http.MethodPut, "/hotels/" + hotelID.String() + "/tags", `{"tags": "pool"}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.target, bytes.NewBufferString(tt.body))
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		assert.Equal(t, tt.status, rr.Code, tt.method+" "+tt.target)
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestListHotels_Handler_TagsAndFacets(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	// Test data
+	page := &HotelPage{Items: []Hotel{}, Facets: &Facets{
+		Tags:      []FacetCount{{"pool", 1}},
+		Countries: []FacetCount{{"Turkey", 1}},
+		Cities:    []CityFacetCount{{"Turkey", "Istanbul", 1}},
+	}}
+	mockService.On("ListHotels", ListOptions{Tags: []string{"pool", "wifi"}, Facets: true}).Return(page, nil)
+
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+
+	// Prepare the request
+	req := httptest.NewRequest(http.MethodGet, "/hotels?tags=pool,wifi&facets=true", nil)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var body map[string]interface{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	assert.Equal(t, map[string]interface{}{
+		"tags":      []interface{}{map[string]interface{}{"value": "pool", "count": 1.0}},
+		"countries": []interface{}{map[string]interface{}{"value": "Turkey", "count": 1.0}},
+		"cities":    []interface{}{map[string]interface{}{"country": "Turkey", "value": "Istanbul", "count": 1.0}},
+	}, body["facets"])
+
+	req = httptest.NewRequest(http.MethodGet, "/hotels?facets=maybe", nil)
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestAddMedia_Handler(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	// Test data
+	hotelID, mediaID := uuid.New(), uuid.New()
+	mockService.On("AddMedia", hotelID, "lobby.jpg", []byte("image bytes"), 3).
+		Return(&Media{ID: mediaID, HotelID: hotelID, Kind: MediaKindImage, Position: 1}, nil)
+
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+
+	// Prepare the request
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", "lobby.jpg")
+	assert.NoError(t, err)
+	part.Write([]byte("image bytes"))
+	form.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/hotels/"+hotelID.String()+"/media", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("If-Match", `"3"`)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.Equal(t, "/hotels/"+hotelID.String()+"/media/"+mediaID.String()+"/content", rr.Header().Get("Location"))
+	mockService.AssertExpectations(t)
+}
+
+func TestGetMediaThumbnail_Handler(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	// Test data
+	hotelID, mediaID := uuid.New(), uuid.New()
+	media := &Media{ID: mediaID, HotelID: hotelID, ContentType: "image/png", Checksum: "abc123", HasThumbnail: true}
+	mockService.On("OpenMedia", hotelID, mediaID, true).Return(media, io.NopCloser(bytes.NewBufferString("thumbnail")), nil)
+
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+
+	// Prepare the request
+	target := "/hotels/" + hotelID.String() + "/media/" + mediaID.String() + "/thumbnail"
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, ThumbnailContentType, rr.Header().Get("Content-Type"))
+	assert.Equal(t, mediaCacheControl, rr.Header().Get("Cache-Control"))
+	assert.Equal(t, `"abc123-thumb"`, rr.Header().Get("ETag"))
+	assert.Equal(t, "thumbnail", rr.Body.String())
+
+	// Clients holding the current thumbnail are told it has not changed
+	req = httptest.NewRequest(http.MethodGet, target, nil)
+	req.Header.Set("If-None-Match", `"abc123-thumb"`)
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNotModified, rr.Code)
+	assert.Empty(t, rr.Body.String())
+
+	mockService.AssertExpectations(t)
+}
+
+func TestMedia_Handler_Errors(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	// Test data
+	hotelID, mediaID := uuid.New(), uuid.New()
+	mockService.On("OpenMedia", hotelID, mediaID, true).Return(nil, nil, ErrThumbnailNotFound)
+	mockService.On("RemoveMedia", hotelID, mediaID, 0).Return(ErrMediaNotFound)
+	mockService.On("ReorderMedia", hotelID, []uuid.UUID{mediaID}, 0).Return(nil, ErrInvalidMediaOrder)
+	mockService.On("AddMedia", hotelID, "notes.txt", []byte("text"), 0).Return(nil, ErrUnsupportedMedia)
+
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+
+	var upload bytes.Buffer
+	form := multipart.NewWriter(&upload)
+	part, _ := form.CreateFormFile("file", "notes.txt")
+	part.Write([]byte("text"))
+	form.Close()
+
+	media := "/hotels/" + hotelID.String() + "/media"
+	tests := []struct {
+		method, target, body string
+		status               int
+	}{
+		{http.MethodGet, media + "/" + mediaID.String() + "/thumbnail", "", http.StatusNotFound},
+		{http.MethodDelete, media + "/" + mediaID.String(), "", http.StatusNotFound},
+		{http.MethodPut, media + "/order", `{"media_ids": ["` + mediaID.String() + `"]}`, http.StatusBadRequest},
+		{http.MethodGet, media + "/not-a-uuid/content", "", http.StatusBadRequest},
+		{http.MethodPost, media, "not a form", http.StatusBadRequest},
+		{http.MethodPost, media, upload.String(), http.StatusUnsupportedMediaType},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.target, bytes.NewBufferString(tt.body))
+		req.Header.Set("Content-Type", form.FormDataContentType())
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		assert.Equal(t, tt.status, rr.Code, tt.method+" "+tt.target)
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestGetHotelDetails_Handler_Localized(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	// Test data
+	hotelID := uuid.New()
+	hotel := &Hotel{
+		ID:           hotelID,
+		CompanyTitle: "Pera Palace",
+		Translations: []HotelTranslation{
+			{HotelID: hotelID, Language: "de", Description: "Am Goldenen Horn"},
+			{HotelID: hotelID, Language: "en", CompanyTitle: "Pera Palace Hotel"},
+		},
+	}
+	mockService.On("GetHotelDetails", hotelID).Return(hotel, nil)
+
+	// Prepare the request
+	req := httptest.NewRequest(http.MethodGet, "/hotels/"+hotelID.String(), nil)
+	req.Header.Set("Accept-Language", "de-CH, tr;q=0.5")
+	rr := httptest.NewRecorder()
+
+	// Register routes and handle request
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+	r.ServeHTTP(rr, req)
+
+	// Assert status code, headers and response body
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "de", rr.Header().Get("Content-Language"))
+	assert.Equal(t, "Accept-Language", rr.Header().Get("Vary"))
+	var response map[string]interface{}
+	err := json.NewDecoder(rr.Body).Decode(&response)
+	assert.NoError(t, err)
+	assert.Equal(t, "Pera Palace Hotel", response["company_title"])
+	assert.Equal(t, "Am Goldenen Horn", response["description"])
+	assert.Equal(t, "de", response["language"])
+	assert.NotContains(t, response, "translations")
+	mockService.AssertExpectations(t)
+}
+
+func TestTranslations_Handler(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	// Test data
+	hotelID := uuid.New()
+	mockService.On("ListTranslations", hotelID).Return([]HotelTranslation{{HotelID: hotelID, Language: "de", CompanyTitle: "Pera Palast"}}, nil)
+	mockService.On("SaveTranslation", hotelID, "de", &HotelTranslation{CompanyTitle: "Pera Palast"}, 4).Return(nil)
+	mockService.On("SaveTranslation", hotelID, "xx-invalid-", mock.Anything, 4).Return(ErrInvalidLanguage)
+	mockService.On("RemoveTranslation", hotelID, "de", 4).Return(nil)
+	mockService.On("RemoveTranslation", hotelID, "tr", 4).Return(fmt.Errorf("failed to remove translation: %w", ErrTranslationNotFound))
+	mockService.On("ListMissingTranslations", MissingTranslationFilter{Languages: []string{"de", "tr"}, Limit: 10}).
+		Return(&MissingTranslationPage{Items: []MissingTranslations{}, Languages: []string{"de", "tr"}, Limit: 10}, nil)
+
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+
+	translations := "/hotels/" + hotelID.String() + "/translations"
+	tests := []struct {
+		method, target, body string
+		status               int
+	}{
+		{http.MethodGet, translations, "", http.StatusOK},
+		{http.MethodPut, translations + "/de", `{"company_title": "Pera Palast"}`, http.StatusOK},
+		{http.MethodPut, translations + "/xx-invalid-", `{"company_title": "Pera Palast"}`, http.StatusBadRequest},
+		{http.MethodDelete, translations + "/de", "", http.StatusNoContent},
+		{http.MethodDelete, translations + "/tr", "", http.StatusNotFound},
+		{http.MethodGet, "/hotels/translations/missing?languages=de,tr&limit=10", "", http.StatusOK},
+		{http.MethodGet, "/hotels/translations/missing?offset=-1", "", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.target, bytes.NewBufferString(tt.body))
+		req.Header.Set("If-Match", `"4"`)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		assert.Equal(t, tt.status, rr.Code, tt.method+" "+tt.target)
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestRatePlans_Handler(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	// Test data
+	hotelID, roomTypeID, ratePlanID := uuid.New(), uuid.New(), uuid.New()
+	summer := func(plan *RatePlan) bool {
+		return plan.Name == "Summer" && plan.BasePrice.Cmp(money.MustParse("120.5")) == 0 &&
+			plan.ValidFrom.Equal(time.Date(2030, time.June, 1, 0, 0, 0, 0, time.UTC))
+	}
+	mockService.On("ListRatePlans", hotelID, roomTypeID).Return([]RatePlan{{ID: ratePlanID, HotelID: hotelID}}, nil)
+	mockService.On("AddRatePlan", hotelID, mock.MatchedBy(summer), 4).Return(nil).Once()
+	mockService.On("AddRatePlan", hotelID, mock.MatchedBy(summer), 4).Return(ErrRatePlanOverlap).Once()
+	mockService.On("GetRatePlan", hotelID, ratePlanID).Return(nil, ErrRatePlanNotFound)
+	mockService.On("UpdateRatePlan", hotelID, ratePlanID, mock.MatchedBy(summer), 4).Return(nil)
+	mockService.On("RemoveRatePlan", hotelID, ratePlanID, 4).Return(nil)
+
+	checkIn := time.Date(2030, time.June, 1, 0, 0, 0, 0, time.UTC)
+	mockService.On("QuoteStay", hotelID, QuoteRequest{RoomTypeID: roomTypeID, CheckIn: checkIn, CheckOut: checkIn.AddDate(0, 0, 2), Rooms: 2, Currency: "TRY"}).
+		Return(&Quote{HotelID: hotelID, Total: money.Amount{Value: money.MustParse("8520"), Currency: "TRY"}}, nil)
+	mockService.On("QuoteStay", hotelID, QuoteRequest{RoomTypeID: roomTypeID, CheckIn: checkIn, CheckOut: checkIn.AddDate(0, 1, 0)}).
+		Return(nil, fmt.Errorf("failed to quote stay: %w", ErrNoRatePlan))
+
+	mockService.On("ListExchangeRates", ExchangeRateFilter{BaseCurrency: "EUR"}).Return([]ExchangeRate{}, nil)
+	mockService.On("SaveExchangeRate", mock.MatchedBy(func(rate *ExchangeRate) bool {
+		return rate.BaseCurrency == "EUR" && rate.QuoteCurrency == "TRY" && rate.Rate.Cmp(money.MustParse("35.5")) == 0
+	})).Return(nil)
+	mockService.On("RemoveExchangeRate", "EUR", "TRY", time.Date(2030, time.June, 1, 0, 0, 0, 0, time.UTC)).Return(ErrExchangeRateNotFound)
+
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+
+	ratePlans := "/hotels/" + hotelID.String() + "/rate-plans"
+	plan := `{"room_type_id": "` + roomTypeID.String() + `", "name": "Summer", "valid_from": "2030-06-01", "valid_to": "2030-09-01", "base_price": "120.50", "currency": "EUR"}`
+	quote := "/hotels/" + hotelID.String() + "/quote?room_type_id=" + roomTypeID.String()
+	tests := []struct {
+		method, target, body string
+		status               int
+	}{
+		{http.MethodGet, ratePlans + "?room_type_id=" + roomTypeID.String(), "", http.StatusOK},
+		{http.MethodPost, ratePlans, plan, http.StatusCreated},
+		{http.MethodPost, ratePlans, plan, http.StatusConflict},
+		{http.MethodPost, ratePlans, `{"name": "Summer", "valid_from": "June"}`, http.StatusBadRequest},
+		{http.MethodPost, ratePlans, `{"name": "Summer", "base_price": 12.3456789}`, http.StatusBadRequest},
+		{http.MethodGet, ratePlans + "/" + ratePlanID.String(), "", http.StatusNotFound},
+		{http.MethodPut, ratePlans + "/" + ratePlanID.String(), plan, http.StatusOK},
+		{http.MethodDelete, ratePlans + "/" + ratePlanID.String(), "", http.StatusNoContent},
+		{http.MethodGet, quote + "&check_in=2030-06-01&check_out=2030-06-03&rooms=2&currency=TRY", "", http.StatusOK},
+		{http.MethodGet, quote + "&check_in=2030-06-01&check_out=2030-07-01", "", http.StatusUnprocessableEntity},
+		{http.MethodGet, quote + "&check_in=2030-06-01", "", http.StatusBadRequest},
+		{http.MethodGet, "/hotels/" + hotelID.String() + "/quote?check_in=2030-06-01&check_out=2030-06-03", "", http.StatusBadRequest},
+		{http.MethodGet, "/exchange-rates?base=EUR", "", http.StatusOK},
+		{http.MethodPut, "/exchange-rates/EUR/TRY/2030-06-01", `{"rate": "35.5"}`, http.StatusOK},
+		{http.MethodPut, "/exchange-rates/EUR/TRY/June", `{"rate": "35.5"}`, http.StatusBadRequest},
+		{http.MethodDelete, "/exchange-rates/EUR/TRY/2030-06-01", "", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.target, bytes.NewBufferString(tt.body))
+		req.Header.Set("If-Match", `"4"`)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		assert.Equal(t, tt.status, rr.Code, tt.method+" "+tt.target)
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestListLocationStats_Handler(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	// Test data
+	page := &LocationStatsPage{Items: []LocationSummary{{Country: "Turkey", City: "Istanbul", HotelCount: 12, PhoneCount: 30}}, Level: LocationLevelCity, Total: 1, Limit: 10}
+	mockService.On("ListLocationStats", LocationStatsOptions{Location: LocationFilter{Country: "Turkey"}, MinHotels: 3, SortBy: "hotel_count", SortDesc: true, Limit: 10}).Return(page, nil)
+	mockService.On("ListLocationStats", LocationStatsOptions{Level: "street"}).Return(nil, ErrInvalidLocationLevel)
+	mockService.On("ListLocationStats", LocationStatsOptions{SortBy: "rating"}).Return(nil, ErrInvalidSort)
+
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+
+	tests := []struct {
+		target string
+		status int
+	}{
+		{"/hotels/stats/locations?country=Turkey&min_hotels=3&sort=-hotel_count&limit=10", http.StatusOK},
+		{"/hotels/stats/locations?level=street", http.StatusBadRequest},
+		{"/hotels/stats/locations?sort=rating", http.StatusBadRequest},
+		{"/hotels/stats/locations?min_hotels=-1", http.StatusBadRequest},
+		{"/hotels/stats/locations?chain_id=hilton", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tt.target, nil))
+		assert.Equal(t, tt.status, rr.Code, tt.target)
+	}
+
+	// The page is returned as is
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tests[0].target, nil))
+	var response LocationStatsPage
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	assert.Equal(t, *page, response)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestBatchHotelStats_Handler(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	// Test data
+	chainID := uuid.New()
+	queries := []LocationStatsQuery{{Filter: LocationFilter{City: "Istanbul"}, Currency: "TRY"}, {Filter: LocationFilter{ChainID: chainID}}}
+	stats := []LocationStats{{HotelCount: 4, PhoneCount: 6}, {HotelCount: 1}}
+	mockService.On("FetchLocationStatsBatch", queries).Return(stats, nil)
+	mockService.On("FetchLocationStatsBatch", []LocationStatsQuery{{}}).
+		Return(nil, &ValidationError{Fields: []FieldError{{"locations[0]", "requires a location, country, city, district or chain_id"}}})
+	mockService.On("FetchLocationStatsBatch", []LocationStatsQuery{{Filter: LocationFilter{Country: "Turkey"}}}).
+		Return(nil, fmt.Errorf("failed to fetch stats for location: database is down"))
+
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+
+	body := `{"locations": [{"city": "Istanbul", "currency": "TRY"}, {"chain_id": "` + chainID.String() + `"}]}`
+	tests := []struct {
+		body   string
+		status int
+	}{
+		{body, http.StatusOK},
+		{`{"locations": [{}]}`, http.StatusUnprocessableEntity},
+		{`{"locations": [{"country": "Turkey"}]}`, http.StatusInternalServerError},
+		{`{"locations": [{"chain_id": "hilton"}]}`, http.StatusBadRequest},
+		{`{"locations":`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/hotels/stats:batch", bytes.NewBufferString(tt.body)))
+		assert.Equal(t, tt.status, rr.Code, tt.body)
+	}
+
+	// The stats are returned in the order of the locations
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/hotels/stats:batch", bytes.NewBufferString(body)))
+	var response struct {
+		Items []LocationStats `json:"items"`
+	}
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	assert.Equal(t, stats, response.Items)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestChains_Handler(t *testing.T) {
+	mockService := new(MockHotelService)
+	handler := NewHandler(mockService)
+
+	// Test data
+	hotelID, groupID, brandID := uuid.New(), uuid.New(), uuid.New()
+	hampton := mock.MatchedBy(func(chain *Chain) bool { return chain.Name == "Hampton" && *chain.ParentID == groupID })
+	mockService.On("ListChains", "brand").Return([]Chain{{ID: brandID, Name: "Hampton", Kind: ChainKindBrand, ParentID: &groupID}}, nil)
+	mockService.On("CreateChain", hampton).Return(nil).Once()
+	mockService.On("CreateChain", hampton).Return(ErrChainExists).Once()
+	mockService.On("GetChain", groupID).Return(&Chain{ID: groupID, Kind: ChainKindGroup}, nil)
+	mockService.On("GetChain", brandID).Return(nil, ErrChainNotFound)
+	mockService.On("UpdateChain", brandID, hampton).Return(nil)
+	mockService.On("DeleteChain", groupID).Return(fmt.Errorf("failed to delete chain: %w", ErrChainInUse))
+	mockService.On("DeleteChain", brandID).Return(nil)
+	mockService.On("ListChainHotels", groupID, ListOptions{Location: LocationFilter{Country: "Turkey"}}).Return(&HotelPage{Items: []Hotel{{ID: hotelID}}}, nil)
+	mockService.On("FetchChainStats", groupID).Return(&ChainStats{ChainID: groupID, HotelCount: 1}, nil)
+	mockService.On("FetchChainStats", brandID).Return(nil, ErrChainNotFound)
+	mockService.On("SetHotelChain", hotelID, &brandID, 4).Return(nil)
+	mockService.On("SetHotelChain", hotelID, (*uuid.UUID)(nil), 4).Return(nil)
+
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+
+	brand := `{"name": "Hampton", "kind": "brand", "parent_id": "` + groupID.String() + `"}`
+	hotelChain := "/hotels/" + hotelID.String() + "/chain"
+	tests := []struct {
+		method, target, body string
+		status               int
+	}{
+		{http.MethodGet, "/chains?kind=brand", "", http.StatusOK},
+		{http.MethodPost, "/chains", brand, http.StatusCreated},
+		{http.MethodPost, "/chains", brand, http.StatusConflict},
+		{http.MethodPost, "/chains", `{"name": `, http.StatusBadRequest},
+		{http.MethodGet, "/chains/" + groupID.String(), "", http.StatusOK},
+		{http.MethodGet, "/chains/" + brandID.String(), "", http.StatusNotFound},
+		{http.MethodGet, "/chains/hilton", "", http.StatusBadRequest},
+		{http.MethodPut, "/chains/" + brandID.String(), brand, http.StatusOK},
+		{http.MethodDelete, "/chains/" + groupID.String(), "", http.StatusConflict},
+		{http.MethodDelete, "/chains/" + brandID.String(), "", http.StatusNoContent},
+		{http.MethodGet, "/chains/" + groupID.String() + "/hotels?country=Turkey", "", http.StatusOK},
+		{http.MethodGet, "/chains/" + groupID.String() + "/stats", "", http.StatusOK},
+		{http.MethodGet, "/chains/" + brandID.String() + "/stats", "", http.StatusNotFound},
+		{http.MethodPut, hotelChain, `{"chain_id": "` + brandID.String() + `"}`, http.StatusOK},
+		{http.MethodPut, hotelChain, `{}`, http.StatusBadRequest},
+		{http.MethodDelete, hotelChain, "", http.StatusNoContent},
+		{http.MethodGet, "/hotels/stats?chain_id=hilton", "", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.target, bytes.NewBufferString(tt.body))
+		req.Header.Set("If-Match", `"4"`)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		assert.Equal(t, tt.status, rr.Code, tt.method+" "+tt.target)
+	}
+	mockService.AssertExpectations(t)
+}
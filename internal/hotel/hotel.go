@@ -1,11 +1,43 @@
 package hotel
 
-import "github.com/google/uuid"
+import (
+	"errors"
 
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Names of the built-in contact types. Further types are managed in the
+// contact type registry.
 const (
 	ContactTypePhone = "phone"
 	ContactTypeEmail = "email"
 	ContactTypeFax   = "fax"
+	// ContactTypeWebsite is the address of the web site of a hotel.
+	ContactTypeWebsite = "website"
+
+	// ContactTypeLocation marks the free-text location contacts used before
+	// hotels had a structured Location. They are migrated by MigrateLocationContacts.
+	ContactTypeLocation = "location"
+)
+
+var (
+	ErrHotelNotFound    = errors.New("hotel not found")
+	ErrContactNotFound  = errors.New("contact info not found")
+	ErrInvalidHotel     = errors.New("owner name, surname, and company title are required")
+	ErrInvalidContact   = errors.New("contact info type and content are required")
+	ErrVersionConflict  = errors.New("hotel version does not match")
+	ErrInvalidLocation  = errors.New("location requires a country and a city, and latitude and longitude must be valid and set together")
+	ErrLocationNotFound = errors.New("location not found")
+	ErrHotelNotDeleted  = errors.New("hotel is not deleted")
+
+	ErrInvalidCoordinates = errors.New("latitude must be within [-90, 90], longitude within [-180, 180] and radius positive")
+	ErrSearchAreaTooLarge = errors.New("radius may be at most 500 km and a bounding box may span at most 10 degrees of latitude and longitude")
+
+	ErrContactTypeNotFound = errors.New("contact type not found")
+	ErrContactTypeExists   = errors.New("contact type already exists")
+	ErrContactTypeInUse    = errors.New("contact type is used by contact infos")
+	ErrInvalidContactType  = errors.New("contact type requires a lowercase name, a display name and a kind of phone, email, url or text, and its pattern must be a valid regular expression")
 )
 
 type ContactInfo struct {
@@ -16,25 +48,128 @@ type ContactInfo struct {
 }
 
 type Hotel struct {
-	ID           uuid.UUID     `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
-	OwnerName    string        `json:"owner_name"`
-	OwnerSurname string        `json:"owner_surname"`
-	CompanyTitle string        `json:"company_title"`
-	ContactInfos []ContactInfo `gorm:"foreignKey:HotelID;references:ID;constraint:OnDelete:CASCADE;"`
+	ID           uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	OwnerName    string    `json:"owner_name"`
+	OwnerSurname string    `json:"owner_surname"`
+	CompanyTitle string    `json:"company_title"`
+	// Description and MarketingText only exist in translations. Read endpoints
+	// fill them in, and replace the company title, from the translation chosen
+	// by Accept-Language; Language is the language that was chosen.
+	Description   string `gorm:"-" json:"description,omitempty"`
+	MarketingText string `gorm:"-" json:"marketing_text,omitempty"`
+	Language      string `gorm:"-" json:"language,omitempty"`
+	Version       int    `gorm:"not null;default:1" json:"version"`
+	// ChainID is the brand or group the hotel belongs to, if any.
+	ChainID *uuid.UUID `gorm:"type:uuid;index" json:"chain_id,omitempty"`
+	// ReviewCount and AverageScore summarize the approved reviews of the hotel.
+	// They are updated together with ScoreTotal, the sum of the scores, as
+	// reviews are moderated and do not change the version.
+	ReviewCount  int     `gorm:"not null;default:0" json:"review_count"`
+	AverageScore float64 `gorm:"not null;default:0;index" json:"average_score"`
+	ScoreTotal   int     `gorm:"not null;default:0" json:"-"`
+	// DeletedAt is set while the hotel is soft-deleted. Soft-deleted hotels are
+	// hidden from every query unless it is explicitly unscoped.
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"deleted_at"`
+	Location     *Location      `gorm:"foreignKey:HotelID;references:ID;constraint:OnDelete:CASCADE;" json:"location,omitempty"`
+	ContactInfos []ContactInfo  `gorm:"foreignKey:HotelID;references:ID;constraint:OnDelete:CASCADE;"`
+	// Officials are the people holding roles at the hotel. The owner fields
+	// above are kept for existing clients and name the owner the hotel was
+	// created with.
+	Officials []HotelOfficial `gorm:"foreignKey:HotelID;references:ID;constraint:OnDelete:CASCADE;" json:"officials,omitempty"`
+	// Tags are the amenities and other tags of the vocabulary the hotel has.
+	Tags []HotelTag `gorm:"foreignKey:HotelID;references:ID;constraint:OnDelete:CASCADE;" json:"tags,omitempty"`
+	// Media are the images and documents of the hotel in display order.
+	Media []Media `gorm:"foreignKey:HotelID;references:ID;constraint:OnDelete:CASCADE;" json:"media,omitempty"`
+	// Translations hold the content of the hotel per language.
+	Translations []HotelTranslation `gorm:"foreignKey:HotelID;references:ID;constraint:OnDelete:CASCADE;" json:"-"`
+	// RoomTypes are only loaded by ListRoomTypes; hotel queries leave them out.
+	RoomTypes []RoomType `gorm:"foreignKey:HotelID;references:ID;constraint:OnDelete:CASCADE;" json:"room_types,omitempty"`
+	// Reviews are only loaded by ListReviews; hotel queries leave them out.
+	Reviews []Review `gorm:"foreignKey:HotelID;references:ID;constraint:OnDelete:CASCADE;" json:"-"`
+}
+
+// Location is the structured address of a hotel. Each hotel has at most one.
+type Location struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	HotelID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex" json:"hotel_id"`
+	Country    string    `gorm:"index" json:"country"`
+	City       string    `gorm:"index" json:"city"`
+	District   string    `gorm:"index" json:"district"`
+	PostalCode string    `json:"postal_code"`
+	Street     string    `json:"street"`
+	Latitude   *float64  `json:"latitude,omitempty"`
+	Longitude  *float64  `json:"longitude,omitempty"`
+}
+
+// LocationFilter selects hotels by location and chain. Country, City and
+// District must all match when set; Name matches any of the three levels.
+// ChainID selects the hotels of a chain, including those of the brands of a
+// group.
+type LocationFilter struct {
+	Name     string
+	Country  string
+	City     string
+	District string
+	ChainID  uuid.UUID
+}
+
+// IsEmpty reports whether the filter has no criteria.
+func (f LocationFilter) IsEmpty() bool {
+	return !f.hasLocation() && f.ChainID == uuid.Nil
 }
 
-type HotelOfficial struct {
-	OwnerName    string `json:"owner_name"`
-	OwnerSurname string `json:"owner_surname"`
-	CompanyTitle string `json:"company_title"`
+// hasLocation reports whether the filter has location criteria. Only those
+// join the locations of the hotels.
+func (f LocationFilter) hasLocation() bool {
+	return f.Name != "" || f.Country != "" || f.City != "" || f.District != ""
 }
 
+// NewHotel returns a hotel whose owner is also recorded as its owner official.
 func NewHotel(ownerName, ownerSurname, companyTitle string, contacts []ContactInfo) *Hotel {
-	return &Hotel{
+	hotel := &Hotel{
 		ID:           uuid.New(),
 		OwnerName:    ownerName,
 		OwnerSurname: ownerSurname,
 		CompanyTitle: companyTitle,
+		Version:      1,
 		ContactInfos: contacts,
 	}
+	hotel.Officials = []HotelOfficial{ownerOfficial(hotel)}
+	return hotel
+}
+
+// HotelUpdate holds the editable hotel fields. Nil fields are left unchanged.
+type HotelUpdate struct {
+	OwnerName    *string
+	OwnerSurname *string
+	CompanyTitle *string
+}
+
+// ContactInfoUpdate holds the editable contact info fields. Nil fields are left unchanged.
+type ContactInfoUpdate struct {
+	InfoType    *string
+	InfoContent *string
+}
+
+// Apply copies the non-nil fields of the update onto the hotel.
+func (u HotelUpdate) Apply(hotel *Hotel) {
+	if u.OwnerName != nil {
+		hotel.OwnerName = *u.OwnerName
+	}
+	if u.OwnerSurname != nil {
+		hotel.OwnerSurname = *u.OwnerSurname
+	}
+	if u.CompanyTitle != nil {
+		hotel.CompanyTitle = *u.CompanyTitle
+	}
+}
+
+// Apply copies the non-nil fields of the update onto the contact info.
+func (u ContactInfoUpdate) Apply(contact *ContactInfo) {
+	if u.InfoType != nil {
+		contact.InfoType = *u.InfoType
+	}
+	if u.InfoContent != nil {
+		contact.InfoContent = *u.InfoContent
+	}
 }
@@ -0,0 +1,433 @@
+package hotel
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql/driver"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Formats accepted by the hotel import.
+const (
+	ImportFormatCSV    = "csv"
+	ImportFormatNDJSON = "ndjson"
+)
+
+const (
+	// MaxImportSize limits the size of an uploaded import file in bytes.
+	MaxImportSize = 64 << 20
+	// SyncImportLimit is the number of rows up to which an import runs within
+	// the request. Larger imports run as an import job.
+	SyncImportLimit = 500
+)
+
+// csvContactPrefix marks the CSV columns holding contacts; the rest of the
+// header names the contact type, e.g. "contact:phone". Columns may repeat.
+const csvContactPrefix = "contact:"
+
+var (
+	ErrImportInterrupted = errors.New("import was interrupted by a restart of the service")
+	ErrInvalidImport     = errors.New("import must be CSV with owner_name, owner_surname and company_title columns or NDJSON")
+	ErrImportJobNotFound = errors.New("import job not found")
+)
+
+// ImportRow is one hotel read from an import file. Row is the line number of
+// the hotel in the file; Err is set when the line could not be parsed.
+type ImportRow struct {
+	Row          int
+	OwnerName    string
+	OwnerSurname string
+	CompanyTitle string
+	Contacts     []ContactInfo
+	Err          error
+}
+
+// ImportOptions controls how rows are stored.
+type ImportOptions struct {
+	// DryRun validates the rows without storing them.
+	DryRun bool
+	// BatchSize stores the valid rows in transactions of this many hotels and
+	// skips invalid rows. Zero stores all rows in one transaction, and nothing
+	// at all if any row is invalid.
+	BatchSize int
+}
+
+func (opts ImportOptions) validate() error {
+	if opts.BatchSize < 0 {
+		return fmt.Errorf("%w: batch size must not be negative", ErrInvalidImport)
+	}
+	return nil
+}
+
+// ImportRowError explains why a row was not imported.
+type ImportRowError struct {
+	Row    int          `json:"row"`
+	Error  string       `json:"error"`
+	Fields []FieldError `json:"fields,omitempty"`
+}
+
+// ImportResult summarizes an import.
+type ImportResult struct {
+	DryRun   bool             `json:"dry_run"`
+	Total    int              `json:"total"`
+	Valid    int              `json:"valid"`
+	Imported int              `json:"imported"`
+	Failed   int              `json:"failed"`
+	Errors   []ImportRowError `json:"errors"`
+}
+
+// fail records that a row was not imported.
+func (r *ImportResult) fail(row int, err error) {
+	rowError := ImportRowError{Row: row, Error: err.Error()}
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		rowError.Error = "validation failed"
+		rowError.Fields = validationErr.Fields
+	}
+	r.Failed++
+	r.Errors = append(r.Errors, rowError)
+}
+
+// ImportErrors is the per-row error report of an import job. It is stored as JSON.
+type ImportErrors []ImportRowError
+
+func (e ImportErrors) Value() (driver.Value, error) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (e *ImportErrors) Scan(value interface{}) error {
+	switch data := value.(type) {
+	case nil:
+		*e = nil
+		return nil
+	case string:
+		return json.Unmarshal([]byte(data), e)
+	case []byte:
+		return json.Unmarshal(data, e)
+	}
+	return fmt.Errorf("cannot scan %T into import errors", value)
+}
+
+type ImportStatus string
+
+const (
+	ImportInProgress ImportStatus = "In Progress"
+	ImportCompleted  ImportStatus = "Completed"
+	ImportFailed     ImportStatus = "Failed"
+)
+
+// ImportJob tracks an import running in the background.
+type ImportJob struct {
+	ID         uuid.UUID    `gorm:"type:uuid;primary_key" json:"id"`
+	Status     ImportStatus `gorm:"not null" json:"status"`
+	DryRun     bool         `json:"dry_run"`
+	BatchSize  int          `json:"batch_size"`
+	Total      int          `json:"total"`
+	Valid      int          `json:"valid"`
+	Imported   int          `json:"imported"`
+	Failed     int          `json:"failed"`
+	Errors     ImportErrors `gorm:"type:text" json:"errors"`
+	Error      string       `json:"error,omitempty"`
+	CreatedAt  time.Time    `json:"created_at"`
+	FinishedAt *time.Time   `json:"finished_at,omitempty"`
+}
+
+// apply copies the progress of an import onto the job.
+func (j *ImportJob) apply(result *ImportResult) {
+	j.Total = result.Total
+	j.Valid = result.Valid
+	j.Imported = result.Imported
+	j.Failed = result.Failed
+	j.Errors = result.Errors
+}
+
+// ImportReader reads the rows of an import file one at a time. Lines that
+// cannot be parsed are returned as rows with Err set, so they show up in the
+// error report.
+type ImportReader interface {
+	// Next returns the next row, or io.EOF after the last one. Any other error
+	// means the file as a whole is unusable.
+	Next() (ImportRow, error)
+}
+
+// NewImportReader starts reading an import file in the given format. The
+// header of a CSV file is read and checked here.
+func NewImportReader(r io.Reader, format string) (ImportReader, error) {
+	switch format {
+	case ImportFormatCSV:
+		return newCSVImportReader(r)
+	case ImportFormatNDJSON:
+		return newNDJSONImportReader(r), nil
+	}
+	return nil, ErrInvalidImport
+}
+
+// ParseImport reads all hotels of an import file into memory; an error is
+// returned only when the file as a whole is unusable.
+func ParseImport(r io.Reader, format string) ([]ImportRow, error) {
+	reader, err := NewImportReader(r, format)
+	if err != nil {
+		return nil, err
+	}
+	return readImportRows(reader)
+}
+
+func readImportRows(reader ImportReader) ([]ImportRow, error) {
+	var rows []ImportRow
+	for {
+		row, err := reader.Next()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+}
+
+// ImportSource holds the rows of an import. The rows can be read more than
+// once, so that an import can validate them before storing them.
+type ImportSource interface {
+	// Len returns the number of rows.
+	Len() int
+	// Read calls fn with a reader at the first row.
+	Read(fn func(ImportReader) error) error
+	// Close releases the rows.
+	Close() error
+}
+
+// ImportRows is an import source holding its rows in memory.
+type ImportRows []ImportRow
+
+func (rows ImportRows) Len() int {
+	return len(rows)
+}
+
+func (rows ImportRows) Read(fn func(ImportReader) error) error {
+	return fn(&importRowsReader{rows: rows})
+}
+
+func (rows ImportRows) Close() error {
+	return nil
+}
+
+type importRowsReader struct {
+	rows []ImportRow
+}
+
+func (r *importRowsReader) Next() (ImportRow, error) {
+	if len(r.rows) == 0 {
+		return ImportRow{}, io.EOF
+	}
+	row := r.rows[0]
+	r.rows = r.rows[1:]
+	return row, nil
+}
+
+// ImportFile is an import file spooled to a temporary file, so that an import
+// job reads its rows one batch at a time instead of holding all of them in
+// memory.
+type ImportFile struct {
+	path   string
+	format string
+	rows   int
+}
+
+// SpoolImport copies an import file to a temporary file and counts its rows.
+// The file must be closed to remove the temporary file.
+func SpoolImport(r io.Reader, format string) (*ImportFile, error) {
+	if format != ImportFormatCSV && format != ImportFormatNDJSON {
+		return nil, ErrInvalidImport
+	}
+	temp, err := os.CreateTemp("", "hotel-import-*")
+	if err != nil {
+		return nil, fmt.Errorf("error spooling import: %w", err)
+	}
+	file := &ImportFile{path: temp.Name(), format: format}
+	_, err = io.Copy(temp, r)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = file.Read(func(reader ImportReader) error {
+			for {
+				if _, err := reader.Next(); err != nil {
+					if err == io.EOF {
+						return nil
+					}
+					return err
+				}
+				file.rows++
+			}
+		})
+	}
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return file, nil
+}
+
+func (f *ImportFile) Len() int {
+	return f.rows
+}
+
+func (f *ImportFile) Read(fn func(ImportReader) error) error {
+	file, err := os.Open(f.path)
+	if err != nil {
+		return fmt.Errorf("error opening spooled import: %w", err)
+	}
+	defer file.Close()
+
+	reader, err := NewImportReader(bufio.NewReader(file), f.format)
+	if err != nil {
+		return err
+	}
+	return fn(reader)
+}
+
+// Rows reads all rows of the file into memory.
+func (f *ImportFile) Rows() ([]ImportRow, error) {
+	var rows []ImportRow
+	err := f.Read(func(reader ImportReader) error {
+		var err error
+		rows, err = readImportRows(reader)
+		return err
+	})
+	return rows, err
+}
+
+// Close removes the temporary file.
+func (f *ImportFile) Close() error {
+	return os.Remove(f.path)
+}
+
+// csvImportReader reads a CSV file whose header names the columns. The
+// owner_name, owner_surname and company_title columns are required; every
+// "contact:<type>" column adds a contact of that type unless its cell is empty.
+type csvImportReader struct {
+	reader       *csv.Reader
+	columns      map[string]int
+	contactTypes map[int]string
+}
+
+func newCSVImportReader(r io.Reader) (*csvImportReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+	}
+	columns := make(map[string]int)
+	contactTypes := make(map[int]string)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if strings.HasPrefix(name, csvContactPrefix) {
+			contactTypes[i] = strings.TrimSpace(strings.TrimPrefix(name, csvContactPrefix))
+			continue
+		}
+		columns[name] = i
+	}
+	for _, required := range []string{"owner_name", "owner_surname", "company_title"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("%w: missing column %s", ErrInvalidImport, required)
+		}
+	}
+	return &csvImportReader{reader: reader, columns: columns, contactTypes: contactTypes}, nil
+}
+
+func (c *csvImportReader) Next() (ImportRow, error) {
+	record, err := c.reader.Read()
+	if err == io.EOF {
+		return ImportRow{}, io.EOF
+	}
+	if err != nil {
+		var parseErr *csv.ParseError
+		if !errors.As(err, &parseErr) {
+			return ImportRow{}, err
+		}
+		return ImportRow{Row: parseErr.StartLine, Err: parseErr.Err}, nil
+	}
+
+	line, _ := c.reader.FieldPos(0)
+	cell := func(i int) string {
+		if i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	row := ImportRow{
+		Row:          line,
+		OwnerName:    cell(c.columns["owner_name"]),
+		OwnerSurname: cell(c.columns["owner_surname"]),
+		CompanyTitle: cell(c.columns["company_title"]),
+	}
+	for i := range record {
+		if infoType, ok := c.contactTypes[i]; ok && cell(i) != "" {
+			row.Contacts = append(row.Contacts, ContactInfo{InfoType: infoType, InfoContent: cell(i)})
+		}
+	}
+	return row, nil
+}
+
+// ndjsonImportReader reads one hotel per line, each in the format of the
+// POST /hotels request body. Blank lines are skipped.
+type ndjsonImportReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func newNDJSONImportReader(r io.Reader) *ndjsonImportReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), MaxImportSize)
+	return &ndjsonImportReader{scanner: scanner}
+}
+
+func (n *ndjsonImportReader) Next() (ImportRow, error) {
+	for n.scanner.Scan() {
+		n.line++
+		data := bytes.TrimSpace(n.scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		var request struct {
+			OwnerName    string        `json:"ownerName"`
+			OwnerSurname string        `json:"ownerSurname"`
+			CompanyTitle string        `json:"companyTitle"`
+			Contacts     []ContactInfo `json:"contacts"`
+		}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&request); err != nil {
+			return ImportRow{Row: n.line, Err: err}, nil
+		}
+		return ImportRow{
+			Row:          n.line,
+			OwnerName:    request.OwnerName,
+			OwnerSurname: request.OwnerSurname,
+			CompanyTitle: request.CompanyTitle,
+			Contacts:     request.Contacts,
+		}, nil
+	}
+	if err := n.scanner.Err(); err != nil {
+		return ImportRow{}, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+	}
+	return ImportRow{}, io.EOF
+}
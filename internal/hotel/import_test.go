@@ -0,0 +1,97 @@
+package hotel
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseImport_CSV(t *testing.T) {
+	file := "owner_name,owner_surname,company_title,contact:phone,contact:phone,contact:email\n" +
+		"John,Doe,Doe Ltd.,0212 555 01 00,,desk@doe.example\n" +
+		"\"Jane,Smith,Broken\n"
+
+	rows, err := ParseImport(strings.NewReader(file), ImportFormatCSV)
+	assert.NoError(t, err)
+	if assert.Len(t, rows, 2) {
+		assert.Equal(t, ImportRow{
+			Row:          2,
+			OwnerName:    "John",
+			OwnerSurname: "Doe",
+			CompanyTitle: "Doe Ltd.",
+			Contacts: []ContactInfo{
+				{InfoType: ContactTypePhone, InfoContent: "0212 555 01 00"},
+				{InfoType: ContactTypeEmail, InfoContent: "desk@doe.example"},
+			},
+		}, rows[0])
+
+		// Unparseable lines are reported as rows
+		assert.Equal(t, 3, rows[1].Row)
+		assert.Error(t, rows[1].Err)
+	}
+
+	// The hotel columns are required
+	_, err = ParseImport(strings.NewReader("owner_name,company_title\nJohn,Doe Ltd.\n"), ImportFormatCSV)
+	assert.ErrorIs(t, err, ErrInvalidImport)
+}
+
+func TestParseImport_NDJSON(t *testing.T) {
+	file := `{"ownerName":"John","ownerSurname":"Doe","companyTitle":"Doe Ltd.","contacts":[{"info_type":"phone","info_content":"+902125550100"}]}
+
+{"ownerName":"Jane","unknown":true}
+`
+
+	rows, err := ParseImport(strings.NewReader(file), ImportFormatNDJSON)
+	assert.NoError(t, err)
+	if assert.Len(t, rows, 2) {
+		assert.Equal(t, 1, rows[0].Row)
+		assert.Equal(t, "Doe Ltd.", rows[0].CompanyTitle)
+		assert.Len(t, rows[0].Contacts, 1)
+
+		// Blank lines are skipped but still counted
+		assert.Equal(t, 3, rows[1].Row)
+		assert.Error(t, rows[1].Err)
+	}
+
+	_, err = ParseImport(strings.NewReader(file), "xml")
+	assert.ErrorIs(t, err, ErrInvalidImport)
+}
+
+func TestSpoolImport(t *testing.T) {
+	file, err := SpoolImport(strings.NewReader("owner_name,owner_surname,company_title\nJohn,Doe,Doe Ltd.\nJane,Smith,Smith Ltd.\n"), ImportFormatCSV)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 2, file.Len())
+
+	// The file can be read more than once
+	for i := 0; i < 2; i++ {
+		var titles []string
+		err := file.Read(func(reader ImportReader) error {
+			for {
+				row, err := reader.Next()
+				if err == io.EOF {
+					return nil
+				}
+				if err != nil {
+					return err
+				}
+				titles = append(titles, row.CompanyTitle)
+			}
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Doe Ltd.", "Smith Ltd."}, titles)
+	}
+
+	// Closing the file removes it
+	assert.NoError(t, file.Close())
+	_, err = os.Stat(file.path)
+	assert.True(t, os.IsNotExist(err))
+
+	// The header is checked while spooling
+	_, err = SpoolImport(strings.NewReader("owner_name\nJohn\n"), ImportFormatCSV)
+	assert.ErrorIs(t, err, ErrInvalidImport)
+}
@@ -0,0 +1,155 @@
+package hotel
+
+import (
+	"encoding/json"
+	"errors"
+	"hotel-guide/internal/pagination"
+	"strings"
+)
+
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+var (
+	ErrInvalidCursor = pagination.ErrInvalidCursor
+	ErrInvalidSort   = errors.New("invalid sort field")
+)
+
+// sortableColumns maps the sort fields accepted by ListHotels to their columns.
+var sortableColumns = map[string]string{
+	"id":            "hotels.id",
+	"owner_name":    "hotels.owner_name",
+	"owner_surname": "hotels.owner_surname",
+	"company_title": "hotels.company_title",
+	"version":       "hotels.version",
+	"rating":        "hotels.average_score",
+}
+
+// ListOptions controls which hotels ListHotels returns and in which order.
+type ListOptions struct {
+	Limit  int
+	Offset int
+	// Cursor continues a listing after the last hotel of a previous page and
+	// takes precedence over Offset.
+	Cursor   string
+	SortBy   string
+	SortDesc bool
+
+	CompanyTitle string
+	OwnerName    string
+	ContactType  string
+	// MinRating only lists hotels whose average score is at least this.
+	MinRating float64
+	// Tags only lists hotels that have every one of these tags.
+	Tags     []string
+	Location LocationFilter
+	// Facets counts the matching hotels by tag and location, see Facets.
+	Facets bool
+	// IncludeDeleted also lists soft-deleted hotels.
+	IncludeDeleted bool
+
+	after *pagination.Cursor
+}
+
+// HotelPage is one page of a hotel listing.
+type HotelPage struct {
+	Items      []Hotel   `json:"items"`
+	Total      int64     `json:"total"`
+	Limit      int       `json:"limit"`
+	Offset     int       `json:"offset"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+	Links      PageLinks `json:"links"`
+	// Facets cover every hotel matching the filters, not only this page.
+	Facets *Facets `json:"facets,omitempty"`
+}
+
+// PageLinks holds the URLs of the current and the next page.
+type PageLinks struct {
+	Self string `json:"self"`
+	Next string `json:"next,omitempty"`
+}
+
+// normalize applies defaults and validates the options.
+func (o *ListOptions) normalize() error {
+	if o.Limit <= 0 {
+		o.Limit = DefaultPageLimit
+	}
+	if o.Limit > MaxPageLimit {
+		o.Limit = MaxPageLimit
+	}
+	if o.Offset < 0 {
+		o.Offset = 0
+	}
+
+	if len(o.Tags) > 0 {
+		o.Tags = normalizeTags(o.Tags)
+	}
+
+	o.SortBy = strings.ToLower(strings.TrimSpace(o.SortBy))
+	if o.SortBy == "" {
+		o.SortBy = "id"
+	}
+	if _, ok := sortableColumns[o.SortBy]; !ok {
+		return ErrInvalidSort
+	}
+
+	o.after = nil
+	if o.Cursor != "" {
+		cursor, err := pagination.Decode(o.Cursor, o.sortKey())
+		if err != nil {
+			return err
+		}
+		switch o.SortBy {
+		case "version":
+			number, ok := cursor.Value.(json.Number)
+			if !ok {
+				return ErrInvalidCursor
+			}
+			if cursor.Value, err = number.Int64(); err != nil {
+				return ErrInvalidCursor
+			}
+		case "rating":
+			number, ok := cursor.Value.(json.Number)
+			if !ok {
+				return ErrInvalidCursor
+			}
+			if cursor.Value, err = number.Float64(); err != nil {
+				return ErrInvalidCursor
+			}
+		}
+		o.after = cursor
+		o.Offset = 0
+	}
+	return nil
+}
+
+// sortKey identifies the ordering a cursor was issued for.
+func (o *ListOptions) sortKey() string {
+	if o.SortDesc {
+		return "-" + o.SortBy
+	}
+	return o.SortBy
+}
+
+// nextCursor returns the cursor that continues the listing after hotel.
+func (o *ListOptions) nextCursor(hotel Hotel) string {
+	var value interface{}
+	switch o.SortBy {
+	case "id":
+		value = hotel.ID.String()
+	case "owner_name":
+		value = hotel.OwnerName
+	case "owner_surname":
+		value = hotel.OwnerSurname
+	case "company_title":
+		value = hotel.CompanyTitle
+	case "version":
+		value = hotel.Version
+	case "rating":
+		value = hotel.AverageScore
+	}
+
+	return pagination.Encode(pagination.Cursor{Sort: o.sortKey(), Value: value, ID: hotel.ID})
+}
@@ -0,0 +1,178 @@
+package hotel
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Kinds of media. Images get a thumbnail; documents are stored as uploaded.
+const (
+	MediaKindImage    = "image"
+	MediaKindDocument = "document"
+)
+
+const (
+	// MaxMediaSize limits the size of an uploaded media file in bytes.
+	MaxMediaSize = 20 << 20
+	// ThumbnailSize is the longest side of a thumbnail in pixels.
+	ThumbnailSize = 320
+	// maxImagePixels keeps uploads from decoding into huge images: a decoded
+	// image takes up to 4 bytes per pixel.
+	maxImagePixels = 16_000_000
+	// ThumbnailContentType is the content type of every thumbnail.
+	ThumbnailContentType = "image/jpeg"
+)
+
+// mediaKinds maps the accepted content types onto their kind.
+var mediaKinds = map[string]string{
+	"image/jpeg":      MediaKindImage,
+	"image/png":       MediaKindImage,
+	"image/gif":       MediaKindImage,
+	"application/pdf": MediaKindDocument,
+}
+
+var (
+	ErrMediaNotFound      = errors.New("media not found")
+	ErrThumbnailNotFound  = errors.New("media has no thumbnail")
+	ErrInvalidMediaOrder  = errors.New("media order must list every media of the hotel exactly once")
+	ErrUnsupportedMedia   = errors.New("media must be a JPEG, PNG or GIF image or a PDF document")
+	ErrInvalidMediaUpload = errors.New("media upload requires a non-empty file")
+)
+
+// Media is an image or document of a hotel. The file itself is kept in the
+// blob store; the media records what is known about it.
+type Media struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	HotelID     uuid.UUID `gorm:"type:uuid;not null;index" json:"hotel_id"`
+	Kind        string    `gorm:"not null" json:"kind"`
+	FileName    string    `json:"file_name"`
+	ContentType string    `gorm:"not null" json:"content_type"`
+	Size        int64     `gorm:"not null" json:"size"`
+	// Width and Height are the dimensions of images in pixels.
+	Width  int `json:"width,omitempty"`
+	Height int `json:"height,omitempty"`
+	// Checksum is the hex SHA-256 of the file.
+	Checksum string `gorm:"not null" json:"checksum"`
+	// Position orders the media of a hotel for display, starting at 1.
+	Position int `gorm:"not null" json:"position"`
+	// StorageKey is the blob key of the file. The thumbnail of an image is
+	// kept under thumbnailKey(StorageKey).
+	StorageKey   string    `gorm:"not null" json:"-"`
+	HasThumbnail bool      `gorm:"not null;default:false" json:"has_thumbnail"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// TableName keeps the media of hotels apart from any other media.
+func (Media) TableName() string {
+	return "hotel_media"
+}
+
+// mediaKey returns the blob key of a new media file.
+func mediaKey(hotelID, mediaID uuid.UUID) string {
+	return path.Join("hotels", hotelID.String(), "media", mediaID.String())
+}
+
+// thumbnailKey returns the blob key of the thumbnail of a media file.
+func thumbnailKey(storageKey string) string {
+	return storageKey + "_thumb.jpg"
+}
+
+// inspectMedia fills in the kind, content type, size, checksum and dimensions
+// of an uploaded file from its contents, and returns the encoded thumbnail of
+// images. The content type is sniffed rather than taken from the client.
+func inspectMedia(media *Media, data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, ErrInvalidMediaUpload
+	}
+	contentType := http.DetectContentType(data)
+	kind, ok := mediaKinds[contentType]
+	if !ok {
+		return nil, ErrUnsupportedMedia
+	}
+
+	sum := sha256.Sum256(data)
+	media.Kind = kind
+	media.ContentType = contentType
+	media.Size = int64(len(data))
+	media.Checksum = hex.EncodeToString(sum[:])
+	media.FileName = path.Base(strings.ReplaceAll(strings.TrimSpace(media.FileName), `\`, "/"))
+	if media.FileName == "." || media.FileName == "/" {
+		media.FileName = ""
+	}
+	if kind != MediaKindImage {
+		return nil, nil
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedMedia, err)
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > maxImagePixels {
+		return nil, fmt.Errorf("%w: image must have at most %d pixels", ErrUnsupportedMedia, maxImagePixels)
+	}
+	media.Width, media.Height = config.Width, config.Height
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedMedia, err)
+	}
+	var thumb bytes.Buffer
+	if err := jpeg.Encode(&thumb, thumbnail(img, ThumbnailSize), &jpeg.Options{Quality: 80}); err != nil {
+		return nil, fmt.Errorf("failed to encode thumbnail: %w", err)
+	}
+	return thumb.Bytes(), nil
+}
+
+// thumbnail scales an image down so that its longest side is at most size,
+// averaging the source pixels that fall into each thumbnail pixel. Pixels are
+// read from the decoded image, so no full-size copy is made. Transparent areas
+// are drawn onto white, as JPEG has no alpha channel. Smaller images keep their
+// size.
+func thumbnail(img image.Image, size int) *image.RGBA {
+	bounds := img.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	dstW, dstH := srcW, srcH
+	if srcW > size || srcH > size {
+		if srcW >= srcH {
+			dstW, dstH = size, max(1, srcH*size/srcW)
+		} else {
+			dstW, dstH = max(1, srcW*size/srcH), size
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := 0; y < dstH; y++ {
+		y0, y1 := y*srcH/dstH, max((y+1)*srcH/dstH, y*srcH/dstH+1)
+		for x := 0; x < dstW; x++ {
+			x0, x1 := x*srcW/dstW, max((x+1)*srcW/dstW, x*srcW/dstW+1)
+			var r, g, b, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					// The channels are alpha-premultiplied, so adding the
+					// transparent part of white draws the pixel onto white.
+					pr, pg, pb, pa := img.At(bounds.Min.X+sx, bounds.Min.Y+sy).RGBA()
+					r += uint64(pr + 0xffff - pa)
+					g += uint64(pg + 0xffff - pa)
+					b += uint64(pb + 0xffff - pa)
+					n++
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{uint8(r / n >> 8), uint8(g / n >> 8), uint8(b / n >> 8), 255})
+		}
+	}
+	return dst
+}
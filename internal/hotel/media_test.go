@@ -0,0 +1,101 @@
+package hotel
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testPNG encodes a width x height image filled with c as PNG.
+func testPNG(t *testing.T, width, height int, c color.Color) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("Failed to encode PNG: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// resizePNG rewrites the size in the header of a PNG without touching its
+// pixels, which is all image.DecodeConfig reads.
+func resizePNG(data []byte, width, height int) []byte {
+	data = bytes.Clone(data)
+	binary.BigEndian.PutUint32(data[16:], uint32(width))
+	binary.BigEndian.PutUint32(data[20:], uint32(height))
+	binary.BigEndian.PutUint32(data[29:], crc32.ChecksumIEEE(data[12:29]))
+	return data
+}
+
+func TestInspectMedia(t *testing.T) {
+	// Images are sniffed, measured and get a thumbnail whatever their file name
+	media := Media{FileName: `C:\photos\lobby.pdf`}
+	thumb, err := inspectMedia(&media, testPNG(t, 800, 400, color.RGBA{200, 0, 0, 255}))
+	assert.NoError(t, err)
+	assert.Equal(t, MediaKindImage, media.Kind)
+	assert.Equal(t, "image/png", media.ContentType)
+	assert.Equal(t, "lobby.pdf", media.FileName)
+	assert.Equal(t, 800, media.Width)
+	assert.Equal(t, 400, media.Height)
+	assert.Len(t, media.Checksum, 64)
+
+	config, err := jpeg.DecodeConfig(bytes.NewReader(thumb))
+	assert.NoError(t, err)
+	assert.Equal(t, ThumbnailSize, config.Width)
+	assert.Equal(t, ThumbnailSize/2, config.Height)
+
+	// Documents are stored as uploaded
+	document := Media{FileName: "menu.pdf"}
+	thumb, err = inspectMedia(&document, []byte("%PDF-1.4\n1 0 obj\n<<>>\nendobj\n"))
+	assert.NoError(t, err)
+	assert.Nil(t, thumb)
+	assert.Equal(t, MediaKindDocument, document.Kind)
+	assert.Equal(t, "application/pdf", document.ContentType)
+	assert.Zero(t, document.Width)
+
+	_, err = inspectMedia(&Media{}, []byte("just some text"))
+	assert.ErrorIs(t, err, ErrUnsupportedMedia)
+	_, err = inspectMedia(&Media{}, append([]byte("\x89PNG\r\n\x1a\n"), "truncated"...))
+	assert.ErrorIs(t, err, ErrUnsupportedMedia)
+	_, err = inspectMedia(&Media{}, nil)
+	assert.ErrorIs(t, err, ErrInvalidMediaUpload)
+
+	// Images too large to decode are rejected from their header
+	_, err = inspectMedia(&Media{}, resizePNG(testPNG(t, 1, 1, color.White), 5000, 4000))
+	assert.ErrorIs(t, err, ErrUnsupportedMedia)
+	assert.ErrorContains(t, err, "at most 16000000 pixels")
+}
+
+func TestThumbnail(t *testing.T) {
+	// Portrait images are scaled to the thumbnail height, averaging pixels
+	img := image.NewRGBA(image.Rect(0, 0, 4, 8))
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 4; x++ {
+			if x%2 == 0 {
+				img.Set(x, y, color.RGBA{255, 255, 255, 255})
+			} else {
+				img.Set(x, y, color.RGBA{0, 0, 0, 255})
+			}
+		}
+	}
+	thumb := thumbnail(img, 4)
+	assert.Equal(t, image.Rect(0, 0, 2, 4), thumb.Bounds())
+	assert.Equal(t, color.RGBA{127, 127, 127, 255}, thumb.RGBAAt(0, 0))
+
+	// Small images keep their size and transparency becomes white
+	transparent := image.NewNRGBA(image.Rect(0, 0, 3, 2))
+	thumb = thumbnail(transparent, ThumbnailSize)
+	assert.Equal(t, image.Rect(0, 0, 3, 2), thumb.Bounds())
+	assert.Equal(t, color.RGBA{255, 255, 255, 255}, thumb.RGBAAt(2, 1))
+}
@@ -0,0 +1,137 @@
+package hotel
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MigrateLocationContacts converts the free-text "location" contact infos into
+// structured Locations. The first location contact of a hotel without a
+// Location is parsed and removed; hotels that already have one are left alone.
+func MigrateLocationContacts(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var contacts []ContactInfo
+		if err := tx.Where("info_type = ?", ContactTypeLocation).Find(&contacts).Error; err != nil {
+			return fmt.Errorf("error fetching location contacts: %w", err)
+		}
+
+		seen := make(map[uuid.UUID]bool)
+		for _, contact := range contacts {
+			if seen[contact.HotelID] {
+				log.Printf("Skipping extra location contact %s of hotel %s", contact.ID, contact.HotelID)
+				continue
+			}
+			seen[contact.HotelID] = true
+
+			var count int64
+			if err := tx.Model(&Location{}).Where("hotel_id = ?", contact.HotelID).Count(&count).Error; err != nil {
+				return fmt.Errorf("error checking location of hotel %v: %w", contact.HotelID, err)
+			}
+			if count > 0 {
+				continue
+			}
+
+			if err := tx.Create(parseLegacyLocation(contact.HotelID, contact.InfoContent)).Error; err != nil {
+				return fmt.Errorf("error creating location of hotel %v: %w", contact.HotelID, err)
+			}
+			if err := tx.Delete(&contact).Error; err != nil {
+				return fmt.Errorf("error removing location contact %v: %w", contact.ID, err)
+			}
+		}
+		return nil
+	})
+}
+
+// MigrateOwnerOfficials records the owner fields of every hotel without any
+// official as its owner official, so hotels created before officials existed
+// list their owner. Hotels that already have officials are left alone.
+func MigrateOwnerOfficials(db *gorm.DB) error {
+	var hotels []Hotel
+	return db.Unscoped().
+		Where("NOT EXISTS (SELECT 1 FROM hotel_officials WHERE hotel_officials.hotel_id = hotels.id)").
+		Where("owner_name <> '' OR owner_surname <> ''").
+		FindInBatches(&hotels, 500, func(_ *gorm.DB, _ int) error {
+			officials := make([]HotelOfficial, len(hotels))
+			for i := range hotels {
+				officials[i] = ownerOfficial(&hotels[i])
+			}
+			if err := db.Create(&officials).Error; err != nil {
+				return fmt.Errorf("error creating owner officials: %w", err)
+			}
+			return nil
+		}).Error
+}
+
+// parseLegacyLocation reads a comma separated "district, city, country" string.
+// A single part is taken as the city and two parts as "city, country"; any
+// parts before the district are kept as the street.
+func parseLegacyLocation(hotelID uuid.UUID, content string) *Location {
+	var parts []string
+	for _, part := range strings.Split(content, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	location := &Location{ID: uuid.New(), HotelID: hotelID}
+	switch n := len(parts); {
+	case n == 1:
+		location.City = parts[0]
+	case n == 2:
+		location.City, location.Country = parts[0], parts[1]
+	case n >= 3:
+		location.Street = strings.Join(parts[:n-3], ", ")
+		location.District, location.City, location.Country = parts[n-3], parts[n-2], parts[n-1]
+	}
+	return location
+}
+
+// DeleteOrphans removes the rows whose parent was deleted before the foreign
+// key between them existed, so that AutoMigrate can add the key. Tables that do
+// not exist yet are skipped.
+func DeleteOrphans(db *gorm.DB) error {
+	for _, orphan := range []struct{ table, column, parent string }{
+		{"reservations", "room_type_id", "room_types"},
+		{"reviews", "hotel_id", "hotels"},
+	} {
+		if !db.Migrator().HasTable(orphan.table) || !db.Migrator().HasTable(orphan.parent) {
+			continue
+		}
+		result := db.Exec(fmt.Sprintf("DELETE FROM %s WHERE %s NOT IN (SELECT id FROM %s)", orphan.table, orphan.column, orphan.parent))
+		if result.Error != nil {
+			return fmt.Errorf("error deleting orphaned %s: %w", orphan.table, result.Error)
+		}
+		if result.RowsAffected > 0 {
+			log.Printf("Deleted %d orphaned %s", result.RowsAffected, orphan.table)
+		}
+	}
+	return nil
+}
+
+// SeedContactTypes adds the default contact types missing from the registry.
+// Types that already exist keep their current settings.
+func SeedContactTypes(db *gorm.DB) error {
+	for _, contactType := range DefaultContactTypes {
+		contactType := contactType
+		if err := db.Where(ContactType{Name: contactType.Name}).FirstOrCreate(&contactType).Error; err != nil {
+			return fmt.Errorf("error seeding contact type %s: %w", contactType.Name, err)
+		}
+	}
+	return nil
+}
+
+// SeedTags adds the default tags missing from the vocabulary. Tags that already
+// exist keep their current settings.
+func SeedTags(db *gorm.DB) error {
+	for _, tag := range DefaultTags {
+		tag := tag
+		if err := db.Where(Tag{Name: tag.Name}).FirstOrCreate(&tag).Error; err != nil {
+			return fmt.Errorf("error seeding tag %s: %w", tag.Name, err)
+		}
+	}
+	return nil
+}
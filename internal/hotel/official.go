@@ -0,0 +1,112 @@
+package hotel
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Common roles of hotel officials. Other roles are accepted as long as they
+// are lowercase names such as "night_manager".
+const (
+	RoleOwner          = "owner"
+	RoleGeneralManager = "general_manager"
+	RoleSalesManager   = "sales_manager"
+)
+
+var ErrOfficialNotFound = errors.New("official not found")
+
+// HotelOfficial is a person holding a role at a hotel, optionally for a
+// limited period. A hotel may have any number of officials, also several with
+// the same role.
+type HotelOfficial struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	HotelID   uuid.UUID  `gorm:"type:uuid;not null;index" json:"hotel_id"`
+	Role      string     `gorm:"not null;index" json:"role"`
+	Name      string     `gorm:"not null" json:"name"`
+	Surname   string     `gorm:"not null" json:"surname"`
+	StartDate *time.Time `json:"start_date,omitempty"`
+	// EndDate is the first day the official no longer holds the role.
+	EndDate      *time.Time        `json:"end_date,omitempty"`
+	ContactInfos []OfficialContact `gorm:"foreignKey:OfficialID;references:ID;constraint:OnDelete:CASCADE;" json:"contact_infos"`
+	// CompanyTitle is the title of the hotel. It is read from the hotel when
+	// officials are listed and is not stored with the official.
+	CompanyTitle string `gorm:"->;-:migration" json:"company_title,omitempty"`
+}
+
+// OfficialContact is a contact info of an official. Its type is one of the
+// contact type registry, as for the contact infos of hotels.
+type OfficialContact struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	OfficialID  uuid.UUID `gorm:"type:uuid;not null;index" json:"official_id"`
+	InfoType    string    `json:"info_type"`
+	InfoContent string    `json:"info_content"`
+}
+
+// OfficialFilter selects the officials ListHotelOfficials returns. Zero fields
+// match every official.
+type OfficialFilter struct {
+	HotelID uuid.UUID
+	Role    string
+	// ActiveAt only matches officials holding their role at that time.
+	ActiveAt time.Time
+}
+
+var roleName = regexp.MustCompile(`^[a-z][a-z0-9_]{0,31}$`)
+
+// normalizeRole lower-cases a role and joins its words with underscores, so
+// that "General Manager" becomes "general_manager".
+func normalizeRole(role string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(role), func(r rune) bool {
+		return r == ' ' || r == '-' || r == '_'
+	}), "_")
+}
+
+// validateOfficial normalizes an official and its contacts and returns a
+// *ValidationError listing every invalid field. Phone numbers in national
+// format are assumed to belong to country.
+func (r contactRegistry) validateOfficial(official *HotelOfficial, country string) error {
+	official.Role = normalizeRole(official.Role)
+	official.Name = strings.TrimSpace(official.Name)
+	official.Surname = strings.TrimSpace(official.Surname)
+
+	var fields []FieldError
+	if !roleName.MatchString(official.Role) {
+		fields = append(fields, FieldError{"role", "must be a name such as owner, general_manager or sales_manager"})
+	}
+	if official.Name == "" {
+		fields = append(fields, FieldError{"name", "is required"})
+	}
+	if official.Surname == "" {
+		fields = append(fields, FieldError{"surname", "is required"})
+	}
+	if official.StartDate != nil && official.EndDate != nil && !official.EndDate.After(*official.StartDate) {
+		fields = append(fields, FieldError{"end_date", "must be after start_date"})
+	}
+
+	for i := range official.ContactInfos {
+		contact := ContactInfo{InfoType: official.ContactInfos[i].InfoType, InfoContent: official.ContactInfos[i].InfoContent}
+		fields = append(fields, r.normalizeContact(&contact, country, fmt.Sprintf("contact_infos[%d].", i))...)
+		official.ContactInfos[i].InfoType, official.ContactInfos[i].InfoContent = contact.InfoType, contact.InfoContent
+	}
+
+	if len(fields) > 0 {
+		return &ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+// ownerOfficial returns the owner official recorded by the owner fields of a hotel.
+func ownerOfficial(hotel *Hotel) HotelOfficial {
+	return HotelOfficial{
+		ID:      uuid.New(),
+		HotelID: hotel.ID,
+		Role:    RoleOwner,
+		Name:    hotel.OwnerName,
+		Surname: hotel.OwnerSurname,
+	}
+}
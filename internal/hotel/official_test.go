@@ -0,0 +1,45 @@
+package hotel
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateOfficial(t *testing.T) {
+	registry := newContactRegistry(DefaultContactTypes)
+
+	start := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	official := &HotelOfficial{
+		Role:         " General Manager ",
+		Name:         " Ayse ",
+		Surname:      "Yilmaz",
+		StartDate:    &start,
+		ContactInfos: []OfficialContact{{InfoType: "Phone", InfoContent: "0532 555 01 00"}},
+	}
+	assert.NoError(t, registry.validateOfficial(official, "TR"))
+	assert.Equal(t, RoleGeneralManager, official.Role)
+	assert.Equal(t, "Ayse", official.Name)
+	assert.Equal(t, OfficialContact{InfoType: ContactTypePhone, InfoContent: "+905325550100"}, official.ContactInfos[0])
+
+	// Every invalid field is reported
+	end := start.AddDate(0, 0, -1)
+	official = &HotelOfficial{
+		Role:         "manager!",
+		Surname:      "Yilmaz",
+		StartDate:    &start,
+		EndDate:      &end,
+		ContactInfos: []OfficialContact{{InfoType: ContactTypeEmail, InfoContent: "not-an-email"}},
+	}
+	err := registry.validateOfficial(official, "TR")
+	var validationErr *ValidationError
+	if assert.True(t, errors.As(err, &validationErr)) {
+		var fields []string
+		for _, field := range validationErr.Fields {
+			fields = append(fields, field.Field)
+		}
+		assert.Equal(t, []string{"role", "name", "end_date", "contact_infos[0].info_content"}, fields)
+	}
+}
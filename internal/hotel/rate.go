@@ -0,0 +1,287 @@
+package hotel
+
+import (
+	"errors"
+	"fmt"
+	"hotel-guide/internal/money"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Meal plans of a rate plan.
+const (
+	MealPlanRoomOnly     = "room_only"
+	MealPlanBreakfast    = "breakfast"
+	MealPlanHalfBoard    = "half_board"
+	MealPlanFullBoard    = "full_board"
+	MealPlanAllInclusive = "all_inclusive"
+)
+
+var mealPlans = map[string]bool{
+	MealPlanRoomOnly: true, MealPlanBreakfast: true, MealPlanHalfBoard: true, MealPlanFullBoard: true, MealPlanAllInclusive: true,
+}
+
+// DefaultCurrency is the currency of location stats when none is requested.
+const DefaultCurrency = "EUR"
+
+// maxPrice limits the base price of a rate plan.
+var maxPrice = money.NewFromInt(1_000_000_000)
+
+var (
+	ErrRatePlanNotFound     = errors.New("rate plan not found")
+	ErrRatePlanOverlap      = errors.New("rate plan overlaps another rate plan of the room type with the same meal plan")
+	ErrNoRatePlan           = errors.New("no rate plan of the room type and meal plan covers every night of the stay")
+	ErrExchangeRateNotFound = errors.New("exchange rate not found")
+	ErrNoExchangeRate       = errors.New("no exchange rate is in effect between the currencies")
+	ErrInvalidExchangeRate  = errors.New("exchange rate requires two different currencies, a positive rate and an effective date")
+)
+
+// RatePlan prices the nights of a room type from ValidFrom up to, but not
+// including, ValidTo. Both are days at midnight UTC. Rate plans of a room type
+// with the same meal plan do not overlap, so every night has at most one
+// price per meal plan.
+type RatePlan struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	HotelID    uuid.UUID `gorm:"type:uuid;not null;index" json:"hotel_id"`
+	RoomTypeID uuid.UUID `gorm:"type:uuid;not null;index:idx_rate_plans_stay" json:"room_type_id"`
+	Name       string    `gorm:"not null" json:"name"`
+	ValidFrom  time.Time `gorm:"not null;index:idx_rate_plans_stay" json:"valid_from"`
+	ValidTo    time.Time `gorm:"not null" json:"valid_to"`
+	// BasePrice is the price of one room for one night in Currency.
+	BasePrice    money.Decimal      `gorm:"type:numeric(18,6);not null" json:"base_price"`
+	Currency     string             `gorm:"type:char(3);not null" json:"currency"`
+	MealPlan     string             `gorm:"not null" json:"meal_plan"`
+	Cancellation CancellationPolicy `gorm:"embedded;embeddedPrefix:cancellation_" json:"cancellation"`
+	CreatedAt    time.Time          `json:"created_at"`
+}
+
+// HotelPrice is the base price of a rate plan of a hotel.
+type HotelPrice struct {
+	HotelID uuid.UUID
+	Price   money.Amount
+}
+
+// CancellationPolicy says until when a stay can be cancelled free of charge.
+type CancellationPolicy struct {
+	Refundable bool `json:"refundable"`
+	// FreeDays is the number of days before check-in up to which a refundable
+	// stay can be cancelled free of charge.
+	FreeDays int `json:"free_days"`
+	// PenaltyNights is the number of nights charged for a later cancellation.
+	PenaltyNights int `json:"penalty_nights"`
+}
+
+// covers reports whether the rate plan prices a night.
+func (p *RatePlan) covers(night time.Time) bool {
+	return !night.Before(p.ValidFrom) && night.Before(p.ValidTo)
+}
+
+// ExchangeRate is the number of units of QuoteCurrency one unit of
+// BaseCurrency buys from EffectiveDate on, until a later rate of the pair
+// takes effect.
+type ExchangeRate struct {
+	BaseCurrency  string        `gorm:"type:char(3);primaryKey" json:"base_currency"`
+	QuoteCurrency string        `gorm:"type:char(3);primaryKey" json:"quote_currency"`
+	EffectiveDate time.Time     `gorm:"primaryKey" json:"effective_date"`
+	Rate          money.Decimal `gorm:"type:numeric(18,6);not null" json:"rate"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+}
+
+// ExchangeRateFilter selects exchange rates by currency pair. Either currency
+// may be empty.
+type ExchangeRateFilter struct {
+	BaseCurrency  string
+	QuoteCurrency string
+}
+
+// QuoteRequest asks for the price of a number of rooms of a room type for the
+// nights from CheckIn up to CheckOut.
+type QuoteRequest struct {
+	RoomTypeID uuid.UUID
+	CheckIn    time.Time
+	CheckOut   time.Time
+	Rooms      int
+	// MealPlan defaults to room only and Currency to the currency of the rate
+	// plan of the first night.
+	MealPlan string
+	Currency string
+}
+
+// Quote is the price of a stay. Nightly prices are converted and rounded to
+// the currency of the quote before they are added up, so the total is the sum
+// of the nights times the rooms.
+type Quote struct {
+	HotelID    uuid.UUID    `json:"hotel_id"`
+	RoomTypeID uuid.UUID    `json:"room_type_id"`
+	CheckIn    string       `json:"check_in"`
+	CheckOut   string       `json:"check_out"`
+	Rooms      int          `json:"rooms"`
+	MealPlan   string       `json:"meal_plan"`
+	Currency   string       `json:"currency"`
+	Nights     []QuoteNight `json:"nights"`
+	Total      money.Amount `json:"total"`
+	// Cancellation is the policy of the rate plan of the first night.
+	// FreeCancellationUntil is the last day a refundable stay can be cancelled
+	// free of charge.
+	Cancellation          CancellationPolicy `json:"cancellation"`
+	FreeCancellationUntil string             `json:"free_cancellation_until,omitempty"`
+	QuotedAt              time.Time          `json:"quoted_at"`
+}
+
+// QuoteNight is the price of one room for a night, in the currency of its
+// rate plan and in the currency of the quote. ExchangeRate is rounded for
+// display; conversions use the stored rate.
+type QuoteNight struct {
+	Date         string        `json:"date"`
+	RatePlanID   uuid.UUID     `json:"rate_plan_id"`
+	Price        money.Amount  `json:"price"`
+	ExchangeRate money.Decimal `json:"exchange_rate"`
+	Converted    money.Amount  `json:"converted"`
+}
+
+// exchange converts amounts with a stored exchange rate, dividing by it when
+// the rate is quoted the other way round, so that no precision is lost to an
+// inverted rate.
+type exchange struct {
+	rate    money.Decimal
+	inverse bool
+}
+
+func (e exchange) convert(amount money.Decimal) money.Decimal {
+	if e.inverse {
+		return amount.Div(e.rate)
+	}
+	return amount.Mul(e.rate)
+}
+
+// effectiveRate returns the number of units of the target currency one unit of
+// the source currency buys.
+func (e exchange) effectiveRate() money.Decimal {
+	return e.convert(money.NewFromInt(1))
+}
+
+// converter returns the exchange from one currency into another.
+type converter func(from, to string) (exchange, error)
+
+// validateRatePlan normalizes a rate plan and returns a *ValidationError
+// listing every invalid field.
+func validateRatePlan(plan *RatePlan) error {
+	plan.Name = strings.TrimSpace(plan.Name)
+	plan.ValidFrom, plan.ValidTo = day(plan.ValidFrom), day(plan.ValidTo)
+	plan.MealPlan = strings.ToLower(strings.TrimSpace(plan.MealPlan))
+	if plan.MealPlan == "" {
+		plan.MealPlan = MealPlanRoomOnly
+	}
+
+	var fields []FieldError
+	if plan.Name == "" {
+		fields = append(fields, FieldError{"name", "is required"})
+	}
+	if plan.RoomTypeID == uuid.Nil {
+		fields = append(fields, FieldError{"room_type_id", "is required"})
+	}
+	if !plan.ValidFrom.Before(plan.ValidTo) {
+		fields = append(fields, FieldError{"valid_to", "must be after valid_from"})
+	}
+	if plan.BasePrice.Sign() <= 0 || plan.BasePrice.Cmp(maxPrice) > 0 {
+		fields = append(fields, FieldError{"base_price", "must be positive and at most 1000000000"})
+	}
+	if currency, err := money.NormalizeCurrency(plan.Currency); err != nil {
+		fields = append(fields, FieldError{"currency", "must be an ISO 4217 code such as EUR or TRY"})
+	} else {
+		plan.Currency = currency
+	}
+	if !mealPlans[plan.MealPlan] {
+		fields = append(fields, FieldError{"meal_plan", "must be one of all_inclusive, breakfast, full_board, half_board, room_only"})
+	}
+	if !plan.Cancellation.Refundable {
+		plan.Cancellation.FreeDays = 0
+	}
+	if plan.Cancellation.FreeDays < 0 {
+		fields = append(fields, FieldError{"cancellation.free_days", "must not be negative"})
+	}
+	if plan.Cancellation.PenaltyNights < 0 {
+		fields = append(fields, FieldError{"cancellation.penalty_nights", "must not be negative"})
+	}
+
+	if len(fields) > 0 {
+		return &ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+// validateExchangeRate normalizes an exchange rate.
+func validateExchangeRate(rate *ExchangeRate) error {
+	base, err := money.NormalizeCurrency(rate.BaseCurrency)
+	if err != nil {
+		return err
+	}
+	quote, err := money.NormalizeCurrency(rate.QuoteCurrency)
+	if err != nil {
+		return err
+	}
+	rate.BaseCurrency, rate.QuoteCurrency = base, quote
+	rate.EffectiveDate = day(rate.EffectiveDate)
+	if base == quote || rate.Rate.Sign() <= 0 || rate.EffectiveDate.IsZero() {
+		return ErrInvalidExchangeRate
+	}
+	return nil
+}
+
+// quoteStay prices the nights of a stay with the rate plans of the room type
+// and meal plan of the request, converting each night into the currency of
+// the quote.
+func quoteStay(plans []RatePlan, request QuoteRequest, convert converter) (*Quote, error) {
+	stay := nights(request.CheckIn, request.CheckOut)
+	quote := &Quote{
+		RoomTypeID: request.RoomTypeID,
+		CheckIn:    request.CheckIn.Format("2006-01-02"),
+		CheckOut:   request.CheckOut.Format("2006-01-02"),
+		Rooms:      request.Rooms,
+		MealPlan:   request.MealPlan,
+		Currency:   request.Currency,
+		Nights:     make([]QuoteNight, 0, stay),
+	}
+
+	perRoom := money.Zero
+	for i := 0; i < stay; i++ {
+		night := request.CheckIn.AddDate(0, 0, i)
+		var plan *RatePlan
+		for j := range plans {
+			if plans[j].MealPlan == request.MealPlan && plans[j].covers(night) {
+				plan = &plans[j]
+				break
+			}
+		}
+		if plan == nil {
+			return nil, fmt.Errorf("%w: %s is not priced", ErrNoRatePlan, night.Format("2006-01-02"))
+		}
+		if i == 0 {
+			if quote.Currency == "" {
+				quote.Currency = plan.Currency
+			}
+			quote.Cancellation = plan.Cancellation
+			if plan.Cancellation.Refundable {
+				quote.FreeCancellationUntil = night.AddDate(0, 0, -plan.Cancellation.FreeDays).Format("2006-01-02")
+			}
+		}
+
+		exchange, err := convert(plan.Currency, quote.Currency)
+		if err != nil {
+			return nil, err
+		}
+		converted := money.Amount{Value: exchange.convert(plan.BasePrice), Currency: quote.Currency}.Rounded()
+		quote.Nights = append(quote.Nights, QuoteNight{
+			Date:         night.Format("2006-01-02"),
+			RatePlanID:   plan.ID,
+			Price:        money.Amount{Value: plan.BasePrice, Currency: plan.Currency},
+			ExchangeRate: exchange.effectiveRate(),
+			Converted:    converted,
+		})
+		perRoom = perRoom.Add(converted.Value)
+	}
+	quote.Total = money.Amount{Value: perRoom.MulInt(int64(request.Rooms)), Currency: quote.Currency}
+	return quote, nil
+}
@@ -0,0 +1,112 @@
+package hotel
+
+import (
+	"hotel-guide/internal/money"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateRatePlan(t *testing.T) {
+	plan := RatePlan{
+		Name:       " Summer ",
+		RoomTypeID: uuid.New(),
+		ValidFrom:  time.Date(2026, 6, 1, 15, 0, 0, 0, time.UTC),
+		ValidTo:    time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+		BasePrice:  money.MustParse("120.50"),
+		Currency:   "eur",
+		Cancellation: CancellationPolicy{
+			FreeDays: 7,
+		},
+	}
+	assert.NoError(t, validateRatePlan(&plan))
+	assert.Equal(t, "Summer", plan.Name)
+	assert.Equal(t, "EUR", plan.Currency)
+	assert.Equal(t, MealPlanRoomOnly, plan.MealPlan)
+	assert.Equal(t, time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC), plan.ValidFrom)
+	// Free cancellation days only apply to refundable plans
+	assert.Equal(t, 0, plan.Cancellation.FreeDays)
+
+	// Every invalid field is reported
+	err := validateRatePlan(&RatePlan{
+		ValidFrom:    plan.ValidTo,
+		ValidTo:      plan.ValidFrom,
+		BasePrice:    money.MustParse("-1"),
+		Currency:     "euro",
+		MealPlan:     "dinner",
+		Cancellation: CancellationPolicy{Refundable: true, FreeDays: -1, PenaltyNights: -2},
+	})
+	var validationErr *ValidationError
+	if assert.ErrorAs(t, err, &validationErr) {
+		fields := make([]string, len(validationErr.Fields))
+		for i, field := range validationErr.Fields {
+			fields[i] = field.Field
+		}
+		assert.Equal(t, []string{
+			"name", "room_type_id", "valid_to", "base_price", "currency", "meal_plan",
+			"cancellation.free_days", "cancellation.penalty_nights",
+		}, fields)
+	}
+}
+
+func TestValidateExchangeRate(t *testing.T) {
+	rate := ExchangeRate{BaseCurrency: "eur", QuoteCurrency: "try", EffectiveDate: time.Date(2026, 10, 1, 9, 30, 0, 0, time.UTC), Rate: money.MustParse("35.2")}
+	assert.NoError(t, validateExchangeRate(&rate))
+	assert.Equal(t, "EUR", rate.BaseCurrency)
+	assert.Equal(t, "TRY", rate.QuoteCurrency)
+	assert.Equal(t, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), rate.EffectiveDate)
+
+	assert.ErrorIs(t, validateExchangeRate(&ExchangeRate{BaseCurrency: "EUR", QuoteCurrency: "T", Rate: money.NewFromInt(1)}), money.ErrInvalidCurrency)
+	assert.ErrorIs(t, validateExchangeRate(&ExchangeRate{BaseCurrency: "EUR", QuoteCurrency: "EUR", EffectiveDate: rate.EffectiveDate, Rate: money.NewFromInt(1)}), ErrInvalidExchangeRate)
+	assert.ErrorIs(t, validateExchangeRate(&ExchangeRate{BaseCurrency: "EUR", QuoteCurrency: "USD", EffectiveDate: rate.EffectiveDate}), ErrInvalidExchangeRate)
+	assert.ErrorIs(t, validateExchangeRate(&ExchangeRate{BaseCurrency: "EUR", QuoteCurrency: "USD", Rate: money.NewFromInt(1)}), ErrInvalidExchangeRate)
+}
+
+func TestQuoteStayNights(t *testing.T) {
+	date := func(d int) time.Time { return time.Date(2026, 6, d, 0, 0, 0, 0, time.UTC) }
+	roomTypeID := uuid.New()
+	early := RatePlan{ID: uuid.New(), RoomTypeID: roomTypeID, ValidFrom: date(1), ValidTo: date(15), BasePrice: money.MustParse("100"), Currency: "EUR", MealPlan: MealPlanRoomOnly,
+		Cancellation: CancellationPolicy{Refundable: true, FreeDays: 3, PenaltyNights: 1}}
+	late := RatePlan{ID: uuid.New(), RoomTypeID: roomTypeID, ValidFrom: date(15), ValidTo: date(30), BasePrice: money.MustParse("4000"), Currency: "TRY", MealPlan: MealPlanRoomOnly}
+	breakfast := RatePlan{ID: uuid.New(), RoomTypeID: roomTypeID, ValidFrom: date(1), ValidTo: date(30), BasePrice: money.MustParse("1"), Currency: "EUR", MealPlan: MealPlanBreakfast}
+	plans := []RatePlan{breakfast, late, early}
+
+	// TRY is converted with the inverse of the EUR/TRY rate
+	convert := func(from, to string) (exchange, error) {
+		if from == to {
+			return exchange{rate: money.NewFromInt(1)}, nil
+		}
+		if from == "TRY" && to == "EUR" {
+			return exchange{rate: money.MustParse("30"), inverse: true}, nil
+		}
+		return exchange{}, ErrNoExchangeRate
+	}
+
+	request := QuoteRequest{RoomTypeID: roomTypeID, CheckIn: date(14), CheckOut: date(16), Rooms: 2, MealPlan: MealPlanRoomOnly}
+	quote, err := quoteStay(plans, request, convert)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "EUR", quote.Currency)
+		assert.Len(t, quote.Nights, 2)
+		assert.Equal(t, early.ID, quote.Nights[0].RatePlanID)
+		assert.Equal(t, late.ID, quote.Nights[1].RatePlanID)
+		assert.Equal(t, "133.33 EUR", quote.Nights[1].Converted.String())
+		assert.Equal(t, "0.033333", quote.Nights[1].ExchangeRate.String())
+		// (100 + 133.33) * 2 rooms
+		assert.Equal(t, "466.66 EUR", quote.Total.String())
+		assert.Equal(t, early.Cancellation, quote.Cancellation)
+		assert.Equal(t, "2026-06-11", quote.FreeCancellationUntil)
+	}
+
+	// Without a rate from EUR into TRY the stay cannot be quoted in TRY
+	request.Currency = "TRY"
+	_, err = quoteStay(plans, request, convert)
+	assert.ErrorIs(t, err, ErrNoExchangeRate)
+
+	// Nights without a plan of the meal plan cannot be priced
+	request = QuoteRequest{RoomTypeID: roomTypeID, CheckIn: date(29), CheckOut: time.Date(2026, 7, 2, 0, 0, 0, 0, time.UTC), Rooms: 1, MealPlan: MealPlanRoomOnly}
+	_, err = quoteStay(plans, request, convert)
+	assert.ErrorIs(t, err, ErrNoRatePlan)
+	assert.Contains(t, err.Error(), "2026-06-30")
+}
@@ -1,21 +1,110 @@
 package hotel
 
 import (
+	"errors"
 	"fmt"
+	"hotel-guide/internal/money"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type HotelRepository interface {
+	Transaction(fn func(repo HotelRepository) error) error
 	Save(hotel *Hotel) error
-	Delete(uuid uuid.UUID) error
-	AddContactInfo(hotelUUID uuid.UUID, contact *ContactInfo) error
-	RemoveContactInfo(hotelUUID, contactUUID uuid.UUID) error
-	ListHotels() ([]Hotel, error)
-	GetHotelOfficials() ([]HotelOfficial, error)
+	Delete(uuid uuid.UUID, version int) error
+	Restore(id uuid.UUID) error
+	LockDeletedHotels(before time.Time) ([]uuid.UUID, error)
+	PurgeHotels(ids []uuid.UUID) (int64, error)
+	RecordAudit(entry *AuditEntry) error
+	ImportHotels(hotels []*Hotel) error
+	CreateImportJob(job *ImportJob) error
+	UpdateImportJob(job *ImportJob) error
+	FailImportJobs(reason string, finishedAt time.Time) (int64, error)
+	GetImportJob(id uuid.UUID) (*ImportJob, error)
+	ListAuditEntries(filter AuditFilter) ([]AuditEntry, error)
+	UpdateHotel(hotel *Hotel) error
+	AddContactInfo(hotelUUID uuid.UUID, contact *ContactInfo, version int) error
+	RemoveContactInfo(hotelUUID, contactUUID uuid.UUID, version int) error
+	GetContactInfo(hotelUUID, contactUUID uuid.UUID) (*ContactInfo, error)
+	UpdateContactInfo(contact *ContactInfo, version int) error
+	SetLocation(location *Location, version int) error
+	DeleteLocation(hotelUUID uuid.UUID, version int) error
+	ListHotels(opts ListOptions) ([]Hotel, int64, error)
+	ExportHotels(opts ListOptions, fn func(hotel *Hotel) error) error
+	CountContactColumns(opts ListOptions) (map[string]int, error)
+	GetHotelOfficials(filter OfficialFilter) ([]HotelOfficial, error)
+	GetOfficial(hotelID, officialID uuid.UUID) (*HotelOfficial, error)
+	AddOfficial(official *HotelOfficial, version int) error
+	UpdateOfficial(official *HotelOfficial, version int) error
+	RemoveOfficial(hotelID, officialID uuid.UUID, version int) error
+	ListRoomTypes(hotelID uuid.UUID) ([]RoomType, error)
+	GetRoomType(hotelID, roomTypeID uuid.UUID) (*RoomType, error)
+	AddRoomType(roomType *RoomType, version int) error
+	UpdateRoomType(roomType *RoomType, version int, now time.Time) error
+	RemoveRoomType(hotelID, roomTypeID uuid.UUID, version int, now time.Time) error
+	FetchRoomCapacity(filter LocationFilter) (rooms, beds int, err error)
+	ListActiveReservations(roomTypeIDs []uuid.UUID, from, to, now time.Time) ([]Reservation, error)
+	GetReservation(hotelID, reservationID uuid.UUID) (*Reservation, error)
+	CreateReservation(reservation *Reservation, now time.Time) error
+	ConfirmReservation(hotelID, reservationID uuid.UUID, now time.Time) error
+	CancelReservation(hotelID, reservationID uuid.UUID) error
+	ListRatePlans(hotelID, roomTypeID uuid.UUID) ([]RatePlan, error)
+	GetRatePlan(hotelID, ratePlanID uuid.UUID) (*RatePlan, error)
+	AddRatePlan(plan *RatePlan, version int) error
+	UpdateRatePlan(plan *RatePlan, version int) error
+	RemoveRatePlan(hotelID, ratePlanID uuid.UUID, version int) error
+	ListRatePlansForStay(hotelID, roomTypeID uuid.UUID, mealPlan string, from, to time.Time) ([]RatePlan, error)
+	FetchNightlyPrices(filter LocationFilter, on time.Time) ([]HotelPrice, error)
+	ListExchangeRates(filter ExchangeRateFilter) ([]ExchangeRate, error)
+	SaveExchangeRate(rate *ExchangeRate) error
+	RemoveExchangeRate(base, quote string, effectiveDate time.Time) error
+	FindExchangeRate(from, to string, on time.Time) (*ExchangeRate, error)
+	ListReviews(filter ReviewFilter) ([]Review, error)
+	GetReview(hotelID, reviewID uuid.UUID) (*Review, error)
+	AddReview(review *Review) error
+	SetReviewStatus(hotelID, reviewID uuid.UUID, status string) error
+	RemoveReview(hotelID, reviewID uuid.UUID) error
+	FetchAllHotels() ([]Hotel, error)
+	ListContactTypes() ([]ContactType, error)
+	GetContactType(name string) (*ContactType, error)
+	CreateContactType(contactType *ContactType) error
+	UpdateContactType(contactType *ContactType) error
+	DeleteContactType(name string) error
+	ListTags() ([]Tag, error)
+	GetTag(name string) (*Tag, error)
+	CreateTag(tag *Tag) error
+	UpdateTag(tag *Tag) error
+	DeleteTag(name string) error
+	SetHotelTags(hotelID uuid.UUID, tags []string, version int) error
+	ListChains(kind string) ([]Chain, error)
+	GetChain(id uuid.UUID) (*Chain, error)
+	CreateChain(chain *Chain) error
+	UpdateChain(chain *Chain) error
+	DeleteChain(id uuid.UUID) error
+	SetHotelChain(hotelID uuid.UUID, chainID *uuid.UUID, version int) error
+	CountHotels(filter LocationFilter) (int64, error)
+	CountHotelsByCity(filter LocationFilter) ([]LocationCount, error)
+	CountHotelsByContactType(filter LocationFilter) (map[string]int64, error)
+	ListMedia(hotelID uuid.UUID) ([]Media, error)
+	GetMedia(hotelID, mediaID uuid.UUID) (*Media, error)
+	AddMedia(media *Media, version int) error
+	ReorderMedia(hotelID uuid.UUID, mediaIDs []uuid.UUID, version int) error
+	RemoveMedia(hotelID, mediaID uuid.UUID, version int) error
+	ListPurgeableMedia(hotelIDs []uuid.UUID) ([]Media, error)
+	ListTranslations(hotelIDs []uuid.UUID) ([]HotelTranslation, error)
+	GetTranslation(hotelID uuid.UUID, language string) (*HotelTranslation, error)
+	SaveTranslation(translation *HotelTranslation, version int) error
+	RemoveTranslation(hotelID uuid.UUID, language string, version int) error
+	ListMissingTranslations(filter MissingTranslationFilter) ([]Hotel, int64, error)
+	CountFacets(opts ListOptions) (*Facets, error)
 	GetHotelDetails(hotelID uuid.UUID) (*Hotel, error)
-	FetchHotelsByLocation(location string) ([]Hotel, error)
+	FetchLocationAggregates(filter LocationFilter, phoneTypes []string) (*LocationAggregates, error)
+	ListLocationStats(opts LocationStatsOptions, phoneTypes []string) ([]LocationSummary, int64, error)
+	FetchHotelsInBoundingBox(box BoundingBox) ([]Hotel, error)
 }
 
 type hotelRepository struct {
@@ -26,67 +115,2036 @@ func NewRepository(db *gorm.DB) HotelRepository {
 	return &hotelRepository{db: db}
 }
 
+// Transaction runs fn with a repository whose writes are committed together
+// when fn succeeds and rolled back when it fails. Transactions started by the
+// methods of that repository become savepoints.
+func (r *hotelRepository) Transaction(fn func(repo HotelRepository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&hotelRepository{db: tx})
+	})
+}
+
 func (r *hotelRepository) Save(hotel *Hotel) error {
 	return r.db.Create(hotel).Error
 }
 
-func (r *hotelRepository) Delete(uuid uuid.UUID) error {
-	return r.db.Where("id = ?", uuid).Delete(&Hotel{}).Error
+// importInsertBatch is the number of hotels sent in one INSERT, which keeps
+// large imports below PostgreSQL's limit of 65535 bind parameters.
+const importInsertBatch = 500
+
+// ImportHotels stores the hotels and their contacts in one transaction.
+func (r *hotelRepository) ImportHotels(hotels []*Hotel) error {
+	if len(hotels) == 0 {
+		return nil
+	}
+	if err := r.db.CreateInBatches(hotels, importInsertBatch).Error; err != nil {
+		return fmt.Errorf("error importing hotels: %w", err)
+	}
+	return nil
+}
+
+func (r *hotelRepository) CreateImportJob(job *ImportJob) error {
+	if err := r.db.Create(job).Error; err != nil {
+		return fmt.Errorf("error creating import job: %w", err)
+	}
+	return nil
+}
+
+// UpdateImportJob stores the progress of an import job.
+func (r *hotelRepository) UpdateImportJob(job *ImportJob) error {
+	if err := r.db.Save(job).Error; err != nil {
+		return fmt.Errorf("error updating import job %v: %w", job.ID, err)
+	}
+	return nil
+}
+
+// FailImportJobs marks every import job still in progress as failed.
+func (r *hotelRepository) FailImportJobs(reason string, finishedAt time.Time) (int64, error) {
+	result := r.db.Model(&ImportJob{}).Where("status = ?", ImportInProgress).
+		Updates(map[string]interface{}{"status": ImportFailed, "error": reason, "finished_at": finishedAt})
+	if result.Error != nil {
+		return 0, fmt.Errorf("error failing import jobs: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+func (r *hotelRepository) GetImportJob(id uuid.UUID) (*ImportJob, error) {
+	var job ImportJob
+	err := r.db.First(&job, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrImportJobNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error fetching import job %v: %w", id, err)
+	}
+	return &job, nil
+}
+
+// Delete soft-deletes the hotel. It stays in the database with its contacts and
+// location until PurgeDeletedHotels removes it, and can be restored until then.
+func (r *hotelRepository) Delete(id uuid.UUID, version int) error {
+	query := r.db.Model(&Hotel{}).Where("id = ?", id)
+	if version != 0 {
+		query = query.Where("version = ?", version)
+	}
+
+	result := query.Updates(map[string]interface{}{
+		"deleted_at": time.Now(),
+		"version":    gorm.Expr("version + 1"),
+	})
+	if result.Error != nil {
+		return fmt.Errorf("error deleting hotel %v: %w", id, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		if version != 0 {
+			return versionMismatch(r.db, id)
+		}
+		return ErrHotelNotFound
+	}
+	return nil
+}
+
+// Restore undoes the soft delete of a hotel.
+func (r *hotelRepository) Restore(id uuid.UUID) error {
+	result := r.db.Unscoped().Model(&Hotel{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Updates(map[string]interface{}{
+			"deleted_at": nil,
+			"version":    gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		return fmt.Errorf("error restoring hotel %v: %w", id, result.Error)
+	}
+	if result.RowsAffected > 0 {
+		return nil
+	}
+
+	var count int64
+	if err := r.db.Model(&Hotel{}).Where("id = ?", id).Count(&count).Error; err != nil {
+		return fmt.Errorf("error checking hotel %v: %w", id, err)
+	}
+	if count > 0 {
+		return ErrHotelNotDeleted
+	}
+	return ErrHotelNotFound
+}
+
+// LockDeletedHotels returns the IDs of the hotels soft-deleted before the given
+// time and locks their rows for the transaction, so that they cannot be
+// restored before PurgeHotels removes them.
+func (r *hotelRepository) LockDeletedHotels(before time.Time) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.Unscoped().Model(&Hotel{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("deleted_at < ?", before).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return nil, fmt.Errorf("error locking deleted hotels: %w", err)
+	}
+	return ids, nil
+}
+
+// PurgeHotels permanently removes the soft-deleted hotels with the given IDs.
+// Their contacts and locations are removed by the cascading foreign keys.
+func (r *hotelRepository) PurgeHotels(ids []uuid.UUID) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	result := r.db.Unscoped().Where("id IN ? AND deleted_at IS NOT NULL", ids).Delete(&Hotel{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("error purging deleted hotels: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// RecordAudit appends an entry to the audit log.
+func (r *hotelRepository) RecordAudit(entry *AuditEntry) error {
+	if entry.ID == uuid.Nil {
+		entry.ID = uuid.New()
+	}
+	if err := r.db.Create(entry).Error; err != nil {
+		return fmt.Errorf("error recording audit entry: %w", err)
+	}
+	return nil
+}
+
+// ListAuditEntries returns up to filter.Limit+1 matching audit entries, newest
+// first, so the caller can tell whether another page follows.
+func (r *hotelRepository) ListAuditEntries(filter AuditFilter) ([]AuditEntry, error) {
+	query := r.db.Model(&AuditEntry{})
+	if filter.HotelID != uuid.Nil {
+		query = query.Where("hotel_id = ?", filter.HotelID)
+	}
+	if filter.Entity != "" {
+		query = query.Where("entity = ?", filter.Entity)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.Actor != "" {
+		query = query.Where("actor = ?", filter.Actor)
+	}
+	if !filter.Since.IsZero() {
+		query = query.Where("created_at >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		query = query.Where("created_at < ?", filter.Until)
+	}
+	if filter.after != nil {
+		query = query.Where("created_at < ? OR (created_at = ? AND id < ?)", filter.after.Value, filter.after.Value, filter.after.ID)
+	}
+
+	var entries []AuditEntry
+	if err := query.Order("created_at DESC, id DESC").Limit(filter.Limit + 1).Find(&entries).Error; err != nil {
+		return nil, fmt.Errorf("error listing audit entries: %w", err)
+	}
+	return entries, nil
+}
+
+// UpdateHotel stores the editable fields of a hotel if its stored version still
+// equals hotel.Version, and advances the version on success.
+func (r *hotelRepository) UpdateHotel(hotel *Hotel) error {
+	result := r.db.Model(&Hotel{}).
+		Where("id = ? AND version = ?", hotel.ID, hotel.Version).
+		Updates(map[string]interface{}{
+			"owner_name":    hotel.OwnerName,
+			"owner_surname": hotel.OwnerSurname,
+			"company_title": hotel.CompanyTitle,
+			"version":       gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		return fmt.Errorf("error updating hotel %v: %w", hotel.ID, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return versionMismatch(r.db, hotel.ID)
+	}
+
+	hotel.Version++
+	return nil
 }
 
-func (r *hotelRepository) AddContactInfo(hotelUUID uuid.UUID, contact *ContactInfo) error {
+func (r *hotelRepository) AddContactInfo(hotelUUID uuid.UUID, contact *ContactInfo, version int) error {
 	if contact.ID == uuid.Nil {
 		contact.ID = uuid.New()
 	}
 
 	contact.HotelID = hotelUUID
-	return r.db.Create(contact).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := bumpVersion(tx, hotelUUID, version); err != nil {
+			return err
+		}
+		return tx.Create(contact).Error
+	})
 }
 
-func (r *hotelRepository) RemoveContactInfo(hotelUUID, contactUUID uuid.UUID) error {
+func (r *hotelRepository) RemoveContactInfo(hotelUUID, contactUUID uuid.UUID, version int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var contact ContactInfo
+		err := tx.Where("id = ? AND hotel_id = ?", contactUUID, hotelUUID).First(&contact).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = ErrContactNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to find contact with ID %v for hotel with ID %v: %w", contactUUID, hotelUUID, err)
+		}
+
+		if err := bumpVersion(tx, hotelUUID, version); err != nil {
+			return err
+		}
+		return tx.Delete(&contact).Error
+	})
+}
+
+func (r *hotelRepository) GetContactInfo(hotelUUID, contactUUID uuid.UUID) (*ContactInfo, error) {
 	var contact ContactInfo
-	if err := r.db.Where("id = ? AND hotel_id = ?", contactUUID, hotelUUID).First(&contact).Error; err != nil {
-		return fmt.Errorf("failed to find contact with ID %v for hotel with ID %v: %v", contactUUID, hotelUUID, err)
+	err := r.db.Where("id = ? AND hotel_id = ?", contactUUID, hotelUUID).First(&contact).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrContactNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error fetching contact info: %w", err)
 	}
+	return &contact, nil
+}
 
-	return r.db.Delete(&contact).Error
+func (r *hotelRepository) UpdateContactInfo(contact *ContactInfo, version int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := bumpVersion(tx, contact.HotelID, version); err != nil {
+			return err
+		}
+
+		result := tx.Model(&ContactInfo{}).
+			Where("id = ? AND hotel_id = ?", contact.ID, contact.HotelID).
+			Updates(map[string]interface{}{
+				"info_type":    contact.InfoType,
+				"info_content": contact.InfoContent,
+			})
+		if result.Error != nil {
+			return fmt.Errorf("error updating contact info %v: %w", contact.ID, result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrContactNotFound
+		}
+		return nil
+	})
 }
 
-func (r *hotelRepository) ListHotels() ([]Hotel, error) {
+// ListHotels returns one page of hotels matching the options together with the
+// total number of matching hotels. One extra hotel is fetched beyond the limit
+// so the caller can tell whether another page follows.
+func (r *hotelRepository) ListHotels(opts ListOptions) ([]Hotel, int64, error) {
+	var total int64
+	if err := r.filterHotels(opts).Model(&Hotel{}).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("error counting hotels: %w", err)
+	}
+
+	column := sortableColumns[opts.SortBy]
+	direction, comparison := "ASC", ">"
+	if opts.SortDesc {
+		direction, comparison = "DESC", "<"
+	}
+
+	// Ties on the sort column are broken by id so that keyset pagination is stable.
+	order := fmt.Sprintf("%s %s", column, direction)
+	query := r.filterHotels(opts)
+	if column != "hotels.id" {
+		order += ", hotels.id " + direction
+		if opts.after != nil {
+			query = query.Where(fmt.Sprintf("%s %s ? OR (%s = ? AND hotels.id %s ?)", column, comparison, column, comparison),
+				opts.after.Value, opts.after.Value, opts.after.ID)
+		}
+	} else if opts.after != nil {
+		query = query.Where("hotels.id "+comparison+" ?", opts.after.ID)
+	}
+	if opts.after == nil && opts.Offset > 0 {
+		query = query.Offset(opts.Offset)
+	}
+
 	var hotels []Hotel
-	err := r.db.Preload("ContactInfos").Find(&hotels).Error
-	return hotels, err
+	err := query.
+		Order(order).
+		Limit(opts.Limit + 1).
+		Preload("ContactInfos").
+		Preload("Location").
+		Preload("Tags").
+		Preload("Translations").
+		Find(&hotels).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("error listing hotels: %w", err)
+	}
+	return hotels, total, nil
+}
+
+// ExportHotels calls fn with every hotel matching the filters of the options,
+// in the order of the options, together with its location and contacts. The
+// hotels are read from a single query joining the three tables, so only one
+// hotel is held in memory at a time. Limit, offset and cursor are ignored.
+func (r *hotelRepository) ExportHotels(opts ListOptions, fn func(hotel *Hotel) error) error {
+	direction := "ASC"
+	if opts.SortDesc {
+		direction = "DESC"
+	}
+	order := fmt.Sprintf("%s %s", sortableColumns[opts.SortBy], direction)
+	if opts.SortBy != "id" {
+		order += ", hotels.id " + direction
+	}
+
+	// The location filter already joins the locations of the hotels.
+	query := r.filterHotels(opts)
+	if !opts.Location.hasLocation() {
+		query = query.Joins("LEFT JOIN locations ON locations.hotel_id = hotels.id")
+	}
+	rows, err := query.
+		Joins("LEFT JOIN contact_infos ON contact_infos.hotel_id = hotels.id").
+		Select("hotels.id, hotels.owner_name, hotels.owner_surname, hotels.company_title, hotels.version, hotels.review_count, hotels.average_score, hotels.deleted_at, " +
+			"locations.id, locations.country, locations.city, locations.district, locations.postal_code, locations.street, locations.latitude, locations.longitude, " +
+			"contact_infos.id, contact_infos.info_type, contact_infos.info_content").
+		Order(order + ", contact_infos.id").
+		Rows()
+	if err != nil {
+		return fmt.Errorf("error exporting hotels: %w", err)
+	}
+	defer rows.Close()
+
+	var current *Hotel
+	for rows.Next() {
+		var (
+			hotel                                       Hotel
+			locationID, contactID                       *uuid.UUID
+			country, city, district, postalCode, street *string
+			latitude, longitude                         *float64
+			infoType, infoContent                       *string
+		)
+		err := rows.Scan(&hotel.ID, &hotel.OwnerName, &hotel.OwnerSurname, &hotel.CompanyTitle, &hotel.Version, &hotel.ReviewCount, &hotel.AverageScore, &hotel.DeletedAt,
+			&locationID, &country, &city, &district, &postalCode, &street, &latitude, &longitude,
+			&contactID, &infoType, &infoContent)
+		if err != nil {
+			return fmt.Errorf("error reading exported hotel: %w", err)
+		}
+
+		// The rows of a hotel are adjacent since the order ends with its id.
+		if current == nil || current.ID != hotel.ID {
+			if current != nil {
+				if err := fn(current); err != nil {
+					return err
+				}
+			}
+			current = &hotel
+			if locationID != nil {
+				current.Location = &Location{
+					ID:         *locationID,
+					HotelID:    hotel.ID,
+					Country:    stringValue(country),
+					City:       stringValue(city),
+					District:   stringValue(district),
+					PostalCode: stringValue(postalCode),
+					Street:     stringValue(street),
+					Latitude:   latitude,
+					Longitude:  longitude,
+				}
+			}
+		}
+		if contactID != nil {
+			current.ContactInfos = append(current.ContactInfos, ContactInfo{
+				ID:          *contactID,
+				HotelID:     current.ID,
+				InfoType:    stringValue(infoType),
+				InfoContent: stringValue(infoContent),
+			})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error exporting hotels: %w", err)
+	}
+	if current != nil {
+		return fn(current)
+	}
+	return nil
+}
+
+// CountContactColumns returns, for every contact type, the largest number of
+// contacts of that type any hotel matching the filters of the options has.
+func (r *hotelRepository) CountContactColumns(opts ListOptions) (map[string]int, error) {
+	perHotel := r.db.Model(&ContactInfo{}).
+		Select("info_type, COUNT(*) AS contacts").
+		Where("hotel_id IN (?)", r.filterHotels(opts).Select("hotels.id")).
+		Group("hotel_id, info_type")
+
+	var counts []struct {
+		InfoType string
+		Contacts int
+	}
+	err := r.db.Table("(?) AS per_hotel", perHotel).
+		Select("info_type, MAX(contacts) AS contacts").
+		Group("info_type").
+		Scan(&counts).Error
+	if err != nil {
+		return nil, fmt.Errorf("error counting contact columns: %w", err)
+	}
+
+	columns := make(map[string]int, len(counts))
+	for _, count := range counts {
+		columns[count.InfoType] = count.Contacts
+	}
+	return columns, nil
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+// filterHotels starts a hotels query restricted by the filters of the options.
+func (r *hotelRepository) filterHotels(opts ListOptions) *gorm.DB {
+	query := r.db.Model(&Hotel{})
+	if opts.IncludeDeleted {
+		query = r.db.Unscoped().Model(&Hotel{})
+	}
+	if !opts.Location.IsEmpty() {
+		query = applyLocationFilter(query, opts.Location)
+	}
+	if opts.CompanyTitle != "" {
+		query = query.Where(`LOWER(hotels.company_title) LIKE ? ESCAPE '\'`, "%"+escapeLike(strings.ToLower(opts.CompanyTitle))+"%")
+	}
+	if opts.OwnerName != "" {
+		query = query.Where("LOWER(hotels.owner_name) = LOWER(?)", opts.OwnerName)
+	}
+	if opts.ContactType != "" {
+		query = query.Where("EXISTS (SELECT 1 FROM contact_infos WHERE contact_infos.hotel_id = hotels.id AND contact_infos.info_type = ?)", opts.ContactType)
+	}
+	if opts.MinRating > 0 {
+		query = query.Where("hotels.average_score >= ?", opts.MinRating)
+	}
+	if len(opts.Tags) > 0 {
+		query = query.Where("hotels.id IN (SELECT hotel_id FROM hotel_tags WHERE tag_name IN ? GROUP BY hotel_id HAVING COUNT(*) = ?)",
+			opts.Tags, len(opts.Tags))
+	}
+	return query
 }
 
-func (r *hotelRepository) GetHotelOfficials() ([]HotelOfficial, error) {
+// GetHotelOfficials returns the officials of the hotels that are not deleted,
+// with their contacts and the title of their hotel.
+func (r *hotelRepository) GetHotelOfficials(filter OfficialFilter) ([]HotelOfficial, error) {
+	query := r.db.Model(&HotelOfficial{}).
+		Select("hotel_officials.*, hotels.company_title").
+		Joins("JOIN hotels ON hotels.id = hotel_officials.hotel_id AND hotels.deleted_at IS NULL")
+	if filter.HotelID != uuid.Nil {
+		query = query.Where("hotel_officials.hotel_id = ?", filter.HotelID)
+	}
+	if filter.Role != "" {
+		query = query.Where("hotel_officials.role = ?", filter.Role)
+	}
+	if !filter.ActiveAt.IsZero() {
+		query = query.Where("(hotel_officials.start_date IS NULL OR hotel_officials.start_date <= ?) AND (hotel_officials.end_date IS NULL OR hotel_officials.end_date > ?)",
+			filter.ActiveAt, filter.ActiveAt)
+	}
+
 	var officials []HotelOfficial
-	err := r.db.Model(&Hotel{}).Select("owner_name, owner_surname, company_title").Find(&officials).Error
+	err := query.
+		Order("hotels.company_title, hotel_officials.role, hotel_officials.surname, hotel_officials.name").
+		Preload("ContactInfos").
+		Find(&officials).Error
 	if err != nil {
 		return nil, fmt.Errorf("error fetching hotel officials: %w", err)
 	}
 	return officials, nil
 }
 
+func (r *hotelRepository) GetOfficial(hotelID, officialID uuid.UUID) (*HotelOfficial, error) {
+	var official HotelOfficial
+	err := r.db.Preload("ContactInfos").Where("id = ? AND hotel_id = ?", officialID, hotelID).First(&official).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrOfficialNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error fetching official: %w", err)
+	}
+	return &official, nil
+}
+
+// AddOfficial stores a new official of a hotel together with its contacts.
+func (r *hotelRepository) AddOfficial(official *HotelOfficial, version int) error {
+	if official.ID == uuid.Nil {
+		official.ID = uuid.New()
+	}
+	assignOfficialContactIDs(official)
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := bumpVersion(tx, official.HotelID, version); err != nil {
+			return err
+		}
+		return tx.Create(official).Error
+	})
+}
+
+// UpdateOfficial replaces the fields and the contacts of an official.
+func (r *hotelRepository) UpdateOfficial(official *HotelOfficial, version int) error {
+	assignOfficialContactIDs(official)
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := bumpVersion(tx, official.HotelID, version); err != nil {
+			return err
+		}
+
+		result := tx.Model(&HotelOfficial{}).
+			Where("id = ? AND hotel_id = ?", official.ID, official.HotelID).
+			Updates(map[string]interface{}{
+				"role":       official.Role,
+				"name":       official.Name,
+				"surname":    official.Surname,
+				"start_date": official.StartDate,
+				"end_date":   official.EndDate,
+			})
+		if result.Error != nil {
+			return fmt.Errorf("error updating official %v: %w", official.ID, result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrOfficialNotFound
+		}
+
+		if err := tx.Where("official_id = ?", official.ID).Delete(&OfficialContact{}).Error; err != nil {
+			return fmt.Errorf("error removing contacts of official %v: %w", official.ID, err)
+		}
+		if len(official.ContactInfos) == 0 {
+			return nil
+		}
+		return tx.Create(&official.ContactInfos).Error
+	})
+}
+
+func (r *hotelRepository) RemoveOfficial(hotelID, officialID uuid.UUID, version int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := bumpVersion(tx, hotelID, version); err != nil {
+			return err
+		}
+
+		result := tx.Where("id = ? AND hotel_id = ?", officialID, hotelID).Delete(&HotelOfficial{})
+		if result.Error != nil {
+			return fmt.Errorf("error removing official %v: %w", officialID, result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrOfficialNotFound
+		}
+		return tx.Where("official_id = ?", officialID).Delete(&OfficialContact{}).Error
+	})
+}
+
+// assignOfficialContactIDs gives the new contacts of an official their IDs.
+func assignOfficialContactIDs(official *HotelOfficial) {
+	for i := range official.ContactInfos {
+		if official.ContactInfos[i].ID == uuid.Nil {
+			official.ContactInfos[i].ID = uuid.New()
+		}
+		official.ContactInfos[i].OfficialID = official.ID
+	}
+}
+
+// ListRoomTypes returns the room types of a hotel ordered by name.
+func (r *hotelRepository) ListRoomTypes(hotelID uuid.UUID) ([]RoomType, error) {
+	var roomTypes []RoomType
+	if err := r.db.Where("hotel_id = ?", hotelID).Order("name, id").Find(&roomTypes).Error; err != nil {
+		return nil, fmt.Errorf("error fetching room types of hotel %v: %w", hotelID, err)
+	}
+	return roomTypes, nil
+}
+
+func (r *hotelRepository) GetRoomType(hotelID, roomTypeID uuid.UUID) (*RoomType, error) {
+	var roomType RoomType
+	err := r.db.Where("id = ? AND hotel_id = ?", roomTypeID, hotelID).First(&roomType).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRoomTypeNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error fetching room type: %w", err)
+	}
+	return &roomType, nil
+}
+
+func (r *hotelRepository) AddRoomType(roomType *RoomType, version int) error {
+	if roomType.ID == uuid.Nil {
+		roomType.ID = uuid.New()
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := bumpVersion(tx, roomType.HotelID, version); err != nil {
+			return err
+		}
+		return tx.Create(roomType).Error
+	})
+}
+
+// UpdateRoomType replaces the fields of a room type. The room count may not
+// drop below the rooms that reservations keep at now on any night; the room
+// type row is locked as in CreateReservation, so that no reservation is made
+// in between.
+func (r *hotelRepository) UpdateRoomType(roomType *RoomType, version int, now time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := bumpVersion(tx, roomType.HotelID, version); err != nil {
+			return err
+		}
+		if err := lockRoomType(tx, roomType.HotelID, roomType.ID); err != nil {
+			return err
+		}
+
+		var reservations []Reservation
+		err := tx.Where("room_type_id = ? AND check_out > ?", roomType.ID, now).
+			Where("status = ? OR (status = ? AND expires_at > ?)", ReservationBooked, ReservationHold, now).
+			Find(&reservations).Error
+		if err != nil {
+			return fmt.Errorf("error fetching reservations: %w", err)
+		}
+		if peakRooms(reservations) > roomType.RoomCount {
+			return ErrRoomsReserved
+		}
+
+		result := tx.Model(&RoomType{}).
+			Where("id = ? AND hotel_id = ?", roomType.ID, roomType.HotelID).
+			Updates(map[string]interface{}{
+				"name":       roomType.Name,
+				"capacity":   roomType.Capacity,
+				"beds":       roomType.Beds,
+				"bed_count":  roomType.BedCount,
+				"room_count": roomType.RoomCount,
+				"amenities":  roomType.Amenities,
+			})
+		if result.Error != nil {
+			return fmt.Errorf("error updating room type %v: %w", roomType.ID, result.Error)
+		}
+		return nil
+	})
+}
+
+// RemoveRoomType removes a room type with its reservations, unless it has
+// bookings that have not ended at now.
+func (r *hotelRepository) RemoveRoomType(hotelID, roomTypeID uuid.UUID, version int, now time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := bumpVersion(tx, hotelID, version); err != nil {
+			return err
+		}
+		if err := lockRoomType(tx, hotelID, roomTypeID); err != nil {
+			return err
+		}
+
+		var bookings int64
+		err := tx.Model(&Reservation{}).
+			Where("room_type_id = ? AND status = ? AND check_out > ?", roomTypeID, ReservationBooked, now).
+			Count(&bookings).Error
+		if err != nil {
+			return fmt.Errorf("error counting bookings of room type %v: %w", roomTypeID, err)
+		}
+		if bookings > 0 {
+			return ErrRoomTypeInUse
+		}
+
+		err = tx.Where("id = ? AND hotel_id = ?", roomTypeID, hotelID).Delete(&RoomType{}).Error
+		if err != nil {
+			return fmt.Errorf("error removing room type %v: %w", roomTypeID, err)
+		}
+		return nil
+	})
+}
+
+// lockRoomType locks the row of a room type for the transaction.
+func lockRoomType(tx *gorm.DB, hotelID, roomTypeID uuid.UUID) error {
+	var roomType RoomType
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").
+		Where("id = ? AND hotel_id = ?", roomTypeID, hotelID).
+		First(&roomType).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrRoomTypeNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("error locking room type %v: %w", roomTypeID, err)
+	}
+	return nil
+}
+
+// FetchRoomCapacity sums the rooms and the beds in them over the hotels whose
+// location matches the filter.
+func (r *hotelRepository) FetchRoomCapacity(filter LocationFilter) (rooms, beds int, err error) {
+	var capacity struct {
+		Rooms int
+		Beds  int
+	}
+	err = applyLocationFilter(r.db.Model(&Hotel{}), filter).
+		Joins("JOIN room_types ON room_types.hotel_id = hotels.id").
+		Select("COALESCE(SUM(room_types.room_count), 0) AS rooms, COALESCE(SUM(room_types.room_count * room_types.bed_count), 0) AS beds").
+		Scan(&capacity).Error
+	if err != nil {
+		return 0, 0, fmt.Errorf("error summing room capacity for location %+v: %w", filter, err)
+	}
+	return capacity.Rooms, capacity.Beds, nil
+}
+
+// ListActiveReservations returns the reservations of the room types that keep
+// rooms at now on any night from from up to to.
+func (r *hotelRepository) ListActiveReservations(roomTypeIDs []uuid.UUID, from, to, now time.Time) ([]Reservation, error) {
+	reservations, err := activeReservations(r.db, roomTypeIDs, from, to, now)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching reservations: %w", err)
+	}
+	return reservations, nil
+}
+
+func activeReservations(tx *gorm.DB, roomTypeIDs []uuid.UUID, from, to, now time.Time) ([]Reservation, error) {
+	var reservations []Reservation
+	err := tx.Where("room_type_id IN ? AND check_in < ? AND check_out > ?", roomTypeIDs, to, from).
+		Where("status = ? OR (status = ? AND expires_at > ?)", ReservationBooked, ReservationHold, now).
+		Find(&reservations).Error
+	return reservations, err
+}
+
+func (r *hotelRepository) GetReservation(hotelID, reservationID uuid.UUID) (*Reservation, error) {
+	var reservation Reservation
+	err := r.db.Where("id = ? AND hotel_id = ?", reservationID, hotelID).First(&reservation).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrReservationNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error fetching reservation: %w", err)
+	}
+	return &reservation, nil
+}
+
+// CreateReservation stores a reservation when enough rooms are free on each of
+// its nights, and returns ErrNotAvailable otherwise. The room type row is locked
+// for the transaction so that concurrent reservations of the same room type are
+// checked one after the other. SQLite has no row locks; there the transaction
+// has to take the database lock up front, see the _txlock connection parameter.
+func (r *hotelRepository) CreateReservation(reservation *Reservation, now time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var roomType RoomType
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND hotel_id = ?", reservation.RoomTypeID, reservation.HotelID).
+			First(&roomType).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrRoomTypeNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("error locking room type %v: %w", reservation.RoomTypeID, err)
+		}
+
+		reservations, err := activeReservations(tx, []uuid.UUID{roomType.ID}, reservation.CheckIn, reservation.CheckOut, now)
+		if err != nil {
+			return fmt.Errorf("error fetching reservations: %w", err)
+		}
+		if minAvailable(availability(&roomType, reservations, reservation.CheckIn, reservation.CheckOut)) < reservation.Rooms {
+			return ErrNotAvailable
+		}
+		return tx.Create(reservation).Error
+	})
+}
+
+// ConfirmReservation turns a hold that has not expired at now into a booking.
+func (r *hotelRepository) ConfirmReservation(hotelID, reservationID uuid.UUID, now time.Time) error {
+	result := r.db.Model(&Reservation{}).
+		Where("id = ? AND hotel_id = ? AND status = ? AND expires_at > ?", reservationID, hotelID, ReservationHold, now).
+		Updates(map[string]interface{}{"status": ReservationBooked, "expires_at": nil})
+	if result.Error != nil {
+		return fmt.Errorf("error confirming reservation %v: %w", reservationID, result.Error)
+	}
+	if result.RowsAffected > 0 {
+		return nil
+	}
+
+	// Tell why the hold could not be confirmed
+	reservation, err := r.GetReservation(hotelID, reservationID)
+	if err != nil {
+		return err
+	}
+	if reservation.Status == ReservationHold {
+		return ErrHoldExpired
+	}
+	return ErrNotHold
+}
+
+// CancelReservation releases the rooms of a hold or booking. Cancelling a
+// cancelled reservation does nothing.
+func (r *hotelRepository) CancelReservation(hotelID, reservationID uuid.UUID) error {
+	result := r.db.Model(&Reservation{}).
+		Where("id = ? AND hotel_id = ? AND status <> ?", reservationID, hotelID, ReservationCancelled).
+		Updates(map[string]interface{}{"status": ReservationCancelled, "expires_at": nil})
+	if result.Error != nil {
+		return fmt.Errorf("error cancelling reservation %v: %w", reservationID, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		_, err := r.GetReservation(hotelID, reservationID)
+		return err
+	}
+	return nil
+}
+
+// ListReviews returns the reviews of a hotel with the status of the filter,
+// newest first.
+func (r *hotelRepository) ListReviews(filter ReviewFilter) ([]Review, error) {
+	var reviews []Review
+	err := r.db.Where("hotel_id = ? AND status = ?", filter.HotelID, filter.Status).
+		Order("date DESC, id DESC").
+		Limit(filter.Limit).
+		Find(&reviews).Error
+	if err != nil {
+		return nil, fmt.Errorf("error fetching reviews of hotel %v: %w", filter.HotelID, err)
+	}
+	return reviews, nil
+}
+
+func (r *hotelRepository) GetReview(hotelID, reviewID uuid.UUID) (*Review, error) {
+	var review Review
+	err := r.db.Where("id = ? AND hotel_id = ?", reviewID, hotelID).First(&review).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrReviewNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error fetching review: %w", err)
+	}
+	return &review, nil
+}
+
+// AddReview stores a review and adds it to the rating of its hotel when it is
+// approved.
+func (r *hotelRepository) AddReview(review *Review) error {
+	if review.ID == uuid.Nil {
+		review.ID = uuid.New()
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(review).Error; err != nil {
+			return err
+		}
+		if review.Status == ReviewApproved {
+			return rateHotel(tx, review.HotelID, review.Score, 1)
+		}
+		return nil
+	})
+}
+
+// SetReviewStatus moderates a review and moves its score into or out of the
+// rating of its hotel. The review row is locked so that concurrent moderation
+// counts each review once.
+func (r *hotelRepository) SetReviewStatus(hotelID, reviewID uuid.UUID, status string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		review, err := lockReview(tx, hotelID, reviewID)
+		if err != nil {
+			return err
+		}
+		if review.Status == status {
+			return nil
+		}
+
+		if err := tx.Model(review).Update("status", status).Error; err != nil {
+			return fmt.Errorf("error updating review %v: %w", reviewID, err)
+		}
+		switch {
+		case status == ReviewApproved:
+			return rateHotel(tx, hotelID, review.Score, 1)
+		case review.Status == ReviewApproved:
+			return rateHotel(tx, hotelID, -review.Score, -1)
+		}
+		return nil
+	})
+}
+
+// RemoveReview deletes a review and takes it out of the rating of its hotel.
+func (r *hotelRepository) RemoveReview(hotelID, reviewID uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		review, err := lockReview(tx, hotelID, reviewID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Delete(review).Error; err != nil {
+			return fmt.Errorf("error removing review %v: %w", reviewID, err)
+		}
+		if review.Status == ReviewApproved {
+			return rateHotel(tx, hotelID, -review.Score, -1)
+		}
+		return nil
+	})
+}
+
+func lockReview(tx *gorm.DB, hotelID, reviewID uuid.UUID) (*Review, error) {
+	var review Review
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND hotel_id = ?", reviewID, hotelID).
+		First(&review).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrReviewNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error locking review %v: %w", reviewID, err)
+	}
+	return &review, nil
+}
+
+// rateHotel adds score to the score total of a hotel and count to its review
+// count, and recomputes its average score from both. Soft-deleted hotels are
+// rated too so that their rating is right when they are restored.
+func rateHotel(tx *gorm.DB, hotelID uuid.UUID, score, count int) error {
+	err := tx.Unscoped().Model(&Hotel{}).
+		Where("id = ?", hotelID).
+		UpdateColumns(map[string]interface{}{
+			"score_total":  gorm.Expr("score_total + ?", score),
+			"review_count": gorm.Expr("review_count + ?", count),
+			"average_score": gorm.Expr("CASE WHEN review_count + ? > 0 THEN (score_total + ?) * 1.0 / (review_count + ?) ELSE 0 END",
+				count, score, count),
+		}).Error
+	if err != nil {
+		return fmt.Errorf("error updating rating of hotel %v: %w", hotelID, err)
+	}
+	return nil
+}
+
+// FetchAllHotels returns every hotel with its contacts, e.g. to build the search index.
+func (r *hotelRepository) FetchAllHotels() ([]Hotel, error) {
+	var hotels []Hotel
+	if err := r.db.Preload("ContactInfos").Find(&hotels).Error; err != nil {
+		return nil, fmt.Errorf("error fetching hotels: %w", err)
+	}
+	return hotels, nil
+}
+
 func (r *hotelRepository) GetHotelDetails(hotelID uuid.UUID) (*Hotel, error) {
 	var hotel Hotel
-	err := r.db.Preload("ContactInfos").First(&hotel, "id = ?", hotelID).Error
+	err := r.db.Preload("ContactInfos").Preload("Location").Preload("Officials.ContactInfos").Preload("Tags").
+		Preload("Media", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).Preload("Translations").
+		First(&hotel, "id = ?", hotelID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrHotelNotFound
+	}
 	if err != nil {
 		return nil, fmt.Errorf("error fetching hotel details: %w", err)
 	}
 	return &hotel, nil
 }
 
-func (r *hotelRepository) FetchHotelsByLocation(location string) ([]Hotel, error) {
+// FetchLocationAggregates totals the hotels matching the filter, their
+// reviews, contacts and officials with one aggregate query each, so that no
+// hotel has to be loaded. Hotels count as without a phone when they have no
+// contact of the phone types.
+func (r *hotelRepository) FetchLocationAggregates(filter LocationFilter, phoneTypes []string) (*LocationAggregates, error) {
+	var totals struct {
+		HotelCount         int
+		ScoreTotal         int
+		ReviewCount        int
+		HotelsWithoutPhone int
+	}
+	phones := r.db.Table("contact_infos").
+		Select("1").
+		Where("contact_infos.hotel_id = hotels.id AND contact_infos.info_type IN ?", phoneTypes)
+	err := applyLocationFilter(r.db.Model(&Hotel{}), filter).
+		Select("COUNT(*) AS hotel_count, "+
+			"COALESCE(SUM(hotels.score_total), 0) AS score_total, "+
+			"COALESCE(SUM(hotels.review_count), 0) AS review_count, "+
+			"COALESCE(SUM(CASE WHEN NOT EXISTS (?) THEN 1 ELSE 0 END), 0) AS hotels_without_phone", phones).
+		Scan(&totals).Error
+	if err != nil {
+		return nil, fmt.Errorf("error totaling hotels for location %+v: %w", filter, err)
+	}
+
+	aggregates := &LocationAggregates{
+		HotelCount:         totals.HotelCount,
+		ScoreTotal:         totals.ScoreTotal,
+		ReviewCount:        totals.ReviewCount,
+		HotelsWithoutPhone: totals.HotelsWithoutPhone,
+		ContactCounts:      make(map[string]int),
+	}
+	if aggregates.HotelCount == 0 {
+		return aggregates, nil
+	}
+
+	var contacts []struct {
+		InfoType string
+		Count    int
+	}
+	err = applyLocationFilter(r.db.Model(&Hotel{}), filter).
+		Joins("JOIN contact_infos ON contact_infos.hotel_id = hotels.id").
+		Select("contact_infos.info_type AS info_type, COUNT(*) AS count").
+		Group("contact_infos.info_type").
+		Scan(&contacts).Error
+	if err != nil {
+		return nil, fmt.Errorf("error counting contacts for location %+v: %w", filter, err)
+	}
+	for _, contact := range contacts {
+		aggregates.ContactCounts[contact.InfoType] = contact.Count
+	}
+
+	err = applyLocationFilter(r.db.Model(&Hotel{}), filter).
+		Joins("JOIN hotel_officials ON hotel_officials.hotel_id = hotels.id").
+		Select("COUNT(DISTINCT LOWER(hotel_officials.name) || ' ' || LOWER(hotel_officials.surname))").
+		Scan(&aggregates.OfficialCount).Error
+	if err != nil {
+		return nil, fmt.Errorf("error counting officials for location %+v: %w", filter, err)
+	}
+	return aggregates, nil
+}
+
+// ListLocationStats groups the hotels matching the location filter of the
+// options by the locations of their level and returns a page of them with
+// their hotel and phone counts, and the number of locations. Phones are the
+// contacts of the phone types. A single GROUP BY query counts both, and a
+// window function counts the locations of all pages.
+func (r *hotelRepository) ListLocationStats(opts LocationStatsOptions, phoneTypes []string) ([]LocationSummary, int64, error) {
+	columns := locationLevelColumns[opts.Level]
+	grouped := func() *gorm.DB {
+		query := applyLocationFilter(r.db.Model(&Hotel{}), opts.Location)
+		if !opts.Location.hasLocation() {
+			query = query.Joins("JOIN locations ON locations.hotel_id = hotels.id")
+		}
+		query = query.
+			Joins("LEFT JOIN contact_infos ON contact_infos.hotel_id = hotels.id AND contact_infos.info_type IN ?", phoneTypes).
+			Group(strings.Join(columns, ", "))
+		if opts.MinHotels > 0 {
+			query = query.Having("COUNT(DISTINCT hotels.id) >= ?", opts.MinHotels)
+		}
+		return query
+	}
+
+	selects := make([]string, 0, len(columns)+3)
+	for _, column := range columns {
+		selects = append(selects, column+" AS "+strings.TrimPrefix(column, "locations."))
+	}
+	selects = append(selects, "COUNT(DISTINCT hotels.id) AS hotel_count", "COUNT(contact_infos.id) AS phone_count", "COUNT(*) OVER () AS total")
+
+	direction := "ASC"
+	if opts.SortDesc {
+		direction = "DESC"
+	}
+	order := make([]string, 0, len(columns)+1)
+	if opts.SortBy != "name" {
+		order = append(order, opts.SortBy+" "+direction)
+	}
+	for _, column := range columns {
+		if opts.SortBy == "name" {
+			column += " " + direction
+		}
+		order = append(order, column)
+	}
+
+	var rows []struct {
+		LocationSummary
+		Total int64
+	}
+	err := grouped().
+		Select(strings.Join(selects, ", ")).
+		Order(strings.Join(order, ", ")).
+		Limit(opts.Limit).
+		Offset(opts.Offset).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("error listing location stats: %w", err)
+	}
+
+	summaries := make([]LocationSummary, len(rows))
+	for i, row := range rows {
+		summaries[i] = row.LocationSummary
+	}
+	if len(rows) > 0 {
+		return summaries, rows[0].Total, nil
+	}
+	if opts.Offset == 0 {
+		return summaries, 0, nil
+	}
+
+	// Past the last page the window function has no row to count on
+	var total int64
+	if err := r.db.Table("(?) AS grouped", grouped().Select(columns)).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("error counting locations: %w", err)
+	}
+	return summaries, total, nil
+}
+
+// FetchHotelsInBoundingBox returns the hotels with coordinates inside the box.
+// Plain range predicates keep the query portable across PostgreSQL and SQLite.
+func (r *hotelRepository) FetchHotelsInBoundingBox(box BoundingBox) ([]Hotel, error) {
 	var hotels []Hotel
 
-	err := r.db.Joins("JOIN contact_infos ON contact_infos.hotel_id = hotels.id").
-		Where("contact_infos.info_type IN (?)", []string{"location", "phone"}).
-		Where("contact_infos.info_content = ?", location).
-		Preload("ContactInfos").
-		Find(&hotels).Error
+	query := r.db.Joins("JOIN locations ON locations.hotel_id = hotels.id").
+		Where("locations.latitude BETWEEN ? AND ?", box.MinLat, box.MaxLat)
+	if box.MinLng <= box.MaxLng {
+		query = query.Where("locations.longitude BETWEEN ? AND ?", box.MinLng, box.MaxLng)
+	} else {
+		query = query.Where("locations.longitude >= ? OR locations.longitude <= ?", box.MinLng, box.MaxLng)
+	}
 
+	err := query.Preload("ContactInfos").Preload("Location").Preload("Translations").Find(&hotels).Error
 	if err != nil {
-		return nil, fmt.Errorf("error fetching hotels by location %s: %w", location, err)
+		return nil, fmt.Errorf("error fetching hotels in bounding box %+v: %w", box, err)
 	}
 	return hotels, nil
 }
+
+// SetLocation creates or replaces the location of a hotel.
+func (r *hotelRepository) SetLocation(location *Location, version int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := bumpVersion(tx, location.HotelID, version); err != nil {
+			return err
+		}
+
+		var existing Location
+		err := tx.Where("hotel_id = ?", location.HotelID).First(&existing).Error
+		switch {
+		case err == nil:
+			location.ID = existing.ID
+			return tx.Save(location).Error
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			if location.ID == uuid.Nil {
+				location.ID = uuid.New()
+			}
+			return tx.Create(location).Error
+		default:
+			return fmt.Errorf("error fetching location of hotel %v: %w", location.HotelID, err)
+		}
+	})
+}
+
+func (r *hotelRepository) DeleteLocation(hotelUUID uuid.UUID, version int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := bumpVersion(tx, hotelUUID, version); err != nil {
+			return err
+		}
+
+		result := tx.Where("hotel_id = ?", hotelUUID).Delete(&Location{})
+		if result.Error != nil {
+			return fmt.Errorf("error deleting location of hotel %v: %w", hotelUUID, result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrLocationNotFound
+		}
+		return nil
+	})
+}
+
+// applyLocationFilter restricts a hotels query to the hotels whose location
+// and chain match the filter. Names are compared case-insensitively. The
+// locations of the hotels are only joined for location criteria.
+func applyLocationFilter(db *gorm.DB, filter LocationFilter) *gorm.DB {
+	query := db
+	if filter.ChainID != uuid.Nil {
+		query = query.Where("hotels.chain_id IN (SELECT id FROM chains WHERE id = ? OR parent_id = ?)", filter.ChainID, filter.ChainID)
+	}
+	if !filter.hasLocation() {
+		return query
+	}
+
+	query = query.Joins("JOIN locations ON locations.hotel_id = hotels.id")
+	if filter.Name != "" {
+		query = query.Where("LOWER(locations.country) = LOWER(?) OR LOWER(locations.city) = LOWER(?) OR LOWER(locations.district) = LOWER(?)",
+			filter.Name, filter.Name, filter.Name)
+	}
+	if filter.Country != "" {
+		query = query.Where("LOWER(locations.country) = LOWER(?)", filter.Country)
+	}
+	if filter.City != "" {
+		query = query.Where("LOWER(locations.city) = LOWER(?)", filter.City)
+	}
+	if filter.District != "" {
+		query = query.Where("LOWER(locations.district) = LOWER(?)", filter.District)
+	}
+	return query
+}
+
+// escapeLike escapes the LIKE wildcards in a user supplied search term.
+func escapeLike(term string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(term)
+}
+
+// bumpVersion advances the version of a hotel whose contact infos are changing.
+// A non-zero version must match the stored one.
+func bumpVersion(tx *gorm.DB, hotelID uuid.UUID, version int) error {
+	query := tx.Model(&Hotel{}).Where("id = ?", hotelID)
+	if version != 0 {
+		query = query.Where("version = ?", version)
+	}
+
+	result := query.UpdateColumn("version", gorm.Expr("version + 1"))
+	if result.Error != nil {
+		return fmt.Errorf("error updating version of hotel %v: %w", hotelID, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		if version != 0 {
+			return versionMismatch(tx, hotelID)
+		}
+		return ErrHotelNotFound
+	}
+	return nil
+}
+
+// versionMismatch explains why a write guarded by a hotel version matched no
+// row: the hotel does not exist, or its version has moved on.
+func versionMismatch(db *gorm.DB, hotelID uuid.UUID) error {
+	var count int64
+	if err := db.Model(&Hotel{}).Where("id = ?", hotelID).Count(&count).Error; err != nil {
+		return fmt.Errorf("error checking hotel %v: %w", hotelID, err)
+	}
+	if count == 0 {
+		return ErrHotelNotFound
+	}
+	return ErrVersionConflict
+}
+
+// ListContactTypes returns the contact type registry ordered by name.
+func (r *hotelRepository) ListContactTypes() ([]ContactType, error) {
+	var contactTypes []ContactType
+	if err := r.db.Order("name").Find(&contactTypes).Error; err != nil {
+		return nil, fmt.Errorf("error listing contact types: %w", err)
+	}
+	return contactTypes, nil
+}
+
+func (r *hotelRepository) GetContactType(name string) (*ContactType, error) {
+	var contactType ContactType
+	err := r.db.Where("name = ?", name).First(&contactType).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrContactTypeNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error fetching contact type: %w", err)
+	}
+	return &contactType, nil
+}
+
+func (r *hotelRepository) CreateContactType(contactType *ContactType) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&ContactType{}).Where("name = ?", contactType.Name).Count(&count).Error; err != nil {
+			return fmt.Errorf("error checking contact type: %w", err)
+		}
+		if count > 0 {
+			return ErrContactTypeExists
+		}
+		if err := tx.Create(contactType).Error; err != nil {
+			return fmt.Errorf("error creating contact type: %w", err)
+		}
+		return nil
+	})
+}
+
+// UpdateContactType replaces every field of the contact type except its name.
+// Existing contacts of the type are not revalidated.
+func (r *hotelRepository) UpdateContactType(contactType *ContactType) error {
+	result := r.db.Model(&ContactType{}).Where("name = ?", contactType.Name).Updates(map[string]interface{}{
+		"display_name":     contactType.DisplayName,
+		"kind":             contactType.Kind,
+		"pattern":          contactType.Pattern,
+		"unique_per_hotel": contactType.UniquePerHotel,
+		"counts_in_stats":  contactType.CountsInStats,
+	})
+	if result.Error != nil {
+		return fmt.Errorf("error updating contact type: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrContactTypeNotFound
+	}
+	return nil
+}
+
+// DeleteContactType removes a contact type that no contact info uses.
+func (r *hotelRepository) DeleteContactType(name string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&ContactInfo{}).Where("info_type = ?", name).Count(&count).Error; err != nil {
+			return fmt.Errorf("error checking contact infos of type: %w", err)
+		}
+		if count > 0 {
+			return ErrContactTypeInUse
+		}
+
+		result := tx.Where("name = ?", name).Delete(&ContactType{})
+		if result.Error != nil {
+			return fmt.Errorf("error deleting contact type: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrContactTypeNotFound
+		}
+		return nil
+	})
+}
+
+// ListTags returns the tag vocabulary ordered by name.
+func (r *hotelRepository) ListTags() ([]Tag, error) {
+	var tags []Tag
+	if err := r.db.Order("name").Find(&tags).Error; err != nil {
+		return nil, fmt.Errorf("error listing tags: %w", err)
+	}
+	return tags, nil
+}
+
+func (r *hotelRepository) GetTag(name string) (*Tag, error) {
+	var tag Tag
+	err := r.db.Where("name = ?", name).First(&tag).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrTagNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error fetching tag: %w", err)
+	}
+	return &tag, nil
+}
+
+func (r *hotelRepository) CreateTag(tag *Tag) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&Tag{}).Where("name = ?", tag.Name).Count(&count).Error; err != nil {
+			return fmt.Errorf("error checking tag: %w", err)
+		}
+		if count > 0 {
+			return ErrTagExists
+		}
+		if err := tx.Create(tag).Error; err != nil {
+			return fmt.Errorf("error creating tag: %w", err)
+		}
+		return nil
+	})
+}
+
+// UpdateTag replaces every field of the tag except its name.
+func (r *hotelRepository) UpdateTag(tag *Tag) error {
+	result := r.db.Model(&Tag{}).Where("name = ?", tag.Name).Updates(map[string]interface{}{
+		"display_name": tag.DisplayName,
+		"kind":         tag.Kind,
+	})
+	if result.Error != nil {
+		return fmt.Errorf("error updating tag: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrTagNotFound
+	}
+	return nil
+}
+
+// DeleteTag removes a tag that no hotel has.
+func (r *hotelRepository) DeleteTag(name string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&HotelTag{}).Where("tag_name = ?", name).Count(&count).Error; err != nil {
+			return fmt.Errorf("error checking hotels with tag: %w", err)
+		}
+		if count > 0 {
+			return ErrTagInUse
+		}
+
+		result := tx.Where("name = ?", name).Delete(&Tag{})
+		if result.Error != nil {
+			return fmt.Errorf("error deleting tag: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrTagNotFound
+		}
+		return nil
+	})
+}
+
+// SetHotelTags replaces the tags of a hotel.
+func (r *hotelRepository) SetHotelTags(hotelID uuid.UUID, tags []string, version int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := bumpVersion(tx, hotelID, version); err != nil {
+			return err
+		}
+		if err := tx.Where("hotel_id = ?", hotelID).Delete(&HotelTag{}).Error; err != nil {
+			return fmt.Errorf("error removing tags of hotel %v: %w", hotelID, err)
+		}
+		if len(tags) == 0 {
+			return nil
+		}
+
+		hotelTags := make([]HotelTag, len(tags))
+		for i, tag := range tags {
+			hotelTags[i] = HotelTag{HotelID: hotelID, TagName: tag}
+		}
+		if err := tx.Create(&hotelTags).Error; err != nil {
+			return fmt.Errorf("error adding tags of hotel %v: %w", hotelID, err)
+		}
+		return nil
+	})
+}
+
+// ListChains returns the chains ordered by name, optionally only those of a
+// kind.
+func (r *hotelRepository) ListChains(kind string) ([]Chain, error) {
+	query := r.db.Order("name")
+	if kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+	var chains []Chain
+	if err := query.Find(&chains).Error; err != nil {
+		return nil, fmt.Errorf("error listing chains: %w", err)
+	}
+	return chains, nil
+}
+
+// GetChain returns a chain together with its brands.
+func (r *hotelRepository) GetChain(id uuid.UUID) (*Chain, error) {
+	var chain Chain
+	err := r.db.Preload("Brands", func(db *gorm.DB) *gorm.DB {
+		return db.Order("name")
+	}).Where("id = ?", id).First(&chain).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrChainNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error fetching chain %v: %w", id, err)
+	}
+	return &chain, nil
+}
+
+func (r *hotelRepository) CreateChain(chain *Chain) error {
+	if chain.ID == uuid.Nil {
+		chain.ID = uuid.New()
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkChainName(tx, chain); err != nil {
+			return err
+		}
+		if err := tx.Omit("Brands").Create(chain).Error; err != nil {
+			return fmt.Errorf("error creating chain: %w", err)
+		}
+		return nil
+	})
+}
+
+// UpdateChain replaces the name and the parent of a chain. Its kind cannot
+// change.
+func (r *hotelRepository) UpdateChain(chain *Chain) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkChainName(tx, chain); err != nil {
+			return err
+		}
+
+		result := tx.Model(&Chain{}).Where("id = ?", chain.ID).Updates(map[string]interface{}{
+			"name":       chain.Name,
+			"parent_id":  chain.ParentID,
+			"updated_at": chain.UpdatedAt,
+		})
+		if result.Error != nil {
+			return fmt.Errorf("error updating chain %v: %w", chain.ID, result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrChainNotFound
+		}
+		return nil
+	})
+}
+
+// checkChainName returns ErrChainExists when another chain has the name of a
+// chain, ignoring case.
+func checkChainName(tx *gorm.DB, chain *Chain) error {
+	var count int64
+	err := tx.Model(&Chain{}).
+		Where("LOWER(name) = LOWER(?) AND id <> ?", chain.Name, chain.ID).
+		Count(&count).Error
+	if err != nil {
+		return fmt.Errorf("error checking chain name: %w", err)
+	}
+	if count > 0 {
+		return ErrChainExists
+	}
+	return nil
+}
+
+// DeleteChain removes a chain that has no brands and no hotels, counting
+// soft-deleted hotels as they may be restored.
+func (r *hotelRepository) DeleteChain(id uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var brands, hotels int64
+		if err := tx.Model(&Chain{}).Where("parent_id = ?", id).Count(&brands).Error; err != nil {
+			return fmt.Errorf("error checking brands of chain %v: %w", id, err)
+		}
+		if err := tx.Unscoped().Model(&Hotel{}).Where("chain_id = ?", id).Count(&hotels).Error; err != nil {
+			return fmt.Errorf("error checking hotels of chain %v: %w", id, err)
+		}
+		if brands > 0 || hotels > 0 {
+			return ErrChainInUse
+		}
+
+		result := tx.Where("id = ?", id).Delete(&Chain{})
+		if result.Error != nil {
+			return fmt.Errorf("error deleting chain %v: %w", id, result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrChainNotFound
+		}
+		return nil
+	})
+}
+
+// SetHotelChain assigns a hotel to a chain, or to none for a nil chain.
+func (r *hotelRepository) SetHotelChain(hotelID uuid.UUID, chainID *uuid.UUID, version int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := bumpVersion(tx, hotelID, version); err != nil {
+			return err
+		}
+		if err := tx.Model(&Hotel{}).Where("id = ?", hotelID).UpdateColumn("chain_id", chainID).Error; err != nil {
+			return fmt.Errorf("error setting chain of hotel %v: %w", hotelID, err)
+		}
+		return nil
+	})
+}
+
+// CountHotels counts the hotels matching the filter.
+func (r *hotelRepository) CountHotels(filter LocationFilter) (int64, error) {
+	var count int64
+	if err := applyLocationFilter(r.db.Model(&Hotel{}), filter).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("error counting hotels for %+v: %w", filter, err)
+	}
+	return count, nil
+}
+
+// CountHotelsByCity counts the hotels matching the filter per country and
+// city, most hotels first. Hotels without a location are left out.
+func (r *hotelRepository) CountHotelsByCity(filter LocationFilter) ([]LocationCount, error) {
+	// The location filter already joins the locations of the hotels.
+	query := applyLocationFilter(r.db.Model(&Hotel{}), filter)
+	if !filter.hasLocation() {
+		query = query.Joins("JOIN locations ON locations.hotel_id = hotels.id")
+	}
+	var counts []LocationCount
+	err := query.
+		Select("locations.country AS country, locations.city AS city, COUNT(*) AS hotel_count").
+		Group("locations.country, locations.city").
+		Order("hotel_count DESC, country, city").
+		Scan(&counts).Error
+	if err != nil {
+		return nil, fmt.Errorf("error counting hotels by city for %+v: %w", filter, err)
+	}
+	return counts, nil
+}
+
+// CountHotelsByContactType counts the hotels matching the filter that have at
+// least one contact of a type, per type.
+func (r *hotelRepository) CountHotelsByContactType(filter LocationFilter) (map[string]int64, error) {
+	var rows []struct {
+		InfoType   string
+		HotelCount int64
+	}
+	err := applyLocationFilter(r.db.Model(&Hotel{}), filter).
+		Joins("JOIN contact_infos ON contact_infos.hotel_id = hotels.id").
+		Select("contact_infos.info_type AS info_type, COUNT(DISTINCT hotels.id) AS hotel_count").
+		Group("contact_infos.info_type").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("error counting hotels by contact type for %+v: %w", filter, err)
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.InfoType] = row.HotelCount
+	}
+	return counts, nil
+}
+
+// CountFacets counts the hotels matching the filters of the options by tag, by
+// country and by city within its country, most frequent values first. Limit,
+// offset and cursor are ignored.
+func (r *hotelRepository) CountFacets(opts ListOptions) (*Facets, error) {
+	facets := &Facets{}
+	err := r.filterHotels(opts).
+		Joins("JOIN hotel_tags ON hotel_tags.hotel_id = hotels.id").
+		Select("hotel_tags.tag_name AS value, COUNT(*) AS count").
+		Group("hotel_tags.tag_name").
+		Order("count DESC, value").
+		Scan(&facets.Tags).Error
+	if err != nil {
+		return nil, fmt.Errorf("error counting tag facets: %w", err)
+	}
+
+	// The location filter already joins the locations of the hotels.
+	locations := func() *gorm.DB {
+		query := r.filterHotels(opts)
+		if !opts.Location.hasLocation() {
+			query = query.Joins("JOIN locations ON locations.hotel_id = hotels.id")
+		}
+		return query
+	}
+	err = locations().
+		Select("locations.country AS value, COUNT(*) AS count").
+		Group("locations.country").
+		Order("count DESC, value").
+		Scan(&facets.Countries).Error
+	if err != nil {
+		return nil, fmt.Errorf("error counting location facets: %w", err)
+	}
+	err = locations().
+		Select("locations.country AS country, locations.city AS value, COUNT(*) AS count").
+		Group("locations.country").Group("locations.city").
+		Order("count DESC, value, country").
+		Scan(&facets.Cities).Error
+	if err != nil {
+		return nil, fmt.Errorf("error counting location facets: %w", err)
+	}
+	return facets, nil
+}
+
+// ListMedia returns the media of a hotel in display order.
+func (r *hotelRepository) ListMedia(hotelID uuid.UUID) ([]Media, error) {
+	var media []Media
+	if err := r.db.Where("hotel_id = ?", hotelID).Order("position").Find(&media).Error; err != nil {
+		return nil, fmt.Errorf("error listing media of hotel %v: %w", hotelID, err)
+	}
+	return media, nil
+}
+
+func (r *hotelRepository) GetMedia(hotelID, mediaID uuid.UUID) (*Media, error) {
+	var media Media
+	err := r.db.Where("id = ? AND hotel_id = ?", mediaID, hotelID).First(&media).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrMediaNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error fetching media: %w", err)
+	}
+	return &media, nil
+}
+
+// AddMedia stores a media after the last media of its hotel.
+func (r *hotelRepository) AddMedia(media *Media, version int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := bumpVersion(tx, media.HotelID, version); err != nil {
+			return err
+		}
+
+		var last int
+		err := tx.Model(&Media{}).Where("hotel_id = ?", media.HotelID).
+			Select("COALESCE(MAX(position), 0)").Scan(&last).Error
+		if err != nil {
+			return fmt.Errorf("error fetching media positions: %w", err)
+		}
+		media.Position = last + 1
+
+		if err := tx.Create(media).Error; err != nil {
+			return fmt.Errorf("error adding media to hotel %v: %w", media.HotelID, err)
+		}
+		return nil
+	})
+}
+
+// ReorderMedia gives the media of a hotel the positions of their IDs in
+// mediaIDs, which must list every media of the hotel once.
+func (r *hotelRepository) ReorderMedia(hotelID uuid.UUID, mediaIDs []uuid.UUID, version int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := bumpVersion(tx, hotelID, version); err != nil {
+			return err
+		}
+
+		var existing []uuid.UUID
+		if err := tx.Model(&Media{}).Where("hotel_id = ?", hotelID).Pluck("id", &existing).Error; err != nil {
+			return fmt.Errorf("error listing media of hotel %v: %w", hotelID, err)
+		}
+		remaining := make(map[uuid.UUID]bool, len(existing))
+		for _, id := range existing {
+			remaining[id] = true
+		}
+		for _, id := range mediaIDs {
+			if !remaining[id] {
+				return ErrInvalidMediaOrder
+			}
+			delete(remaining, id)
+		}
+		if len(remaining) > 0 {
+			return ErrInvalidMediaOrder
+		}
+
+		for i, id := range mediaIDs {
+			if err := tx.Model(&Media{}).Where("id = ?", id).Update("position", i+1).Error; err != nil {
+				return fmt.Errorf("error reordering media of hotel %v: %w", hotelID, err)
+			}
+		}
+		return nil
+	})
+}
+
+// RemoveMedia deletes the record of a media. Its blobs are left to the caller.
+func (r *hotelRepository) RemoveMedia(hotelID, mediaID uuid.UUID, version int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := bumpVersion(tx, hotelID, version); err != nil {
+			return err
+		}
+		result := tx.Where("id = ? AND hotel_id = ?", mediaID, hotelID).Delete(&Media{})
+		if result.Error != nil {
+			return fmt.Errorf("error removing media: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrMediaNotFound
+		}
+		return nil
+	})
+}
+
+// ListPurgeableMedia returns the media of the hotels PurgeHotels is about to
+// remove.
+func (r *hotelRepository) ListPurgeableMedia(hotelIDs []uuid.UUID) ([]Media, error) {
+	var media []Media
+	if len(hotelIDs) == 0 {
+		return media, nil
+	}
+	err := r.db.Where("hotel_id IN ?", hotelIDs).Find(&media).Error
+	if err != nil {
+		return nil, fmt.Errorf("error listing media of deleted hotels: %w", err)
+	}
+	return media, nil
+}
+
+// ListTranslations returns the translations of the hotels ordered by hotel and
+// language.
+func (r *hotelRepository) ListTranslations(hotelIDs []uuid.UUID) ([]HotelTranslation, error) {
+	var translations []HotelTranslation
+	if len(hotelIDs) == 0 {
+		return translations, nil
+	}
+	err := r.db.Where("hotel_id IN ?", hotelIDs).Order("hotel_id, language").Find(&translations).Error
+	if err != nil {
+		return nil, fmt.Errorf("error listing translations: %w", err)
+	}
+	return translations, nil
+}
+
+func (r *hotelRepository) GetTranslation(hotelID uuid.UUID, language string) (*HotelTranslation, error) {
+	var translation HotelTranslation
+	err := r.db.Where("hotel_id = ? AND language = ?", hotelID, language).First(&translation).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrTranslationNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error fetching translation: %w", err)
+	}
+	return &translation, nil
+}
+
+// SaveTranslation creates the translation of a hotel into a language or
+// replaces the existing one.
+func (r *hotelRepository) SaveTranslation(translation *HotelTranslation, version int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := bumpVersion(tx, translation.HotelID, version); err != nil {
+			return err
+		}
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "hotel_id"}, {Name: "language"}},
+			DoUpdates: clause.AssignmentColumns([]string{"company_title", "description", "marketing_text", "updated_at"}),
+		}).Create(translation).Error
+		if err != nil {
+			return fmt.Errorf("error saving translation of hotel %v: %w", translation.HotelID, err)
+		}
+		return nil
+	})
+}
+
+func (r *hotelRepository) RemoveTranslation(hotelID uuid.UUID, language string, version int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := bumpVersion(tx, hotelID, version); err != nil {
+			return err
+		}
+		result := tx.Where("hotel_id = ? AND language = ?", hotelID, language).Delete(&HotelTranslation{})
+		if result.Error != nil {
+			return fmt.Errorf("error removing translation: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrTranslationNotFound
+		}
+		return nil
+	})
+}
+
+// ListMissingTranslations returns a page of the hotels lacking a complete
+// translation into any of the languages of the filter, ordered by ID, with
+// their translations, and the number of such hotels.
+func (r *hotelRepository) ListMissingTranslations(filter MissingTranslationFilter) ([]Hotel, int64, error) {
+	complete := r.db.Model(&HotelTranslation{}).Select("hotel_id").
+		Where("language IN ? AND company_title <> '' AND description <> '' AND marketing_text <> ''", filter.Languages).
+		Group("hotel_id").
+		Having("COUNT(*) = ?", len(filter.Languages))
+	query := r.db.Model(&Hotel{}).Where("hotels.id NOT IN (?)", complete)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("error counting hotels with missing translations: %w", err)
+	}
+
+	var hotels []Hotel
+	err := query.Order("hotels.id").Limit(filter.Limit).Offset(filter.Offset).Preload("Translations").Find(&hotels).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("error listing hotels with missing translations: %w", err)
+	}
+	return hotels, total, nil
+}
+
+// ListRatePlans returns the rate plans of a hotel, or of one of its room types
+// when roomTypeID is set, ordered by room type, meal plan and start.
+func (r *hotelRepository) ListRatePlans(hotelID, roomTypeID uuid.UUID) ([]RatePlan, error) {
+	query := r.db.Where("hotel_id = ?", hotelID)
+	if roomTypeID != uuid.Nil {
+		query = query.Where("room_type_id = ?", roomTypeID)
+	}
+	var plans []RatePlan
+	if err := query.Order("room_type_id, meal_plan, valid_from").Find(&plans).Error; err != nil {
+		return nil, fmt.Errorf("error fetching rate plans of hotel %v: %w", hotelID, err)
+	}
+	return plans, nil
+}
+
+func (r *hotelRepository) GetRatePlan(hotelID, ratePlanID uuid.UUID) (*RatePlan, error) {
+	var plan RatePlan
+	err := r.db.Where("id = ? AND hotel_id = ?", ratePlanID, hotelID).First(&plan).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRatePlanNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error fetching rate plan: %w", err)
+	}
+	return &plan, nil
+}
+
+func (r *hotelRepository) AddRatePlan(plan *RatePlan, version int) error {
+	if plan.ID == uuid.Nil {
+		plan.ID = uuid.New()
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := bumpVersion(tx, plan.HotelID, version); err != nil {
+			return err
+		}
+		if err := checkRatePlanOverlap(tx, plan); err != nil {
+			return err
+		}
+		return tx.Create(plan).Error
+	})
+}
+
+// UpdateRatePlan replaces the fields of a rate plan.
+func (r *hotelRepository) UpdateRatePlan(plan *RatePlan, version int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := bumpVersion(tx, plan.HotelID, version); err != nil {
+			return err
+		}
+		if err := checkRatePlanOverlap(tx, plan); err != nil {
+			return err
+		}
+
+		result := tx.Model(&RatePlan{}).
+			Where("id = ? AND hotel_id = ?", plan.ID, plan.HotelID).
+			Updates(map[string]interface{}{
+				"room_type_id":                plan.RoomTypeID,
+				"name":                        plan.Name,
+				"valid_from":                  plan.ValidFrom,
+				"valid_to":                    plan.ValidTo,
+				"base_price":                  plan.BasePrice,
+				"currency":                    plan.Currency,
+				"meal_plan":                   plan.MealPlan,
+				"cancellation_refundable":     plan.Cancellation.Refundable,
+				"cancellation_free_days":      plan.Cancellation.FreeDays,
+				"cancellation_penalty_nights": plan.Cancellation.PenaltyNights,
+			})
+		if result.Error != nil {
+			return fmt.Errorf("error updating rate plan %v: %w", plan.ID, result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrRatePlanNotFound
+		}
+		return nil
+	})
+}
+
+// checkRatePlanOverlap locks the room type of a rate plan, so that rate plans
+// of the same room type are checked one after the other, and returns
+// ErrRatePlanOverlap when another plan with the same meal plan prices any of
+// its nights.
+func checkRatePlanOverlap(tx *gorm.DB, plan *RatePlan) error {
+	var roomType RoomType
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND hotel_id = ?", plan.RoomTypeID, plan.HotelID).
+		First(&roomType).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrRoomTypeNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("error locking room type %v: %w", plan.RoomTypeID, err)
+	}
+
+	var overlapping int64
+	err = tx.Model(&RatePlan{}).
+		Where("room_type_id = ? AND meal_plan = ? AND id <> ?", plan.RoomTypeID, plan.MealPlan, plan.ID).
+		Where("valid_from < ? AND valid_to > ?", plan.ValidTo, plan.ValidFrom).
+		Count(&overlapping).Error
+	if err != nil {
+		return fmt.Errorf("error checking rate plans of room type %v: %w", plan.RoomTypeID, err)
+	}
+	if overlapping > 0 {
+		return ErrRatePlanOverlap
+	}
+	return nil
+}
+
+func (r *hotelRepository) RemoveRatePlan(hotelID, ratePlanID uuid.UUID, version int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := bumpVersion(tx, hotelID, version); err != nil {
+			return err
+		}
+
+		result := tx.Where("id = ? AND hotel_id = ?", ratePlanID, hotelID).Delete(&RatePlan{})
+		if result.Error != nil {
+			return fmt.Errorf("error removing rate plan %v: %w", ratePlanID, result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrRatePlanNotFound
+		}
+		return nil
+	})
+}
+
+// ListRatePlansForStay returns the rate plans of a room type and meal plan
+// that price any of the nights from from up to to, in order of their start.
+func (r *hotelRepository) ListRatePlansForStay(hotelID, roomTypeID uuid.UUID, mealPlan string, from, to time.Time) ([]RatePlan, error) {
+	var plans []RatePlan
+	err := r.db.Where("hotel_id = ? AND room_type_id = ? AND meal_plan = ?", hotelID, roomTypeID, mealPlan).
+		Where("valid_from < ? AND valid_to > ?", to, from).
+		Order("valid_from").
+		Find(&plans).Error
+	if err != nil {
+		return nil, fmt.Errorf("error fetching rate plans of room type %v: %w", roomTypeID, err)
+	}
+	return plans, nil
+}
+
+// FetchNightlyPrices returns the base prices of the room only rate plans in
+// effect on a day at the hotels whose location matches the filter.
+func (r *hotelRepository) FetchNightlyPrices(filter LocationFilter, on time.Time) ([]HotelPrice, error) {
+	var rows []struct {
+		HotelID   uuid.UUID
+		BasePrice money.Decimal
+		Currency  string
+	}
+	err := applyLocationFilter(r.db.Model(&Hotel{}), filter).
+		Joins("JOIN rate_plans ON rate_plans.hotel_id = hotels.id").
+		Where("rate_plans.meal_plan = ? AND rate_plans.valid_from <= ? AND rate_plans.valid_to > ?", MealPlanRoomOnly, on, on).
+		Select("rate_plans.hotel_id, rate_plans.base_price, rate_plans.currency").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("error fetching nightly prices for location %+v: %w", filter, err)
+	}
+
+	prices := make([]HotelPrice, len(rows))
+	for i, row := range rows {
+		prices[i] = HotelPrice{HotelID: row.HotelID, Price: money.Amount{Value: row.BasePrice, Currency: row.Currency}}
+	}
+	return prices, nil
+}
+
+// ListExchangeRates returns the exchange rates of the filter ordered by
+// currency pair and effective date.
+func (r *hotelRepository) ListExchangeRates(filter ExchangeRateFilter) ([]ExchangeRate, error) {
+	query := r.db.Model(&ExchangeRate{})
+	if filter.BaseCurrency != "" {
+		query = query.Where("base_currency = ?", filter.BaseCurrency)
+	}
+	if filter.QuoteCurrency != "" {
+		query = query.Where("quote_currency = ?", filter.QuoteCurrency)
+	}
+	var rates []ExchangeRate
+	if err := query.Order("base_currency, quote_currency, effective_date").Find(&rates).Error; err != nil {
+		return nil, fmt.Errorf("error listing exchange rates: %w", err)
+	}
+	return rates, nil
+}
+
+// SaveExchangeRate creates the rate of a currency pair for its effective date
+// or replaces the existing one.
+func (r *hotelRepository) SaveExchangeRate(rate *ExchangeRate) error {
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "base_currency"}, {Name: "quote_currency"}, {Name: "effective_date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rate", "updated_at"}),
+	}).Create(rate).Error
+	if err != nil {
+		return fmt.Errorf("error saving exchange rate %s/%s: %w", rate.BaseCurrency, rate.QuoteCurrency, err)
+	}
+	return nil
+}
+
+func (r *hotelRepository) RemoveExchangeRate(base, quote string, effectiveDate time.Time) error {
+	result := r.db.Where("base_currency = ? AND quote_currency = ? AND effective_date = ?", base, quote, effectiveDate).
+		Delete(&ExchangeRate{})
+	if result.Error != nil {
+		return fmt.Errorf("error removing exchange rate %s/%s: %w", base, quote, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrExchangeRateNotFound
+	}
+	return nil
+}
+
+// FindExchangeRate returns the latest rate in effect on a day between two
+// currencies, quoted in either direction.
+func (r *hotelRepository) FindExchangeRate(from, to string, on time.Time) (*ExchangeRate, error) {
+	var rate ExchangeRate
+	err := r.db.Where("((base_currency = ? AND quote_currency = ?) OR (base_currency = ? AND quote_currency = ?)) AND effective_date <= ?",
+		from, to, to, from, on).
+		Order("effective_date DESC").
+		First(&rate).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNoExchangeRate
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error fetching exchange rate %s/%s: %w", from, to, err)
+	}
+	return &rate, nil
+}
@@ -1,15 +1,25 @@
 package hotel
 
 import (
+	"errors"
+	"hotel-guide/internal/money"
+	"hotel-guide/internal/pagination"
+	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
+// notDeleted is the condition gorm adds to every query of soft-deletable hotels.
+const notDeleted = " AND `hotels`.`deleted_at` IS NULL"
+
 func TestSaveHotel(t *testing.T) {
 	// Set up mock database connection with sqlmock
 	db, mock, err := sqlmock.New()
@@ -37,13 +47,14 @@ func TestSaveHotel(t *testing.T) {
 		OwnerName:    "Test Owner",
 		OwnerSurname: "Test Surname",
 		CompanyTitle: "Test Company",
+		Version:      1,
 		ContactInfos: []ContactInfo{},
 	}
 
 	// Expectation: a successful call to Create method with backticks around the table name
 	mock.ExpectBegin()
 	mock.ExpectExec(`INSERT INTO `+"`hotels`"+` \(`).
-		WithArgs(hotel.OwnerName, hotel.OwnerSurname, hotel.CompanyTitle, hotel.ID.String()). // Pass UUID as string
+		WithArgs(hotel.OwnerName, hotel.OwnerSurname, hotel.CompanyTitle, hotel.Version, nil, 0, 0.0, 0, nil, hotel.ID.String()). // Pass UUID as string
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
 
@@ -79,15 +90,15 @@ func TestDeleteHotel_Repository(t *testing.T) {
 	// Sample hotel ID
 	hotelID := uuid.New()
 
-	// Expectation: a successful call to Delete method
+	// Expectation: the hotel is soft-deleted by stamping deleted_at and bumping its version
 	mock.ExpectBegin()
-	mock.ExpectExec(`DELETE FROM ` + "`hotels`" + ` WHERE id = ?`).
-		WithArgs(hotelID.String()). // Pass UUID as string
+	mock.ExpectExec(`UPDATE `+"`hotels`"+` SET `+"`deleted_at`"+`=\?,`+"`version`"+`=version \+ 1 WHERE id = \?`+notDeleted).
+		WithArgs(sqlmock.AnyArg(), hotelID.String()). // Pass UUID as string
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
 
 	// Test Delete method
-	err = repo.Delete(hotelID)
+	err = repo.Delete(hotelID, 0)
 	assert.NoError(t, err)
 
 	// Ensure all expectations were met
@@ -96,6 +107,37 @@ func TestDeleteHotel_Repository(t *testing.T) {
 	}
 }
 
+func TestRemoveContactInfo_Repository_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to open mock database connection: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`(?i)^SELECT sqlite_version\(\)$`).WillReturnRows(sqlmock.NewRows([]string{"sqlite_version"}).AddRow("3.32.3"))
+
+	gormDB, err := gorm.Open(sqlite.New(sqlite.Config{Conn: db}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to initialize GORM: %v", err)
+	}
+	repo := NewRepository(gormDB)
+
+	// A missing contact is reported as not found, so that the handler answers 404
+	hotelID, contactID := uuid.New(), uuid.New()
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT \* FROM `+"`contact_infos`"+` WHERE id = \? AND hotel_id = \?`).
+		WithArgs(contactID.String(), hotelID.String()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectRollback()
+
+	err = repo.RemoveContactInfo(hotelID, contactID, 3)
+	assert.ErrorIs(t, err, ErrContactNotFound)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There were unfulfilled expectations: %s", err)
+	}
+}
+
 func TestAddContactInfo_Repository(t *testing.T) {
 	// Set up mock database connection with sqlmock
 	db, mock, err := sqlmock.New()
@@ -125,13 +167,16 @@ func TestAddContactInfo_Repository(t *testing.T) {
 
 	// Expectation: a successful call to Create method for ContactInfo
 	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE ` + "`hotels`" + ` SET ` + "`version`" + `=version \+ 1 WHERE id = \?`).
+		WithArgs(hotelUUID.String()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
 	mock.ExpectExec(`INSERT INTO `+"`contact_infos`"+` \(`).
 		WithArgs(hotelUUID.String(), contact.InfoType, contact.InfoContent, contact.ID.String()). // Fix order here
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
 
 	// Test AddContactInfo method
-	err = repo.AddContactInfo(hotelUUID, contact)
+	err = repo.AddContactInfo(hotelUUID, contact, 0)
 	assert.NoError(t, err)
 
 	// Ensure all expectations were met
@@ -166,8 +211,12 @@ func TestListHotels_Repository(t *testing.T) {
 		{ID: uuid.New(), OwnerName: "Owner 2", OwnerSurname: "Surname 2", CompanyTitle: "Company 2"},
 	}
 
+	// Expectation for counting hotels
+	mock.ExpectQuery(`(?i)^SELECT count\(\*\) FROM ` + "`hotels`").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
 	// Expectation for querying hotels
-	mock.ExpectQuery(`(?i)^SELECT .* FROM ` + "`hotels`" + `.*`).
+	mock.ExpectQuery(`(?i)^SELECT .* FROM ` + "`hotels`" + ` WHERE ` + "`hotels`.`deleted_at`" + ` IS NULL ORDER BY hotels.id ASC LIMIT 21$`).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "owner_name", "owner_surname", "company_title"}).
 			AddRow(hotels[0].ID.String(), hotels[0].OwnerName, hotels[0].OwnerSurname, hotels[0].CompanyTitle).
 			AddRow(hotels[1].ID.String(), hotels[1].OwnerName, hotels[1].OwnerSurname, hotels[1].CompanyTitle))
@@ -179,10 +228,28 @@ func TestListHotels_Repository(t *testing.T) {
 			AddRow(uuid.New().String(), hotels[0].ID.String(), "contact1").
 			AddRow(uuid.New().String(), hotels[1].ID.String(), "contact2"))
 
+	// Expectation for querying locations
+	mock.ExpectQuery(`(?i)^SELECT .* FROM `+"`locations`"+`.*`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "hotel_id", "country", "city"}).
+			AddRow(uuid.New().String(), hotels[0].ID.String(), "Turkey", "Istanbul"))
+
+	// Expectation for querying hotel_tags
+	mock.ExpectQuery(`(?i)^SELECT .* FROM `+"`hotel_tags`"+`.*`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"hotel_id", "tag_name"}).
+			AddRow(hotels[1].ID.String(), "pool"))
+
+	// Expectation for querying hotel_translations
+	mock.ExpectQuery(`(?i)^SELECT .* FROM `+"`hotel_translations`"+`.*`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"hotel_id", "language", "company_title"}))
+
 	// Test ListHotels method
-	result, err := repo.ListHotels()
+	result, total, err := repo.ListHotels(ListOptions{Limit: DefaultPageLimit, SortBy: "id"})
 	assert.NoError(t, err)   // No error should occur
 	assert.Len(t, result, 2) // We should have 2 hotels
+	assert.Equal(t, int64(2), total)
 
 	// Verify expectations
 	if err := mock.ExpectationsWereMet(); err != nil {
@@ -210,21 +277,35 @@ func TestGetHotelOfficials(t *testing.T) {
 	repo := NewRepository(gormDB)
 
 	// Sample hotel official data
+	hotelID := uuid.New()
 	officials := []HotelOfficial{
-		{OwnerName: "Owner 1", OwnerSurname: "Surname 1", CompanyTitle: "Company 1"},
-		{OwnerName: "Owner 2", OwnerSurname: "Surname 2", CompanyTitle: "Company 2"},
+		{ID: uuid.New(), HotelID: hotelID, Role: RoleOwner, Name: "Owner 1", Surname: "Surname 1", CompanyTitle: "Company 1"},
+		{ID: uuid.New(), HotelID: hotelID, Role: RoleGeneralManager, Name: "Manager 1", Surname: "Surname 2", CompanyTitle: "Company 1"},
 	}
+	activeAt := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
 
-	// Expectation: a successful call to Find method to list hotel officials
-	mock.ExpectQuery(`(?i)^SELECT .* FROM ` + "`hotels`" + `.*`).
-		WillReturnRows(sqlmock.NewRows([]string{"owner_name", "owner_surname", "company_title"}).
-			AddRow(officials[0].OwnerName, officials[0].OwnerSurname, officials[0].CompanyTitle).
-			AddRow(officials[1].OwnerName, officials[1].OwnerSurname, officials[1].CompanyTitle))
+	// Expectation: officials of hotels that are not deleted, with the title of their hotel
+	mock.ExpectQuery(`(?i)^SELECT hotel_officials.\*, hotels.company_title FROM `+"`hotel_officials`"+` JOIN hotels ON hotels.id = hotel_officials.hotel_id AND hotels.deleted_at IS NULL `+
+		`WHERE hotel_officials.hotel_id = \? AND \(\(hotel_officials.start_date IS NULL OR hotel_officials.start_date <= \?\) AND \(hotel_officials.end_date IS NULL OR hotel_officials.end_date > \?\)\) ORDER BY`).
+		WithArgs(hotelID, activeAt, activeAt).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "hotel_id", "role", "name", "surname", "company_title"}).
+			AddRow(officials[0].ID, hotelID, officials[0].Role, officials[0].Name, officials[0].Surname, officials[0].CompanyTitle).
+			AddRow(officials[1].ID, hotelID, officials[1].Role, officials[1].Name, officials[1].Surname, officials[1].CompanyTitle))
+
+	// Expectation: preloading the contacts of the officials
+	mock.ExpectQuery(`(?i)^SELECT \* FROM `+"`official_contacts`"+` WHERE `+"`official_contacts`.`official_id`"+` IN \(\?,\?\)$`).
+		WithArgs(officials[0].ID, officials[1].ID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "official_id", "info_type", "info_content"}).
+			AddRow(uuid.New(), officials[1].ID, ContactTypeEmail, "manager@company1.example"))
 
 	// Test GetHotelOfficials method
-	result, err := repo.GetHotelOfficials()
+	result, err := repo.GetHotelOfficials(OfficialFilter{HotelID: hotelID, ActiveAt: activeAt})
 	assert.NoError(t, err)
-	assert.Len(t, result, 2)
+	if assert.Len(t, result, 2) {
+		assert.Equal(t, "Company 1", result[0].CompanyTitle)
+		assert.Empty(t, result[0].ContactInfos)
+		assert.Len(t, result[1].ContactInfos, 1)
+	}
 
 	// Ensure all expectations were met
 	if err := mock.ExpectationsWereMet(); err != nil {
@@ -252,11 +333,12 @@ func TestGetHotelOfficials_NoRecords(t *testing.T) {
 	repo := NewRepository(gormDB)
 
 	// Expectation: no records for hotel officials
-	mock.ExpectQuery(`(?i)^SELECT .* FROM ` + "`hotels`" + `.*`).
-		WillReturnRows(sqlmock.NewRows([]string{"owner_name", "owner_surname", "company_title"}))
+	mock.ExpectQuery(`(?i)^SELECT .* FROM ` + "`hotel_officials`" + `.* WHERE hotel_officials.role = \?`).
+		WithArgs(RoleSalesManager).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "hotel_id", "role", "name", "surname", "company_title"}))
 
 	// Test GetHotelOfficials method when no data is found
-	result, err := repo.GetHotelOfficials()
+	result, err := repo.GetHotelOfficials(OfficialFilter{Role: RoleSalesManager})
 	assert.NoError(t, err)
 	assert.Len(t, result, 0)
 
@@ -306,13 +388,1585 @@ func TestGetHotelDetails_Repository(t *testing.T) {
 		WillReturnRows(sqlmock.NewRows([]string{"id", "hotel_id", "info_type", "info_content"}).
 			AddRow(hotel.ContactInfos[0].ID.String(), hotel.ID.String(), hotel.ContactInfos[0].InfoType, hotel.ContactInfos[0].InfoContent))
 
+	// Expectation: querying the location of the hotel
+	mock.ExpectQuery(`(?i)^SELECT .* FROM ` + "`locations`" + `.*`).
+		WithArgs(hotel.ID.String()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "hotel_id", "country", "city", "district"}).
+			AddRow(uuid.New().String(), hotel.ID.String(), "Turkey", "Istanbul", "Besiktas"))
+
+	// Expectation: querying the media of the hotel in display order
+	mock.ExpectQuery(`(?i)^SELECT \* FROM ` + "`hotel_media`" + ` WHERE ` + "`hotel_media`.`hotel_id`" + ` = \? ORDER BY position$`).
+		WithArgs(hotel.ID.String()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "hotel_id", "kind", "position", "has_thumbnail"}).
+			AddRow(uuid.New().String(), hotel.ID.String(), MediaKindImage, 1, true))
+
+	// Expectation: querying the officials of the hotel, which have no contacts to preload
+	mock.ExpectQuery(`(?i)^SELECT .* FROM ` + "`hotel_officials`" + `.*`).
+		WithArgs(hotel.ID.String()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "hotel_id", "role", "name", "surname"}))
+
+	// Expectation: querying the tags of the hotel
+	mock.ExpectQuery(`(?i)^SELECT .* FROM ` + "`hotel_tags`" + `.*`).
+		WithArgs(hotel.ID.String()).
+		WillReturnRows(sqlmock.NewRows([]string{"hotel_id", "tag_name"}).
+			AddRow(hotel.ID.String(), "parking").
+			AddRow(hotel.ID.String(), "pool"))
+
+	// Expectation: querying the translations of the hotel
+	mock.ExpectQuery(`(?i)^SELECT .* FROM ` + "`hotel_translations`" + `.*`).
+		WithArgs(hotel.ID.String()).
+		WillReturnRows(sqlmock.NewRows([]string{"hotel_id", "language", "company_title", "description"}).
+			AddRow(hotel.ID.String(), "de", "Hotel Besiktas", "Am Bosporus"))
+
 	// Test GetHotelDetails method
 	result, err := repo.GetHotelDetails(hotel.ID)
 	assert.NoError(t, err)
 	assert.Equal(t, hotel.ID, result.ID)
+	assert.Equal(t, "Istanbul", result.Location.City)
+	assert.Equal(t, []HotelTag{{HotelID: hotel.ID, TagName: "parking"}, {HotelID: hotel.ID, TagName: "pool"}}, result.Tags)
+	assert.Len(t, result.Media, 1)
+	assert.True(t, result.Media[0].HasThumbnail)
+	if assert.Len(t, result.Translations, 1) {
+		assert.Equal(t, "Am Bosporus", result.Translations[0].Description)
+	}
+
+	// Ensure all expectations were met
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestUpdateHotel_Repository(t *testing.T) {
+	// Set up mock database connection with sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to open mock database connection: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`(?i)^SELECT sqlite_version\(\)$`).WillReturnRows(sqlmock.NewRows([]string{"sqlite_version"}).AddRow("3.32.3"))
+
+	// Open GORM DB from mock sql.DB
+	gormDB, err := gorm.Open(sqlite.New(sqlite.Config{Conn: db}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to initialize GORM: %v", err)
+	}
+
+	// Create an instance of hotelRepository
+	repo := NewRepository(gormDB)
+
+	hotel := &Hotel{ID: uuid.New(), OwnerName: "Owner", OwnerSurname: "Surname", CompanyTitle: "Company", Version: 2}
+
+	// Expectation: an update of the editable columns guarded by the current version
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE `+"`hotels`"+` SET .*`+"`version`"+`=version \+ 1 WHERE \(id = \? AND version = \?\)`+notDeleted).
+		WithArgs(hotel.CompanyTitle, hotel.OwnerName, hotel.OwnerSurname, hotel.ID.String(), 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err = repo.UpdateHotel(hotel)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, hotel.Version)
+
+	// Ensure all expectations were met
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestUpdateHotel_Repository_VersionConflict(t *testing.T) {
+	// Set up mock database connection with sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to open mock database connection: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`(?i)^SELECT sqlite_version\(\)$`).WillReturnRows(sqlmock.NewRows([]string{"sqlite_version"}).AddRow("3.32.3"))
+
+	// Open GORM DB from mock sql.DB
+	gormDB, err := gorm.Open(sqlite.New(sqlite.Config{Conn: db}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to initialize GORM: %v", err)
+	}
+
+	// Create an instance of hotelRepository
+	repo := NewRepository(gormDB)
+
+	hotel := &Hotel{ID: uuid.New(), OwnerName: "Owner", OwnerSurname: "Surname", CompanyTitle: "Company", Version: 2}
+
+	// Expectation: the version moved on, so the update matches no rows
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE ` + "`hotels`").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	mock.ExpectQuery(`SELECT count\(\*\) FROM ` + "`hotels`" + ` WHERE id = \?` + notDeleted).
+		WithArgs(hotel.ID.String()).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	err = repo.UpdateHotel(hotel)
+	assert.ErrorIs(t, err, ErrVersionConflict)
+	assert.Equal(t, 2, hotel.Version)
+
+	// An unknown hotel is not found rather than in conflict
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE ` + "`hotels`").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	mock.ExpectQuery(`SELECT count\(\*\) FROM ` + "`hotels`" + ` WHERE id = \?` + notDeleted).
+		WithArgs(hotel.ID.String()).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	assert.ErrorIs(t, repo.UpdateHotel(hotel), ErrHotelNotFound)
+
+	// Ensure all expectations were met
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestDeleteHotel_Repository_VersionConflict(t *testing.T) {
+	// Set up mock database connection with sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to open mock database connection: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`(?i)^SELECT sqlite_version\(\)$`).WillReturnRows(sqlmock.NewRows([]string{"sqlite_version"}).AddRow("3.32.3"))
+
+	// Open GORM DB from mock sql.DB
+	gormDB, err := gorm.Open(sqlite.New(sqlite.Config{Conn: db}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to initialize GORM: %v", err)
+	}
+
+	// Create an instance of hotelRepository
+	repo := NewRepository(gormDB)
+
+	hotelID := uuid.New()
+
+	// Expectation: a delete guarded by a stale version removes nothing
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE `+"`hotels`"+` SET `+"`deleted_at`"+`=\?,`+"`version`"+`=version \+ 1 WHERE id = \? AND version = \?`+notDeleted).
+		WithArgs(sqlmock.AnyArg(), hotelID.String(), 4).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	mock.ExpectQuery(`SELECT count\(\*\) FROM ` + "`hotels`" + ` WHERE id = \?` + notDeleted).
+		WithArgs(hotelID.String()).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	err = repo.Delete(hotelID, 4)
+	assert.ErrorIs(t, err, ErrVersionConflict)
+
+	// With If-Match on an unknown hotel the delete reports it as not found
+	unknownID := uuid.New()
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE `+"`hotels`").
+		WithArgs(sqlmock.AnyArg(), unknownID.String(), 4).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	mock.ExpectQuery(`SELECT count\(\*\) FROM ` + "`hotels`" + ` WHERE id = \?` + notDeleted).
+		WithArgs(unknownID.String()).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	assert.ErrorIs(t, repo.Delete(unknownID, 4), ErrHotelNotFound)
+
+	// Ensure all expectations were met
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestFetchLocationAggregates_Repository(t *testing.T) {
+	// Set up mock database connection with sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to open mock database connection: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`(?i)^SELECT sqlite_version\(\)$`).WillReturnRows(sqlmock.NewRows([]string{"sqlite_version"}).AddRow("3.32.3"))
+
+	// Open GORM DB from mock sql.DB
+	gormDB, err := gorm.Open(sqlite.New(sqlite.Config{Conn: db}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to initialize GORM: %v", err)
+	}
+
+	// Create an instance of hotelRepository
+	repo := NewRepository(gormDB)
+
+	// Expectation: hotels are matched on the structured location only, never on contact infos,
+	// and are totaled without being loaded
+	join := ` JOIN locations ON locations.hotel_id = hotels.id`
+	location := ` WHERE LOWER\(locations.country\) = LOWER\(\?\) AND LOWER\(locations.city\) = LOWER\(\?\)`
+	mock.ExpectQuery(`(?i)^SELECT COUNT\(\*\) AS hotel_count, .* NOT EXISTS \(SELECT 1 FROM `+"`contact_infos`"+` WHERE contact_infos.hotel_id = hotels.id AND contact_infos.info_type IN \(\?,\?\)\).* FROM `+"`hotels`"+join+location+notDeleted+`$`).
+		WithArgs(ContactTypePhone, "whatsapp", "Turkey", "istanbul").
+		WillReturnRows(sqlmock.NewRows([]string{"hotel_count", "score_total", "review_count", "hotels_without_phone"}).AddRow(3, 51, 6, 1))
+	mock.ExpectQuery(`(?i)^SELECT contact_infos.info_type AS info_type, COUNT\(\*\) AS count FROM `+"`hotels`"+join+` JOIN contact_infos ON contact_infos.hotel_id = hotels.id`+location+notDeleted+` GROUP BY `+"`contact_infos`.`info_type`"+`$`).
+		WithArgs("Turkey", "istanbul").
+		WillReturnRows(sqlmock.NewRows([]string{"info_type", "count"}).AddRow(ContactTypePhone, 4).AddRow(ContactTypeEmail, 2))
+	mock.ExpectQuery(`(?i)^SELECT COUNT\(DISTINCT LOWER\(hotel_officials.name\) \|\| ' ' \|\| LOWER\(hotel_officials.surname\)\) FROM `+"`hotels`"+join+` JOIN hotel_officials ON hotel_officials.hotel_id = hotels.id`+location+notDeleted+`$`).
+		WithArgs("Turkey", "istanbul").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+	aggregates, err := repo.FetchLocationAggregates(LocationFilter{Country: "Turkey", City: "istanbul"}, []string{ContactTypePhone, "whatsapp"})
+	assert.NoError(t, err)
+	assert.Equal(t, &LocationAggregates{
+		HotelCount: 3, ScoreTotal: 51, ReviewCount: 6, HotelsWithoutPhone: 1, OfficialCount: 2,
+		ContactCounts: map[string]int{ContactTypePhone: 4, ContactTypeEmail: 2},
+	}, aggregates)
+
+	// Ensure all expectations were met
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestFetchHotelsInBoundingBox_Repository(t *testing.T) {
+	// Set up mock database connection with sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to open mock database connection: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`(?i)^SELECT sqlite_version\(\)$`).WillReturnRows(sqlmock.NewRows([]string{"sqlite_version"}).AddRow("3.32.3"))
+
+	// Open GORM DB from mock sql.DB
+	gormDB, err := gorm.Open(sqlite.New(sqlite.Config{Conn: db}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to initialize GORM: %v", err)
+	}
+
+	// Create an instance of hotelRepository
+	repo := NewRepository(gormDB)
+
+	// A box crossing the antimeridian needs an OR on longitude
+	box := BoundingBox{MinLat: -18, MinLng: 179.5, MaxLat: -17, MaxLng: -179.5}
+
+	mock.ExpectQuery(`(?i)^SELECT .* FROM `+"`hotels`"+` JOIN locations ON locations.hotel_id = hotels.id WHERE \(locations.latitude BETWEEN \? AND \?\) AND \(locations.longitude >= \? OR locations.longitude <= \?\)`).
+		WithArgs(-18.0, -17.0, 179.5, -179.5).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "owner_name", "owner_surname", "company_title"}))
+
+	hotels, err := repo.FetchHotelsInBoundingBox(box)
+	assert.NoError(t, err)
+	assert.Empty(t, hotels)
+
+	// Ensure all expectations were met
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestListHotels_Repository_FiltersAndCursor(t *testing.T) {
+	// Set up mock database connection with sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to open mock database connection: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`(?i)^SELECT sqlite_version\(\)$`).WillReturnRows(sqlmock.NewRows([]string{"sqlite_version"}).AddRow("3.32.3"))
+
+	// Open GORM DB from mock sql.DB
+	gormDB, err := gorm.Open(sqlite.New(sqlite.Config{Conn: db}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to initialize GORM: %v", err)
+	}
+
+	// Create an instance of hotelRepository
+	repo := NewRepository(gormDB)
+
+	lastID := uuid.New()
+	opts := ListOptions{
+		Limit:        10,
+		SortBy:       "company_title",
+		SortDesc:     true,
+		CompanyTitle: "50%_Inn",
+		ContactType:  ContactTypeEmail,
+		after:        &pagination.Cursor{Sort: "-company_title", Value: "Bosphorus Inn", ID: lastID},
+	}
+
+	// The count ignores the cursor, the page query continues after it
+	mock.ExpectQuery(`(?i)^SELECT count\(\*\) FROM `+"`hotels`"+` WHERE LOWER\(hotels.company_title\) LIKE \? ESCAPE '\\' AND \(EXISTS \(.*contact_infos.info_type = \?\)\)`+notDeleted+`$`).
+		WithArgs(`%50\%\_inn%`, ContactTypeEmail).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(`(?i)^SELECT .* FROM `+"`hotels`"+` WHERE .* AND \(hotels.company_title < \? OR \(hotels.company_title = \? AND hotels.id < \?\)\)`+notDeleted+` ORDER BY hotels.company_title DESC, hotels.id DESC LIMIT 11$`).
+		WithArgs(`%50\%\_inn%`, ContactTypeEmail, "Bosphorus Inn", "Bosphorus Inn", lastID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "owner_name", "owner_surname", "company_title"}))
+
+	hotels, total, err := repo.ListHotels(opts)
+	assert.NoError(t, err)
+	assert.Empty(t, hotels)
+	assert.Equal(t, int64(0), total)
 
 	// Ensure all expectations were met
 	if err := mock.ExpectationsWereMet(); err != nil {
 		t.Errorf("There were unfulfilled expectations: %s", err)
 	}
 }
+
+func TestFetchAllHotels_Repository(t *testing.T) {
+	// Set up mock database connection with sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to open mock database connection: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`(?i)^SELECT sqlite_version\(\)$`).WillReturnRows(sqlmock.NewRows([]string{"sqlite_version"}).AddRow("3.32.3"))
+
+	// Open GORM DB from mock sql.DB
+	gormDB, err := gorm.Open(sqlite.New(sqlite.Config{Conn: db}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to initialize GORM: %v", err)
+	}
+
+	// Create an instance of hotelRepository
+	repo := NewRepository(gormDB)
+
+	hotelID := uuid.New()
+	mock.ExpectQuery(`(?i)^SELECT \* FROM ` + "`hotels`" + ` WHERE ` + "`hotels`.`deleted_at`" + ` IS NULL$`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "owner_name", "owner_surname", "company_title"}).
+			AddRow(hotelID.String(), "Jane", "Smith", "Pera Palace"))
+	mock.ExpectQuery(`(?i)^SELECT \* FROM ` + "`contact_infos`" + ` WHERE .*hotel_id.* = \?`).
+		WithArgs(hotelID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "hotel_id", "info_type", "info_content"}).
+			AddRow(uuid.New().String(), hotelID.String(), ContactTypePhone, "+90 212 555 1234"))
+
+	hotels, err := repo.FetchAllHotels()
+	assert.NoError(t, err)
+	if assert.Len(t, hotels, 1) {
+		assert.Len(t, hotels[0].ContactInfos, 1)
+	}
+
+	// Ensure all expectations were met
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestDeleteContactType_InUse_Repository(t *testing.T) {
+	// Set up mock database connection with sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to open mock database connection: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`(?i)^SELECT sqlite_version\(\)$`).WillReturnRows(sqlmock.NewRows([]string{"sqlite_version"}).AddRow("3.32.3"))
+
+	// Open GORM DB from mock sql.DB
+	gormDB, err := gorm.Open(sqlite.New(sqlite.Config{Conn: db}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to initialize GORM: %v", err)
+	}
+
+	// Create an instance of hotelRepository
+	repo := NewRepository(gormDB)
+
+	// A type still used by contact infos is not deleted
+	mock.ExpectBegin()
+	mock.ExpectQuery(`(?i)^SELECT count\(\*\) FROM ` + "`contact_infos`" + ` WHERE info_type = \?`).
+		WithArgs(ContactTypePhone).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectRollback()
+
+	err = repo.DeleteContactType(ContactTypePhone)
+	assert.ErrorIs(t, err, ErrContactTypeInUse)
+
+	// Ensure all expectations were met
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestUpdateContactType_NotFound_Repository(t *testing.T) {
+	// Set up mock database connection with sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to open mock database connection: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`(?i)^SELECT sqlite_version\(\)$`).WillReturnRows(sqlmock.NewRows([]string{"sqlite_version"}).AddRow("3.32.3"))
+
+	// Open GORM DB from mock sql.DB
+	gormDB, err := gorm.Open(sqlite.New(sqlite.Config{Conn: db}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to initialize GORM: %v", err)
+	}
+
+	// Create an instance of hotelRepository
+	repo := NewRepository(gormDB)
+
+	// Boolean fields are written even when false
+	mock.ExpectBegin()
+	mock.ExpectExec(`(?i)^UPDATE ` + "`contact_types`" + ` SET .*` + "`counts_in_stats`" + `=\?.*` + "`unique_per_hotel`" + `=\? WHERE name = \?`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	err = repo.UpdateContactType(&ContactType{Name: "whatsapp", DisplayName: "WhatsApp", Kind: ContactKindPhone})
+	assert.ErrorIs(t, err, ErrContactTypeNotFound)
+
+	// Ensure all expectations were met
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestRestoreHotel_NotDeleted_Repository(t *testing.T) {
+	// Set up mock database connection with sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to open mock database connection: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`(?i)^SELECT sqlite_version\(\)$`).WillReturnRows(sqlmock.NewRows([]string{"sqlite_version"}).AddRow("3.32.3"))
+
+	// Open GORM DB from mock sql.DB
+	gormDB, err := gorm.Open(sqlite.New(sqlite.Config{Conn: db}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to initialize GORM: %v", err)
+	}
+
+	// Create an instance of hotelRepository
+	repo := NewRepository(gormDB)
+
+	hotelID := uuid.New()
+
+	// Expectation: only deleted hotels are restored; a live hotel is left alone
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE `+"`hotels`"+` SET `+"`deleted_at`"+`=\?,`+"`version`"+`=version \+ 1 WHERE id = \? AND deleted_at IS NOT NULL$`).
+		WithArgs(nil, hotelID.String()).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	mock.ExpectQuery(`(?i)^SELECT count\(\*\) FROM ` + "`hotels`" + ` WHERE id = \?` + notDeleted + `$`).
+		WithArgs(hotelID.String()).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	err = repo.Restore(hotelID)
+	assert.ErrorIs(t, err, ErrHotelNotDeleted)
+
+	// Ensure all expectations were met
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestPurgeHotels_Repository(t *testing.T) {
+	// Set up mock database connection with sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to open mock database connection: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`(?i)^SELECT sqlite_version\(\)$`).WillReturnRows(sqlmock.NewRows([]string{"sqlite_version"}).AddRow("3.32.3"))
+
+	// Open GORM DB from mock sql.DB
+	gormDB, err := gorm.Open(sqlite.New(sqlite.Config{Conn: db}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to initialize GORM: %v", err)
+	}
+
+	// Create an instance of hotelRepository
+	repo := NewRepository(gormDB)
+
+	before := time.Now().Add(-24 * time.Hour)
+	ids := []uuid.UUID{uuid.New(), uuid.New()}
+
+	// Expectation: the hotels deleted before the cutoff are selected, SQLite
+	// leaves out the row lock
+	mock.ExpectQuery(`SELECT ` + "`id`" + ` FROM ` + "`hotels`" + ` WHERE deleted_at < \?$`).
+		WithArgs(before).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(ids[0].String()).AddRow(ids[1].String()))
+
+	locked, err := repo.LockDeletedHotels(before)
+	assert.NoError(t, err)
+	assert.Equal(t, ids, locked)
+
+	// Expectation: the selected hotels are removed for good, if still deleted
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM `+"`hotels`"+` WHERE id IN \(\?,\?\) AND deleted_at IS NOT NULL$`).
+		WithArgs(ids[0], ids[1]).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	purged, err := repo.PurgeHotels(ids)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), purged)
+
+	// Ensure all expectations were met
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestPurgeDeletedHotels_Repository_Reviews(t *testing.T) {
+	gormDB := openReservationDB(t)
+	// The hotel table uses PostgreSQL defaults; parsing the hotel schema is
+	// enough for the reviews table to get the foreign key of Hotel.Reviews.
+	if err := gormDB.Exec("CREATE TABLE hotels (id TEXT PRIMARY KEY, version INTEGER, deleted_at DATETIME)").Error; err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	if err := (&gorm.Statement{DB: gormDB}).Parse(&Hotel{}); err != nil {
+		t.Fatalf("Failed to parse hotel schema: %v", err)
+	}
+	if err := gormDB.AutoMigrate(&Review{}); err != nil {
+		t.Fatalf("Failed to migrate SQLite database: %v", err)
+	}
+	repo := NewRepository(gormDB)
+
+	now := time.Now()
+	deletedID, keptID := uuid.New(), uuid.New()
+	gormDB.Exec("INSERT INTO hotels (id, version, deleted_at) VALUES (?, 1, ?)", deletedID, now.Add(-48*time.Hour))
+	gormDB.Exec("INSERT INTO hotels (id, version) VALUES (?, 1)", keptID)
+	for _, hotelID := range []uuid.UUID{deletedID, keptID} {
+		review := &Review{ID: uuid.New(), HotelID: hotelID, Score: 8, Author: "Joe", Date: now, Status: ReviewApproved}
+		if err := gormDB.Create(review).Error; err != nil {
+			t.Fatalf("Failed to create review: %v", err)
+		}
+	}
+
+	ids, err := repo.LockDeletedHotels(now.Add(-24 * time.Hour))
+	assert.NoError(t, err)
+	purged, err := repo.PurgeHotels(ids)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+
+	// The reviews of the purged hotel go with it
+	var hotelIDs []uuid.UUID
+	gormDB.Model(&Review{}).Pluck("hotel_id", &hotelIDs)
+	assert.Equal(t, []uuid.UUID{keptID}, hotelIDs)
+}
+
+func TestListAuditEntries_Repository(t *testing.T) {
+	// Set up mock database connection with sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to open mock database connection: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`(?i)^SELECT sqlite_version\(\)$`).WillReturnRows(sqlmock.NewRows([]string{"sqlite_version"}).AddRow("3.32.3"))
+
+	// Open GORM DB from mock sql.DB
+	gormDB, err := gorm.Open(sqlite.New(sqlite.Config{Conn: db}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to initialize GORM: %v", err)
+	}
+
+	// Create an instance of hotelRepository
+	repo := NewRepository(gormDB)
+
+	hotelID, lastID := uuid.New(), uuid.New()
+	lastAt := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	filter := AuditFilter{
+		HotelID: hotelID,
+		Actor:   "alice",
+		Limit:   10,
+		after:   &pagination.Cursor{Sort: auditCursorSort, Value: lastAt, ID: lastID},
+	}
+
+	// Expectation: the newest entries older than the cursor, one more than the limit
+	mock.ExpectQuery(`(?i)^SELECT \* FROM `+"`audit_entries`"+` WHERE hotel_id = \? AND actor = \? AND \(created_at < \? OR \(created_at = \? AND id < \?\)\) ORDER BY created_at DESC, id DESC LIMIT 11$`).
+		WithArgs(hotelID.String(), "alice", lastAt, lastAt, lastID.String()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "hotel_id", "entity", "action", "actor", "changes"}).
+			AddRow(uuid.NewString(), hotelID.String(), AuditEntityContact, AuditActionUpdate, "alice", `{"info_content":{"before":"a","after":"b"}}`))
+
+	entries, err := repo.ListAuditEntries(filter)
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, AuditChange{Before: "a", After: "b"}, entries[0].Changes["info_content"])
+	}
+
+	// Ensure all expectations were met
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetImportJob_NotFound_Repository(t *testing.T) {
+	// Set up mock database connection with sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to open mock database connection: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`(?i)^SELECT sqlite_version\(\)$`).WillReturnRows(sqlmock.NewRows([]string{"sqlite_version"}).AddRow("3.32.3"))
+
+	// Open GORM DB from mock sql.DB
+	gormDB, err := gorm.Open(sqlite.New(sqlite.Config{Conn: db}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to initialize GORM: %v", err)
+	}
+
+	// Create an instance of hotelRepository
+	repo := NewRepository(gormDB)
+
+	jobID := uuid.New()
+
+	// Expectation: an unknown job yields no rows
+	mock.ExpectQuery(`(?i)^SELECT \* FROM ` + "`import_jobs`" + ` WHERE id = \?`).
+		WithArgs(jobID.String()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status"}))
+
+	_, err = repo.GetImportJob(jobID)
+	assert.ErrorIs(t, err, ErrImportJobNotFound)
+
+	// Ensure all expectations were met
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExportHotels_Repository(t *testing.T) {
+	// Set up mock database connection with sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to open mock database connection: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`(?i)^SELECT sqlite_version\(\)$`).WillReturnRows(sqlmock.NewRows([]string{"sqlite_version"}).AddRow("3.32.3"))
+
+	// Open GORM DB from mock sql.DB
+	gormDB, err := gorm.Open(sqlite.New(sqlite.Config{Conn: db}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to initialize GORM: %v", err)
+	}
+
+	// Create an instance of hotelRepository
+	repo := NewRepository(gormDB)
+
+	firstID, secondID := uuid.New(), uuid.New()
+	locationID, phoneID, emailID := uuid.New(), uuid.New(), uuid.New()
+
+	// One row per contact; hotels without contacts or location come back with NULLs
+	columns := []string{
+		"id", "owner_name", "owner_surname", "company_title", "version", "review_count", "average_score", "deleted_at",
+		"id", "country", "city", "district", "postal_code", "street", "latitude", "longitude",
+		"id", "info_type", "info_content",
+	}
+	mock.ExpectQuery(`(?i)^SELECT hotels.id, .* FROM ` + "`hotels`" + ` LEFT JOIN locations ON locations.hotel_id = hotels.id LEFT JOIN contact_infos ON contact_infos.hotel_id = hotels.id WHERE LOWER\(hotels.owner_name\) = LOWER\(\?\)` + notDeleted + ` ORDER BY hotels.company_title ASC, hotels.id ASC, contact_infos.id$`).
+		WithArgs("John").
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(firstID, "John", "Doe", "Alpha Inn", 2, 4, 8.5, nil, locationID, "Turkey", "Istanbul", "Besiktas", "34353", "Main St", 41.04, 29.0, phoneID, "phone", "+902121234567").
+			AddRow(firstID, "John", "Doe", "Alpha Inn", 2, 4, 8.5, nil, locationID, "Turkey", "Istanbul", "Besiktas", "34353", "Main St", 41.04, 29.0, emailID, "email", "info@alpha.example").
+			AddRow(secondID, "John", "Smith", "Beta Inn", 1, 0, 0.0, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil))
+
+	var hotels []Hotel
+	err = repo.ExportHotels(ListOptions{SortBy: "company_title", OwnerName: "John"}, func(hotel *Hotel) error {
+		hotels = append(hotels, *hotel)
+		return nil
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, hotels, 2) {
+		assert.Equal(t, firstID, hotels[0].ID)
+		assert.Equal(t, 8.5, hotels[0].AverageScore)
+		assert.Equal(t, "Istanbul", hotels[0].Location.City)
+		assert.Equal(t, []ContactInfo{
+			{ID: phoneID, HotelID: firstID, InfoType: "phone", InfoContent: "+902121234567"},
+			{ID: emailID, HotelID: firstID, InfoType: "email", InfoContent: "info@alpha.example"},
+		}, hotels[0].ContactInfos)
+		assert.Equal(t, secondID, hotels[1].ID)
+		assert.Nil(t, hotels[1].Location)
+		assert.Empty(t, hotels[1].ContactInfos)
+	}
+
+	// Ensure all expectations were met
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestCountContactColumns_Repository(t *testing.T) {
+	// Set up mock database connection with sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to open mock database connection: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`(?i)^SELECT sqlite_version\(\)$`).WillReturnRows(sqlmock.NewRows([]string{"sqlite_version"}).AddRow("3.32.3"))
+
+	// Open GORM DB from mock sql.DB
+	gormDB, err := gorm.Open(sqlite.New(sqlite.Config{Conn: db}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to initialize GORM: %v", err)
+	}
+
+	// Create an instance of hotelRepository
+	repo := NewRepository(gormDB)
+
+	mock.ExpectQuery(`(?i)^SELECT info_type, MAX\(contacts\) AS contacts FROM \(SELECT info_type, COUNT\(\*\) AS contacts FROM ` + "`contact_infos`" + ` WHERE hotel_id IN \(SELECT hotels.id FROM ` + "`hotels`" + ` WHERE .*\) GROUP BY hotel_id, info_type\) AS per_hotel GROUP BY ` + "`info_type`" + `$`).
+		WithArgs(ContactTypePhone).
+		WillReturnRows(sqlmock.NewRows([]string{"info_type", "contacts"}).AddRow("phone", 2).AddRow("email", 1))
+
+	columns, err := repo.CountContactColumns(ListOptions{ContactType: ContactTypePhone})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"phone": 2, "email": 1}, columns)
+
+	// Ensure all expectations were met
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestRemoveOfficial_NotFound_Repository(t *testing.T) {
+	// Set up mock database connection with sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to open mock database connection: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`(?i)^SELECT sqlite_version\(\)$`).WillReturnRows(sqlmock.NewRows([]string{"sqlite_version"}).AddRow("3.32.3"))
+
+	// Open GORM DB from mock sql.DB
+	gormDB, err := gorm.Open(sqlite.New(sqlite.Config{Conn: db}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to initialize GORM: %v", err)
+	}
+
+	// Create an instance of hotelRepository
+	repo := NewRepository(gormDB)
+
+	hotelID, officialID := uuid.New(), uuid.New()
+
+	// Expectation: the hotel version is bumped but the official belongs to another hotel
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE ` + "`hotels`" + ` SET ` + "`version`" + `=version \+ 1 WHERE id = \?`).
+		WithArgs(hotelID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM `+"`hotel_officials`"+` WHERE id = \? AND hotel_id = \?`).
+		WithArgs(officialID, hotelID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err = repo.RemoveOfficial(hotelID, officialID, 0)
+	assert.ErrorIs(t, err, ErrOfficialNotFound)
+
+	// Ensure all expectations were met
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestFetchRoomCapacity_Repository(t *testing.T) {
+	// Set up mock database connection with sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to open mock database connection: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`(?i)^SELECT sqlite_version\(\)$`).WillReturnRows(sqlmock.NewRows([]string{"sqlite_version"}).AddRow("3.32.3"))
+
+	// Open GORM DB from mock sql.DB
+	gormDB, err := gorm.Open(sqlite.New(sqlite.Config{Conn: db}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to initialize GORM: %v", err)
+	}
+
+	// Create an instance of hotelRepository
+	repo := NewRepository(gormDB)
+
+	// Expectation: rooms and beds are summed over the room types of the matching hotels
+	mock.ExpectQuery(`(?i)^SELECT COALESCE\(SUM\(room_types.room_count\), 0\) AS rooms, COALESCE\(SUM\(room_types.room_count \* room_types.bed_count\), 0\) AS beds FROM ` + "`hotels`" +
+		` JOIN locations ON locations.hotel_id = hotels.id JOIN room_types ON room_types.hotel_id = hotels.id WHERE LOWER\(locations.city\) = LOWER\(\?\)` + notDeleted + `$`).
+		WithArgs("Istanbul").
+		WillReturnRows(sqlmock.NewRows([]string{"rooms", "beds"}).AddRow(42, 70))
+
+	rooms, beds, err := repo.FetchRoomCapacity(LocationFilter{City: "Istanbul"})
+	assert.NoError(t, err)
+	assert.Equal(t, 42, rooms)
+	assert.Equal(t, 70, beds)
+
+	// Ensure all expectations were met
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestFetchNightlyPrices_Repository(t *testing.T) {
+	// Set up mock database connection with sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to open mock database connection: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`(?i)^SELECT sqlite_version\(\)$`).WillReturnRows(sqlmock.NewRows([]string{"sqlite_version"}).AddRow("3.32.3"))
+
+	// Open GORM DB from mock sql.DB
+	gormDB, err := gorm.Open(sqlite.New(sqlite.Config{Conn: db}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to initialize GORM: %v", err)
+	}
+
+	// Create an instance of hotelRepository
+	repo := NewRepository(gormDB)
+
+	// Expectation: only room only rate plans in effect on the day are priced
+	hotelID := uuid.New()
+	on := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`(?i)^SELECT rate_plans.hotel_id, rate_plans.base_price, rate_plans.currency FROM `+"`hotels`"+
+		` JOIN locations ON locations.hotel_id = hotels.id JOIN rate_plans ON rate_plans.hotel_id = hotels.id WHERE LOWER\(locations.city\) = LOWER\(\?\)`+
+		` AND \(rate_plans.meal_plan = \? AND rate_plans.valid_from <= \? AND rate_plans.valid_to > \?\)`+notDeleted+`$`).
+		WithArgs("Istanbul", MealPlanRoomOnly, on, on).
+		WillReturnRows(sqlmock.NewRows([]string{"hotel_id", "base_price", "currency"}).
+			AddRow(hotelID.String(), "120.50", "EUR").
+			AddRow(hotelID.String(), "3000", "TRY"))
+
+	prices, err := repo.FetchNightlyPrices(LocationFilter{City: "Istanbul"}, on)
+	assert.NoError(t, err)
+	assert.Equal(t, []HotelPrice{
+		{HotelID: hotelID, Price: money.Amount{Value: money.MustParse("120.50"), Currency: "EUR"}},
+		{HotelID: hotelID, Price: money.Amount{Value: money.MustParse("3000"), Currency: "TRY"}},
+	}, prices)
+
+	// Ensure all expectations were met
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestFailImportJobs_Repository(t *testing.T) {
+	gormDB := openReservationDB(t)
+	if err := gormDB.AutoMigrate(&ImportJob{}); err != nil {
+		t.Fatalf("Failed to migrate SQLite database: %v", err)
+	}
+	repo := NewRepository(gormDB)
+
+	running := &ImportJob{ID: uuid.New(), Status: ImportInProgress, Errors: ImportErrors{}}
+	completed := &ImportJob{ID: uuid.New(), Status: ImportCompleted, Errors: ImportErrors{}}
+	for _, job := range []*ImportJob{running, completed} {
+		if err := repo.CreateImportJob(job); err != nil {
+			t.Fatalf("Failed to create import job: %v", err)
+		}
+	}
+
+	failed, err := repo.FailImportJobs("interrupted", time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), failed)
+
+	// Only the job in progress is failed
+	job, err := repo.GetImportJob(running.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, ImportFailed, job.Status)
+	assert.Equal(t, "interrupted", job.Error)
+	assert.NotNil(t, job.FinishedAt)
+
+	job, err = repo.GetImportJob(completed.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, ImportCompleted, job.Status)
+	assert.Nil(t, job.FinishedAt)
+}
+
+// openReservationDB opens a SQLite database file with the room type and
+// reservation tables. Transactions take the database lock when they begin, as
+// SQLite has no row locks to take in CreateReservation.
+func openReservationDB(t *testing.T) *gorm.DB {
+	gormDB, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "hotels.db")+"?_txlock=immediate&_busy_timeout=5000&_foreign_keys=1"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("Failed to open SQLite database: %v", err)
+	}
+	if err := gormDB.AutoMigrate(&RoomType{}, &Reservation{}); err != nil {
+		t.Fatalf("Failed to migrate SQLite database: %v", err)
+	}
+	return gormDB
+}
+
+func TestCreateReservation_Repository_Concurrent(t *testing.T) {
+	gormDB := openReservationDB(t)
+	repo := NewRepository(gormDB)
+
+	roomType := &RoomType{ID: uuid.New(), HotelID: uuid.New(), Name: "Twin", Capacity: 2, RoomCount: 3}
+	if err := gormDB.Create(roomType).Error; err != nil {
+		t.Fatalf("Failed to create room type: %v", err)
+	}
+
+	// Ten guests try to book one of the three rooms at the same time
+	now := time.Now().UTC()
+	checkIn := day(now).AddDate(0, 0, 7)
+	errs := make(chan error, 10)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- repo.CreateReservation(&Reservation{
+				ID:         uuid.New(),
+				HotelID:    roomType.HotelID,
+				RoomTypeID: roomType.ID,
+				CheckIn:    checkIn,
+				CheckOut:   checkIn.AddDate(0, 0, 2),
+				Rooms:      1,
+				Status:     ReservationBooked,
+				CreatedAt:  now,
+			}, now)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	booked := 0
+	for err := range errs {
+		if err == nil {
+			booked++
+		} else {
+			assert.ErrorIs(t, err, ErrNotAvailable)
+		}
+	}
+	assert.Equal(t, 3, booked)
+
+	reservations, err := repo.ListActiveReservations([]uuid.UUID{roomType.ID}, checkIn, checkIn.AddDate(0, 0, 2), now)
+	assert.NoError(t, err)
+	assert.Len(t, reservations, 3)
+}
+
+func TestConfirmAndCancelReservation_Repository(t *testing.T) {
+	gormDB := openReservationDB(t)
+	repo := NewRepository(gormDB)
+
+	roomType := &RoomType{ID: uuid.New(), HotelID: uuid.New(), Name: "Twin", Capacity: 2, RoomCount: 1}
+	if err := gormDB.Create(roomType).Error; err != nil {
+		t.Fatalf("Failed to create room type: %v", err)
+	}
+
+	now := time.Now().UTC()
+	checkIn := day(now).AddDate(0, 0, 1)
+	expiresAt := now.Add(HoldDuration)
+	hold := &Reservation{
+		ID: uuid.New(), HotelID: roomType.HotelID, RoomTypeID: roomType.ID,
+		CheckIn: checkIn, CheckOut: checkIn.AddDate(0, 0, 1), Rooms: 1,
+		Status: ReservationHold, ExpiresAt: &expiresAt, CreatedAt: now,
+	}
+	assert.NoError(t, repo.CreateReservation(hold, now))
+
+	// The hold keeps the only room until it expires
+	other := *hold
+	other.ID = uuid.New()
+	assert.ErrorIs(t, repo.CreateReservation(&other, now), ErrNotAvailable)
+	assert.ErrorIs(t, repo.ConfirmReservation(hold.HotelID, hold.ID, expiresAt.Add(time.Second)), ErrHoldExpired)
+
+	assert.NoError(t, repo.ConfirmReservation(hold.HotelID, hold.ID, now))
+	assert.ErrorIs(t, repo.ConfirmReservation(hold.HotelID, hold.ID, now), ErrNotHold)
+
+	// Booked rooms are not released when the hold would have expired
+	assert.ErrorIs(t, repo.CreateReservation(&other, expiresAt.Add(time.Second)), ErrNotAvailable)
+
+	assert.NoError(t, repo.CancelReservation(hold.HotelID, hold.ID))
+	assert.NoError(t, repo.CancelReservation(hold.HotelID, hold.ID))
+	assert.ErrorIs(t, repo.CancelReservation(hold.HotelID, uuid.New()), ErrReservationNotFound)
+	assert.NoError(t, repo.CreateReservation(&other, now))
+}
+
+func TestRatePlans_Repository(t *testing.T) {
+	gormDB := openReservationDB(t)
+	if err := gormDB.AutoMigrate(&RatePlan{}, &ExchangeRate{}); err != nil {
+		t.Fatalf("Failed to migrate SQLite database: %v", err)
+	}
+	// Hotels only need the columns of their version, the full model uses PostgreSQL defaults
+	hotelID := uuid.New()
+	if err := gormDB.Exec("CREATE TABLE hotels (id TEXT PRIMARY KEY, version INTEGER, deleted_at DATETIME)").Error; err != nil {
+		t.Fatalf("Failed to create hotels table: %v", err)
+	}
+	if err := gormDB.Exec("INSERT INTO hotels (id, version) VALUES (?, 1)", hotelID).Error; err != nil {
+		t.Fatalf("Failed to create hotel: %v", err)
+	}
+	repo := NewRepository(gormDB)
+
+	roomType := &RoomType{ID: uuid.New(), HotelID: hotelID, Name: "Twin", Capacity: 2, RoomCount: 3}
+	if err := gormDB.Create(roomType).Error; err != nil {
+		t.Fatalf("Failed to create room type: %v", err)
+	}
+
+	june := time.Date(2030, time.June, 1, 0, 0, 0, 0, time.UTC)
+	summer := &RatePlan{HotelID: hotelID, RoomTypeID: roomType.ID, Name: "Summer", ValidFrom: june, ValidTo: june.AddDate(0, 3, 0),
+		BasePrice: money.MustParse("120.125"), Currency: "EUR", MealPlan: MealPlanRoomOnly}
+	assert.NoError(t, repo.AddRatePlan(summer, 1))
+
+	// Plans of the same meal plan must not overlap, other meal plans may
+	overlapping := &RatePlan{HotelID: hotelID, RoomTypeID: roomType.ID, Name: "August", ValidFrom: june.AddDate(0, 2, 0), ValidTo: june.AddDate(0, 4, 0),
+		BasePrice: money.MustParse("150"), Currency: "EUR", MealPlan: MealPlanRoomOnly}
+	assert.ErrorIs(t, repo.AddRatePlan(overlapping, 2), ErrRatePlanOverlap)
+	overlapping.MealPlan = MealPlanBreakfast
+	assert.NoError(t, repo.AddRatePlan(overlapping, 2))
+	assert.ErrorIs(t, repo.AddRatePlan(&RatePlan{HotelID: hotelID, RoomTypeID: uuid.New(), ValidFrom: june, ValidTo: june.AddDate(0, 0, 1)}, 3), ErrRoomTypeNotFound)
+
+	// Prices keep their decimals
+	plans, err := repo.ListRatePlansForStay(hotelID, roomType.ID, MealPlanRoomOnly, june.AddDate(0, 0, 10), june.AddDate(0, 0, 12))
+	if assert.NoError(t, err) && assert.Len(t, plans, 1) {
+		assert.Equal(t, "120.125", plans[0].BasePrice.String())
+	}
+
+	// The latest rate in effect is found in either direction
+	for _, rate := range []ExchangeRate{
+		{BaseCurrency: "EUR", QuoteCurrency: "TRY", EffectiveDate: june, Rate: money.MustParse("35.5")},
+		{BaseCurrency: "EUR", QuoteCurrency: "TRY", EffectiveDate: june.AddDate(0, 1, 0), Rate: money.MustParse("36.25")},
+	} {
+		assert.NoError(t, repo.SaveExchangeRate(&rate))
+	}
+	rate, err := repo.FindExchangeRate("TRY", "EUR", june.AddDate(0, 0, 15))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "35.5", rate.Rate.String())
+	}
+	rate, err = repo.FindExchangeRate("EUR", "TRY", june.AddDate(0, 2, 0))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "36.25", rate.Rate.String())
+	}
+	_, err = repo.FindExchangeRate("EUR", "TRY", june.AddDate(0, 0, -1))
+	assert.ErrorIs(t, err, ErrNoExchangeRate)
+}
+
+func TestSetReviewStatus_Repository(t *testing.T) {
+	// Set up mock database connection with sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to open mock database connection: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`(?i)^SELECT sqlite_version\(\)$`).WillReturnRows(sqlmock.NewRows([]string{"sqlite_version"}).AddRow("3.32.3"))
+
+	// Open GORM DB from mock sql.DB
+	gormDB, err := gorm.Open(sqlite.New(sqlite.Config{Conn: db}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to initialize GORM: %v", err)
+	}
+
+	// Create an instance of hotelRepository
+	repo := NewRepository(gormDB)
+
+	hotelID, reviewID := uuid.New(), uuid.New()
+
+	// Expectation: approving a pending review adds its score to the rating of the hotel
+	mock.ExpectBegin()
+	mock.ExpectQuery(`(?i)^SELECT \* FROM `+"`reviews`"+` WHERE id = \? AND hotel_id = \? ORDER BY`).
+		WithArgs(reviewID, hotelID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "hotel_id", "score", "status"}).AddRow(reviewID, hotelID, 8, ReviewPending))
+	mock.ExpectExec(`(?i)^UPDATE `+"`reviews`"+` SET `+"`status`"+`=\? WHERE`).
+		WithArgs(ReviewApproved, reviewID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`(?i)^UPDATE `+"`hotels`"+` SET `+"`average_score`"+`=CASE WHEN review_count \+ \? > 0 THEN \(score_total \+ \?\) \* 1.0 / \(review_count \+ \?\) ELSE 0 END,`+
+		"`review_count`"+`=review_count \+ \?,`+"`score_total`"+`=score_total \+ \? WHERE id = \?$`).
+		WithArgs(1, 8, 1, 1, 8, hotelID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, repo.SetReviewStatus(hotelID, reviewID, ReviewApproved))
+
+	// Expectation: unknown reviews are reported and nothing is changed
+	mock.ExpectBegin()
+	mock.ExpectQuery(`(?i)^SELECT \* FROM ` + "`reviews`").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectRollback()
+
+	assert.ErrorIs(t, repo.SetReviewStatus(hotelID, uuid.New(), ReviewRejected), ErrReviewNotFound)
+
+	// Ensure all expectations were met
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestListHotels_Repository_Rating(t *testing.T) {
+	// Set up mock database connection with sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to open mock database connection: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`(?i)^SELECT sqlite_version\(\)$`).WillReturnRows(sqlmock.NewRows([]string{"sqlite_version"}).AddRow("3.32.3"))
+
+	// Open GORM DB from mock sql.DB
+	gormDB, err := gorm.Open(sqlite.New(sqlite.Config{Conn: db}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to initialize GORM: %v", err)
+	}
+
+	// Create an instance of hotelRepository
+	repo := NewRepository(gormDB)
+
+	// Expectation: the best rated hotels with at least the minimum rating come first
+	mock.ExpectQuery(`(?i)^SELECT count\(\*\) FROM ` + "`hotels`" + ` WHERE hotels.average_score >= \?` + notDeleted + `$`).
+		WithArgs(8.0).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(`(?i)^SELECT \* FROM ` + "`hotels`" + ` WHERE hotels.average_score >= \?` + notDeleted + ` ORDER BY hotels.average_score DESC, hotels.id DESC LIMIT 21$`).
+		WithArgs(8.0).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	opts := ListOptions{SortBy: "rating", SortDesc: true, MinRating: 8}
+	assert.NoError(t, opts.normalize())
+	hotels, total, err := repo.ListHotels(opts)
+	assert.NoError(t, err)
+	assert.Empty(t, hotels)
+	assert.Zero(t, total)
+
+	// Ensure all expectations were met
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestDeleteTag_InUse_Repository(t *testing.T) {
+	// Set up mock database connection with sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to open mock database connection: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`(?i)^SELECT sqlite_version\(\)$`).WillReturnRows(sqlmock.NewRows([]string{"sqlite_version"}).AddRow("3.32.3"))
+
+	// Open GORM DB from mock sql.DB
+	gormDB, err := gorm.Open(sqlite.New(sqlite.Config{Conn: db}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to initialize GORM: %v", err)
+	}
+
+	// Create an instance of hotelRepository
+	repo := NewRepository(gormDB)
+
+	// A tag some hotels still have is not deleted
+	mock.ExpectBegin()
+	mock.ExpectQuery(`(?i)^SELECT count\(\*\) FROM ` + "`hotel_tags`" + ` WHERE tag_name = \?`).
+		WithArgs("pool").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectRollback()
+
+	err = repo.DeleteTag("pool")
+	assert.ErrorIs(t, err, ErrTagInUse)
+
+	// Ensure all expectations were met
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestCountFacets_Repository(t *testing.T) {
+	// Set up mock database connection with sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to open mock database connection: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`(?i)^SELECT sqlite_version\(\)$`).WillReturnRows(sqlmock.NewRows([]string{"sqlite_version"}).AddRow("3.32.3"))
+
+	// Open GORM DB from mock sql.DB
+	gormDB, err := gorm.Open(sqlite.New(sqlite.Config{Conn: db}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to initialize GORM: %v", err)
+	}
+
+	// Create an instance of hotelRepository
+	repo := NewRepository(gormDB)
+
+	// Expectation: only hotels with every tag of the filter are counted
+	tagFilter := ` WHERE hotels.id IN \(SELECT hotel_id FROM hotel_tags WHERE tag_name IN \(\?,\?\) GROUP BY hotel_id HAVING COUNT\(\*\) = \?\)` + notDeleted
+	mock.ExpectQuery(`(?i)^SELECT hotel_tags.tag_name AS value, COUNT\(\*\) AS count FROM `+"`hotels`"+
+		` JOIN hotel_tags ON hotel_tags.hotel_id = hotels.id`+tagFilter+` GROUP BY `+"`hotel_tags`.`tag_name`"+` ORDER BY count DESC, value$`).
+		WithArgs("parking", "pool", 2).
+		WillReturnRows(sqlmock.NewRows([]string{"value", "count"}).AddRow("parking", 3).AddRow("pool", 3).AddRow("spa", 1))
+	mock.ExpectQuery(`(?i)^SELECT locations.country AS value, COUNT\(\*\) AS count FROM `+"`hotels`"+
+		` JOIN locations ON locations.hotel_id = hotels.id`+tagFilter+` GROUP BY `+"`locations`.`country`"+` ORDER BY count DESC, value$`).
+		WithArgs("parking", "pool", 2).
+		WillReturnRows(sqlmock.NewRows([]string{"value", "count"}).AddRow("Turkey", 3))
+	mock.ExpectQuery(`(?i)^SELECT locations.country AS country, locations.city AS value, COUNT\(\*\) AS count FROM `+"`hotels`"+
+		` JOIN locations ON locations.hotel_id = hotels.id`+tagFilter+` GROUP BY `+"`locations`.`country`,`locations`.`city`"+` ORDER BY count DESC, value, country$`).
+		WithArgs("parking", "pool", 2).
+		WillReturnRows(sqlmock.NewRows([]string{"country", "value", "count"}).AddRow("Egypt", "Alexandria", 2).AddRow("United States", "Alexandria", 1))
+
+	facets, err := repo.CountFacets(ListOptions{Tags: []string{"parking", "pool"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []FacetCount{{"parking", 3}, {"pool", 3}, {"spa", 1}}, facets.Tags)
+	assert.Equal(t, []FacetCount{{"Turkey", 3}}, facets.Countries)
+	// Cities of the same name are counted per country
+	assert.Equal(t, []CityFacetCount{{"Egypt", "Alexandria", 2}, {"United States", "Alexandria", 1}}, facets.Cities)
+
+	// Ensure all expectations were met
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestReorderMedia_Repository_Invalid(t *testing.T) {
+	// Set up mock database connection with sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to open mock database connection: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`(?i)^SELECT sqlite_version\(\)$`).WillReturnRows(sqlmock.NewRows([]string{"sqlite_version"}).AddRow("3.32.3"))
+
+	// Open GORM DB from mock sql.DB
+	gormDB, err := gorm.Open(sqlite.New(sqlite.Config{Conn: db}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to initialize GORM: %v", err)
+	}
+
+	// Create an instance of hotelRepository
+	repo := NewRepository(gormDB)
+
+	// Expectation: an order missing one of the media of the hotel is rejected
+	hotelID, first, second := uuid.New(), uuid.New(), uuid.New()
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE `+"`hotels`"+` SET `+"`version`"+`=version \+ 1 WHERE id = \? AND version = \?`+notDeleted).
+		WithArgs(hotelID.String(), 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`(?i)^SELECT ` + "`id`" + ` FROM ` + "`hotel_media`" + ` WHERE hotel_id = \?$`).
+		WithArgs(hotelID.String()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(first.String()).AddRow(second.String()))
+	mock.ExpectRollback()
+
+	err = repo.ReorderMedia(hotelID, []uuid.UUID{second}, 2)
+	assert.ErrorIs(t, err, ErrInvalidMediaOrder)
+
+	// Ensure all expectations were met
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestListMissingTranslations_Repository(t *testing.T) {
+	// Set up mock database connection with sqlmock
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to open mock database connection: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`(?i)^SELECT sqlite_version\(\)$`).WillReturnRows(sqlmock.NewRows([]string{"sqlite_version"}).AddRow("3.32.3"))
+
+	// Open GORM DB from mock sql.DB
+	gormDB, err := gorm.Open(sqlite.New(sqlite.Config{Conn: db}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to initialize GORM: %v", err)
+	}
+
+	// Create an instance of hotelRepository
+	repo := NewRepository(gormDB)
+
+	// Expectation: hotels with a complete translation into every language are left out
+	missing := ` WHERE hotels.id NOT IN \(SELECT ` + "`hotel_id`" + ` FROM ` + "`hotel_translations`" +
+		` WHERE language IN \(\?,\?\) AND company_title <> '' AND description <> '' AND marketing_text <> '' GROUP BY ` + "`hotel_id`" +
+		` HAVING COUNT\(\*\) = \?\)` + notDeleted
+	hotelID := uuid.New()
+	mock.ExpectQuery(`(?i)^SELECT count\(\*\) FROM `+"`hotels`"+missing+`$`).
+		WithArgs("tr", "de", 2).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(`(?i)^SELECT \* FROM `+"`hotels`"+missing+` ORDER BY hotels.id LIMIT 20 OFFSET 20$`).
+		WithArgs("tr", "de", 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "company_title"}).AddRow(hotelID.String(), "Pera Palace"))
+	mock.ExpectQuery(`(?i)^SELECT \* FROM ` + "`hotel_translations`" + ` WHERE ` + "`hotel_translations`.`hotel_id`" + ` = \?$`).
+		WithArgs(hotelID.String()).
+		WillReturnRows(sqlmock.NewRows([]string{"hotel_id", "language", "company_title"}).AddRow(hotelID.String(), "de", "Pera Palast"))
+
+	hotels, total, err := repo.ListMissingTranslations(MissingTranslationFilter{Languages: []string{"tr", "de"}, Limit: 20, Offset: 20})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	if assert.Len(t, hotels, 1) {
+		assert.Equal(t, "Pera Palast", hotels[0].Translations[0].CompanyTitle)
+	}
+
+	// Ensure all expectations were met
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestChains_Repository(t *testing.T) {
+	gormDB := openReservationDB(t)
+	if err := gormDB.AutoMigrate(&Chain{}); err != nil {
+		t.Fatalf("Failed to migrate SQLite database: %v", err)
+	}
+	// Hotels, locations and contacts only need the columns that are counted
+	for _, statement := range []string{
+		"CREATE TABLE hotels (id TEXT PRIMARY KEY, version INTEGER, chain_id TEXT, deleted_at DATETIME)",
+		"CREATE TABLE locations (id TEXT PRIMARY KEY, hotel_id TEXT, country TEXT, city TEXT, district TEXT)",
+		"CREATE TABLE contact_infos (id TEXT PRIMARY KEY, hotel_id TEXT, info_type TEXT, info_content TEXT)",
+	} {
+		if err := gormDB.Exec(statement).Error; err != nil {
+			t.Fatalf("Failed to create table: %v", err)
+		}
+	}
+	repo := NewRepository(gormDB)
+
+	group := &Chain{Name: "Hilton", Kind: ChainKindGroup}
+	assert.NoError(t, repo.CreateChain(group))
+	brand := &Chain{Name: "Hampton", Kind: ChainKindBrand, ParentID: &group.ID}
+	assert.NoError(t, repo.CreateChain(brand))
+	assert.ErrorIs(t, repo.CreateChain(&Chain{Name: "HILTON", Kind: ChainKindGroup}), ErrChainExists)
+
+	stored, err := repo.GetChain(group.ID)
+	if assert.NoError(t, err) && assert.Len(t, stored.Brands, 1) {
+		assert.Equal(t, "Hampton", stored.Brands[0].Name)
+	}
+
+	// Hotels of the brand count towards the group, deleted hotels do not
+	hotelIDs := []uuid.UUID{uuid.New(), uuid.New(), uuid.New(), uuid.New()}
+	for _, statement := range []struct {
+		sql  string
+		args []interface{}
+	}{
+		{"INSERT INTO hotels (id, version, chain_id) VALUES (?, 1, ?)", []interface{}{hotelIDs[0], group.ID}},
+		{"INSERT INTO hotels (id, version, chain_id) VALUES (?, 1, ?)", []interface{}{hotelIDs[1], brand.ID}},
+		{"INSERT INTO hotels (id, version) VALUES (?, 1)", []interface{}{hotelIDs[2]}},
+		{"INSERT INTO hotels (id, version, chain_id, deleted_at) VALUES (?, 1, ?, CURRENT_TIMESTAMP)", []interface{}{hotelIDs[3], brand.ID}},
+		{"INSERT INTO locations (id, hotel_id, country, city) VALUES (?, ?, 'Turkey', 'Istanbul')", []interface{}{uuid.New(), hotelIDs[0]}},
+		{"INSERT INTO locations (id, hotel_id, country, city) VALUES (?, ?, 'Turkey', 'Istanbul')", []interface{}{uuid.New(), hotelIDs[2]}},
+		{"INSERT INTO contact_infos (id, hotel_id, info_type) VALUES (?, ?, 'phone')", []interface{}{uuid.New(), hotelIDs[0]}},
+		{"INSERT INTO contact_infos (id, hotel_id, info_type) VALUES (?, ?, 'phone')", []interface{}{uuid.New(), hotelIDs[0]}},
+		{"INSERT INTO contact_infos (id, hotel_id, info_type) VALUES (?, ?, 'email')", []interface{}{uuid.New(), hotelIDs[1]}},
+	} {
+		if err := gormDB.Exec(statement.sql, statement.args...).Error; err != nil {
+			t.Fatalf("Failed to insert test data: %v", err)
+		}
+	}
+
+	count, err := repo.CountHotels(LocationFilter{ChainID: group.ID})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+	count, err = repo.CountHotels(LocationFilter{ChainID: brand.ID, City: "Istanbul"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+
+	cities, err := repo.CountHotelsByCity(LocationFilter{ChainID: group.ID})
+	assert.NoError(t, err)
+	assert.Equal(t, []LocationCount{{Country: "Turkey", City: "Istanbul", HotelCount: 1}}, cities)
+
+	byType, err := repo.CountHotelsByContactType(LocationFilter{ChainID: group.ID})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int64{ContactTypePhone: 1, ContactTypeEmail: 1}, byType)
+
+	// Chains with brands or hotels, even deleted ones, cannot be deleted
+	assert.ErrorIs(t, repo.DeleteChain(group.ID), ErrChainInUse)
+	assert.ErrorIs(t, repo.DeleteChain(brand.ID), ErrChainInUse)
+	assert.NoError(t, repo.SetHotelChain(hotelIDs[0], nil, 1))
+	assert.ErrorIs(t, repo.SetHotelChain(hotelIDs[0], &group.ID, 1), ErrVersionConflict)
+	assert.ErrorIs(t, repo.DeleteChain(uuid.New()), ErrChainNotFound)
+}
+
+func TestListLocationStats_Repository(t *testing.T) {
+	gormDB := openReservationDB(t)
+	// Hotels, locations and contacts only need the columns that are counted
+	for _, statement := range []string{
+		"CREATE TABLE hotels (id TEXT PRIMARY KEY, version INTEGER, chain_id TEXT, deleted_at DATETIME)",
+		"CREATE TABLE locations (id TEXT PRIMARY KEY, hotel_id TEXT, country TEXT, city TEXT, district TEXT)",
+		"CREATE TABLE contact_infos (id TEXT PRIMARY KEY, hotel_id TEXT, info_type TEXT, info_content TEXT)",
+	} {
+		if err := gormDB.Exec(statement).Error; err != nil {
+			t.Fatalf("Failed to create table: %v", err)
+		}
+	}
+	repo := NewRepository(gormDB)
+	insert := func(statement string, args ...interface{}) {
+		if err := gormDB.Exec(statement, args...).Error; err != nil {
+			t.Fatalf("Failed to insert test data: %v", err)
+		}
+	}
+
+	// Three hotels in Istanbul, two in Izmir, one in Paris and a deleted one in Paris
+	for _, location := range []struct {
+		country, city, district string
+		phones                  int
+		deleted                 bool
+	}{
+		{"Turkey", "Istanbul", "Kadikoy", 2, false},
+		{"Turkey", "Istanbul", "Kadikoy", 1, false},
+		{"Turkey", "Istanbul", "Besiktas", 0, false},
+		{"Turkey", "Izmir", "Konak", 1, false},
+		{"Turkey", "Izmir", "Konak", 1, false},
+		{"France", "Paris", "Marais", 1, false},
+		{"France", "Paris", "Marais", 4, true},
+	} {
+		hotelID := uuid.New()
+		var deletedAt *time.Time
+		if location.deleted {
+			now := time.Now()
+			deletedAt = &now
+		}
+		insert("INSERT INTO hotels (id, version, deleted_at) VALUES (?, 1, ?)", hotelID, deletedAt)
+		insert("INSERT INTO locations (id, hotel_id, country, city, district) VALUES (?, ?, ?, ?, ?)", uuid.New(), hotelID, location.country, location.city, location.district)
+		// Emails are not phones
+		insert("INSERT INTO contact_infos (id, hotel_id, info_type, info_content) VALUES (?, ?, 'email', 'desk@example.com')", uuid.New(), hotelID)
+		for i := 0; i < location.phones; i++ {
+			insert("INSERT INTO contact_infos (id, hotel_id, info_type, info_content) VALUES (?, ?, 'phone', '+902125550100')", uuid.New(), hotelID)
+		}
+	}
+
+	phoneTypes := []string{ContactTypePhone}
+	opts := LocationStatsOptions{Level: LocationLevelCity, SortBy: "hotel_count", SortDesc: true, Limit: 2}
+	summaries, total, err := repo.ListLocationStats(opts, phoneTypes)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+	assert.Equal(t, []LocationSummary{
+		{Country: "Turkey", City: "Istanbul", HotelCount: 3, PhoneCount: 3},
+		{Country: "Turkey", City: "Izmir", HotelCount: 2, PhoneCount: 2},
+	}, summaries)
+
+	// Locations below the threshold are left out of the page and the total
+	opts = LocationStatsOptions{Level: LocationLevelCountry, MinHotels: 2, SortBy: "name", Limit: 10}
+	summaries, total, err = repo.ListLocationStats(opts, phoneTypes)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, []LocationSummary{{Country: "Turkey", HotelCount: 5, PhoneCount: 5}}, summaries)
+
+	// Districts are grouped within their city and can be filtered by location
+	opts = LocationStatsOptions{Level: LocationLevelDistrict, Location: LocationFilter{City: "istanbul"}, SortBy: "phone_count", SortDesc: true, Limit: 10}
+	summaries, total, err = repo.ListLocationStats(opts, phoneTypes)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Equal(t, []LocationSummary{
+		{Country: "Turkey", City: "Istanbul", District: "Kadikoy", HotelCount: 2, PhoneCount: 3},
+		{Country: "Turkey", City: "Istanbul", District: "Besiktas", HotelCount: 1, PhoneCount: 0},
+	}, summaries)
+
+	// Past the last page the locations are still counted
+	opts = LocationStatsOptions{Level: LocationLevelCity, SortBy: "hotel_count", Limit: 2, Offset: 4}
+	summaries, total, err = repo.ListLocationStats(opts, phoneTypes)
+	assert.NoError(t, err)
+	assert.Empty(t, summaries)
+	assert.Equal(t, int64(3), total)
+}
+
+func TestRemoveRoomType_Repository_Bookings(t *testing.T) {
+	gormDB := openReservationDB(t)
+	if err := gormDB.Exec("CREATE TABLE hotels (id TEXT PRIMARY KEY, version INTEGER, deleted_at DATETIME)").Error; err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	repo := NewRepository(gormDB)
+
+	roomType := &RoomType{ID: uuid.New(), HotelID: uuid.New(), Name: "Twin", Capacity: 2, RoomCount: 2}
+	gormDB.Exec("INSERT INTO hotels (id, version) VALUES (?, 1)", roomType.HotelID)
+	if err := gormDB.Create(roomType).Error; err != nil {
+		t.Fatalf("Failed to create room type: %v", err)
+	}
+
+	now := time.Now().UTC()
+	checkIn := day(now).AddDate(0, 0, 3)
+	booking := &Reservation{
+		ID: uuid.New(), HotelID: roomType.HotelID, RoomTypeID: roomType.ID,
+		CheckIn: checkIn, CheckOut: checkIn.AddDate(0, 0, 2), Rooms: 1,
+		Status: ReservationBooked, CreatedAt: now,
+	}
+	past := &Reservation{
+		ID: uuid.New(), HotelID: roomType.HotelID, RoomTypeID: roomType.ID,
+		CheckIn: checkIn.AddDate(0, 0, -30), CheckOut: checkIn.AddDate(0, 0, -28), Rooms: 1,
+		Status: ReservationBooked, CreatedAt: now,
+	}
+	for _, reservation := range []*Reservation{booking, past} {
+		if err := gormDB.Create(reservation).Error; err != nil {
+			t.Fatalf("Failed to create reservation: %v", err)
+		}
+	}
+
+	// A booking that has not ended keeps the room type
+	err := repo.RemoveRoomType(roomType.HotelID, roomType.ID, 1, now)
+	assert.ErrorIs(t, err, ErrRoomTypeInUse)
+	_, err = repo.GetRoomType(roomType.HotelID, roomType.ID)
+	assert.NoError(t, err)
+
+	// Once it is cancelled, the room type goes with all of its reservations
+	assert.NoError(t, repo.CancelReservation(roomType.HotelID, booking.ID))
+	assert.NoError(t, repo.RemoveRoomType(roomType.HotelID, roomType.ID, 1, now))
+
+	var reservations int64
+	gormDB.Model(&Reservation{}).Where("room_type_id = ?", roomType.ID).Count(&reservations)
+	assert.Equal(t, int64(0), reservations)
+}
+
+func TestUpdateRoomType_Repository_Reserved(t *testing.T) {
+	gormDB := openReservationDB(t)
+	if err := gormDB.Exec("CREATE TABLE hotels (id TEXT PRIMARY KEY, version INTEGER, deleted_at DATETIME)").Error; err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	repo := NewRepository(gormDB)
+
+	roomType := &RoomType{ID: uuid.New(), HotelID: uuid.New(), Name: "Twin", Capacity: 2, RoomCount: 5}
+	gormDB.Exec("INSERT INTO hotels (id, version) VALUES (?, 1)", roomType.HotelID)
+	if err := gormDB.Create(roomType).Error; err != nil {
+		t.Fatalf("Failed to create room type: %v", err)
+	}
+
+	// The bookings keep two rooms on the first night, three on the second and
+	// one on the third; expired holds and cancelled reservations keep none
+	now := time.Now().UTC()
+	checkIn := day(now).AddDate(0, 0, 3)
+	expired := now.Add(-time.Minute)
+	for _, reservation := range []*Reservation{
+		{CheckIn: checkIn, CheckOut: checkIn.AddDate(0, 0, 2), Rooms: 2, Status: ReservationBooked},
+		{CheckIn: checkIn.AddDate(0, 0, 1), CheckOut: checkIn.AddDate(0, 0, 3), Rooms: 1, Status: ReservationBooked},
+		{CheckIn: checkIn, CheckOut: checkIn.AddDate(0, 0, 2), Rooms: 2, Status: ReservationHold, ExpiresAt: &expired},
+		{CheckIn: checkIn, CheckOut: checkIn.AddDate(0, 0, 2), Rooms: 2, Status: ReservationCancelled},
+	} {
+		reservation.ID, reservation.HotelID, reservation.RoomTypeID, reservation.CreatedAt = uuid.New(), roomType.HotelID, roomType.ID, now
+		if err := gormDB.Create(reservation).Error; err != nil {
+			t.Fatalf("Failed to create reservation: %v", err)
+		}
+	}
+
+	// Below the three rooms of the second night the room count is refused
+	shrunk := *roomType
+	shrunk.RoomCount = 2
+	assert.ErrorIs(t, repo.UpdateRoomType(&shrunk, 1, now), ErrRoomsReserved)
+	stored, err := repo.GetRoomType(roomType.HotelID, roomType.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, stored.RoomCount)
+
+	shrunk.RoomCount = 3
+	assert.NoError(t, repo.UpdateRoomType(&shrunk, 1, now))
+	stored, err = repo.GetRoomType(roomType.HotelID, roomType.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, stored.RoomCount)
+
+	missing := &RoomType{ID: uuid.New(), HotelID: roomType.HotelID, Name: "Suite", RoomCount: 1}
+	assert.ErrorIs(t, repo.UpdateRoomType(missing, 2, now), ErrRoomTypeNotFound)
+}
+
+func TestTransaction_Repository_Rollback(t *testing.T) {
+	gormDB := openReservationDB(t)
+	for _, statement := range []string{
+		"CREATE TABLE hotels (id TEXT PRIMARY KEY, version INTEGER, deleted_at DATETIME)",
+		"CREATE TABLE contact_infos (id TEXT PRIMARY KEY, hotel_id TEXT, info_type TEXT, info_content TEXT)",
+	} {
+		if err := gormDB.Exec(statement).Error; err != nil {
+			t.Fatalf("Failed to create table: %v", err)
+		}
+	}
+	repo := NewRepository(gormDB)
+
+	hotelID, contactID := uuid.New(), uuid.New()
+	gormDB.Exec("INSERT INTO hotels (id, version) VALUES (?, 1)", hotelID)
+	gormDB.Exec("INSERT INTO contact_infos (id, hotel_id, info_type, info_content) VALUES (?, ?, 'phone', '+902125550100')", contactID, hotelID)
+
+	// A change is rolled back with the transaction, including the transaction of
+	// the repository method itself
+	err := repo.Transaction(func(repo HotelRepository) error {
+		if err := repo.RemoveContactInfo(hotelID, contactID, 1); err != nil {
+			return err
+		}
+		return errors.New("audit entry not stored")
+	})
+	assert.EqualError(t, err, "audit entry not stored")
+
+	contact, err := repo.GetContactInfo(hotelID, contactID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "+902125550100", contact.InfoContent)
+	}
+	var version int
+	gormDB.Raw("SELECT version FROM hotels WHERE id = ?", hotelID).Scan(&version)
+	assert.Equal(t, 1, version)
+}
@@ -0,0 +1,174 @@
+package hotel
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Statuses of a reservation. A hold keeps its rooms until it expires or is
+// confirmed as a booking; cancelled reservations keep no rooms.
+const (
+	ReservationHold      = "hold"
+	ReservationBooked    = "booked"
+	ReservationCancelled = "cancelled"
+)
+
+// HoldDuration is how long a hold keeps its rooms unless it is confirmed.
+const HoldDuration = 15 * time.Minute
+
+// maxNights limits the nights of a stay and of an availability query.
+const maxNights = 366
+
+var (
+	ErrReservationNotFound = errors.New("reservation not found")
+	ErrNotAvailable        = errors.New("not enough rooms available for the requested dates")
+	ErrHoldExpired         = errors.New("hold has expired")
+	ErrNotHold             = errors.New("reservation is not a hold")
+	ErrInvalidDateRange    = errors.New("date range requires from to be before to and to span at most 366 nights")
+)
+
+// Reservation holds or books rooms of a room type for the nights from CheckIn
+// up to, but not including, CheckOut. Both are days at midnight UTC.
+type Reservation struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	HotelID    uuid.UUID `gorm:"type:uuid;not null;index" json:"hotel_id"`
+	RoomTypeID uuid.UUID `gorm:"type:uuid;not null;index:idx_reservations_stay" json:"room_type_id"`
+	CheckIn    time.Time `gorm:"not null;index:idx_reservations_stay" json:"check_in"`
+	CheckOut   time.Time `gorm:"not null" json:"check_out"`
+	Rooms      int       `gorm:"not null" json:"rooms"`
+	GuestName  string    `json:"guest_name"`
+	Status     string    `gorm:"not null" json:"status"`
+	// ExpiresAt is when a hold releases its rooms.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// keepsRooms reports whether the reservation keeps its rooms at now.
+func (r *Reservation) keepsRooms(now time.Time) bool {
+	switch r.Status {
+	case ReservationBooked:
+		return true
+	case ReservationHold:
+		return r.ExpiresAt != nil && r.ExpiresAt.After(now)
+	}
+	return false
+}
+
+// AvailabilityQuery selects the nights from From up to, but not including, To,
+// and optionally a single room type.
+type AvailabilityQuery struct {
+	From       time.Time
+	To         time.Time
+	RoomTypeID uuid.UUID
+}
+
+// RoomAvailability is the availability calendar of a room type.
+type RoomAvailability struct {
+	RoomTypeID uuid.UUID          `json:"room_type_id"`
+	Name       string             `json:"name"`
+	RoomCount  int                `json:"room_count"`
+	Dates      []DateAvailability `json:"dates"`
+}
+
+// DateAvailability is the number of rooms still free on a night.
+type DateAvailability struct {
+	Date      string `json:"date"`
+	Available int    `json:"available"`
+}
+
+// day truncates a time to midnight UTC of its day.
+func day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// nights returns the number of nights between two days.
+func nights(from, to time.Time) int {
+	return int(to.Sub(from).Hours() / 24)
+}
+
+// availability returns the rooms of a room type that are free on each night
+// from from up to to, given the reservations that keep rooms at that time.
+func availability(roomType *RoomType, reservations []Reservation, from, to time.Time) []DateAvailability {
+	dates := make([]DateAvailability, nights(from, to))
+	for i := range dates {
+		night := from.AddDate(0, 0, i)
+		available := roomType.RoomCount
+		for _, reservation := range reservations {
+			if reservation.RoomTypeID == roomType.ID && !night.Before(reservation.CheckIn) && night.Before(reservation.CheckOut) {
+				available -= reservation.Rooms
+			}
+		}
+		if available < 0 {
+			available = 0
+		}
+		dates[i] = DateAvailability{Date: night.Format("2006-01-02"), Available: available}
+	}
+	return dates
+}
+
+// peakRooms returns the most rooms the reservations keep on any one night.
+func peakRooms(reservations []Reservation) int {
+	rooms := make(map[string]int)
+	for _, reservation := range reservations {
+		for night := reservation.CheckIn; night.Before(reservation.CheckOut); night = night.AddDate(0, 0, 1) {
+			rooms[night.Format("2006-01-02")] += reservation.Rooms
+		}
+	}
+	peak := 0
+	for _, count := range rooms {
+		if count > peak {
+			peak = count
+		}
+	}
+	return peak
+}
+
+// minAvailable returns the fewest rooms free on any of the nights.
+func minAvailable(dates []DateAvailability) int {
+	if len(dates) == 0 {
+		return 0
+	}
+	available := dates[0].Available
+	for _, date := range dates[1:] {
+		if date.Available < available {
+			available = date.Available
+		}
+	}
+	return available
+}
+
+// validateReservation normalizes a new reservation and returns a
+// *ValidationError listing every invalid field. Stays may not start before
+// today.
+func validateReservation(reservation *Reservation, today time.Time) error {
+	reservation.CheckIn, reservation.CheckOut = day(reservation.CheckIn), day(reservation.CheckOut)
+	if reservation.Status == "" {
+		reservation.Status = ReservationBooked
+	}
+
+	var fields []FieldError
+	if reservation.RoomTypeID == uuid.Nil {
+		fields = append(fields, FieldError{"room_type_id", "is required"})
+	}
+	if reservation.CheckIn.Before(today) {
+		fields = append(fields, FieldError{"check_in", "must not be in the past"})
+	}
+	if stay := nights(reservation.CheckIn, reservation.CheckOut); stay < 1 {
+		fields = append(fields, FieldError{"check_out", "must be after check_in"})
+	} else if stay > maxNights {
+		fields = append(fields, FieldError{"check_out", "must be at most 366 nights after check_in"})
+	}
+	if reservation.Rooms < 1 {
+		fields = append(fields, FieldError{"rooms", "must be at least 1"})
+	}
+	if reservation.Status != ReservationHold && reservation.Status != ReservationBooked {
+		fields = append(fields, FieldError{"status", "must be hold or booked"})
+	}
+
+	if len(fields) > 0 {
+		return &ValidationError{Fields: fields}
+	}
+	return nil
+}
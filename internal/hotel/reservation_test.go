@@ -0,0 +1,57 @@
+package hotel
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAvailability(t *testing.T) {
+	roomType := &RoomType{ID: uuid.New(), RoomCount: 3}
+	june := func(d int) time.Time { return time.Date(2030, time.June, d, 0, 0, 0, 0, time.UTC) }
+
+	reservations := []Reservation{
+		{RoomTypeID: roomType.ID, CheckIn: june(1), CheckOut: june(3), Rooms: 1},
+		{RoomTypeID: roomType.ID, CheckIn: june(2), CheckOut: june(4), Rooms: 3},
+		// Reservations of other room types are ignored
+		{RoomTypeID: uuid.New(), CheckIn: june(1), CheckOut: june(5), Rooms: 2},
+	}
+
+	dates := availability(roomType, reservations, june(1), june(5))
+	assert.Equal(t, []DateAvailability{
+		{Date: "2030-06-01", Available: 2},
+		{Date: "2030-06-02", Available: 0}, // overbooked nights are not negative
+		{Date: "2030-06-03", Available: 0},
+		{Date: "2030-06-04", Available: 3}, // the check-out day is free again
+	}, dates)
+	assert.Equal(t, 0, minAvailable(dates))
+	assert.Equal(t, 3, minAvailable(dates[3:]))
+}
+
+func TestValidateReservation(t *testing.T) {
+	today := time.Date(2030, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	reservation := &Reservation{
+		RoomTypeID: uuid.New(),
+		CheckIn:    time.Date(2030, time.June, 1, 14, 0, 0, 0, time.UTC),
+		CheckOut:   time.Date(2030, time.June, 3, 0, 0, 0, 0, time.UTC),
+		Rooms:      1,
+	}
+	assert.NoError(t, validateReservation(reservation, today))
+	assert.Equal(t, today, reservation.CheckIn)
+	assert.Equal(t, ReservationBooked, reservation.Status)
+
+	// Every invalid field is reported
+	err := validateReservation(&Reservation{CheckIn: today.AddDate(0, 0, -1), CheckOut: today.AddDate(0, 0, -1), Status: ReservationCancelled}, today)
+	var validationErr *ValidationError
+	if assert.True(t, errors.As(err, &validationErr)) {
+		var fields []string
+		for _, field := range validationErr.Fields {
+			fields = append(fields, field.Field)
+		}
+		assert.Equal(t, []string{"room_type_id", "check_in", "check_out", "rooms", "status"}, fields)
+	}
+}
@@ -0,0 +1,87 @@
+package hotel
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Moderation statuses of a review. New reviews are pending; only approved
+// reviews are shown by default and count toward the rating of their hotel.
+const (
+	ReviewPending  = "pending"
+	ReviewApproved = "approved"
+	ReviewRejected = "rejected"
+)
+
+// Review scores range from MinReviewScore to MaxReviewScore.
+const (
+	MinReviewScore = 1
+	MaxReviewScore = 10
+)
+
+// maxReviewText limits the length of a review text in characters.
+const maxReviewText = 5000
+
+var (
+	ErrReviewNotFound      = errors.New("review not found")
+	ErrInvalidReviewStatus = errors.New("review status must be pending, approved or rejected")
+)
+
+// Review is a guest's score of a hotel with an optional text.
+type Review struct {
+	ID      uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	HotelID uuid.UUID `gorm:"type:uuid;not null;index" json:"hotel_id"`
+	Score   int       `gorm:"not null" json:"score"`
+	Text    string    `json:"text"`
+	Author  string    `gorm:"not null" json:"author"`
+	Date    time.Time `gorm:"not null" json:"date"`
+	Status  string    `gorm:"not null;index" json:"status"`
+}
+
+// ReviewFilter selects the reviews of a hotel ListReviews returns, newest first.
+type ReviewFilter struct {
+	HotelID uuid.UUID
+	// Status defaults to approved.
+	Status string
+	Limit  int
+}
+
+// validReviewStatus reports whether status is a moderation status.
+func validReviewStatus(status string) bool {
+	return status == ReviewPending || status == ReviewApproved || status == ReviewRejected
+}
+
+// validateReview normalizes a new review and returns a *ValidationError listing
+// every invalid field.
+func validateReview(review *Review) error {
+	review.Author = strings.TrimSpace(review.Author)
+	review.Text = strings.TrimSpace(review.Text)
+
+	var fields []FieldError
+	if review.Score < MinReviewScore || review.Score > MaxReviewScore {
+		fields = append(fields, FieldError{"score", "must be between 1 and 10"})
+	}
+	if review.Author == "" {
+		fields = append(fields, FieldError{"author", "is required"})
+	}
+	if len([]rune(review.Text)) > maxReviewText {
+		fields = append(fields, FieldError{"text", "must be at most 5000 characters"})
+	}
+
+	if len(fields) > 0 {
+		return &ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+// averageRating returns the average score of approved reviews from the sum of
+// their scores, or zero when there are none.
+func averageRating(scoreTotal, reviewCount int) float64 {
+	if reviewCount == 0 {
+		return 0
+	}
+	return float64(scoreTotal) / float64(reviewCount)
+}
@@ -0,0 +1,33 @@
+package hotel
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateReview(t *testing.T) {
+	review := &Review{Score: 9, Text: " Lovely stay ", Author: " Jane "}
+	assert.NoError(t, validateReview(review))
+	assert.Equal(t, "Lovely stay", review.Text)
+	assert.Equal(t, "Jane", review.Author)
+
+	// Every invalid field is reported
+	err := validateReview(&Review{Score: 11, Text: strings.Repeat("a", maxReviewText+1)})
+	var validationErr *ValidationError
+	if assert.True(t, errors.As(err, &validationErr)) {
+		var fields []string
+		for _, field := range validationErr.Fields {
+			fields = append(fields, field.Field)
+		}
+		assert.Equal(t, []string{"score", "author", "text"}, fields)
+	}
+}
+
+func TestAverageRating(t *testing.T) {
+	// Scores are summed over the hotels, so hotels are weighted by their number of reviews
+	assert.Equal(t, 7.0, averageRating(27+8, 3+2))
+	assert.Equal(t, 0.0, averageRating(0, 0))
+}
@@ -0,0 +1,206 @@
+package hotel
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hotel-guide/internal/money"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Bed types of a bed configuration.
+const (
+	BedSingle = "single"
+	BedDouble = "double"
+	BedQueen  = "queen"
+	BedKing   = "king"
+	BedSofa   = "sofa"
+	BedBunk   = "bunk"
+)
+
+var bedTypes = map[string]bool{BedSingle: true, BedDouble: true, BedQueen: true, BedKing: true, BedSofa: true, BedBunk: true}
+
+var (
+	ErrRoomTypeNotFound = errors.New("room type not found")
+	ErrRoomTypeInUse    = errors.New("room type has bookings that have not ended")
+	ErrRoomsReserved    = errors.New("room count is below the rooms reserved on some night")
+)
+
+// RoomType is a kind of room a hotel offers, such as "Deluxe Double", and the
+// number of physical rooms of that kind.
+type RoomType struct {
+	ID      uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	HotelID uuid.UUID `gorm:"type:uuid;not null;index" json:"hotel_id"`
+	Name    string    `gorm:"not null" json:"name"`
+	// Capacity is the number of guests a room of this type sleeps.
+	Capacity int              `gorm:"not null" json:"capacity"`
+	Beds     BedConfiguration `gorm:"type:text" json:"beds"`
+	// BedCount is the number of beds in one room, derived from Beds so that
+	// capacity can be summed in SQL.
+	BedCount  int        `gorm:"not null;default:0" json:"bed_count"`
+	RoomCount int        `gorm:"not null" json:"room_count"`
+	Amenities StringList `gorm:"type:text" json:"amenities"`
+	// RatePlans are only loaded by ListRatePlans; room type queries leave them out.
+	RatePlans []RatePlan `gorm:"foreignKey:RoomTypeID;references:ID;constraint:OnDelete:CASCADE;" json:"rate_plans,omitempty"`
+	// Reservations are never loaded with the room type.
+	Reservations []Reservation `gorm:"foreignKey:RoomTypeID;references:ID;constraint:OnDelete:CASCADE;" json:"-"`
+}
+
+// Bed is a number of beds of one type.
+type Bed struct {
+	Type  string `json:"type"`
+	Count int    `json:"count"`
+}
+
+// BedConfiguration lists the beds of a room. It is stored as JSON.
+type BedConfiguration []Bed
+
+// count returns the number of beds in the configuration.
+func (c BedConfiguration) count() int {
+	total := 0
+	for _, bed := range c {
+		total += bed.Count
+	}
+	return total
+}
+
+func (c BedConfiguration) Value() (driver.Value, error) {
+	return jsonValue(c)
+}
+
+func (c *BedConfiguration) Scan(value interface{}) error {
+	return scanJSON(value, c, "bed configuration")
+}
+
+// StringList is a list of strings stored as JSON.
+type StringList []string
+
+func (l StringList) Value() (driver.Value, error) {
+	return jsonValue(l)
+}
+
+func (l *StringList) Scan(value interface{}) error {
+	return scanJSON(value, l, "string list")
+}
+
+func jsonValue(value interface{}) (driver.Value, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func scanJSON(value, target interface{}, name string) error {
+	switch data := value.(type) {
+	case nil:
+		return nil
+	case string:
+		return json.Unmarshal([]byte(data), target)
+	case []byte:
+		return json.Unmarshal(data, target)
+	}
+	return fmt.Errorf("cannot scan %T into %s", value, name)
+}
+
+// validateRoomType normalizes a room type and returns a *ValidationError
+// listing every invalid field.
+func validateRoomType(roomType *RoomType) error {
+	roomType.Name = strings.TrimSpace(roomType.Name)
+
+	var fields []FieldError
+	if roomType.Name == "" {
+		fields = append(fields, FieldError{"name", "is required"})
+	}
+	if roomType.Capacity < 1 {
+		fields = append(fields, FieldError{"capacity", "must be at least 1"})
+	}
+	if roomType.RoomCount < 0 {
+		fields = append(fields, FieldError{"room_count", "must not be negative"})
+	}
+	if len(roomType.Beds) == 0 {
+		fields = append(fields, FieldError{"beds", "must list at least one bed"})
+	}
+	for i := range roomType.Beds {
+		bed := &roomType.Beds[i]
+		bed.Type = strings.ToLower(strings.TrimSpace(bed.Type))
+		if !bedTypes[bed.Type] {
+			fields = append(fields, FieldError{fmt.Sprintf("beds[%d].type", i), "must be one of bunk, double, king, queen, single, sofa"})
+		}
+		if bed.Count < 1 {
+			fields = append(fields, FieldError{fmt.Sprintf("beds[%d].count", i), "must be at least 1"})
+		}
+	}
+	roomType.BedCount = roomType.Beds.count()
+	roomType.Amenities = normalizeAmenities(roomType.Amenities)
+
+	if len(fields) > 0 {
+		return &ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+// normalizeAmenities lower-cases the amenities, drops empty and duplicate
+// entries and sorts them.
+func normalizeAmenities(amenities []string) StringList {
+	seen := make(map[string]bool)
+	normalized := StringList{}
+	for _, amenity := range amenities {
+		amenity = strings.ToLower(strings.TrimSpace(amenity))
+		if amenity != "" && !seen[amenity] {
+			seen[amenity] = true
+			normalized = append(normalized, amenity)
+		}
+	}
+	sort.Strings(normalized)
+	return normalized
+}
+
+// LocationStats summarizes the hotels of a location.
+type LocationStats struct {
+	HotelCount int `json:"hotel_count"`
+	// PhoneCount counts the contacts of the types that count toward the stats
+	// and HotelsWithoutPhone the hotels without such a contact.
+	PhoneCount         int `json:"phone_count"`
+	HotelsWithoutPhone int `json:"hotels_without_phone"`
+	EmailCount         int `json:"email_count"`
+	FaxCount           int `json:"fax_count"`
+	WebsiteCount       int `json:"website_count"`
+	// AverageContacts is the number of contacts per hotel, leaving out legacy
+	// location contacts.
+	AverageContacts float64 `json:"average_contacts"`
+	// OfficialCount is the number of distinct people holding a role at the
+	// hotels. People are told apart by name and surname.
+	OfficialCount int `json:"official_count"`
+	// RoomCount is the number of physical rooms of the hotels and BedCount the
+	// number of beds in them.
+	RoomCount int `json:"room_count"`
+	BedCount  int `json:"bed_count"`
+	// AverageRating is the average score of the approved reviews of the
+	// hotels, or zero when they have none.
+	AverageRating float64 `json:"average_rating"`
+	// MedianNightlyPrice is the median over the hotels of the base price of
+	// their cheapest room only rate plan in effect today, converted into
+	// Currency, or nil when none is.
+	MedianNightlyPrice *money.Decimal `json:"median_nightly_price,omitempty"`
+	Currency           string         `json:"currency,omitempty"`
+}
+
+// LocationAggregates are the totals over the hotels of a location that the
+// location stats are computed from.
+type LocationAggregates struct {
+	HotelCount int
+	// ScoreTotal and ReviewCount add up the approved reviews of the hotels.
+	ScoreTotal  int
+	ReviewCount int
+	// HotelsWithoutPhone counts the hotels without a contact of the phone
+	// types FetchLocationAggregates is given.
+	HotelsWithoutPhone int
+	OfficialCount      int
+	// ContactCounts is the number of contacts of the hotels per type.
+	ContactCounts map[string]int
+}
@@ -0,0 +1,49 @@
+package hotel
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateRoomType(t *testing.T) {
+	roomType := &RoomType{
+		Name:      " Deluxe Double ",
+		Capacity:  3,
+		RoomCount: 12,
+		Beds:      BedConfiguration{{Type: "Double", Count: 1}, {Type: BedSofa, Count: 1}},
+		Amenities: StringList{"Minibar", " balcony", "minibar", ""},
+	}
+	assert.NoError(t, validateRoomType(roomType))
+	assert.Equal(t, "Deluxe Double", roomType.Name)
+	assert.Equal(t, BedDouble, roomType.Beds[0].Type)
+	assert.Equal(t, 2, roomType.BedCount)
+	assert.Equal(t, StringList{"balcony", "minibar"}, roomType.Amenities)
+
+	// Every invalid field is reported
+	err := validateRoomType(&RoomType{RoomCount: -1, Beds: BedConfiguration{{Type: "hammock", Count: 0}}})
+	var validationErr *ValidationError
+	if assert.True(t, errors.As(err, &validationErr)) {
+		var fields []string
+		for _, field := range validationErr.Fields {
+			fields = append(fields, field.Field)
+		}
+		assert.Equal(t, []string{"name", "capacity", "room_count", "beds[0].type", "beds[0].count"}, fields)
+	}
+	assert.NotErrorIs(t, err, ErrInvalidContact)
+}
+
+func TestBedConfiguration_ValueAndScan(t *testing.T) {
+	beds := BedConfiguration{{Type: BedSingle, Count: 2}}
+	value, err := beds.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, `[{"type":"single","count":2}]`, value)
+
+	var scanned BedConfiguration
+	assert.NoError(t, scanned.Scan(value))
+	assert.Equal(t, beds, scanned)
+	assert.NoError(t, scanned.Scan([]byte(`[]`)))
+	assert.Empty(t, scanned)
+	assert.Error(t, scanned.Scan(42))
+}
@@ -0,0 +1,397 @@
+package hotel
+
+import (
+	"errors"
+	"html"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidSearchQuery = errors.New("search query must contain at least one letter or digit")
+
+// Weights of the searchable fields. A match in the company title ranks a hotel
+// above the same match in one of its contacts.
+const (
+	companyTitleWeight = 3.0
+	ownerWeight        = 2.0
+	contactWeight      = 1.0
+)
+
+// SearchResult is a hotel matching a search query.
+type SearchResult struct {
+	ID           uuid.UUID     `json:"id"`
+	OwnerName    string        `json:"owner_name"`
+	OwnerSurname string        `json:"owner_surname"`
+	CompanyTitle string        `json:"company_title"`
+	Score        float64       `json:"score"`
+	Matches      []SearchMatch `json:"matches"`
+}
+
+// SearchMatch describes a field that matched the query. Highlighted is the field
+// value with the matching words wrapped in <em> tags.
+type SearchMatch struct {
+	Field       string `json:"field"`
+	InfoType    string `json:"info_type,omitempty"`
+	Value       string `json:"value"`
+	Highlighted string `json:"highlighted"`
+}
+
+// searchIndex is an in-memory index over the searchable fields of all hotels.
+// It is loaded from the repository on first use and kept in sync by the service
+// afterwards; changes made before it is loaded are picked up by the load.
+type searchIndex struct {
+	mu     sync.RWMutex
+	loaded bool
+	docs   map[uuid.UUID]*searchDocument
+}
+
+type searchDocument struct {
+	hotel    Hotel
+	contacts map[uuid.UUID]ContactInfo
+}
+
+func newSearchIndex() *searchIndex {
+	return &searchIndex{docs: make(map[uuid.UUID]*searchDocument)}
+}
+
+// ensureLoaded fills the index with the hotels returned by fetch unless it has
+// already been loaded. A failed load is retried on the next call.
+func (idx *searchIndex) ensureLoaded(fetch func() ([]Hotel, error)) error {
+	idx.mu.RLock()
+	loaded := idx.loaded
+	idx.mu.RUnlock()
+	if loaded {
+		return nil
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if idx.loaded {
+		return nil
+	}
+	hotels, err := fetch()
+	if err != nil {
+		return err
+	}
+	for _, hotel := range hotels {
+		idx.docs[hotel.ID] = newSearchDocument(hotel)
+	}
+	idx.loaded = true
+	return nil
+}
+
+func newSearchDocument(hotel Hotel) *searchDocument {
+	doc := &searchDocument{contacts: make(map[uuid.UUID]ContactInfo, len(hotel.ContactInfos))}
+	for _, contact := range hotel.ContactInfos {
+		doc.contacts[contact.ID] = contact
+	}
+	hotel.ContactInfos = nil
+	hotel.Location = nil
+	doc.hotel = hotel
+	return doc
+}
+
+// put adds or replaces a hotel, including its contacts.
+func (idx *searchIndex) put(hotel Hotel) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if idx.loaded {
+		idx.docs[hotel.ID] = newSearchDocument(hotel)
+	}
+}
+
+// reset empties the index, so that the next search loads it again. It is used
+// after changes too large to apply one hotel at a time.
+func (idx *searchIndex) reset() {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.loaded = false
+	idx.docs = make(map[uuid.UUID]*searchDocument)
+}
+
+func (idx *searchIndex) remove(hotelID uuid.UUID) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	delete(idx.docs, hotelID)
+}
+
+// putContact adds or replaces a contact of an indexed hotel.
+func (idx *searchIndex) putContact(hotelID uuid.UUID, contact ContactInfo) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if doc, ok := idx.docs[hotelID]; ok {
+		doc.contacts[contact.ID] = contact
+	}
+}
+
+func (idx *searchIndex) removeContact(hotelID, contactID uuid.UUID) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if doc, ok := idx.docs[hotelID]; ok {
+		delete(doc.contacts, contactID)
+	}
+}
+
+// search returns up to limit hotels matching the query, best match first.
+func (idx *searchIndex) search(query string, limit int) ([]SearchResult, error) {
+	terms := tokenize(query)
+	if len(terms) == 0 {
+		return nil, ErrInvalidSearchQuery
+	}
+
+	idx.mu.RLock()
+	results := make([]SearchResult, 0)
+	for _, doc := range idx.docs {
+		if result, ok := doc.match(terms); ok {
+			results = append(results, result)
+		}
+	}
+	idx.mu.RUnlock()
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		if results[i].CompanyTitle != results[j].CompanyTitle {
+			return results[i].CompanyTitle < results[j].CompanyTitle
+		}
+		return results[i].ID.String() < results[j].ID.String()
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// searchField is one value of a document the query is matched against.
+type searchField struct {
+	name     string
+	infoType string
+	value    string
+	weight   float64
+}
+
+// match scores the document against the query terms. Each term contributes the
+// best weighted similarity it reaches in any field.
+func (doc *searchDocument) match(terms []token) (SearchResult, bool) {
+	fields := []searchField{
+		{name: "company_title", value: doc.hotel.CompanyTitle, weight: companyTitleWeight},
+		{name: "owner_name", value: doc.hotel.OwnerName, weight: ownerWeight},
+		{name: "owner_surname", value: doc.hotel.OwnerSurname, weight: ownerWeight},
+	}
+	contactIDs := make([]uuid.UUID, 0, len(doc.contacts))
+	for id := range doc.contacts {
+		contactIDs = append(contactIDs, id)
+	}
+	sort.Slice(contactIDs, func(i, j int) bool { return contactIDs[i].String() < contactIDs[j].String() })
+	for _, id := range contactIDs {
+		contact := doc.contacts[id]
+		fields = append(fields, searchField{name: "contact", infoType: contact.InfoType, value: contact.InfoContent, weight: contactWeight})
+	}
+
+	hits := make([][]token, len(fields))
+	score := 0.0
+	for _, term := range terms {
+		best, bestField := 0.0, -1
+		var bestToken token
+		for i, field := range fields {
+			for _, candidate := range fieldTokens(field) {
+				if similarity := termSimilarity(term.text, candidate.text) * field.weight; similarity > best {
+					best, bestField, bestToken = similarity, i, candidate
+				}
+			}
+		}
+		if bestField >= 0 {
+			score += best
+			hits[bestField] = append(hits[bestField], bestToken)
+		}
+	}
+	if score == 0 {
+		return SearchResult{}, false
+	}
+
+	result := SearchResult{
+		ID:           doc.hotel.ID,
+		OwnerName:    doc.hotel.OwnerName,
+		OwnerSurname: doc.hotel.OwnerSurname,
+		CompanyTitle: doc.hotel.CompanyTitle,
+		Score:        math.Round(score*1000) / 1000,
+	}
+	for i, field := range fields {
+		if len(hits[i]) > 0 {
+			result.Matches = append(result.Matches, SearchMatch{
+				Field:       field.name,
+				InfoType:    field.infoType,
+				Value:       field.value,
+				Highlighted: highlight(field.value, hits[i]),
+			})
+		}
+	}
+	return result, true
+}
+
+// fieldTokens splits a field into words. Phone numbers, recognized by their type
+// or by the E.164 format, are also matched as a whole, so "5551234" finds
+// "+90 555 123 4".
+func fieldTokens(field searchField) []token {
+	tokens := tokenize(field.value)
+	e164 := len(field.value) > 1 && field.value[0] == '+' && isDigits(field.value[1:])
+	if field.infoType == ContactTypePhone || field.infoType == ContactTypeFax || e164 {
+		var digits strings.Builder
+		for _, r := range field.value {
+			if unicode.IsDigit(r) {
+				digits.WriteRune(r)
+			}
+		}
+		if digits.Len() > 0 {
+			tokens = append(tokens, token{text: digits.String(), start: 0, end: len(field.value)})
+		}
+	}
+	return tokens
+}
+
+// token is a normalized word and its byte range in the original text.
+type token struct {
+	text       string
+	start, end int
+}
+
+// searchFolding maps letters to the ASCII letter users commonly type instead.
+var searchFolding = map[rune]rune{
+	'ç': 'c', 'ğ': 'g', 'ı': 'i', 'ö': 'o', 'ş': 's', 'ü': 'u',
+	'â': 'a', 'î': 'i', 'û': 'u', 'é': 'e', 'è': 'e', 'á': 'a', 'à': 'a',
+}
+
+// tokenize splits text into lowercase words of letters and digits.
+func tokenize(text string) []token {
+	var tokens []token
+	var current strings.Builder
+	start := -1
+	flush := func(end int) {
+		if start >= 0 {
+			tokens = append(tokens, token{text: current.String(), start: start, end: end})
+			current.Reset()
+			start = -1
+		}
+	}
+
+	for i, r := range text {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush(i)
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+		r = unicode.ToLower(r)
+		if folded, ok := searchFolding[r]; ok {
+			r = folded
+		}
+		current.WriteRune(r)
+	}
+	flush(len(text))
+	return tokens
+}
+
+// termSimilarity rates how well a query term matches a word, from 0 to 1.
+// Exact matches beat prefixes, which beat words within a small edit distance.
+func termSimilarity(term, word string) float64 {
+	switch {
+	case term == word:
+		return 1
+	case len(term) >= 2 && strings.HasPrefix(word, term):
+		return 0.8
+	case len(term) >= 4 && isDigits(term) && strings.Contains(word, term):
+		// Callers often read out only the local part of a phone number.
+		return 0.8
+	}
+
+	maxEdits := 0
+	switch n := utf8.RuneCountInString(term); {
+	case n >= 8:
+		maxEdits = 2
+	case n >= 4:
+		maxEdits = 1
+	}
+	if maxEdits == 0 {
+		return 0
+	}
+
+	// Also allow typos in a prefix of the word, e.g. "marmra" for "marmaray".
+	termRunes, wordRunes := []rune(term), []rune(word)
+	distance := editDistance(termRunes, wordRunes)
+	if len(termRunes) < len(wordRunes) {
+		distance = min(distance, editDistance(termRunes, wordRunes[:len(termRunes)]))
+	}
+	switch {
+	case distance == 1 && distance <= maxEdits:
+		return 0.6
+	case distance == 2 && distance <= maxEdits:
+		return 0.4
+	}
+	return 0
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// editDistance returns the optimal string alignment distance between a and b:
+// the number of insertions, deletions, substitutions and adjacent
+// transpositions needed to turn one into the other.
+func editDistance(a, b []rune) int {
+	rows := make([][]int, len(a)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(b)+1)
+		rows[i][0] = i
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			rows[i][j] = min(rows[i-1][j]+1, rows[i][j-1]+1, rows[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				rows[i][j] = min(rows[i][j], rows[i-2][j-2]+1)
+			}
+		}
+	}
+	return rows[len(a)][len(b)]
+}
+
+// highlight wraps the byte ranges of the tokens in <em> tags. The value is
+// HTML-escaped, so that the tags are the only markup in the result.
+func highlight(value string, hits []token) string {
+	sort.Slice(hits, func(i, j int) bool { return hits[i].start < hits[j].start })
+
+	var b strings.Builder
+	position := 0
+	for _, hit := range hits {
+		if hit.start < position {
+			continue
+		}
+		b.WriteString(html.EscapeString(value[position:hit.start]))
+		b.WriteString("<em>")
+		b.WriteString(html.EscapeString(value[hit.start:hit.end]))
+		b.WriteString("</em>")
+		position = hit.end
+	}
+	b.WriteString(html.EscapeString(value[position:]))
+	return b.String()
+}
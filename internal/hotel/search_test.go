@@ -0,0 +1,116 @@
+package hotel
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenize(t *testing.T) {
+	tokens := tokenize("Çırağan Palace, İstanbul!")
+	if assert.Len(t, tokens, 3) {
+		assert.Equal(t, "ciragan", tokens[0].text)
+		assert.Equal(t, "palace", tokens[1].text)
+		// The byte range points into the original text
+		assert.Equal(t, "Palace", "Çırağan Palace, İstanbul!"[tokens[1].start:tokens[1].end])
+	}
+
+	assert.Empty(t, tokenize(" -- "))
+}
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, editDistance([]rune("hotel"), []rune("hotel")))
+	assert.Equal(t, 1, editDistance([]rune("hotel"), []rune("hotle")))
+	assert.Equal(t, 1, editDistance([]rune("hotel"), []rune("hotels")))
+	assert.Equal(t, 2, editDistance([]rune("grand"), []rune("gren")))
+}
+
+func TestTermSimilarity(t *testing.T) {
+	assert.Equal(t, 1.0, termSimilarity("hilton", "hilton"))
+	assert.Equal(t, 0.8, termSimilarity("hil", "hilton"))
+	assert.Equal(t, 0.6, termSimilarity("hiltn", "hilton"))
+	assert.Equal(t, 0.6, termSimilarity("marmra", "marmaray"))
+	assert.Equal(t, 0.4, termSimilarity("bosforus", "bosphorus"))
+	assert.Equal(t, 0.8, termSimilarity("1234", "905551234"))
+
+	// Short terms must match exactly or as a prefix
+	assert.Equal(t, 0.0, termSimilarity("inm", "inn"))
+}
+
+func TestSearchIndex_RanksAndHighlights(t *testing.T) {
+	idx := newSearchIndex()
+	grand := Hotel{ID: uuid.New(), OwnerName: "Ayşe", OwnerSurname: "Yılmaz", CompanyTitle: "Grand Bosphorus Hotel"}
+	harbour := Hotel{
+		ID: uuid.New(), OwnerName: "John", OwnerSurname: "Grand", CompanyTitle: "Harbour Inn",
+		ContactInfos: []ContactInfo{{ID: uuid.New(), InfoType: ContactTypePhone, InfoContent: "+90 212 555 1234"}},
+	}
+	err := idx.ensureLoaded(func() ([]Hotel, error) { return []Hotel{grand, harbour}, nil })
+	assert.NoError(t, err)
+
+	// A misspelled title still finds the hotel, and the title outranks the surname
+	results, err := idx.search("grnd bosphrous", 10)
+	assert.NoError(t, err)
+	if assert.Len(t, results, 2) {
+		assert.Equal(t, grand.ID, results[0].ID)
+		assert.Equal(t, "company_title", results[0].Matches[0].Field)
+		assert.Equal(t, "<em>Grand</em> <em>Bosphorus</em> Hotel", results[0].Matches[0].Highlighted)
+		assert.Equal(t, "owner_surname", results[1].Matches[0].Field)
+	}
+
+	// Phone numbers are found by their digits
+	results, err = idx.search("5551234", 10)
+	assert.NoError(t, err)
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, harbour.ID, results[0].ID)
+		assert.Equal(t, ContactTypePhone, results[0].Matches[0].InfoType)
+	}
+
+	// Turkish letters can be typed without diacritics
+	results, err = idx.search("ayse yilmaz", 10)
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+
+	_, err = idx.search("?!", 10)
+	assert.ErrorIs(t, err, ErrInvalidSearchQuery)
+}
+
+func TestSearchIndex_EscapesHighlights(t *testing.T) {
+	idx := newSearchIndex()
+	hotel := Hotel{ID: uuid.New(), CompanyTitle: "Smith & Sons <script>alert(1)</script>"}
+	err := idx.ensureLoaded(func() ([]Hotel, error) { return []Hotel{hotel}, nil })
+	assert.NoError(t, err)
+
+	// The value is escaped; only the highlight tags are markup
+	results, err := idx.search("smith script", 10)
+	assert.NoError(t, err)
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, "Smith & Sons <script>alert(1)</script>", results[0].Matches[0].Value)
+		assert.Equal(t, "<em>Smith</em> &amp; Sons &lt;<em>script</em>&gt;alert(1)&lt;/script&gt;", results[0].Matches[0].Highlighted)
+	}
+}
+
+func TestSearchIndex_KeptInSync(t *testing.T) {
+	idx := newSearchIndex()
+	hotel := Hotel{ID: uuid.New(), OwnerName: "John", OwnerSurname: "Doe", CompanyTitle: "Seaside Inn"}
+
+	// Changes before the index is loaded are left to the load
+	idx.put(hotel)
+	assert.NoError(t, idx.ensureLoaded(func() ([]Hotel, error) { return nil, nil }))
+	results, _ := idx.search("seaside", 10)
+	assert.Empty(t, results)
+
+	idx.put(hotel)
+	contact := ContactInfo{ID: uuid.New(), InfoType: ContactTypeEmail, InfoContent: "desk@seaside.example"}
+	idx.putContact(hotel.ID, contact)
+	results, _ = idx.search("desk", 10)
+	assert.Len(t, results, 1)
+
+	idx.removeContact(hotel.ID, contact.ID)
+	results, _ = idx.search("desk", 10)
+	assert.Empty(t, results)
+
+	idx.remove(hotel.ID)
+	results, _ = idx.search("seaside", 10)
+	assert.Empty(t, results)
+}
@@ -1,74 +1,1901 @@
 package hotel
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"hotel-guide/internal/money"
+	"hotel-guide/internal/storage"
+	"io"
+	"log"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 )
 
+// HotelService defines hotel operations. Mutating methods take a context
+// carrying the actor recorded in the audit log, see WithAuditInfo, and the hotel
+// version the caller expects to change; zero means no expectation.
 type HotelService interface {
-	CreateHotel(ownerName, ownerSurname, companyTitle string, contacts []ContactInfo) (*Hotel, error)
-	DeleteHotel(id uuid.UUID) error
-	AddContactInfo(hotelID uuid.UUID, contact *ContactInfo) error
-	RemoveContactInfo(hotelID uuid.UUID, contactUUID uuid.UUID) error
-	ListHotels() ([]Hotel, error)
-	ListHotelOfficials() ([]HotelOfficial, error)
+	CreateHotel(ctx context.Context, ownerName, ownerSurname, companyTitle string, contacts []ContactInfo) (*Hotel, error)
+	DeleteHotel(ctx context.Context, id uuid.UUID, version int) error
+	RestoreHotel(ctx context.Context, id uuid.UUID) (*Hotel, error)
+	PurgeDeletedHotels(retention time.Duration) (int64, error)
+	StartPurgeWorker(retention, interval time.Duration)
+	UpdateHotel(ctx context.Context, id uuid.UUID, update HotelUpdate, version int) (*Hotel, error)
+	AddContactInfo(ctx context.Context, hotelID uuid.UUID, contact *ContactInfo, version int) error
+	RemoveContactInfo(ctx context.Context, hotelID uuid.UUID, contactUUID uuid.UUID, version int) error
+	UpdateContactInfo(ctx context.Context, hotelID, contactID uuid.UUID, update ContactInfoUpdate, version int) (*ContactInfo, error)
+	SetLocation(ctx context.Context, hotelID uuid.UUID, location *Location, version int) error
+	DeleteLocation(ctx context.Context, hotelID uuid.UUID, version int) error
+	AddOfficial(ctx context.Context, hotelID uuid.UUID, official *HotelOfficial, version int) error
+	UpdateOfficial(ctx context.Context, hotelID, officialID uuid.UUID, official *HotelOfficial, version int) error
+	RemoveOfficial(ctx context.Context, hotelID, officialID uuid.UUID, version int) error
+	ListRoomTypes(hotelID uuid.UUID) ([]RoomType, error)
+	GetRoomType(hotelID, roomTypeID uuid.UUID) (*RoomType, error)
+	AddRoomType(ctx context.Context, hotelID uuid.UUID, roomType *RoomType, version int) error
+	UpdateRoomType(ctx context.Context, hotelID, roomTypeID uuid.UUID, roomType *RoomType, version int) error
+	RemoveRoomType(ctx context.Context, hotelID, roomTypeID uuid.UUID, version int) error
+	GetAvailability(hotelID uuid.UUID, query AvailabilityQuery) ([]RoomAvailability, error)
+	GetReservation(hotelID, reservationID uuid.UUID) (*Reservation, error)
+	CreateReservation(ctx context.Context, hotelID uuid.UUID, reservation *Reservation) error
+	ConfirmReservation(ctx context.Context, hotelID, reservationID uuid.UUID) (*Reservation, error)
+	CancelReservation(ctx context.Context, hotelID, reservationID uuid.UUID) error
+	ListRatePlans(hotelID, roomTypeID uuid.UUID) ([]RatePlan, error)
+	GetRatePlan(hotelID, ratePlanID uuid.UUID) (*RatePlan, error)
+	AddRatePlan(ctx context.Context, hotelID uuid.UUID, plan *RatePlan, version int) error
+	UpdateRatePlan(ctx context.Context, hotelID, ratePlanID uuid.UUID, plan *RatePlan, version int) error
+	RemoveRatePlan(ctx context.Context, hotelID, ratePlanID uuid.UUID, version int) error
+	QuoteStay(hotelID uuid.UUID, request QuoteRequest) (*Quote, error)
+	ListExchangeRates(filter ExchangeRateFilter) ([]ExchangeRate, error)
+	SaveExchangeRate(rate *ExchangeRate) error
+	RemoveExchangeRate(base, quote string, effectiveDate time.Time) error
+	ListReviews(filter ReviewFilter) ([]Review, error)
+	AddReview(ctx context.Context, hotelID uuid.UUID, review *Review) error
+	ModerateReview(ctx context.Context, hotelID, reviewID uuid.UUID, status string) (*Review, error)
+	RemoveReview(ctx context.Context, hotelID, reviewID uuid.UUID) error
+	ListHotels(opts ListOptions) (*HotelPage, error)
+	ExportHotels(w io.Writer, format string, opts ListOptions) error
+	ListHotelOfficials(filter OfficialFilter) ([]HotelOfficial, error)
 	GetHotelDetails(hotelID uuid.UUID) (*Hotel, error)
-	FetchLocationStats(location string) (int, int, error)
+	FetchLocationStats(filter LocationFilter, currency string) (*LocationStats, error)
+	FetchLocationStatsBatch(queries []LocationStatsQuery) ([]LocationStats, error)
+	ListLocationStats(opts LocationStatsOptions) (*LocationStatsPage, error)
+	FindNearbyHotels(lat, lng, radiusKm float64, limit int) ([]HotelDistance, error)
+	FindHotelsInBoundingBox(box BoundingBox, limit int) ([]HotelDistance, error)
+	SearchHotels(query string, limit int) ([]SearchResult, error)
+	ListContactTypes() ([]ContactType, error)
+	GetContactType(name string) (*ContactType, error)
+	CreateContactType(contactType *ContactType) error
+	UpdateContactType(name string, contactType *ContactType) error
+	DeleteContactType(name string) error
+	ListTags() ([]Tag, error)
+	GetTag(name string) (*Tag, error)
+	CreateTag(tag *Tag) error
+	UpdateTag(name string, tag *Tag) error
+	DeleteTag(name string) error
+	SetHotelTags(ctx context.Context, hotelID uuid.UUID, tags []string, version int) ([]string, error)
+	ListChains(kind string) ([]Chain, error)
+	GetChain(id uuid.UUID) (*Chain, error)
+	CreateChain(chain *Chain) error
+	UpdateChain(id uuid.UUID, chain *Chain) error
+	DeleteChain(id uuid.UUID) error
+	SetHotelChain(ctx context.Context, hotelID uuid.UUID, chainID *uuid.UUID, version int) error
+	ListChainHotels(chainID uuid.UUID, opts ListOptions) (*HotelPage, error)
+	FetchChainStats(chainID uuid.UUID) (*ChainStats, error)
+	ListMedia(hotelID uuid.UUID) ([]Media, error)
+	AddMedia(ctx context.Context, hotelID uuid.UUID, fileName string, data []byte, version int) (*Media, error)
+	OpenMedia(hotelID, mediaID uuid.UUID, thumbnail bool) (*Media, io.ReadCloser, error)
+	ReorderMedia(ctx context.Context, hotelID uuid.UUID, mediaIDs []uuid.UUID, version int) ([]Media, error)
+	RemoveMedia(ctx context.Context, hotelID, mediaID uuid.UUID, version int) error
+	ListTranslations(hotelID uuid.UUID) ([]HotelTranslation, error)
+	FindTranslations(hotelIDs []uuid.UUID) ([]HotelTranslation, error)
+	SaveTranslation(ctx context.Context, hotelID uuid.UUID, language string, translation *HotelTranslation, version int) error
+	RemoveTranslation(ctx context.Context, hotelID uuid.UUID, language string, version int) error
+	ListMissingTranslations(filter MissingTranslationFilter) (*MissingTranslationPage, error)
+	ListAuditEntries(filter AuditFilter) (*AuditPage, error)
+	ImportHotels(ctx context.Context, rows []ImportRow, opts ImportOptions) (*ImportResult, error)
+	StartImportJob(ctx context.Context, source ImportSource, opts ImportOptions) (*ImportJob, error)
+	GetImportJob(id uuid.UUID) (*ImportJob, error)
+	FailInterruptedImportJobs() (int64, error)
 }
 
 // hotelService struct implements the HotelService interface
 type hotelService struct {
 	hotelRepo HotelRepository
+	blobs     storage.BlobStore
+	search    *searchIndex
 }
 
-func NewService(repo HotelRepository) HotelService {
+func NewService(repo HotelRepository, blobs storage.BlobStore) HotelService {
 	return &hotelService{
 		hotelRepo: repo,
+		blobs:     blobs,
+		search:    newSearchIndex(),
 	}
 }
 
-func (s *hotelService) CreateHotel(ownerName, ownerSurname, companyTitle string, contacts []ContactInfo) (*Hotel, error) {
-	if ownerName == "" || ownerSurname == "" || companyTitle == "" {
-		return nil, fmt.Errorf("owner name, surname, and company title are required")
+func (s *hotelService) CreateHotel(ctx context.Context, ownerName, ownerSurname, companyTitle string, contacts []ContactInfo) (*Hotel, error) {
+	hotel := NewHotel(ownerName, ownerSurname, companyTitle, contacts)
+	if err := validateHotel(hotel); err != nil {
+		return nil, err
+	}
+	if len(hotel.ContactInfos) > 0 {
+		registry, err := s.contactRegistry()
+		if err != nil {
+			return nil, err
+		}
+		if err := registry.validateContacts(hotel.ContactInfos, DefaultPhoneCountry); err != nil {
+			return nil, err
+		}
+	}
+	err := s.hotelRepo.Transaction(func(repo HotelRepository) error {
+		if err := repo.Save(hotel); err != nil {
+			return err
+		}
+		return s.auditCreatedHotel(ctx, repo, hotel)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.search.put(*hotel)
+	return hotel, nil
+}
+
+// auditCreatedHotel records the creation of a hotel and of its contacts.
+func (s *hotelService) auditCreatedHotel(ctx context.Context, repo HotelRepository, hotel *Hotel) error {
+	if err := s.audit(ctx, repo, hotel.ID, AuditEntityHotel, hotel.ID, AuditActionCreate, nil, hotel); err != nil {
+		return err
+	}
+	for i := range hotel.ContactInfos {
+		contact := &hotel.ContactInfos[i]
+		if err := s.audit(ctx, repo, hotel.ID, AuditEntityContact, contact.ID, AuditActionCreate, nil, contact); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *hotelService) DeleteHotel(ctx context.Context, id uuid.UUID, version int) error {
+	hotel, err := s.hotelRepo.GetHotelDetails(id)
+	if err != nil {
+		return fmt.Errorf("failed to delete hotel: %w", err)
+	}
+	err = s.hotelRepo.Transaction(func(repo HotelRepository) error {
+		if err := repo.Delete(id, version); err != nil {
+			return err
+		}
+		return s.audit(ctx, repo, id, AuditEntityHotel, id, AuditActionDelete, hotel, nil)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete hotel: %w", err)
+	}
+	s.search.remove(id)
+	return nil
+}
+
+func (s *hotelService) RestoreHotel(ctx context.Context, id uuid.UUID) (*Hotel, error) {
+	var hotel *Hotel
+	err := s.hotelRepo.Transaction(func(repo HotelRepository) error {
+		if err := repo.Restore(id); err != nil {
+			return err
+		}
+		var err error
+		if hotel, err = repo.GetHotelDetails(id); err != nil {
+			return err
+		}
+		return s.audit(ctx, repo, id, AuditEntityHotel, id, AuditActionRestore, nil, hotel)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore hotel: %w", err)
+	}
+	s.search.put(*hotel)
+	return hotel, nil
+}
+
+// PurgeDeletedHotels permanently removes the hotels soft-deleted longer than the
+// retention period ago. The hotels are locked while they are purged, so a hotel
+// restored in the meantime keeps its media.
+func (s *hotelService) PurgeDeletedHotels(retention time.Duration) (int64, error) {
+	before := time.Now().Add(-retention)
+	var media []Media
+	var purged int64
+	err := s.hotelRepo.Transaction(func(repo HotelRepository) error {
+		ids, err := repo.LockDeletedHotels(before)
+		if err != nil {
+			return err
+		}
+		if media, err = repo.ListPurgeableMedia(ids); err != nil {
+			return err
+		}
+		purged, err = repo.PurgeHotels(ids)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge deleted hotels: %w", err)
+	}
+
+	// Blobs are only deleted once the hotels are gone for good
+	for i := range media {
+		s.deleteMediaBlobs(&media[i])
+	}
+	return purged, nil
+}
+
+// StartPurgeWorker purges expired soft-deleted hotels every interval.
+func (s *hotelService) StartPurgeWorker(retention, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			purged, err := s.PurgeDeletedHotels(retention)
+			if err != nil {
+				log.Printf("Failed to purge deleted hotels: %v", err)
+				continue
+			}
+			if purged > 0 {
+				log.Printf("Purged %d hotels deleted more than %s ago", purged, retention)
+			}
+		}
+	}()
+}
+
+func (s *hotelService) UpdateHotel(ctx context.Context, id uuid.UUID, update HotelUpdate, version int) (*Hotel, error) {
+	hotel, err := s.hotelRepo.GetHotelDetails(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update hotel: %w", err)
+	}
+	if version != 0 && hotel.Version != version {
+		return nil, ErrVersionConflict
+	}
+
+	before := *hotel
+	update.Apply(hotel)
+	if err := validateHotel(hotel); err != nil {
+		return nil, err
+	}
+
+	err = s.hotelRepo.Transaction(func(repo HotelRepository) error {
+		if err := repo.UpdateHotel(hotel); err != nil {
+			return err
+		}
+		return s.audit(ctx, repo, id, AuditEntityHotel, id, AuditActionUpdate, &before, hotel)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update hotel: %w", err)
+	}
+	s.search.put(*hotel)
+	return hotel, nil
+}
+
+func (s *hotelService) AddContactInfo(ctx context.Context, hotelID uuid.UUID, contact *ContactInfo, version int) error {
+	hotel, err := s.hotelRepo.GetHotelDetails(hotelID)
+	if err != nil {
+		return fmt.Errorf("failed to add contact info: %w", err)
+	}
+	registry, err := s.contactRegistry()
+	if err != nil {
+		return err
+	}
+	if err := registry.validateContact(contact, hotel); err != nil {
+		return err
+	}
+
+	err = s.hotelRepo.Transaction(func(repo HotelRepository) error {
+		if err := repo.AddContactInfo(hotelID, contact, version); err != nil {
+			return err
+		}
+		return s.audit(ctx, repo, hotelID, AuditEntityContact, contact.ID, AuditActionCreate, nil, contact)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add contact info: %w", err)
+	}
+	s.search.putContact(hotelID, *contact)
+	return nil
+}
+
+func (s *hotelService) RemoveContactInfo(ctx context.Context, hotelID uuid.UUID, contactUUID uuid.UUID, version int) error {
+	contact, err := s.hotelRepo.GetContactInfo(hotelID, contactUUID)
+	if err != nil {
+		return fmt.Errorf("failed to remove contact info: %w", err)
+	}
+	err = s.hotelRepo.Transaction(func(repo HotelRepository) error {
+		if err := repo.RemoveContactInfo(hotelID, contactUUID, version); err != nil {
+			return err
+		}
+		return s.audit(ctx, repo, hotelID, AuditEntityContact, contactUUID, AuditActionDelete, contact, nil)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove contact info: %w", err)
+	}
+	s.search.removeContact(hotelID, contactUUID)
+	return nil
+}
+
+func (s *hotelService) UpdateContactInfo(ctx context.Context, hotelID, contactID uuid.UUID, update ContactInfoUpdate, version int) (*ContactInfo, error) {
+	hotel, err := s.hotelRepo.GetHotelDetails(hotelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update contact info: %w", err)
+	}
+	if version != 0 && hotel.Version != version {
+		return nil, ErrVersionConflict
+	}
+
+	contact, err := s.hotelRepo.GetContactInfo(hotelID, contactID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update contact info: %w", err)
+	}
+
+	before := *contact
+	update.Apply(contact)
+	registry, err := s.contactRegistry()
+	if err != nil {
+		return nil, err
+	}
+	if err := registry.validateContact(contact, hotel); err != nil {
+		return nil, err
+	}
+
+	err = s.hotelRepo.Transaction(func(repo HotelRepository) error {
+		if err := repo.UpdateContactInfo(contact, version); err != nil {
+			return err
+		}
+		return s.audit(ctx, repo, hotelID, AuditEntityContact, contactID, AuditActionUpdate, &before, contact)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update contact info: %w", err)
+	}
+	s.search.putContact(hotelID, *contact)
+	return contact, nil
+}
+
+func (s *hotelService) SetLocation(ctx context.Context, hotelID uuid.UUID, location *Location, version int) error {
+	location.HotelID = hotelID
+	if err := validateLocation(location); err != nil {
+		return err
+	}
+
+	hotel, err := s.hotelRepo.GetHotelDetails(hotelID)
+	if err != nil {
+		return fmt.Errorf("failed to set location: %w", err)
+	}
+	err = s.hotelRepo.Transaction(func(repo HotelRepository) error {
+		if err := repo.SetLocation(location, version); err != nil {
+			return err
+		}
+		if hotel.Location == nil {
+			return s.audit(ctx, repo, hotelID, AuditEntityLocation, location.ID, AuditActionCreate, nil, location)
+		}
+		return s.audit(ctx, repo, hotelID, AuditEntityLocation, location.ID, AuditActionUpdate, hotel.Location, location)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set location: %w", err)
+	}
+	return nil
+}
+
+func (s *hotelService) DeleteLocation(ctx context.Context, hotelID uuid.UUID, version int) error {
+	hotel, err := s.hotelRepo.GetHotelDetails(hotelID)
+	if err != nil {
+		return fmt.Errorf("failed to delete location: %w", err)
+	}
+	if hotel.Location == nil {
+		return fmt.Errorf("failed to delete location: %w", ErrLocationNotFound)
+	}
+	err = s.hotelRepo.Transaction(func(repo HotelRepository) error {
+		if err := repo.DeleteLocation(hotelID, version); err != nil {
+			return err
+		}
+		return s.audit(ctx, repo, hotelID, AuditEntityLocation, hotel.Location.ID, AuditActionDelete, hotel.Location, nil)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete location: %w", err)
+	}
+	return nil
+}
+
+func (s *hotelService) AddOfficial(ctx context.Context, hotelID uuid.UUID, official *HotelOfficial, version int) error {
+	hotel, err := s.hotelRepo.GetHotelDetails(hotelID)
+	if err != nil {
+		return fmt.Errorf("failed to add official: %w", err)
+	}
+	if err := s.validateOfficial(official, hotel); err != nil {
+		return err
+	}
+
+	official.ID = uuid.New()
+	official.HotelID = hotelID
+	official.CompanyTitle = hotel.CompanyTitle
+	err = s.hotelRepo.Transaction(func(repo HotelRepository) error {
+		if err := repo.AddOfficial(official, version); err != nil {
+			return err
+		}
+		return s.audit(ctx, repo, hotelID, AuditEntityOfficial, official.ID, AuditActionCreate, nil, official)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add official: %w", err)
+	}
+	return nil
+}
+
+// UpdateOfficial replaces an official, including all of its contacts.
+func (s *hotelService) UpdateOfficial(ctx context.Context, hotelID, officialID uuid.UUID, official *HotelOfficial, version int) error {
+	hotel, err := s.hotelRepo.GetHotelDetails(hotelID)
+	if err != nil {
+		return fmt.Errorf("failed to update official: %w", err)
+	}
+	before, err := s.hotelRepo.GetOfficial(hotelID, officialID)
+	if err != nil {
+		return fmt.Errorf("failed to update official: %w", err)
+	}
+	if err := s.validateOfficial(official, hotel); err != nil {
+		return err
+	}
+
+	official.ID = officialID
+	official.HotelID = hotelID
+	official.CompanyTitle = hotel.CompanyTitle
+	err = s.hotelRepo.Transaction(func(repo HotelRepository) error {
+		if err := repo.UpdateOfficial(official, version); err != nil {
+			return err
+		}
+		return s.audit(ctx, repo, hotelID, AuditEntityOfficial, officialID, AuditActionUpdate, before, official)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update official: %w", err)
+	}
+	return nil
+}
+
+func (s *hotelService) RemoveOfficial(ctx context.Context, hotelID, officialID uuid.UUID, version int) error {
+	official, err := s.hotelRepo.GetOfficial(hotelID, officialID)
+	if err != nil {
+		return fmt.Errorf("failed to remove official: %w", err)
+	}
+	err = s.hotelRepo.Transaction(func(repo HotelRepository) error {
+		if err := repo.RemoveOfficial(hotelID, officialID, version); err != nil {
+			return err
+		}
+		return s.audit(ctx, repo, hotelID, AuditEntityOfficial, officialID, AuditActionDelete, official, nil)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove official: %w", err)
+	}
+	return nil
+}
+
+// validateOfficial normalizes an official of the hotel against the contact
+// type registry. Its contacts are stored as new contacts.
+func (s *hotelService) validateOfficial(official *HotelOfficial, hotel *Hotel) error {
+	registry, err := s.contactRegistry()
+	if err != nil {
+		return err
+	}
+	for i := range official.ContactInfos {
+		official.ContactInfos[i].ID = uuid.Nil
+	}
+	return registry.validateOfficial(official, phoneCountry(hotel))
+}
+
+func (s *hotelService) ListRoomTypes(hotelID uuid.UUID) ([]RoomType, error) {
+	if _, err := s.hotelRepo.GetHotelDetails(hotelID); err != nil {
+		return nil, fmt.Errorf("failed to list room types: %w", err)
+	}
+	roomTypes, err := s.hotelRepo.ListRoomTypes(hotelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list room types: %w", err)
+	}
+	if roomTypes == nil {
+		roomTypes = []RoomType{}
+	}
+	return roomTypes, nil
+}
+
+func (s *hotelService) GetRoomType(hotelID, roomTypeID uuid.UUID) (*RoomType, error) {
+	roomType, err := s.hotelRepo.GetRoomType(hotelID, roomTypeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get room type: %w", err)
+	}
+	return roomType, nil
+}
+
+func (s *hotelService) AddRoomType(ctx context.Context, hotelID uuid.UUID, roomType *RoomType, version int) error {
+	if _, err := s.hotelRepo.GetHotelDetails(hotelID); err != nil {
+		return fmt.Errorf("failed to add room type: %w", err)
+	}
+	if err := validateRoomType(roomType); err != nil {
+		return err
+	}
+
+	roomType.ID = uuid.New()
+	roomType.HotelID = hotelID
+	err := s.hotelRepo.Transaction(func(repo HotelRepository) error {
+		if err := repo.AddRoomType(roomType, version); err != nil {
+			return err
+		}
+		return s.audit(ctx, repo, hotelID, AuditEntityRoomType, roomType.ID, AuditActionCreate, nil, roomType)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add room type: %w", err)
+	}
+	return nil
+}
+
+// UpdateRoomType replaces a room type.
+func (s *hotelService) UpdateRoomType(ctx context.Context, hotelID, roomTypeID uuid.UUID, roomType *RoomType, version int) error {
+	before, err := s.hotelRepo.GetRoomType(hotelID, roomTypeID)
+	if err != nil {
+		return fmt.Errorf("failed to update room type: %w", err)
+	}
+	if err := validateRoomType(roomType); err != nil {
+		return err
+	}
+
+	roomType.ID = roomTypeID
+	roomType.HotelID = hotelID
+	err = s.hotelRepo.Transaction(func(repo HotelRepository) error {
+		if err := repo.UpdateRoomType(roomType, version, time.Now()); err != nil {
+			return err
+		}
+		return s.audit(ctx, repo, hotelID, AuditEntityRoomType, roomTypeID, AuditActionUpdate, before, roomType)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update room type: %w", err)
+	}
+	return nil
+}
+
+func (s *hotelService) RemoveRoomType(ctx context.Context, hotelID, roomTypeID uuid.UUID, version int) error {
+	roomType, err := s.hotelRepo.GetRoomType(hotelID, roomTypeID)
+	if err != nil {
+		return fmt.Errorf("failed to remove room type: %w", err)
+	}
+	err = s.hotelRepo.Transaction(func(repo HotelRepository) error {
+		if err := repo.RemoveRoomType(hotelID, roomTypeID, version, time.Now()); err != nil {
+			return err
+		}
+		return s.audit(ctx, repo, hotelID, AuditEntityRoomType, roomTypeID, AuditActionDelete, roomType, nil)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove room type: %w", err)
+	}
+	return nil
+}
+
+// GetAvailability returns the availability calendar of the room types of a
+// hotel, or of the room type of the query, for the nights of the query.
+func (s *hotelService) GetAvailability(hotelID uuid.UUID, query AvailabilityQuery) ([]RoomAvailability, error) {
+	from, to := day(query.From), day(query.To)
+	if stay := nights(from, to); stay < 1 || stay > maxNights {
+		return nil, ErrInvalidDateRange
+	}
+
+	var roomTypes []RoomType
+	if query.RoomTypeID != uuid.Nil {
+		roomType, err := s.hotelRepo.GetRoomType(hotelID, query.RoomTypeID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get availability: %w", err)
+		}
+		roomTypes = []RoomType{*roomType}
+	} else {
+		var err error
+		if roomTypes, err = s.ListRoomTypes(hotelID); err != nil {
+			return nil, fmt.Errorf("failed to get availability: %w", err)
+		}
+	}
+
+	calendar := make([]RoomAvailability, 0, len(roomTypes))
+	if len(roomTypes) == 0 {
+		return calendar, nil
+	}
+	roomTypeIDs := make([]uuid.UUID, len(roomTypes))
+	for i := range roomTypes {
+		roomTypeIDs[i] = roomTypes[i].ID
+	}
+	reservations, err := s.hotelRepo.ListActiveReservations(roomTypeIDs, from, to, time.Now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get availability: %w", err)
+	}
+
+	for i := range roomTypes {
+		calendar = append(calendar, RoomAvailability{
+			RoomTypeID: roomTypes[i].ID,
+			Name:       roomTypes[i].Name,
+			RoomCount:  roomTypes[i].RoomCount,
+			Dates:      availability(&roomTypes[i], reservations, from, to),
+		})
+	}
+	return calendar, nil
+}
+
+func (s *hotelService) GetReservation(hotelID, reservationID uuid.UUID) (*Reservation, error) {
+	reservation, err := s.hotelRepo.GetReservation(hotelID, reservationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reservation: %w", err)
+	}
+	return reservation, nil
+}
+
+// CreateReservation holds or books rooms. Holds expire after HoldDuration
+// unless they are confirmed.
+func (s *hotelService) CreateReservation(ctx context.Context, hotelID uuid.UUID, reservation *Reservation) error {
+	if _, err := s.hotelRepo.GetHotelDetails(hotelID); err != nil {
+		return fmt.Errorf("failed to create reservation: %w", err)
+	}
+	now := time.Now().UTC()
+	if err := validateReservation(reservation, day(now)); err != nil {
+		return err
+	}
+
+	reservation.ID = uuid.New()
+	reservation.HotelID = hotelID
+	reservation.CreatedAt = now
+	reservation.ExpiresAt = nil
+	if reservation.Status == ReservationHold {
+		expiresAt := now.Add(HoldDuration)
+		reservation.ExpiresAt = &expiresAt
+	}
+	err := s.hotelRepo.Transaction(func(repo HotelRepository) error {
+		if err := repo.CreateReservation(reservation, now); err != nil {
+			return err
+		}
+		return s.audit(ctx, repo, hotelID, AuditEntityReservation, reservation.ID, AuditActionCreate, nil, reservation)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create reservation: %w", err)
+	}
+	return nil
+}
+
+// ConfirmReservation turns a hold into a booking before it expires.
+func (s *hotelService) ConfirmReservation(ctx context.Context, hotelID, reservationID uuid.UUID) (*Reservation, error) {
+	before, err := s.hotelRepo.GetReservation(hotelID, reservationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to confirm reservation: %w", err)
+	}
+	after := *before
+	after.Status, after.ExpiresAt = ReservationBooked, nil
+	err = s.hotelRepo.Transaction(func(repo HotelRepository) error {
+		if err := repo.ConfirmReservation(hotelID, reservationID, time.Now().UTC()); err != nil {
+			return err
+		}
+		return s.audit(ctx, repo, hotelID, AuditEntityReservation, reservationID, AuditActionUpdate, before, &after)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to confirm reservation: %w", err)
+	}
+	return &after, nil
+}
+
+// CancelReservation releases the rooms of a hold or booking.
+func (s *hotelService) CancelReservation(ctx context.Context, hotelID, reservationID uuid.UUID) error {
+	before, err := s.hotelRepo.GetReservation(hotelID, reservationID)
+	if err != nil {
+		return fmt.Errorf("failed to cancel reservation: %w", err)
+	}
+	if before.Status == ReservationCancelled {
+		return nil
+	}
+	after := *before
+	after.Status, after.ExpiresAt = ReservationCancelled, nil
+	err = s.hotelRepo.Transaction(func(repo HotelRepository) error {
+		if err := repo.CancelReservation(hotelID, reservationID); err != nil {
+			return err
+		}
+		return s.audit(ctx, repo, hotelID, AuditEntityReservation, reservationID, AuditActionUpdate, before, &after)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to cancel reservation: %w", err)
+	}
+	return nil
+}
+
+// ListReviews returns the reviews of a hotel with a moderation status, newest
+// first. Only approved reviews are listed unless the filter asks otherwise.
+func (s *hotelService) ListReviews(filter ReviewFilter) ([]Review, error) {
+	if filter.Status == "" {
+		filter.Status = ReviewApproved
+	}
+	if !validReviewStatus(filter.Status) {
+		return nil, ErrInvalidReviewStatus
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultPageLimit
+	}
+	if filter.Limit > MaxPageLimit {
+		filter.Limit = MaxPageLimit
+	}
+
+	if _, err := s.hotelRepo.GetHotelDetails(filter.HotelID); err != nil {
+		return nil, fmt.Errorf("failed to list reviews: %w", err)
+	}
+	reviews, err := s.hotelRepo.ListReviews(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reviews: %w", err)
+	}
+	if reviews == nil {
+		reviews = []Review{}
+	}
+	return reviews, nil
+}
+
+// AddReview stores a review awaiting moderation. It counts toward the rating
+// of the hotel once it is approved.
+func (s *hotelService) AddReview(ctx context.Context, hotelID uuid.UUID, review *Review) error {
+	if _, err := s.hotelRepo.GetHotelDetails(hotelID); err != nil {
+		return fmt.Errorf("failed to add review: %w", err)
+	}
+	if err := validateReview(review); err != nil {
+		return err
+	}
+
+	review.ID = uuid.New()
+	review.HotelID = hotelID
+	review.Date = time.Now().UTC()
+	review.Status = ReviewPending
+	err := s.hotelRepo.Transaction(func(repo HotelRepository) error {
+		if err := repo.AddReview(review); err != nil {
+			return err
+		}
+		return s.audit(ctx, repo, hotelID, AuditEntityReview, review.ID, AuditActionCreate, nil, review)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add review: %w", err)
+	}
+	return nil
+}
+
+// ModerateReview sets the moderation status of a review, which updates the
+// rating of the hotel when the review is approved or no longer approved.
+func (s *hotelService) ModerateReview(ctx context.Context, hotelID, reviewID uuid.UUID, status string) (*Review, error) {
+	status = strings.ToLower(strings.TrimSpace(status))
+	if !validReviewStatus(status) {
+		return nil, &ValidationError{Fields: []FieldError{{"status", "must be pending, approved or rejected"}}}
+	}
+
+	before, err := s.hotelRepo.GetReview(hotelID, reviewID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to moderate review: %w", err)
+	}
+	if before.Status == status {
+		return before, nil
+	}
+	after := *before
+	after.Status = status
+	err = s.hotelRepo.Transaction(func(repo HotelRepository) error {
+		if err := repo.SetReviewStatus(hotelID, reviewID, status); err != nil {
+			return err
+		}
+		return s.audit(ctx, repo, hotelID, AuditEntityReview, reviewID, AuditActionUpdate, before, &after)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to moderate review: %w", err)
+	}
+	return &after, nil
+}
+
+func (s *hotelService) RemoveReview(ctx context.Context, hotelID, reviewID uuid.UUID) error {
+	review, err := s.hotelRepo.GetReview(hotelID, reviewID)
+	if err != nil {
+		return fmt.Errorf("failed to remove review: %w", err)
+	}
+	err = s.hotelRepo.Transaction(func(repo HotelRepository) error {
+		if err := repo.RemoveReview(hotelID, reviewID); err != nil {
+			return err
+		}
+		return s.audit(ctx, repo, hotelID, AuditEntityReview, reviewID, AuditActionDelete, review, nil)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove review: %w", err)
+	}
+	return nil
+}
+
+func (s *hotelService) ListTags() ([]Tag, error) {
+	tags, err := s.hotelRepo.ListTags()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+	return tags, nil
+}
+
+func (s *hotelService) GetTag(name string) (*Tag, error) {
+	tag, err := s.hotelRepo.GetTag(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tag: %w", err)
+	}
+	return tag, nil
+}
+
+func (s *hotelService) CreateTag(tag *Tag) error {
+	if err := validateTag(tag); err != nil {
+		return err
+	}
+	if err := s.hotelRepo.CreateTag(tag); err != nil {
+		return fmt.Errorf("failed to create tag: %w", err)
+	}
+	return nil
+}
+
+// UpdateTag replaces the tag with the given name. The name itself cannot
+// change, as hotels refer to it.
+func (s *hotelService) UpdateTag(name string, tag *Tag) error {
+	tag.Name = name
+	if err := validateTag(tag); err != nil {
+		return err
+	}
+	if err := s.hotelRepo.UpdateTag(tag); err != nil {
+		return fmt.Errorf("failed to update tag: %w", err)
+	}
+	return nil
+}
+
+func (s *hotelService) DeleteTag(name string) error {
+	if err := s.hotelRepo.DeleteTag(name); err != nil {
+		return fmt.Errorf("failed to delete tag: %w", err)
+	}
+	return nil
+}
+
+// SetHotelTags replaces the tags of a hotel with tags of the vocabulary and
+// returns them normalized.
+func (s *hotelService) SetHotelTags(ctx context.Context, hotelID uuid.UUID, tags []string, version int) ([]string, error) {
+	hotel, err := s.hotelRepo.GetHotelDetails(hotelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set tags: %w", err)
+	}
+	vocabulary, err := s.hotelRepo.ListTags()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tags: %w", err)
+	}
+
+	known := make(map[string]bool, len(vocabulary))
+	for _, tag := range vocabulary {
+		known[tag.Name] = true
+	}
+	var fields []FieldError
+	for i, tag := range tags {
+		if name := strings.ToLower(strings.TrimSpace(tag)); !known[name] {
+			fields = append(fields, FieldError{fmt.Sprintf("tags[%d]", i), fmt.Sprintf("unknown tag %q", tag)})
+		}
+	}
+	if len(fields) > 0 {
+		return nil, &ValidationError{Fields: fields}
+	}
+
+	tags = normalizeTags(tags)
+	before := make([]string, len(hotel.Tags))
+	for i, tag := range hotel.Tags {
+		before[i] = tag.TagName
+	}
+	err = s.hotelRepo.Transaction(func(repo HotelRepository) error {
+		if err := repo.SetHotelTags(hotelID, tags, version); err != nil {
+			return err
+		}
+		return s.audit(ctx, repo, hotelID, AuditEntityHotel, hotelID, AuditActionUpdate,
+			map[string]string{"tags": strings.Join(normalizeTags(before), ", ")},
+			map[string]string{"tags": strings.Join(tags, ", ")})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set tags: %w", err)
+	}
+	return tags, nil
+}
+
+func (s *hotelService) ListChains(kind string) ([]Chain, error) {
+	chains, err := s.hotelRepo.ListChains(strings.ToLower(strings.TrimSpace(kind)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list chains: %w", err)
+	}
+	if chains == nil {
+		chains = []Chain{}
+	}
+	return chains, nil
+}
+
+func (s *hotelService) GetChain(id uuid.UUID) (*Chain, error) {
+	chain, err := s.hotelRepo.GetChain(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chain: %w", err)
+	}
+	return chain, nil
+}
+
+func (s *hotelService) CreateChain(chain *Chain) error {
+	chain.ID = uuid.New()
+	if err := s.validateChain(chain); err != nil {
+		return err
+	}
+
+	chain.CreatedAt = time.Now().UTC()
+	chain.UpdatedAt = chain.CreatedAt
+	if err := s.hotelRepo.CreateChain(chain); err != nil {
+		return fmt.Errorf("failed to create chain: %w", err)
+	}
+	return nil
+}
+
+// UpdateChain renames a chain or moves a brand to another group. The kind of
+// a chain cannot change, as groups and brands are referred to differently.
+func (s *hotelService) UpdateChain(id uuid.UUID, chain *Chain) error {
+	stored, err := s.hotelRepo.GetChain(id)
+	if err != nil {
+		return fmt.Errorf("failed to update chain: %w", err)
+	}
+
+	chain.ID = id
+	chain.Kind = stored.Kind
+	if err := s.validateChain(chain); err != nil {
+		return err
+	}
+
+	chain.CreatedAt = stored.CreatedAt
+	chain.UpdatedAt = time.Now().UTC()
+	if err := s.hotelRepo.UpdateChain(chain); err != nil {
+		return fmt.Errorf("failed to update chain: %w", err)
+	}
+	chain.Brands = stored.Brands
+	return nil
+}
+
+func (s *hotelService) DeleteChain(id uuid.UUID) error {
+	if err := s.hotelRepo.DeleteChain(id); err != nil {
+		return fmt.Errorf("failed to delete chain: %w", err)
+	}
+	return nil
+}
+
+// validateChain validates a chain and checks that the parent of a brand is a
+// group.
+func (s *hotelService) validateChain(chain *Chain) error {
+	if err := validateChain(chain); err != nil {
+		return err
+	}
+	if chain.ParentID == nil {
+		return nil
+	}
+
+	parent, err := s.hotelRepo.GetChain(*chain.ParentID)
+	if errors.Is(err, ErrChainNotFound) || (err == nil && parent.Kind != ChainKindGroup) {
+		return &ValidationError{Fields: []FieldError{{"parent_id", "must be an existing group"}}}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load parent chain: %w", err)
+	}
+	return nil
+}
+
+// SetHotelChain assigns a hotel to a brand or group, or to no chain for a nil
+// chain ID.
+func (s *hotelService) SetHotelChain(ctx context.Context, hotelID uuid.UUID, chainID *uuid.UUID, version int) error {
+	hotel, err := s.hotelRepo.GetHotelDetails(hotelID)
+	if err != nil {
+		return fmt.Errorf("failed to set chain: %w", err)
+	}
+	if chainID != nil {
+		if _, err := s.hotelRepo.GetChain(*chainID); errors.Is(err, ErrChainNotFound) {
+			return &ValidationError{Fields: []FieldError{{"chain_id", "unknown chain"}}}
+		} else if err != nil {
+			return fmt.Errorf("failed to load chain: %w", err)
+		}
+	}
+
+	chainIDOf := func(id *uuid.UUID) string {
+		if id == nil {
+			return ""
+		}
+		return id.String()
+	}
+	err = s.hotelRepo.Transaction(func(repo HotelRepository) error {
+		if err := repo.SetHotelChain(hotelID, chainID, version); err != nil {
+			return err
+		}
+		return s.audit(ctx, repo, hotelID, AuditEntityHotel, hotelID, AuditActionUpdate,
+			map[string]string{"chain_id": chainIDOf(hotel.ChainID)},
+			map[string]string{"chain_id": chainIDOf(chainID)})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set chain: %w", err)
+	}
+	return nil
+}
+
+// ListChainHotels lists the hotels of a chain and, for a group, of its
+// brands, with the options of ListHotels.
+func (s *hotelService) ListChainHotels(chainID uuid.UUID, opts ListOptions) (*HotelPage, error) {
+	if _, err := s.hotelRepo.GetChain(chainID); err != nil {
+		return nil, fmt.Errorf("failed to list hotels of chain: %w", err)
+	}
+	opts.Location.ChainID = chainID
+	return s.ListHotels(opts)
+}
+
+// FetchChainStats counts the hotels of a chain per city and the hotels that
+// can be reached through each contact type.
+func (s *hotelService) FetchChainStats(chainID uuid.UUID) (*ChainStats, error) {
+	if _, err := s.hotelRepo.GetChain(chainID); err != nil {
+		return nil, fmt.Errorf("failed to fetch chain stats: %w", err)
+	}
+
+	filter := LocationFilter{ChainID: chainID}
+	hotelCount, err := s.hotelRepo.CountHotels(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch chain stats: %w", err)
+	}
+	locations, err := s.hotelRepo.CountHotelsByCity(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch chain stats: %w", err)
+	}
+	hotelsByType, err := s.hotelRepo.CountHotelsByContactType(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch chain stats: %w", err)
+	}
+	registry, err := s.contactRegistry()
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &ChainStats{
+		ChainID:         chainID,
+		HotelCount:      hotelCount,
+		Locations:       locations,
+		UnlocatedCount:  hotelCount,
+		ContactCoverage: contactCoverage(registry, hotelsByType, hotelCount),
+	}
+	if stats.Locations == nil {
+		stats.Locations = []LocationCount{}
+	}
+	for _, location := range stats.Locations {
+		stats.UnlocatedCount -= location.HotelCount
+	}
+	return stats, nil
+}
+
+func (s *hotelService) ListMedia(hotelID uuid.UUID) ([]Media, error) {
+	if _, err := s.hotelRepo.GetHotelDetails(hotelID); err != nil {
+		return nil, fmt.Errorf("failed to list media: %w", err)
+	}
+	media, err := s.hotelRepo.ListMedia(hotelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list media: %w", err)
+	}
+	if media == nil {
+		media = []Media{}
+	}
+	return media, nil
+}
+
+// AddMedia stores an uploaded file, and the thumbnail of an image, in the blob
+// store and records it as the last media of the hotel.
+func (s *hotelService) AddMedia(ctx context.Context, hotelID uuid.UUID, fileName string, data []byte, version int) (*Media, error) {
+	if _, err := s.hotelRepo.GetHotelDetails(hotelID); err != nil {
+		return nil, fmt.Errorf("failed to add media: %w", err)
+	}
+
+	media := &Media{ID: uuid.New(), HotelID: hotelID, FileName: fileName}
+	thumb, err := inspectMedia(media, data)
+	if err != nil {
+		return nil, err
+	}
+	media.StorageKey = mediaKey(hotelID, media.ID)
+	media.HasThumbnail = thumb != nil
+
+	if err := s.blobs.Put(media.StorageKey, bytes.NewReader(data)); err != nil {
+		return nil, fmt.Errorf("failed to store media: %w", err)
+	}
+	if media.HasThumbnail {
+		if err := s.blobs.Put(thumbnailKey(media.StorageKey), bytes.NewReader(thumb)); err != nil {
+			s.deleteMediaBlobs(media)
+			return nil, fmt.Errorf("failed to store thumbnail: %w", err)
+		}
+	}
+	err = s.hotelRepo.Transaction(func(repo HotelRepository) error {
+		if err := repo.AddMedia(media, version); err != nil {
+			return err
+		}
+		return s.audit(ctx, repo, hotelID, AuditEntityMedia, media.ID, AuditActionCreate, nil, media)
+	})
+	if err != nil {
+		s.deleteMediaBlobs(media)
+		return nil, fmt.Errorf("failed to add media: %w", err)
+	}
+	return media, nil
+}
+
+// OpenMedia returns a media with its file, or with its thumbnail. The caller
+// closes the file.
+func (s *hotelService) OpenMedia(hotelID, mediaID uuid.UUID, thumbnail bool) (*Media, io.ReadCloser, error) {
+	media, err := s.hotelRepo.GetMedia(hotelID, mediaID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open media: %w", err)
+	}
+	key := media.StorageKey
+	if thumbnail {
+		if !media.HasThumbnail {
+			return nil, nil, ErrThumbnailNotFound
+		}
+		key = thumbnailKey(key)
+	}
+
+	file, err := s.blobs.Open(key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open media: %w", err)
+	}
+	return media, file, nil
+}
+
+// ReorderMedia sets the display order of the media of a hotel and returns them
+// in that order.
+func (s *hotelService) ReorderMedia(ctx context.Context, hotelID uuid.UUID, mediaIDs []uuid.UUID, version int) ([]Media, error) {
+	before, err := s.hotelRepo.ListMedia(hotelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reorder media: %w", err)
+	}
+	positions := make(map[uuid.UUID]Media, len(before))
+	for _, media := range before {
+		positions[media.ID] = media
+	}
+
+	var after []Media
+	err = s.hotelRepo.Transaction(func(repo HotelRepository) error {
+		if err := repo.ReorderMedia(hotelID, mediaIDs, version); err != nil {
+			return err
+		}
+		var err error
+		if after, err = repo.ListMedia(hotelID); err != nil {
+			return err
+		}
+		for i := range after {
+			if old := positions[after[i].ID]; old.Position != after[i].Position {
+				if err := s.audit(ctx, repo, hotelID, AuditEntityMedia, after[i].ID, AuditActionUpdate, old, after[i]); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to reorder media: %w", err)
+	}
+	return after, nil
+}
+
+// RemoveMedia deletes a media and then its blobs.
+func (s *hotelService) RemoveMedia(ctx context.Context, hotelID, mediaID uuid.UUID, version int) error {
+	media, err := s.hotelRepo.GetMedia(hotelID, mediaID)
+	if err != nil {
+		return fmt.Errorf("failed to remove media: %w", err)
+	}
+	err = s.hotelRepo.Transaction(func(repo HotelRepository) error {
+		if err := repo.RemoveMedia(hotelID, mediaID, version); err != nil {
+			return err
+		}
+		return s.audit(ctx, repo, hotelID, AuditEntityMedia, mediaID, AuditActionDelete, media, nil)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove media: %w", err)
+	}
+	s.deleteMediaBlobs(media)
+	return nil
+}
+
+// deleteMediaBlobs removes the file and thumbnail of a media from the blob
+// store. Failures only leave unreferenced blobs behind, so they are logged.
+func (s *hotelService) deleteMediaBlobs(media *Media) {
+	keys := []string{media.StorageKey}
+	if media.HasThumbnail {
+		keys = append(keys, thumbnailKey(media.StorageKey))
+	}
+	for _, key := range keys {
+		if err := s.blobs.Delete(key); err != nil {
+			log.Printf("Failed to delete blob %s of media %s: %v", key, media.ID, err)
+		}
+	}
+}
+
+// ListRatePlans returns the rate plans of a hotel, or of one of its room types
+// when roomTypeID is set.
+func (s *hotelService) ListRatePlans(hotelID, roomTypeID uuid.UUID) ([]RatePlan, error) {
+	if roomTypeID != uuid.Nil {
+		if _, err := s.hotelRepo.GetRoomType(hotelID, roomTypeID); err != nil {
+			return nil, fmt.Errorf("failed to list rate plans: %w", err)
+		}
+	} else if _, err := s.hotelRepo.GetHotelDetails(hotelID); err != nil {
+		return nil, fmt.Errorf("failed to list rate plans: %w", err)
+	}
+	plans, err := s.hotelRepo.ListRatePlans(hotelID, roomTypeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rate plans: %w", err)
+	}
+	if plans == nil {
+		plans = []RatePlan{}
+	}
+	return plans, nil
+}
+
+func (s *hotelService) GetRatePlan(hotelID, ratePlanID uuid.UUID) (*RatePlan, error) {
+	plan, err := s.hotelRepo.GetRatePlan(hotelID, ratePlanID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rate plan: %w", err)
+	}
+	return plan, nil
+}
+
+func (s *hotelService) AddRatePlan(ctx context.Context, hotelID uuid.UUID, plan *RatePlan, version int) error {
+	if err := validateRatePlan(plan); err != nil {
+		return err
+	}
+
+	plan.ID = uuid.New()
+	plan.HotelID = hotelID
+	plan.CreatedAt = time.Now().UTC()
+	err := s.hotelRepo.Transaction(func(repo HotelRepository) error {
+		if err := repo.AddRatePlan(plan, version); err != nil {
+			return err
+		}
+		return s.audit(ctx, repo, hotelID, AuditEntityRatePlan, plan.ID, AuditActionCreate, nil, plan)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add rate plan: %w", err)
+	}
+	return nil
+}
+
+// UpdateRatePlan replaces a rate plan.
+func (s *hotelService) UpdateRatePlan(ctx context.Context, hotelID, ratePlanID uuid.UUID, plan *RatePlan, version int) error {
+	before, err := s.hotelRepo.GetRatePlan(hotelID, ratePlanID)
+	if err != nil {
+		return fmt.Errorf("failed to update rate plan: %w", err)
+	}
+	if err := validateRatePlan(plan); err != nil {
+		return err
+	}
+
+	plan.ID = ratePlanID
+	plan.HotelID = hotelID
+	plan.CreatedAt = before.CreatedAt
+	err = s.hotelRepo.Transaction(func(repo HotelRepository) error {
+		if err := repo.UpdateRatePlan(plan, version); err != nil {
+			return err
+		}
+		return s.audit(ctx, repo, hotelID, AuditEntityRatePlan, ratePlanID, AuditActionUpdate, before, plan)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update rate plan: %w", err)
+	}
+	return nil
+}
+
+func (s *hotelService) RemoveRatePlan(ctx context.Context, hotelID, ratePlanID uuid.UUID, version int) error {
+	plan, err := s.hotelRepo.GetRatePlan(hotelID, ratePlanID)
+	if err != nil {
+		return fmt.Errorf("failed to remove rate plan: %w", err)
+	}
+	err = s.hotelRepo.Transaction(func(repo HotelRepository) error {
+		if err := repo.RemoveRatePlan(hotelID, ratePlanID, version); err != nil {
+			return err
+		}
+		return s.audit(ctx, repo, hotelID, AuditEntityRatePlan, ratePlanID, AuditActionDelete, plan, nil)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove rate plan: %w", err)
+	}
+	return nil
+}
+
+// QuoteStay prices a stay with the rate plans of a room type. Prices are
+// converted with the exchange rates in effect on the day of the quote.
+func (s *hotelService) QuoteStay(hotelID uuid.UUID, request QuoteRequest) (*Quote, error) {
+	request.CheckIn, request.CheckOut = day(request.CheckIn), day(request.CheckOut)
+	if stay := nights(request.CheckIn, request.CheckOut); stay < 1 || stay > maxNights {
+		return nil, ErrInvalidDateRange
+	}
+	var fields []FieldError
+	if request.RoomTypeID == uuid.Nil {
+		fields = append(fields, FieldError{"room_type_id", "is required"})
+	}
+	if request.Rooms == 0 {
+		request.Rooms = 1
+	}
+	if request.Rooms < 1 {
+		fields = append(fields, FieldError{"rooms", "must be at least 1"})
+	}
+	request.MealPlan = strings.ToLower(strings.TrimSpace(request.MealPlan))
+	if request.MealPlan == "" {
+		request.MealPlan = MealPlanRoomOnly
+	}
+	if !mealPlans[request.MealPlan] {
+		fields = append(fields, FieldError{"meal_plan", "must be one of all_inclusive, breakfast, full_board, half_board, room_only"})
+	}
+	if request.Currency != "" {
+		currency, err := money.NormalizeCurrency(request.Currency)
+		if err != nil {
+			fields = append(fields, FieldError{"currency", "must be an ISO 4217 code such as EUR or TRY"})
+		}
+		request.Currency = currency
+	}
+	if len(fields) > 0 {
+		return nil, &ValidationError{Fields: fields}
+	}
+
+	if _, err := s.hotelRepo.GetRoomType(hotelID, request.RoomTypeID); err != nil {
+		return nil, fmt.Errorf("failed to quote stay: %w", err)
+	}
+	plans, err := s.hotelRepo.ListRatePlansForStay(hotelID, request.RoomTypeID, request.MealPlan, request.CheckIn, request.CheckOut)
+	if err != nil {
+		return nil, fmt.Errorf("failed to quote stay: %w", err)
+	}
+
+	now := time.Now().UTC()
+	quote, err := quoteStay(plans, request, s.converter(day(now)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to quote stay: %w", err)
+	}
+	quote.HotelID = hotelID
+	quote.QuotedAt = now
+	return quote, nil
+}
+
+// converter returns a converter using the exchange rates in effect on a day.
+// Rates are looked up once per currency pair.
+func (s *hotelService) converter(on time.Time) converter {
+	exchanges := make(map[[2]string]exchange)
+	return func(from, to string) (exchange, error) {
+		if from == to {
+			return exchange{rate: money.NewFromInt(1)}, nil
+		}
+		if cached, ok := exchanges[[2]string{from, to}]; ok {
+			return cached, nil
+		}
+		rate, err := s.hotelRepo.FindExchangeRate(from, to, on)
+		if err != nil {
+			return exchange{}, fmt.Errorf("failed to convert %s to %s: %w", from, to, err)
+		}
+		found := exchange{rate: rate.Rate, inverse: rate.BaseCurrency != from}
+		exchanges[[2]string{from, to}] = found
+		return found, nil
+	}
+}
+
+func (s *hotelService) ListExchangeRates(filter ExchangeRateFilter) ([]ExchangeRate, error) {
+	for _, currency := range []*string{&filter.BaseCurrency, &filter.QuoteCurrency} {
+		if *currency == "" {
+			continue
+		}
+		code, err := money.NormalizeCurrency(*currency)
+		if err != nil {
+			return nil, err
+		}
+		*currency = code
+	}
+	rates, err := s.hotelRepo.ListExchangeRates(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list exchange rates: %w", err)
+	}
+	if rates == nil {
+		rates = []ExchangeRate{}
+	}
+	return rates, nil
+}
+
+// SaveExchangeRate creates or replaces the rate of a currency pair for its
+// effective date.
+func (s *hotelService) SaveExchangeRate(rate *ExchangeRate) error {
+	if err := validateExchangeRate(rate); err != nil {
+		return err
+	}
+	rate.UpdatedAt = time.Now().UTC()
+	if err := s.hotelRepo.SaveExchangeRate(rate); err != nil {
+		return fmt.Errorf("failed to save exchange rate: %w", err)
+	}
+	return nil
+}
+
+func (s *hotelService) RemoveExchangeRate(base, quote string, effectiveDate time.Time) error {
+	base, err := money.NormalizeCurrency(base)
+	if err != nil {
+		return err
+	}
+	if quote, err = money.NormalizeCurrency(quote); err != nil {
+		return err
+	}
+	if err := s.hotelRepo.RemoveExchangeRate(base, quote, day(effectiveDate)); err != nil {
+		return fmt.Errorf("failed to remove exchange rate: %w", err)
+	}
+	return nil
+}
+
+// ListTranslations returns the translations of a hotel ordered by language.
+func (s *hotelService) ListTranslations(hotelID uuid.UUID) ([]HotelTranslation, error) {
+	if _, err := s.hotelRepo.GetHotelDetails(hotelID); err != nil {
+		return nil, fmt.Errorf("failed to list translations: %w", err)
+	}
+	return s.FindTranslations([]uuid.UUID{hotelID})
+}
+
+// FindTranslations returns the translations of the hotels, which need not
+// exist.
+func (s *hotelService) FindTranslations(hotelIDs []uuid.UUID) ([]HotelTranslation, error) {
+	translations, err := s.hotelRepo.ListTranslations(hotelIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list translations: %w", err)
+	}
+	if translations == nil {
+		translations = []HotelTranslation{}
+	}
+	return translations, nil
+}
+
+// SaveTranslation creates or replaces the translation of a hotel into a
+// language. The language is stored in canonical form.
+func (s *hotelService) SaveTranslation(ctx context.Context, hotelID uuid.UUID, language string, translation *HotelTranslation, version int) error {
+	language, err := canonicalLanguage(language)
+	if err != nil {
+		return err
+	}
+	if err := validateTranslation(translation); err != nil {
+		return err
+	}
+
+	before, err := s.hotelRepo.GetTranslation(hotelID, language)
+	if err != nil && !errors.Is(err, ErrTranslationNotFound) {
+		return fmt.Errorf("failed to save translation: %w", err)
+	}
+
+	translation.HotelID = hotelID
+	translation.Language = language
+	translation.UpdatedAt = time.Now().UTC()
+	err = s.hotelRepo.Transaction(func(repo HotelRepository) error {
+		if err := repo.SaveTranslation(translation, version); err != nil {
+			return err
+		}
+		if before == nil {
+			return s.audit(ctx, repo, hotelID, AuditEntityTranslation, hotelID, AuditActionCreate, nil, translation)
+		}
+		return s.audit(ctx, repo, hotelID, AuditEntityTranslation, hotelID, AuditActionUpdate, before, translation)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save translation: %w", err)
 	}
-	hotel := NewHotel(ownerName, ownerSurname, companyTitle, contacts)
-	if err := s.hotelRepo.Save(hotel); err != nil {
+	return nil
+}
+
+func (s *hotelService) RemoveTranslation(ctx context.Context, hotelID uuid.UUID, language string, version int) error {
+	language, err := canonicalLanguage(language)
+	if err != nil {
+		return err
+	}
+	translation, err := s.hotelRepo.GetTranslation(hotelID, language)
+	if err != nil {
+		return fmt.Errorf("failed to remove translation: %w", err)
+	}
+	err = s.hotelRepo.Transaction(func(repo HotelRepository) error {
+		if err := repo.RemoveTranslation(hotelID, language, version); err != nil {
+			return err
+		}
+		return s.audit(ctx, repo, hotelID, AuditEntityTranslation, hotelID, AuditActionDelete, translation, nil)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove translation: %w", err)
+	}
+	return nil
+}
+
+// ListMissingTranslations returns a page of the hotels lacking a translation
+// of any field into any of the languages of the filter.
+func (s *hotelService) ListMissingTranslations(filter MissingTranslationFilter) (*MissingTranslationPage, error) {
+	if len(filter.Languages) == 0 {
+		filter.Languages = SupportedLanguages
+	}
+	languages := make([]string, 0, len(filter.Languages))
+	seen := make(map[string]bool)
+	for _, tag := range filter.Languages {
+		language, err := canonicalLanguage(tag)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[language] {
+			seen[language] = true
+			languages = append(languages, language)
+		}
+	}
+	filter.Languages = languages
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultPageLimit
+	}
+	if filter.Limit > MaxPageLimit {
+		filter.Limit = MaxPageLimit
+	}
+
+	hotels, total, err := s.hotelRepo.ListMissingTranslations(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list missing translations: %w", err)
+	}
+
+	page := &MissingTranslationPage{
+		Items:     make([]MissingTranslations, len(hotels)),
+		Languages: languages,
+		Total:     total,
+		Limit:     filter.Limit,
+		Offset:    filter.Offset,
+	}
+	for i, hotel := range hotels {
+		page.Items[i] = MissingTranslations{
+			HotelID:      hotel.ID,
+			CompanyTitle: hotel.CompanyTitle,
+			Missing:      missingTranslations(hotel.Translations, languages),
+		}
+	}
+	return page, nil
+}
+
+// ListAuditEntries returns a page of the audit log, newest entries first.
+func (s *hotelService) ListAuditEntries(filter AuditFilter) (*AuditPage, error) {
+	if err := filter.normalize(); err != nil {
 		return nil, err
 	}
-	return hotel, nil
+
+	entries, err := s.hotelRepo.ListAuditEntries(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit entries: %w", err)
+	}
+
+	page := &AuditPage{Items: entries, Limit: filter.Limit}
+	if len(entries) > filter.Limit {
+		page.Items = entries[:filter.Limit]
+		page.NextCursor = filter.nextCursor(page.Items[filter.Limit-1])
+	}
+	if page.Items == nil {
+		page.Items = []AuditEntry{}
+	}
+	return page, nil
 }
 
-func (s *hotelService) DeleteHotel(id uuid.UUID) error {
-	if err := s.hotelRepo.Delete(id); err != nil {
-		return fmt.Errorf("failed to delete hotel: %w", err)
+// ImportHotels validates every row like CreateHotel does and stores the valid
+// hotels as the options describe.
+func (s *hotelService) ImportHotels(ctx context.Context, rows []ImportRow, opts ImportOptions) (*ImportResult, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
 	}
-	return nil
+	return s.importHotels(ctx, ImportRows(rows), opts, func(*ImportResult) {})
 }
 
-func (s *hotelService) AddContactInfo(hotelID uuid.UUID, contact *ContactInfo) error {
-	if err := s.hotelRepo.AddContactInfo(hotelID, contact); err != nil {
-		return fmt.Errorf("failed to add contact info: %w", err)
+// StartImportJob runs an import in the background. The returned job is updated
+// after every batch and can be polled with GetImportJob. The job takes over the
+// source and closes it when it ends, or right away if it cannot be started.
+func (s *hotelService) StartImportJob(ctx context.Context, source ImportSource, opts ImportOptions) (*ImportJob, error) {
+	if err := opts.validate(); err != nil {
+		source.Close()
+		return nil, err
+	}
+
+	job := &ImportJob{
+		ID:        uuid.New(),
+		Status:    ImportInProgress,
+		DryRun:    opts.DryRun,
+		BatchSize: opts.BatchSize,
+		Total:     source.Len(),
+		Errors:    ImportErrors{},
+		CreatedAt: time.Now(),
+	}
+	if err := s.hotelRepo.CreateImportJob(job); err != nil {
+		source.Close()
+		return nil, fmt.Errorf("failed to start import job: %w", err)
+	}
+
+	// The job outlives the request that started it.
+	go s.runImportJob(context.WithoutCancel(ctx), *job, source, opts)
+	return job, nil
+}
+
+func (s *hotelService) runImportJob(ctx context.Context, job ImportJob, source ImportSource, opts ImportOptions) {
+	defer func() {
+		if err := source.Close(); err != nil {
+			log.Printf("Failed to close the source of import job %s: %v", job.ID, err)
+		}
+	}()
+
+	result, err := s.importHotels(ctx, source, opts, func(result *ImportResult) {
+		job.apply(result)
+		if err := s.hotelRepo.UpdateImportJob(&job); err != nil {
+			log.Printf("Failed to update progress of import job %s: %v", job.ID, err)
+		}
+	})
+
+	finishedAt := time.Now()
+	job.FinishedAt = &finishedAt
+	if err != nil {
+		job.Status, job.Error = ImportFailed, err.Error()
+	} else {
+		job.Status = ImportCompleted
+		job.apply(result)
+	}
+	if err := s.hotelRepo.UpdateImportJob(&job); err != nil {
+		log.Printf("Failed to finish import job %s: %v", job.ID, err)
+		return
+	}
+	log.Printf("Import job %s finished with status %s: %d of %d hotels imported", job.ID, job.Status, job.Imported, job.Total)
+}
+
+// FailInterruptedImportJobs marks the jobs left in progress by a previous run of
+// the service as failed. Jobs run inside the service, so it must be called on
+// startup before new jobs are started.
+func (s *hotelService) FailInterruptedImportJobs() (int64, error) {
+	failed, err := s.hotelRepo.FailImportJobs(ErrImportInterrupted.Error(), time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to fail interrupted import jobs: %w", err)
+	}
+	return failed, nil
+}
+
+func (s *hotelService) GetImportJob(id uuid.UUID) (*ImportJob, error) {
+	job, err := s.hotelRepo.GetImportJob(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get import job: %w", err)
+	}
+	return job, nil
+}
+
+// importHotels validates the rows and stores the valid hotels, calling progress
+// after every stored batch. The rows are read one at a time, so that only a
+// batch of hotels is held in memory. The options must be valid.
+func (s *hotelService) importHotels(ctx context.Context, source ImportSource, opts ImportOptions, progress func(*ImportResult)) (*ImportResult, error) {
+	registry, err := s.contactRegistry()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{DryRun: opts.DryRun, Total: source.Len(), Errors: []ImportRowError{}}
+	if opts.BatchSize == 0 {
+		return s.importAllHotels(ctx, source, registry, opts, result, progress)
+	}
+
+	var batch []*Hotel
+	var batchRows []int
+	store := func() {
+		if len(batch) == 0 {
+			return
+		}
+		err := s.hotelRepo.Transaction(func(repo HotelRepository) error {
+			return s.storeImportedHotels(ctx, repo, batch)
+		})
+		if err != nil {
+			for _, row := range batchRows {
+				result.fail(row, err)
+			}
+		} else {
+			result.Imported += len(batch)
+			for _, hotel := range batch {
+				s.search.put(*hotel)
+			}
+		}
+		progress(result)
+		batch, batchRows = nil, nil
+	}
+	err = source.Read(func(reader ImportReader) error {
+		return validateImportRows(reader, registry, result, func(hotel *Hotel, row int) error {
+			if opts.DryRun {
+				return nil
+			}
+			batch = append(batch, hotel)
+			batchRows = append(batchRows, row)
+			if len(batch) == opts.BatchSize {
+				store()
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read import: %w", err)
+	}
+	store()
+	return result, nil
+}
+
+// importAllHotels imports all rows or none: a single invalid row rejects the
+// whole import. The rows are validated before any is stored, and then read
+// again to store them in one transaction.
+func (s *hotelService) importAllHotels(ctx context.Context, source ImportSource, registry contactRegistry, opts ImportOptions, result *ImportResult, progress func(*ImportResult)) (*ImportResult, error) {
+	err := source.Read(func(reader ImportReader) error {
+		return validateImportRows(reader, registry, result, func(*Hotel, int) error {
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read import: %w", err)
+	}
+	if result.Failed > 0 || opts.DryRun || result.Valid == 0 {
+		return result, nil
+	}
+
+	// The stored hotels are kept for the search index only if they fit in
+	// one batch.
+	var batch, stored []*Hotel
+	err = s.hotelRepo.Transaction(func(repo HotelRepository) error {
+		store := func() error {
+			if len(batch) == 0 {
+				return nil
+			}
+			if err := s.storeImportedHotels(ctx, repo, batch); err != nil {
+				return err
+			}
+			if result.Valid <= importInsertBatch {
+				stored = batch
+			}
+			batch = nil
+			return nil
+		}
+		err := source.Read(func(reader ImportReader) error {
+			// The rows passed validation already, so they pass again here.
+			return validateImportRows(reader, registry, &ImportResult{}, func(hotel *Hotel, _ int) error {
+				batch = append(batch, hotel)
+				if len(batch) == importInsertBatch {
+					return store()
+				}
+				return nil
+			})
+		})
+		if err != nil {
+			return err
+		}
+		return store()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to import hotels: %w", err)
+	}
+
+	if result.Valid <= importInsertBatch {
+		for _, hotel := range stored {
+			s.search.put(*hotel)
+		}
+	} else {
+		// The hotels were not kept, so the index is loaded again instead.
+		s.search.reset()
+	}
+	result.Imported = result.Valid
+	progress(result)
+	return result, nil
+}
+
+// validateImportRows validates every row read from reader like CreateHotel
+// does, recording the invalid ones in result and passing the valid hotels to
+// fn.
+func validateImportRows(reader ImportReader, registry contactRegistry, result *ImportResult, fn func(hotel *Hotel, row int) error) error {
+	for {
+		row, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if row.Err != nil {
+			result.fail(row.Row, row.Err)
+			continue
+		}
+		hotel := NewHotel(row.OwnerName, row.OwnerSurname, row.CompanyTitle, row.Contacts)
+		if err := validateHotel(hotel); err != nil {
+			result.fail(row.Row, err)
+			continue
+		}
+		if err := registry.validateContacts(hotel.ContactInfos, DefaultPhoneCountry); err != nil {
+			result.fail(row.Row, err)
+			continue
+		}
+		result.Valid++
+		if err := fn(hotel, row.Row); err != nil {
+			return err
+		}
+	}
+}
+
+// storeImportedHotels stores the hotels and their audit entries through repo.
+func (s *hotelService) storeImportedHotels(ctx context.Context, repo HotelRepository, hotels []*Hotel) error {
+	if err := repo.ImportHotels(hotels); err != nil {
+		return err
+	}
+	for _, hotel := range hotels {
+		if err := s.auditCreatedHotel(ctx, repo, hotel); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
-func (s *hotelService) RemoveContactInfo(hotelID uuid.UUID, contactUUID uuid.UUID) error {
-	if err := s.hotelRepo.RemoveContactInfo(hotelID, contactUUID); err != nil {
-		return fmt.Errorf("failed to remove contact info: %w", err)
+// audit appends a change to the audit log through repo, the repository of the
+// transaction that stores the change, so that no change is stored without its
+// audit entry.
+func (s *hotelService) audit(ctx context.Context, repo HotelRepository, hotelID uuid.UUID, entity string, entityID uuid.UUID, action string, before, after interface{}) error {
+	changes, err := diffSnapshots(before, after)
+	if err != nil {
+		return fmt.Errorf("failed to diff %s %s for the audit log: %w", entity, entityID, err)
+	}
+
+	info := auditInfoFrom(ctx)
+	entry := &AuditEntry{
+		ID:        uuid.New(),
+		HotelID:   hotelID,
+		Entity:    entity,
+		EntityID:  entityID,
+		Action:    action,
+		Actor:     info.actor,
+		RequestID: info.requestID,
+		Changes:   changes,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := repo.RecordAudit(entry); err != nil {
+		return fmt.Errorf("failed to record %s of %s %s: %w", action, entity, entityID, err)
 	}
 	return nil
 }
 
-func (s *hotelService) ListHotels() ([]Hotel, error) {
-	return s.hotelRepo.ListHotels()
+func (s *hotelService) ListHotels(opts ListOptions) (*HotelPage, error) {
+	if err := opts.normalize(); err != nil {
+		return nil, err
+	}
+
+	hotels, total, err := s.hotelRepo.ListHotels(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list hotels: %w", err)
+	}
+
+	page := &HotelPage{Items: hotels, Total: total, Limit: opts.Limit, Offset: opts.Offset}
+	if len(hotels) > opts.Limit {
+		page.Items = hotels[:opts.Limit]
+		page.NextCursor = opts.nextCursor(page.Items[opts.Limit-1])
+	}
+	if page.Items == nil {
+		page.Items = []Hotel{}
+	}
+	if opts.Facets {
+		if page.Facets, err = s.hotelRepo.CountFacets(opts); err != nil {
+			return nil, fmt.Errorf("failed to count facets: %w", err)
+		}
+	}
+	return page, nil
+}
+
+// ExportHotels writes every hotel matching the filters of the options to w in
+// the format. Invalid options are reported before anything is written.
+func (s *hotelService) ExportHotels(w io.Writer, format string, opts ListOptions) error {
+	opts.Cursor = ""
+	if err := opts.normalize(); err != nil {
+		return err
+	}
+	opts.Limit, opts.Offset = 0, 0
+
+	var contactColumns map[string]int
+	if format == ExportFormatCSV {
+		var err error
+		if contactColumns, err = s.hotelRepo.CountContactColumns(opts); err != nil {
+			return fmt.Errorf("failed to export hotels: %w", err)
+		}
+	} else if format != ExportFormatNDJSON && format != ExportFormatJSON {
+		return ErrInvalidExportFormat
+	}
+
+	writer, err := newExportWriter(w, format, contactColumns)
+	if err != nil {
+		return fmt.Errorf("failed to export hotels: %w", err)
+	}
+	if err := s.hotelRepo.ExportHotels(opts, writer.write); err != nil {
+		return fmt.Errorf("failed to export hotels: %w", err)
+	}
+	return writer.close()
 }
 
-func (s *hotelService) ListHotelOfficials() ([]HotelOfficial, error) {
-	officials, err := s.hotelRepo.GetHotelOfficials()
+func (s *hotelService) ListHotelOfficials(filter OfficialFilter) ([]HotelOfficial, error) {
+	filter.Role = normalizeRole(filter.Role)
+	officials, err := s.hotelRepo.GetHotelOfficials(filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list hotel officials: %w", err)
 	}
+	if officials == nil {
+		officials = []HotelOfficial{}
+	}
 	return officials, nil
 }
 
@@ -80,25 +1907,326 @@ func (s *hotelService) GetHotelDetails(hotelID uuid.UUID) (*Hotel, error) {
 	return hotelDetails, nil
 }
 
-func (s *hotelService) FetchLocationStats(location string) (int, int, error) {
-	hotels, err := s.hotelRepo.FetchHotelsByLocation(location)
+// FetchLocationStats counts the hotels of a location, their contacts and
+// officials and the rooms and beds they offer, and finds the median nightly
+// price of the rate plans in effect today in currency, DefaultCurrency when
+// empty.
+func (s *hotelService) FetchLocationStats(filter LocationFilter, currency string) (*LocationStats, error) {
+	currency, err := statsCurrency(currency)
+	if err != nil {
+		return nil, err
+	}
+	registry, err := s.contactRegistry()
+	if err != nil {
+		return nil, err
+	}
+	today := day(time.Now().UTC())
+	return s.locationStats(filter, currency, registry, today, s.converter(today))
+}
+
+// FetchLocationStatsBatch returns the stats of every query, in the order of
+// the queries. The contact types and exchange rates are loaded once for the
+// whole batch, and one failing location fails the batch.
+func (s *hotelService) FetchLocationStatsBatch(queries []LocationStatsQuery) ([]LocationStats, error) {
+	queries = append([]LocationStatsQuery(nil), queries...)
+	if err := validateStatsBatch(queries); err != nil {
+		return nil, err
+	}
+	registry, err := s.contactRegistry()
+	if err != nil {
+		return nil, err
+	}
+
+	today := day(time.Now().UTC())
+	convert := s.converter(today)
+	stats := make([]LocationStats, len(queries))
+	for i, query := range queries {
+		entry, err := s.locationStats(query.Filter, query.Currency, registry, today, convert)
+		if err != nil {
+			return nil, err
+		}
+		stats[i] = *entry
+	}
+	return stats, nil
+}
+
+// locationStats computes the stats of a location in a normalized currency.
+func (s *hotelService) locationStats(filter LocationFilter, currency string, registry contactRegistry, today time.Time, convert converter) (*LocationStats, error) {
+	phoneTypes := registry.phoneTypes()
+	aggregates, err := s.hotelRepo.FetchLocationAggregates(filter, phoneTypes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch stats for location %+v: %w", filter, err)
+	}
+
+	stats := &LocationStats{
+		HotelCount:         aggregates.HotelCount,
+		HotelsWithoutPhone: aggregates.HotelsWithoutPhone,
+		EmailCount:         aggregates.ContactCounts[ContactTypeEmail],
+		FaxCount:           aggregates.ContactCounts[ContactTypeFax],
+		WebsiteCount:       aggregates.ContactCounts[ContactTypeWebsite],
+		OfficialCount:      aggregates.OfficialCount,
+		AverageRating:      averageRating(aggregates.ScoreTotal, aggregates.ReviewCount),
+	}
+	for _, phoneType := range phoneTypes {
+		stats.PhoneCount += aggregates.ContactCounts[phoneType]
+	}
+	contactCount := 0
+	for infoType, count := range aggregates.ContactCounts {
+		if infoType != ContactTypeLocation {
+			contactCount += count
+		}
+	}
+	if stats.HotelCount > 0 {
+		stats.AverageContacts = float64(contactCount) / float64(stats.HotelCount)
+		if stats.RoomCount, stats.BedCount, err = s.hotelRepo.FetchRoomCapacity(filter); err != nil {
+			return nil, fmt.Errorf("failed to fetch room capacity for location %+v: %w", filter, err)
+		}
+		if stats.MedianNightlyPrice, err = s.medianNightlyPrice(filter, currency, today, convert); err != nil {
+			return nil, err
+		}
+		if stats.MedianNightlyPrice != nil {
+			stats.Currency = currency
+		}
+	}
+	return stats, nil
+}
+
+// ListLocationStats returns a page of the locations of a level with the
+// number of hotels and phones in each.
+func (s *hotelService) ListLocationStats(opts LocationStatsOptions) (*LocationStatsPage, error) {
+	if err := opts.normalize(); err != nil {
+		return nil, err
+	}
+	registry, err := s.contactRegistry()
+	if err != nil {
+		return nil, err
+	}
+
+	summaries, total, err := s.hotelRepo.ListLocationStats(opts, registry.phoneTypes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list location stats: %w", err)
+	}
+	return &LocationStatsPage{
+		Items:  summaries,
+		Level:  opts.Level,
+		Total:  total,
+		Limit:  opts.Limit,
+		Offset: opts.Offset,
+	}, nil
+}
+
+// medianNightlyPrice returns the median over the hotels of a location of the
+// price of their cheapest room only rate plan in effect today, converted into
+// currency and rounded to it, or nil when no such rate plan is in effect or a
+// price has no exchange rate into currency. A missing median does not fail the
+// stats.
+func (s *hotelService) medianNightlyPrice(filter LocationFilter, currency string, today time.Time, convert converter) (*money.Decimal, error) {
+	prices, err := s.hotelRepo.FetchNightlyPrices(filter, today)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch nightly prices for location %+v: %w", filter, err)
+	}
+	if len(prices) == 0 {
+		return nil, nil
+	}
+
+	cheapest := make(map[uuid.UUID]money.Decimal)
+	for _, price := range prices {
+		exchange, err := convert(price.Price.Currency, currency)
+		if errors.Is(err, ErrNoExchangeRate) {
+			log.Printf("Leaving out the median nightly price of location %+v: %v", filter, err)
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert nightly prices for location %+v: %w", filter, err)
+		}
+		value := exchange.convert(price.Price.Value)
+		if current, ok := cheapest[price.HotelID]; !ok || value.Cmp(current) < 0 {
+			cheapest[price.HotelID] = value
+		}
+	}
+
+	values := make([]money.Decimal, 0, len(cheapest))
+	for _, value := range cheapest {
+		values = append(values, value)
+	}
+	median := money.Median(values).Round(money.MinorUnits(currency))
+	return &median, nil
+}
+
+// FindNearbyHotels returns up to limit hotels within radiusKm of a point,
+// nearest first.
+func (s *hotelService) FindNearbyHotels(lat, lng, radiusKm float64, limit int) ([]HotelDistance, error) {
+	if !validCoordinates(lat, lng) || radiusKm <= 0 {
+		return nil, ErrInvalidCoordinates
+	}
+	if radiusKm > MaxRadiusKm {
+		return nil, ErrSearchAreaTooLarge
+	}
+
+	hotels, err := s.hotelRepo.FetchHotelsInBoundingBox(boundingBoxAround(lat, lng, radiusKm))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find nearby hotels: %w", err)
+	}
+
+	results := sortByDistance(hotels, lat, lng)
+	for i, result := range results {
+		if result.DistanceKm > radiusKm {
+			results = results[:i]
+			break
+		}
+	}
+	return limitDistances(results, limit), nil
+}
+
+// FindHotelsInBoundingBox returns up to limit hotels inside the box, nearest to
+// its center first.
+func (s *hotelService) FindHotelsInBoundingBox(box BoundingBox, limit int) ([]HotelDistance, error) {
+	if !box.Valid() {
+		return nil, ErrInvalidCoordinates
+	}
+	if latSpan, lngSpan := box.Spans(); latSpan > MaxBoxSpan || lngSpan > MaxBoxSpan {
+		return nil, ErrSearchAreaTooLarge
+	}
+
+	hotels, err := s.hotelRepo.FetchHotelsInBoundingBox(box)
 	if err != nil {
-		return 0, 0, fmt.Errorf("failed to fetch hotels for location %s: %w", location, err)
+		return nil, fmt.Errorf("failed to find hotels in bounding box: %w", err)
+	}
+
+	lat, lng := box.Center()
+	return limitDistances(sortByDistance(hotels, lat, lng), limit), nil
+}
+
+// limitDistances keeps the first limit results, DefaultGeoLimit when limit is
+// 0 and at most MaxGeoLimit.
+func limitDistances(results []HotelDistance, limit int) []HotelDistance {
+	if limit <= 0 {
+		limit = DefaultGeoLimit
+	}
+	if limit > MaxGeoLimit {
+		limit = MaxGeoLimit
+	}
+	if len(results) > limit {
+		return results[:limit]
+	}
+	return results
+}
+
+// SearchHotels ranks the hotels by how well their company title, owner and
+// contacts match the query, tolerating typos. The search index is loaded on the
+// first search.
+func (s *hotelService) SearchHotels(query string, limit int) ([]SearchResult, error) {
+	if limit <= 0 {
+		limit = DefaultPageLimit
+	}
+	if limit > MaxPageLimit {
+		limit = MaxPageLimit
 	}
 
-	hotelCount := len(hotels)
-	phoneCount := s.countContactsByType(hotels, ContactTypePhone)
-	return hotelCount, phoneCount, nil
+	if err := s.search.ensureLoaded(s.hotelRepo.FetchAllHotels); err != nil {
+		return nil, fmt.Errorf("failed to load search index: %w", err)
+	}
+	return s.search.search(query, limit)
 }
 
-func (s *hotelService) countContactsByType(hotels []Hotel, contactType string) int {
-	count := 0
+// sortByDistance pairs each hotel with its distance from the origin and sorts
+// them nearest first. Hotels without coordinates are dropped.
+func sortByDistance(hotels []Hotel, lat, lng float64) []HotelDistance {
+	results := make([]HotelDistance, 0, len(hotels))
 	for _, hotel := range hotels {
-		for _, contact := range hotel.ContactInfos {
-			if contact.InfoType == contactType {
-				count++
-			}
+		if hotel.Location == nil || hotel.Location.Latitude == nil || hotel.Location.Longitude == nil {
+			continue
 		}
+		distance := haversineKm(lat, lng, *hotel.Location.Latitude, *hotel.Location.Longitude)
+		results = append(results, HotelDistance{Hotel: hotel, DistanceKm: distance})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].DistanceKm < results[j].DistanceKm
+	})
+	return results
+}
+
+// validateHotel checks the fields every stored hotel must have.
+func validateHotel(hotel *Hotel) error {
+	if hotel.OwnerName == "" || hotel.OwnerSurname == "" || hotel.CompanyTitle == "" {
+		return ErrInvalidHotel
+	}
+	return nil
+}
+
+// validateLocation trims the address fields and checks that the location is
+// complete enough to be found by country and city.
+func validateLocation(location *Location) error {
+	location.Country = strings.TrimSpace(location.Country)
+	location.City = strings.TrimSpace(location.City)
+	location.District = strings.TrimSpace(location.District)
+	location.PostalCode = strings.TrimSpace(location.PostalCode)
+	location.Street = strings.TrimSpace(location.Street)
+
+	if location.Country == "" || location.City == "" {
+		return ErrInvalidLocation
+	}
+	if (location.Latitude == nil) != (location.Longitude == nil) {
+		return ErrInvalidLocation
+	}
+	if location.Latitude != nil && !validCoordinates(*location.Latitude, *location.Longitude) {
+		return ErrInvalidLocation
+	}
+	return nil
+}
+
+// contactRegistry loads the contact type registry.
+func (s *hotelService) contactRegistry() (contactRegistry, error) {
+	contactTypes, err := s.hotelRepo.ListContactTypes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load contact types: %w", err)
+	}
+	return newContactRegistry(contactTypes), nil
+}
+
+func (s *hotelService) ListContactTypes() ([]ContactType, error) {
+	contactTypes, err := s.hotelRepo.ListContactTypes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list contact types: %w", err)
+	}
+	return contactTypes, nil
+}
+
+func (s *hotelService) GetContactType(name string) (*ContactType, error) {
+	contactType, err := s.hotelRepo.GetContactType(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contact type: %w", err)
+	}
+	return contactType, nil
+}
+
+func (s *hotelService) CreateContactType(contactType *ContactType) error {
+	if err := validateContactType(contactType); err != nil {
+		return err
+	}
+	if err := s.hotelRepo.CreateContactType(contactType); err != nil {
+		return fmt.Errorf("failed to create contact type: %w", err)
+	}
+	return nil
+}
+
+// UpdateContactType replaces the contact type with the given name. The name
+// itself cannot change, as contact infos refer to it.
+func (s *hotelService) UpdateContactType(name string, contactType *ContactType) error {
+	contactType.Name = name
+	if err := validateContactType(contactType); err != nil {
+		return err
+	}
+	if err := s.hotelRepo.UpdateContactType(contactType); err != nil {
+		return fmt.Errorf("failed to update contact type: %w", err)
+	}
+	return nil
+}
+
+func (s *hotelService) DeleteContactType(name string) error {
+	if err := s.hotelRepo.DeleteContactType(name); err != nil {
+		return fmt.Errorf("failed to delete contact type: %w", err)
 	}
-	return count
+	return nil
 }
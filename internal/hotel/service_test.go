@@ -1,8 +1,16 @@
 package hotel
 
 import (
+	"context"
 	"fmt"
+	"hotel-guide/internal/money"
+	"hotel-guide/internal/storage"
+	"image/color"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -13,43 +21,498 @@ type MockHotelRepository struct {
 	mock.Mock
 }
 
+// Transaction runs fn with the mock itself, so that the calls made inside the
+// transaction meet the expectations of the test.
+func (m *MockHotelRepository) Transaction(fn func(repo HotelRepository) error) error {
+	return fn(m)
+}
+
 func (m *MockHotelRepository) Save(hotel *Hotel) error {
 	args := m.Called(hotel)
 	return args.Error(0)
 }
 
-func (m *MockHotelRepository) Delete(id uuid.UUID) error {
+func (m *MockHotelRepository) Delete(id uuid.UUID, version int) error {
+	args := m.Called(id, version)
+	return args.Error(0)
+}
+
+func (m *MockHotelRepository) Restore(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockHotelRepository) LockDeletedHotels(before time.Time) ([]uuid.UUID, error) {
+	args := m.Called(before)
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+func (m *MockHotelRepository) PurgeHotels(ids []uuid.UUID) (int64, error) {
+	args := m.Called(ids)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockHotelRepository) RecordAudit(entry *AuditEntry) error {
+	args := m.Called(entry)
+	return args.Error(0)
+}
+
+func (m *MockHotelRepository) ImportHotels(hotels []*Hotel) error {
+	args := m.Called(hotels)
+	return args.Error(0)
+}
+
+func (m *MockHotelRepository) CreateImportJob(job *ImportJob) error {
+	args := m.Called(job)
+	return args.Error(0)
+}
+
+func (m *MockHotelRepository) UpdateImportJob(job *ImportJob) error {
+	args := m.Called(job)
+	return args.Error(0)
+}
+
+func (m *MockHotelRepository) FailImportJobs(reason string, finishedAt time.Time) (int64, error) {
+	args := m.Called(reason, finishedAt)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockHotelRepository) GetImportJob(id uuid.UUID) (*ImportJob, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ImportJob), args.Error(1)
+}
+
+func (m *MockHotelRepository) ListAuditEntries(filter AuditFilter) ([]AuditEntry, error) {
+	args := m.Called(filter)
+	return args.Get(0).([]AuditEntry), args.Error(1)
+}
+
+func (m *MockHotelRepository) UpdateHotel(hotel *Hotel) error {
+	args := m.Called(hotel)
+	return args.Error(0)
+}
+
+func (m *MockHotelRepository) GetContactInfo(hotelID, contactID uuid.UUID) (*ContactInfo, error) {
+	args := m.Called(hotelID, contactID)
+	return args.Get(0).(*ContactInfo), args.Error(1)
+}
+
+func (m *MockHotelRepository) UpdateContactInfo(contact *ContactInfo, version int) error {
+	args := m.Called(contact, version)
+	return args.Error(0)
+}
+
+func (m *MockHotelRepository) AddContactInfo(hotelID uuid.UUID, contact *ContactInfo, version int) error {
+	args := m.Called(hotelID, contact, version)
+	return args.Error(0)
+}
+
+func (m *MockHotelRepository) RemoveContactInfo(hotelID uuid.UUID, contactUUID uuid.UUID, version int) error {
+	args := m.Called(hotelID, contactUUID, version)
+	return args.Error(0)
+}
+
+func (m *MockHotelRepository) ListHotels(opts ListOptions) ([]Hotel, int64, error) {
+	args := m.Called(opts)
+	return args.Get(0).([]Hotel), args.Get(1).(int64), args.Error(2)
+}
+
+// ExportHotels passes the hotels given to the mock to fn.
+func (m *MockHotelRepository) ExportHotels(opts ListOptions, fn func(hotel *Hotel) error) error {
+	args := m.Called(opts)
+	for _, hotel := range args.Get(0).([]Hotel) {
+		hotel := hotel
+		if err := fn(&hotel); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockHotelRepository) CountContactColumns(opts ListOptions) (map[string]int, error) {
+	args := m.Called(opts)
+	return args.Get(0).(map[string]int), args.Error(1)
+}
+
+func (m *MockHotelRepository) ListRoomTypes(hotelID uuid.UUID) ([]RoomType, error) {
+	args := m.Called(hotelID)
+	return args.Get(0).([]RoomType), args.Error(1)
+}
+
+func (m *MockHotelRepository) GetRoomType(hotelID, roomTypeID uuid.UUID) (*RoomType, error) {
+	args := m.Called(hotelID, roomTypeID)
+	return args.Get(0).(*RoomType), args.Error(1)
+}
+
+func (m *MockHotelRepository) AddRoomType(roomType *RoomType, version int) error {
+	args := m.Called(roomType, version)
+	return args.Error(0)
+}
+
+func (m *MockHotelRepository) UpdateRoomType(roomType *RoomType, version int, now time.Time) error {
+	args := m.Called(roomType, version, now)
+	return args.Error(0)
+}
+
+func (m *MockHotelRepository) RemoveRoomType(hotelID, roomTypeID uuid.UUID, version int, now time.Time) error {
+	args := m.Called(hotelID, roomTypeID, version, now)
+	return args.Error(0)
+}
+
+func (m *MockHotelRepository) FetchRoomCapacity(filter LocationFilter) (int, int, error) {
+	args := m.Called(filter)
+	return args.Int(0), args.Int(1), args.Error(2)
+}
+
+func (m *MockHotelRepository) ListActiveReservations(roomTypeIDs []uuid.UUID, from, to, now time.Time) ([]Reservation, error) {
+	args := m.Called(roomTypeIDs, from, to, now)
+	return args.Get(0).([]Reservation), args.Error(1)
+}
+
+func (m *MockHotelRepository) GetReservation(hotelID, reservationID uuid.UUID) (*Reservation, error) {
+	args := m.Called(hotelID, reservationID)
+	return args.Get(0).(*Reservation), args.Error(1)
+}
+
+func (m *MockHotelRepository) CreateReservation(reservation *Reservation, now time.Time) error {
+	args := m.Called(reservation, now)
+	return args.Error(0)
+}
+
+func (m *MockHotelRepository) ConfirmReservation(hotelID, reservationID uuid.UUID, now time.Time) error {
+	args := m.Called(hotelID, reservationID, now)
+	return args.Error(0)
+}
+
+func (m *MockHotelRepository) CancelReservation(hotelID, reservationID uuid.UUID) error {
+	args := m.Called(hotelID, reservationID)
+	return args.Error(0)
+}
+
+func (m *MockHotelRepository) ListReviews(filter ReviewFilter) ([]Review, error) {
+	args := m.Called(filter)
+	return args.Get(0).([]Review), args.Error(1)
+}
+
+func (m *MockHotelRepository) GetReview(hotelID, reviewID uuid.UUID) (*Review, error) {
+	args := m.Called(hotelID, reviewID)
+	return args.Get(0).(*Review), args.Error(1)
+}
+
+func (m *MockHotelRepository) AddReview(review *Review) error {
+	args := m.Called(review)
+	return args.Error(0)
+}
+
+func (m *MockHotelRepository) SetReviewStatus(hotelID, reviewID uuid.UUID, status string) error {
+	args := m.Called(hotelID, reviewID, status)
+	return args.Error(0)
+}
+
+func (m *MockHotelRepository) RemoveReview(hotelID, reviewID uuid.UUID) error {
+	args := m.Called(hotelID, reviewID)
+	return args.Error(0)
+}
+
+func (m *MockHotelRepository) ListTags() ([]Tag, error) {
+	args := m.Called()
+	return args.Get(0).([]Tag), args.Error(1)
+}
+
+func (m *MockHotelRepository) GetTag(name string) (*Tag, error) {
+	args := m.Called(name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Tag), args.Error(1)
+}
+
+func (m *MockHotelRepository) CreateTag(tag *Tag) error {
+	args := m.Called(tag)
+	return args.Error(0)
+}
+
+func (m *MockHotelRepository) UpdateTag(tag *Tag) error {
+	args := m.Called(tag)
+	return args.Error(0)
+}
+
+func (m *MockHotelRepository) DeleteTag(name string) error {
+	args := m.Called(name)
+	return args.Error(0)
+}
+
+func (m *MockHotelRepository) SetHotelTags(hotelID uuid.UUID, tags []string, version int) error {
+	args := m.Called(hotelID, tags, version)
+	return args.Error(0)
+}
+
+func (m *MockHotelRepository) CountFacets(opts ListOptions) (*Facets, error) {
+	args := m.Called(opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Facets), args.Error(1)
+}
+
+func (m *MockHotelRepository) ListMedia(hotelID uuid.UUID) ([]Media, error) {
+	args := m.Called(hotelID)
+	return args.Get(0).([]Media), args.Error(1)
+}
+
+func (m *MockHotelRepository) GetMedia(hotelID, mediaID uuid.UUID) (*Media, error) {
+	args := m.Called(hotelID, mediaID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Media), args.Error(1)
+}
+
+func (m *MockHotelRepository) AddMedia(media *Media, version int) error {
+	args := m.Called(media, version)
+	return args.Error(0)
+}
+
+func (m *MockHotelRepository) ReorderMedia(hotelID uuid.UUID, mediaIDs []uuid.UUID, version int) error {
+	args := m.Called(hotelID, mediaIDs, version)
+	return args.Error(0)
+}
+
+func (m *MockHotelRepository) RemoveMedia(hotelID, mediaID uuid.UUID, version int) error {
+	args := m.Called(hotelID, mediaID, version)
+	return args.Error(0)
+}
+
+func (m *MockHotelRepository) ListPurgeableMedia(hotelIDs []uuid.UUID) ([]Media, error) {
+	args := m.Called(hotelIDs)
+	return args.Get(0).([]Media), args.Error(1)
+}
+
+func (m *MockHotelRepository) ListTranslations(hotelIDs []uuid.UUID) ([]HotelTranslation, error) {
+	args := m.Called(hotelIDs)
+	return args.Get(0).([]HotelTranslation), args.Error(1)
+}
+
+func (m *MockHotelRepository) GetTranslation(hotelID uuid.UUID, language string) (*HotelTranslation, error) {
+	args := m.Called(hotelID, language)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*HotelTranslation), args.Error(1)
+}
+
+func (m *MockHotelRepository) SaveTranslation(translation *HotelTranslation, version int) error {
+	args := m.Called(translation, version)
+	return args.Error(0)
+}
+
+func (m *MockHotelRepository) RemoveTranslation(hotelID uuid.UUID, language string, version int) error {
+	args := m.Called(hotelID, language, version)
+	return args.Error(0)
+}
+
+func (m *MockHotelRepository) ListMissingTranslations(filter MissingTranslationFilter) ([]Hotel, int64, error) {
+	args := m.Called(filter)
+	return args.Get(0).([]Hotel), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockHotelRepository) ListRatePlans(hotelID, roomTypeID uuid.UUID) ([]RatePlan, error) {
+	args := m.Called(hotelID, roomTypeID)
+	return args.Get(0).([]RatePlan), args.Error(1)
+}
+
+func (m *MockHotelRepository) GetRatePlan(hotelID, ratePlanID uuid.UUID) (*RatePlan, error) {
+	args := m.Called(hotelID, ratePlanID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*RatePlan), args.Error(1)
+}
+
+func (m *MockHotelRepository) AddRatePlan(plan *RatePlan, version int) error {
+	args := m.Called(plan, version)
+	return args.Error(0)
+}
+
+func (m *MockHotelRepository) UpdateRatePlan(plan *RatePlan, version int) error {
+	args := m.Called(plan, version)
+	return args.Error(0)
+}
+
+func (m *MockHotelRepository) RemoveRatePlan(hotelID, ratePlanID uuid.UUID, version int) error {
+	args := m.Called(hotelID, ratePlanID, version)
+	return args.Error(0)
+}
+
+func (m *MockHotelRepository) ListRatePlansForStay(hotelID, roomTypeID uuid.UUID, mealPlan string, from, to time.Time) ([]RatePlan, error) {
+	args := m.Called(hotelID, roomTypeID, mealPlan, from, to)
+	return args.Get(0).([]RatePlan), args.Error(1)
+}
+
+func (m *MockHotelRepository) FetchNightlyPrices(filter LocationFilter, on time.Time) ([]HotelPrice, error) {
+	args := m.Called(filter, on)
+	return args.Get(0).([]HotelPrice), args.Error(1)
+}
+
+func (m *MockHotelRepository) ListExchangeRates(filter ExchangeRateFilter) ([]ExchangeRate, error) {
+	args := m.Called(filter)
+	return args.Get(0).([]ExchangeRate), args.Error(1)
+}
+
+func (m *MockHotelRepository) SaveExchangeRate(rate *ExchangeRate) error {
+	args := m.Called(rate)
+	return args.Error(0)
+}
+
+func (m *MockHotelRepository) RemoveExchangeRate(base, quote string, effectiveDate time.Time) error {
+	args := m.Called(base, quote, effectiveDate)
+	return args.Error(0)
+}
+
+func (m *MockHotelRepository) FindExchangeRate(from, to string, on time.Time) (*ExchangeRate, error) {
+	args := m.Called(from, to, on)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ExchangeRate), args.Error(1)
+}
+
+func (m *MockHotelRepository) ListChains(kind string) ([]Chain, error) {
+	args := m.Called(kind)
+	return args.Get(0).([]Chain), args.Error(1)
+}
+
+func (m *MockHotelRepository) GetChain(id uuid.UUID) (*Chain, error) {
 	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Chain), args.Error(1)
+}
+
+func (m *MockHotelRepository) CreateChain(chain *Chain) error {
+	args := m.Called(chain)
+	return args.Error(0)
+}
+
+func (m *MockHotelRepository) UpdateChain(chain *Chain) error {
+	args := m.Called(chain)
 	return args.Error(0)
 }
 
-func (m *MockHotelRepository) AddContactInfo(hotelID uuid.UUID, contact *ContactInfo) error {
-	args := m.Called(hotelID, contact)
+func (m *MockHotelRepository) DeleteChain(id uuid.UUID) error {
+	args := m.Called(id)
 	return args.Error(0)
 }
 
-func (m *MockHotelRepository) RemoveContactInfo(hotelID uuid.UUID, contactUUID uuid.UUID) error {
-	args := m.Called(hotelID, contactUUID)
+func (m *MockHotelRepository) SetHotelChain(hotelID uuid.UUID, chainID *uuid.UUID, version int) error {
+	args := m.Called(hotelID, chainID, version)
 	return args.Error(0)
 }
 
-func (m *MockHotelRepository) ListHotels() ([]Hotel, error) {
+func (m *MockHotelRepository) CountHotels(filter LocationFilter) (int64, error) {
+	args := m.Called(filter)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockHotelRepository) CountHotelsByCity(filter LocationFilter) ([]LocationCount, error) {
+	args := m.Called(filter)
+	return args.Get(0).([]LocationCount), args.Error(1)
+}
+
+func (m *MockHotelRepository) CountHotelsByContactType(filter LocationFilter) (map[string]int64, error) {
+	args := m.Called(filter)
+	return args.Get(0).(map[string]int64), args.Error(1)
+}
+
+func (m *MockHotelRepository) FetchAllHotels() ([]Hotel, error) {
 	args := m.Called()
 	return args.Get(0).([]Hotel), args.Error(1)
 }
 
-func (m *MockHotelRepository) GetHotelOfficials() ([]HotelOfficial, error) {
+func (m *MockHotelRepository) ListContactTypes() ([]ContactType, error) {
 	args := m.Called()
+	return args.Get(0).([]ContactType), args.Error(1)
+}
+
+func (m *MockHotelRepository) GetContactType(name string) (*ContactType, error) {
+	args := m.Called(name)
+	return args.Get(0).(*ContactType), args.Error(1)
+}
+
+func (m *MockHotelRepository) CreateContactType(contactType *ContactType) error {
+	args := m.Called(contactType)
+	return args.Error(0)
+}
+
+func (m *MockHotelRepository) UpdateContactType(contactType *ContactType) error {
+	args := m.Called(contactType)
+	return args.Error(0)
+}
+
+func (m *MockHotelRepository) DeleteContactType(name string) error {
+	args := m.Called(name)
+	return args.Error(0)
+}
+
+func (m *MockHotelRepository) GetHotelOfficials(filter OfficialFilter) ([]HotelOfficial, error) {
+	args := m.Called(filter)
 	return args.Get(0).([]HotelOfficial), args.Error(1)
 }
 
+func (m *MockHotelRepository) GetOfficial(hotelID, officialID uuid.UUID) (*HotelOfficial, error) {
+	args := m.Called(hotelID, officialID)
+	return args.Get(0).(*HotelOfficial), args.Error(1)
+}
+
+func (m *MockHotelRepository) AddOfficial(official *HotelOfficial, version int) error {
+	args := m.Called(official, version)
+	return args.Error(0)
+}
+
+func (m *MockHotelRepository) UpdateOfficial(official *HotelOfficial, version int) error {
+	args := m.Called(official, version)
+	return args.Error(0)
+}
+
+func (m *MockHotelRepository) RemoveOfficial(hotelID, officialID uuid.UUID, version int) error {
+	args := m.Called(hotelID, officialID, version)
+	return args.Error(0)
+}
+
 func (m *MockHotelRepository) GetHotelDetails(hotelID uuid.UUID) (*Hotel, error) {
 	args := m.Called(hotelID)
 	return args.Get(0).(*Hotel), args.Error(1)
 }
 
-func (m *MockHotelRepository) FetchHotelsByLocation(location string) ([]Hotel, error) {
-	args := m.Called(location)
+func (m *MockHotelRepository) SetLocation(location *Location, version int) error {
+	args := m.Called(location, version)
+	return args.Error(0)
+}
+
+func (m *MockHotelRepository) DeleteLocation(hotelID uuid.UUID, version int) error {
+	args := m.Called(hotelID, version)
+	return args.Error(0)
+}
+
+func (m *MockHotelRepository) FetchLocationAggregates(filter LocationFilter, phoneTypes []string) (*LocationAggregates, error) {
+	args := m.Called(filter, phoneTypes)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*LocationAggregates), args.Error(1)
+}
+
+func (m *MockHotelRepository) ListLocationStats(opts LocationStatsOptions, phoneTypes []string) ([]LocationSummary, int64, error) {
+	args := m.Called(opts, phoneTypes)
+	return args.Get(0).([]LocationSummary), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockHotelRepository) FetchHotelsInBoundingBox(box BoundingBox) ([]Hotel, error) {
+	args := m.Called(box)
 	return args.Get(0).([]Hotel), args.Error(1)
 }
 
@@ -71,10 +534,11 @@ func TestCreateHotel(t *testing.T) {
 	})).Return(nil).Once()
 
 	// Create the service with the mocked repository
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, nil)
+	mockRepo.On("RecordAudit", mock.Anything).Return(nil).Once()
 
 	// Call CreateHotel
-	createdHotel, err := service.CreateHotel(hotel.OwnerName, hotel.OwnerSurname, hotel.CompanyTitle, nil)
+	createdHotel, err := service.CreateHotel(context.Background(), hotel.OwnerName, hotel.OwnerSurname, hotel.CompanyTitle, nil)
 
 	// Assert no error occurred and the hotel was created with the expected values
 	assert.NoError(t, err)
@@ -88,13 +552,15 @@ func TestCreateHotel(t *testing.T) {
 
 func TestDeleteHotel(t *testing.T) {
 	mockRepo := new(MockHotelRepository)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, nil)
+	mockRepo.On("RecordAudit", mock.Anything).Return(nil).Once()
 
 	hotelID := uuid.New()
 
-	mockRepo.On("Delete", hotelID).Return(nil).Once()
+	mockRepo.On("GetHotelDetails", hotelID).Return(&Hotel{ID: hotelID}, nil).Once()
+	mockRepo.On("Delete", hotelID, 0).Return(nil).Once()
 
-	err := service.DeleteHotel(hotelID)
+	err := service.DeleteHotel(context.Background(), hotelID, 0)
 	assert.NoError(t, err)
 
 	mockRepo.AssertExpectations(t)
@@ -102,32 +568,38 @@ func TestDeleteHotel(t *testing.T) {
 
 func TestAddContactInfo(t *testing.T) {
 	mockRepo := new(MockHotelRepository)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, nil)
+	mockRepo.On("RecordAudit", mock.Anything).Return(nil).Once()
 
 	hotelID := uuid.New()
 	contact := &ContactInfo{
 		InfoType:    ContactTypePhone,
-		InfoContent: "123-456-7890",
+		InfoContent: "0212-456-7890",
 	}
 
-	mockRepo.On("AddContactInfo", hotelID, contact).Return(nil).Once()
+	mockRepo.On("GetHotelDetails", hotelID).Return(&Hotel{ID: hotelID}, nil).Once()
+	mockRepo.On("ListContactTypes").Return(DefaultContactTypes, nil).Once()
+	mockRepo.On("AddContactInfo", hotelID, contact, 0).Return(nil).Once()
 
-	err := service.AddContactInfo(hotelID, contact)
+	err := service.AddContactInfo(context.Background(), hotelID, contact, 0)
 	assert.NoError(t, err)
+	assert.Equal(t, "+902124567890", contact.InfoContent)
 
 	mockRepo.AssertExpectations(t)
 }
 
 func TestRemoveContactInfo(t *testing.T) {
 	mockRepo := new(MockHotelRepository)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, nil)
+	mockRepo.On("RecordAudit", mock.Anything).Return(nil).Once()
 
 	hotelID := uuid.New()
 	contactID := uuid.New()
 
-	mockRepo.On("RemoveContactInfo", hotelID, contactID).Return(nil).Once()
+	mockRepo.On("GetContactInfo", hotelID, contactID).Return(&ContactInfo{ID: contactID, HotelID: hotelID}, nil).Once()
+	mockRepo.On("RemoveContactInfo", hotelID, contactID, 0).Return(nil).Once()
 
-	err := service.RemoveContactInfo(hotelID, contactID)
+	err := service.RemoveContactInfo(context.Background(), hotelID, contactID, 0)
 	assert.NoError(t, err)
 
 	mockRepo.AssertExpectations(t)
@@ -135,34 +607,86 @@ func TestRemoveContactInfo(t *testing.T) {
 
 func TestListHotels(t *testing.T) {
 	mockRepo := new(MockHotelRepository)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, nil)
 
 	expectedHotels := []Hotel{
 		{ID: uuid.New(), OwnerName: "John", OwnerSurname: "Doe", CompanyTitle: "Doe Ltd."},
 		{ID: uuid.New(), OwnerName: "Jane", OwnerSurname: "Smith", CompanyTitle: "Smith Ltd."},
 	}
 
-	mockRepo.On("ListHotels").Return(expectedHotels, nil).Once()
+	mockRepo.On("ListHotels", ListOptions{Limit: DefaultPageLimit, SortBy: "id"}).Return(expectedHotels, int64(2), nil).Once()
+
+	page, err := service.ListHotels(ListOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, expectedHotels, page.Items)
+	assert.Equal(t, int64(2), page.Total)
+	assert.Empty(t, page.NextCursor)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestListHotels_NextCursor(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+
+	// The repository returns one hotel more than the limit when another page exists
+	hotels := []Hotel{
+		{ID: uuid.New(), CompanyTitle: "Alpha Inn"},
+		{ID: uuid.New(), CompanyTitle: "Beta Inn"},
+		{ID: uuid.New(), CompanyTitle: "Gamma Inn"},
+	}
+	mockRepo.On("ListHotels", ListOptions{Limit: 2, SortBy: "company_title"}).Return(hotels, int64(3), nil).Once()
+
+	page, err := service.ListHotels(ListOptions{Limit: 2, SortBy: "company_title"})
+	assert.NoError(t, err)
+	assert.Len(t, page.Items, 2)
+	assert.NotEmpty(t, page.NextCursor)
+
+	// The cursor continues after the last hotel of the page
+	mockRepo.On("ListHotels", mock.MatchedBy(func(opts ListOptions) bool {
+		return opts.after != nil && opts.after.ID == hotels[1].ID && opts.after.Value == "Beta Inn"
+	})).Return(hotels[2:], int64(3), nil).Once()
 
-	hotels, err := service.ListHotels()
+	page, err = service.ListHotels(ListOptions{Limit: 2, SortBy: "company_title", Cursor: page.NextCursor, Offset: 5})
 	assert.NoError(t, err)
-	assert.Equal(t, expectedHotels, hotels)
+	assert.Len(t, page.Items, 1)
+	assert.Equal(t, 0, page.Offset)
+	assert.Empty(t, page.NextCursor)
 
 	mockRepo.AssertExpectations(t)
 }
 
+func TestListHotels_InvalidOptions(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+
+	_, err := service.ListHotels(ListOptions{SortBy: "popularity"})
+	assert.ErrorIs(t, err, ErrInvalidSort)
+
+	_, err = service.ListHotels(ListOptions{Cursor: "not-a-cursor"})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	// A cursor issued for another ordering is rejected
+	cursor := (&ListOptions{SortBy: "id"}).nextCursor(Hotel{ID: uuid.New()})
+	_, err = service.ListHotels(ListOptions{Cursor: cursor, SortBy: "id", SortDesc: true})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	mockRepo.AssertNotCalled(t, "ListHotels", mock.Anything)
+}
+
 func TestListHotelOfficials(t *testing.T) {
 	mockRepo := new(MockHotelRepository)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, nil)
 
 	expectedOfficials := []HotelOfficial{
-		{OwnerName: "John", OwnerSurname: "Doe", CompanyTitle: "Doe Ltd."},
-		{OwnerName: "Jane", OwnerSurname: "Smith", CompanyTitle: "Smith Ltd."},
+		{ID: uuid.New(), Role: RoleGeneralManager, Name: "John", Surname: "Doe", CompanyTitle: "Doe Ltd."},
+		{ID: uuid.New(), Role: RoleGeneralManager, Name: "Jane", Surname: "Smith", CompanyTitle: "Smith Ltd."},
 	}
 
-	mockRepo.On("GetHotelOfficials").Return(expectedOfficials, nil).Once()
+	// Roles are normalized before filtering
+	mockRepo.On("GetHotelOfficials", OfficialFilter{Role: RoleGeneralManager}).Return(expectedOfficials, nil).Once()
 
-	officials, err := service.ListHotelOfficials()
+	officials, err := service.ListHotelOfficials(OfficialFilter{Role: "General Manager"})
 	assert.NoError(t, err)
 	assert.Equal(t, expectedOfficials, officials)
 
@@ -171,7 +695,7 @@ func TestListHotelOfficials(t *testing.T) {
 
 func TestGetHotelDetails(t *testing.T) {
 	mockRepo := new(MockHotelRepository)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, nil)
 
 	hotelID := uuid.New()
 	expectedHotel := &Hotel{
@@ -192,22 +716,63 @@ func TestGetHotelDetails(t *testing.T) {
 
 func TestFetchLocationStats(t *testing.T) {
 	mockRepo := new(MockHotelRepository)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, nil)
 
 	location := "New York"
-	expectedHotels := []Hotel{
-		{ID: uuid.New(), ContactInfos: []ContactInfo{{InfoType: ContactTypePhone, InfoContent: "123-456"}}},
-		{ID: uuid.New(), ContactInfos: []ContactInfo{{InfoType: ContactTypePhone, InfoContent: "789-101"}}},
+	aggregates := &LocationAggregates{
+		HotelCount: 4, ScoreTotal: 24, ReviewCount: 3, HotelsWithoutPhone: 1, OfficialCount: 3,
+		ContactCounts: map[string]int{ContactTypePhone: 5, ContactTypeEmail: 3, ContactTypeFax: 1, ContactTypeWebsite: 2, ContactTypeLocation: 4},
 	}
-	hotelCount := len(expectedHotels)
-	phoneCount := 2
 
-	mockRepo.On("FetchHotelsByLocation", location).Return(expectedHotels, nil).Once()
+	mockRepo.On("ListContactTypes").Return(DefaultContactTypes, nil).Once()
+	mockRepo.On("FetchLocationAggregates", LocationFilter{City: location}, []string{ContactTypePhone}).Return(aggregates, nil).Once()
+	mockRepo.On("FetchRoomCapacity", LocationFilter{City: location}).Return(40, 64, nil).Once()
+
+	// Prices are converted into the currency of the stats, with rates quoted
+	// either way, and each hotel counts with its cheapest price
+	today := day(time.Now().UTC())
+	first, second, third := uuid.New(), uuid.New(), uuid.New()
+	mockRepo.On("FetchNightlyPrices", LocationFilter{City: location}, today).Return([]HotelPrice{
+		{HotelID: first, Price: money.Amount{Value: money.MustParse("140"), Currency: "EUR"}},
+		{HotelID: first, Price: money.Amount{Value: money.MustParse("3000"), Currency: "TRY"}},
+		{HotelID: second, Price: money.Amount{Value: money.MustParse("150"), Currency: "USD"}},
+		{HotelID: third, Price: money.Amount{Value: money.MustParse("160"), Currency: "EUR"}},
+	}, nil).Once()
+	mockRepo.On("FindExchangeRate", "TRY", "EUR", today).Return(&ExchangeRate{BaseCurrency: "EUR", QuoteCurrency: "TRY", Rate: money.MustParse("30")}, nil).Once()
+	mockRepo.On("FindExchangeRate", "USD", "EUR", today).Return(&ExchangeRate{BaseCurrency: "USD", QuoteCurrency: "EUR", Rate: money.MustParse("0.9")}, nil).Once()
+
+	stats, err := service.FetchLocationStats(LocationFilter{City: location}, "")
+	assert.NoError(t, err)
+	median := money.MustParse("135")
+	// Legacy location contacts are not counted as contacts of the hotels
+	assert.Equal(t, &LocationStats{
+		HotelCount: 4, PhoneCount: 5, HotelsWithoutPhone: 1, EmailCount: 3, FaxCount: 1, WebsiteCount: 2,
+		AverageContacts: 2.75, OfficialCount: 3, RoomCount: 40, BedCount: 64, AverageRating: 8,
+		MedianNightlyPrice: &median, Currency: "EUR",
+	}, stats)
+
+	mockRepo.AssertExpectations(t)
+}
 
-	hotelCountResult, phoneCountResult, err := service.FetchLocationStats(location)
+func TestFetchLocationStats_NoExchangeRate(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+
+	filter := LocationFilter{City: "Istanbul"}
+	today := day(time.Now().UTC())
+	mockRepo.On("ListContactTypes").Return(DefaultContactTypes, nil).Once()
+	mockRepo.On("FetchLocationAggregates", filter, []string{ContactTypePhone}).Return(&LocationAggregates{HotelCount: 2, ContactCounts: map[string]int{ContactTypePhone: 3}}, nil).Once()
+	mockRepo.On("FetchRoomCapacity", filter).Return(10, 20, nil).Once()
+	mockRepo.On("FetchNightlyPrices", filter, today).Return([]HotelPrice{
+		{HotelID: uuid.New(), Price: money.Amount{Value: money.MustParse("100"), Currency: "EUR"}},
+		{HotelID: uuid.New(), Price: money.Amount{Value: money.MustParse("4"), Currency: "XAU"}},
+	}, nil).Once()
+	mockRepo.On("FindExchangeRate", "XAU", "EUR", today).Return(nil, ErrNoExchangeRate).Once()
+
+	// A price that cannot be converted leaves out the median, not the counts
+	stats, err := service.FetchLocationStats(filter, "")
 	assert.NoError(t, err)
-	assert.Equal(t, hotelCount, hotelCountResult)
-	assert.Equal(t, phoneCount, phoneCountResult)
+	assert.Equal(t, &LocationStats{HotelCount: 2, PhoneCount: 3, AverageContacts: 1.5, RoomCount: 10, BedCount: 20}, stats)
 
 	mockRepo.AssertExpectations(t)
 }
@@ -230,10 +795,10 @@ func TestCreateHotel_Error(t *testing.T) {
 	})).Return(fmt.Errorf("error saving hotel")).Once()
 
 	// Create the service with the mocked repository
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, nil)
 
 	// Call CreateHotel and assert error
-	createdHotel, err := service.CreateHotel(hotel.OwnerName, hotel.OwnerSurname, hotel.CompanyTitle, nil)
+	createdHotel, err := service.CreateHotel(context.Background(), hotel.OwnerName, hotel.OwnerSurname, hotel.CompanyTitle, nil)
 	assert.Error(t, err)
 	assert.Nil(t, createdHotel)
 
@@ -243,14 +808,15 @@ func TestCreateHotel_Error(t *testing.T) {
 
 func TestDeleteHotel_Error(t *testing.T) {
 	mockRepo := new(MockHotelRepository)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, nil)
 
 	hotelID := uuid.New()
 
 	// Simulate an error when deleting the hotel
-	mockRepo.On("Delete", hotelID).Return(fmt.Errorf("error deleting hotel")).Once()
+	mockRepo.On("GetHotelDetails", hotelID).Return(&Hotel{ID: hotelID}, nil).Once()
+	mockRepo.On("Delete", hotelID, 0).Return(fmt.Errorf("error deleting hotel")).Once()
 
-	err := service.DeleteHotel(hotelID)
+	err := service.DeleteHotel(context.Background(), hotelID, 0)
 	assert.Error(t, err)
 
 	mockRepo.AssertExpectations(t)
@@ -258,18 +824,20 @@ func TestDeleteHotel_Error(t *testing.T) {
 
 func TestAddContactInfo_Error(t *testing.T) {
 	mockRepo := new(MockHotelRepository)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, nil)
 
 	hotelID := uuid.New()
 	contact := &ContactInfo{
 		InfoType:    ContactTypePhone,
-		InfoContent: "123-456-7890",
+		InfoContent: "+1 212-456-7890",
 	}
 
 	// Simulate an error when adding contact info
-	mockRepo.On("AddContactInfo", hotelID, contact).Return(fmt.Errorf("error adding contact info")).Once()
+	mockRepo.On("GetHotelDetails", hotelID).Return(&Hotel{ID: hotelID}, nil).Once()
+	mockRepo.On("ListContactTypes").Return(DefaultContactTypes, nil).Once()
+	mockRepo.On("AddContactInfo", hotelID, contact, 0).Return(fmt.Errorf("error adding contact info")).Once()
 
-	err := service.AddContactInfo(hotelID, contact)
+	err := service.AddContactInfo(context.Background(), hotelID, contact, 0)
 	assert.Error(t, err)
 
 	mockRepo.AssertExpectations(t)
@@ -277,15 +845,16 @@ func TestAddContactInfo_Error(t *testing.T) {
 
 func TestRemoveContactInfo_Error(t *testing.T) {
 	mockRepo := new(MockHotelRepository)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, nil)
 
 	hotelID := uuid.New()
 	contactID := uuid.New()
 
 	// Simulate an error when removing contact info
-	mockRepo.On("RemoveContactInfo", hotelID, contactID).Return(fmt.Errorf("error removing contact info")).Once()
+	mockRepo.On("GetContactInfo", hotelID, contactID).Return(&ContactInfo{ID: contactID, HotelID: hotelID}, nil).Once()
+	mockRepo.On("RemoveContactInfo", hotelID, contactID, 0).Return(fmt.Errorf("error removing contact info")).Once()
 
-	err := service.RemoveContactInfo(hotelID, contactID)
+	err := service.RemoveContactInfo(context.Background(), hotelID, contactID, 0)
 	assert.Error(t, err)
 
 	mockRepo.AssertExpectations(t)
@@ -293,27 +862,28 @@ func TestRemoveContactInfo_Error(t *testing.T) {
 
 func TestListHotels_Empty(t *testing.T) {
 	mockRepo := new(MockHotelRepository)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, nil)
 
 	// Simulate an empty list of hotels
-	mockRepo.On("ListHotels").Return([]Hotel{}, nil).Once()
+	mockRepo.On("ListHotels", mock.Anything).Return([]Hotel{}, int64(0), nil).Once()
 
-	hotels, err := service.ListHotels()
+	page, err := service.ListHotels(ListOptions{})
 	assert.NoError(t, err)
-	assert.Empty(t, hotels)
+	assert.Empty(t, page.Items)
 
 	mockRepo.AssertExpectations(t)
 }
 
 func TestListHotelOfficials_Empty(t *testing.T) {
 	mockRepo := new(MockHotelRepository)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, nil)
 
 	// Simulate an empty list of hotel officials
-	mockRepo.On("GetHotelOfficials").Return([]HotelOfficial{}, nil).Once()
+	mockRepo.On("GetHotelOfficials", OfficialFilter{}).Return([]HotelOfficial(nil), nil).Once()
 
-	officials, err := service.ListHotelOfficials()
+	officials, err := service.ListHotelOfficials(OfficialFilter{})
 	assert.NoError(t, err)
+	assert.NotNil(t, officials)
 	assert.Empty(t, officials)
 
 	mockRepo.AssertExpectations(t)
@@ -321,16 +891,1449 @@ func TestListHotelOfficials_Empty(t *testing.T) {
 
 func TestFetchLocationStats_ZeroHotels(t *testing.T) {
 	mockRepo := new(MockHotelRepository)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, nil)
 
 	location := "New York"
 	// Simulate zero hotels for the given location
-	mockRepo.On("FetchHotelsByLocation", location).Return([]Hotel{}, nil).Once()
+	mockRepo.On("ListContactTypes").Return(DefaultContactTypes, nil).Once()
+	mockRepo.On("FetchLocationAggregates", LocationFilter{Name: location}, []string{ContactTypePhone}).Return(&LocationAggregates{ContactCounts: map[string]int{}}, nil).Once()
+
+	// Room capacity is not summed without hotels
+	stats, err := service.FetchLocationStats(LocationFilter{Name: location}, "")
+	assert.NoError(t, err)
+	assert.Equal(t, &LocationStats{}, stats)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestListLocationStats(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+
+	contactTypes := append([]ContactType{{Name: "whatsapp", DisplayName: "WhatsApp", Kind: ContactKindPhone, CountsInStats: true}}, DefaultContactTypes...)
+	opts := LocationStatsOptions{Level: LocationLevelCity, Location: LocationFilter{Country: "Turkey"}, MinHotels: 2, SortBy: "hotel_count", SortDesc: true, Limit: 2}
+	summaries := []LocationSummary{{Country: "Turkey", City: "Istanbul", HotelCount: 12, PhoneCount: 30}, {Country: "Turkey", City: "Izmir", HotelCount: 4, PhoneCount: 7}}
+	mockRepo.On("ListContactTypes").Return(contactTypes, nil).Once()
+	mockRepo.On("ListLocationStats", opts, []string{ContactTypePhone, "whatsapp"}).Return(summaries, int64(5), nil).Once()
+
+	page, err := service.ListLocationStats(LocationStatsOptions{Location: LocationFilter{Country: "Turkey"}, MinHotels: 2, Limit: 2})
+	if assert.NoError(t, err) {
+		assert.Equal(t, &LocationStatsPage{Items: summaries, Level: LocationLevelCity, Total: 5, Limit: 2}, page)
+	}
+
+	// Invalid options never reach the repository
+	_, err = service.ListLocationStats(LocationStatsOptions{Level: "street"})
+	assert.ErrorIs(t, err, ErrInvalidLocationLevel)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestFetchLocationStatsBatch(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+
+	chainID := uuid.New()
+	istanbul, chain := LocationFilter{City: "Istanbul"}, LocationFilter{ChainID: chainID}
+	today := day(time.Now().UTC())
+
+	// Contact types and exchange rates are loaded once for the whole batch
+	mockRepo.On("ListContactTypes").Return(DefaultContactTypes, nil).Once()
+	mockRepo.On("FindExchangeRate", "TRY", "EUR", today).Return(&ExchangeRate{BaseCurrency: "EUR", QuoteCurrency: "TRY", Rate: money.MustParse("30")}, nil).Once()
+	mockRepo.On("FetchLocationAggregates", istanbul, []string{ContactTypePhone}).Return(&LocationAggregates{HotelCount: 2, ContactCounts: map[string]int{ContactTypePhone: 3}}, nil).Once()
+	mockRepo.On("FetchRoomCapacity", istanbul).Return(10, 20, nil).Once()
+	mockRepo.On("FetchNightlyPrices", istanbul, today).Return([]HotelPrice{
+		{HotelID: uuid.New(), Price: money.Amount{Value: money.MustParse("3000"), Currency: "TRY"}},
+		{HotelID: uuid.New(), Price: money.Amount{Value: money.MustParse("100"), Currency: "EUR"}},
+	}, nil).Once()
+	mockRepo.On("FetchLocationAggregates", chain, []string{ContactTypePhone}).Return(&LocationAggregates{HotelCount: 1, ContactCounts: map[string]int{ContactTypePhone: 1, ContactTypeEmail: 1}}, nil).Once()
+	mockRepo.On("FetchRoomCapacity", chain).Return(4, 8, nil).Once()
+	mockRepo.On("FetchNightlyPrices", chain, today).Return([]HotelPrice{{HotelID: uuid.New(), Price: money.Amount{Value: money.MustParse("6000"), Currency: "TRY"}}}, nil).Once()
+
+	stats, err := service.FetchLocationStatsBatch([]LocationStatsQuery{{Filter: istanbul, Currency: "eur"}, {Filter: chain}})
+	if assert.NoError(t, err) {
+		first, second := money.MustParse("100"), money.MustParse("200")
+		assert.Equal(t, []LocationStats{
+			{HotelCount: 2, PhoneCount: 3, AverageContacts: 1.5, RoomCount: 10, BedCount: 20, MedianNightlyPrice: &first, Currency: "EUR"},
+			{HotelCount: 1, PhoneCount: 1, EmailCount: 1, AverageContacts: 2, RoomCount: 4, BedCount: 8, MedianNightlyPrice: &second, Currency: "EUR"},
+		}, stats)
+	}
+
+	// Every invalid query is reported before any stats are computed
+	_, err = service.FetchLocationStatsBatch([]LocationStatsQuery{{}, {Filter: istanbul, Currency: "lira"}})
+	var validationErr *ValidationError
+	if assert.ErrorAs(t, err, &validationErr) {
+		assert.Equal(t, []FieldError{
+			{"locations[0]", "requires a location, country, city, district or chain_id"},
+			{"locations[1].currency", "must be an ISO 4217 code such as EUR or TRY"},
+		}, validationErr.Fields)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateHotel(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+	mockRepo.On("RecordAudit", mock.Anything).Return(nil).Once()
+
+	hotelID := uuid.New()
+	existing := &Hotel{ID: hotelID, OwnerName: "John", OwnerSurname: "Doe", CompanyTitle: "Doe Ltd."}
+	title := "Doe Hotels Ltd."
+
+	mockRepo.On("GetHotelDetails", hotelID).Return(existing, nil).Once()
+	mockRepo.On("UpdateHotel", mock.MatchedBy(func(h *Hotel) bool {
+		return h.ID == hotelID && h.OwnerName == "John" && h.CompanyTitle == title
+	})).Return(nil).Once()
+
+	hotel, err := service.UpdateHotel(context.Background(), hotelID, HotelUpdate{CompanyTitle: &title}, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, title, hotel.CompanyTitle)
+	assert.Equal(t, hotelID, hotel.ID)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateHotel_Invalid(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+
+	hotelID := uuid.New()
+	existing := &Hotel{ID: hotelID, OwnerName: "John", OwnerSurname: "Doe", CompanyTitle: "Doe Ltd."}
+	empty := ""
+
+	// Clearing a required field must fail the same validation as CreateHotel
+	mockRepo.On("GetHotelDetails", hotelID).Return(existing, nil).Once()
+
+	hotel, err := service.UpdateHotel(context.Background(), hotelID, HotelUpdate{OwnerName: &empty}, 0)
+	assert.ErrorIs(t, err, ErrInvalidHotel)
+	assert.Nil(t, hotel)
+
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "UpdateHotel", mock.Anything)
+}
+
+func TestUpdateHotel_NotFound(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+
+	hotelID := uuid.New()
+	title := "Doe Hotels Ltd."
+	var missing *Hotel
+
+	mockRepo.On("GetHotelDetails", hotelID).Return(missing, ErrHotelNotFound).Once()
+
+	_, err := service.UpdateHotel(context.Background(), hotelID, HotelUpdate{CompanyTitle: &title}, 0)
+	assert.ErrorIs(t, err, ErrHotelNotFound)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateContactInfo(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+	mockRepo.On("RecordAudit", mock.Anything).Return(nil).Once()
+
+	hotelID := uuid.New()
+	contactID := uuid.New()
+	existing := &ContactInfo{ID: contactID, HotelID: hotelID, InfoType: ContactTypePhone, InfoContent: "+902125550100"}
+	content := "030 555 0100"
+
+	// National numbers take the country code of the hotel's location
+	hotel := &Hotel{ID: hotelID, Location: &Location{Country: "Germany", City: "Berlin"}}
+	mockRepo.On("GetHotelDetails", hotelID).Return(hotel, nil).Once()
+	mockRepo.On("GetContactInfo", hotelID, contactID).Return(existing, nil).Once()
+	mockRepo.On("ListContactTypes").Return(DefaultContactTypes, nil).Once()
+	mockRepo.On("UpdateContactInfo", mock.MatchedBy(func(c *ContactInfo) bool {
+		return c.ID == contactID && c.InfoType == ContactTypePhone && c.InfoContent == "+49305550100"
+	}), 0).Return(nil).Once()
+
+	contact, err := service.UpdateContactInfo(context.Background(), hotelID, contactID, ContactInfoUpdate{InfoContent: &content}, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "+49305550100", contact.InfoContent)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateContactInfo_HotelNotFound(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+
+	hotelID := uuid.New()
+	contactID := uuid.New()
+	content := "456"
+	var missing *Hotel
+
+	mockRepo.On("GetHotelDetails", hotelID).Return(missing, ErrHotelNotFound).Once()
+
+	_, err := service.UpdateContactInfo(context.Background(), hotelID, contactID, ContactInfoUpdate{InfoContent: &content}, 0)
+	assert.ErrorIs(t, err, ErrHotelNotFound)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateHotel_VersionConflict(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+
+	hotelID := uuid.New()
+	existing := &Hotel{ID: hotelID, OwnerName: "John", OwnerSurname: "Doe", CompanyTitle: "Doe Ltd.", Version: 3}
+	title := "Doe Hotels Ltd."
+
+	// The caller expects version 2 but the hotel has moved on to version 3
+	mockRepo.On("GetHotelDetails", hotelID).Return(existing, nil).Once()
+
+	hotel, err := service.UpdateHotel(context.Background(), hotelID, HotelUpdate{CompanyTitle: &title}, 2)
+	assert.ErrorIs(t, err, ErrVersionConflict)
+	assert.Nil(t, hotel)
+
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "UpdateHotel", mock.Anything)
+}
+
+func TestSetLocation(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+	mockRepo.On("RecordAudit", mock.Anything).Return(nil).Once()
+
+	hotelID := uuid.New()
+	lat, lng := 41.0082, 28.9784
+	location := &Location{Country: " Turkey ", City: "Istanbul", Latitude: &lat, Longitude: &lng}
+
+	mockRepo.On("GetHotelDetails", hotelID).Return(&Hotel{ID: hotelID}, nil).Once()
+	mockRepo.On("SetLocation", mock.MatchedBy(func(l *Location) bool {
+		return l.HotelID == hotelID && l.Country == "Turkey"
+	}), 0).Return(nil).Once()
+
+	err := service.SetLocation(context.Background(), hotelID, location, 0)
+	assert.NoError(t, err)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSetLocation_Invalid(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+
+	lat := 41.0082
+	invalid := []*Location{
+		{Country: "Turkey"},
+		{City: "Istanbul"},
+		{Country: "Turkey", City: "Istanbul", Latitude: &lat},
+		{Country: "Turkey", City: "Istanbul", Latitude: &lat, Longitude: func() *float64 { v := 181.0; return &v }()},
+	}
+
+	for _, location := range invalid {
+		err := service.SetLocation(context.Background(), uuid.New(), location, 0)
+		assert.ErrorIs(t, err, ErrInvalidLocation)
+	}
+
+	mockRepo.AssertNotCalled(t, "SetLocation", mock.Anything, mock.Anything)
+}
+
+func TestParseLegacyLocation(t *testing.T) {
+	hotelID := uuid.New()
+
+	location := parseLegacyLocation(hotelID, "New York")
+	assert.Equal(t, hotelID, location.HotelID)
+	assert.Equal(t, "New York", location.City)
+	assert.Empty(t, location.Country)
+
+	location = parseLegacyLocation(hotelID, "Istanbul, Turkey")
+	assert.Equal(t, "Istanbul", location.City)
+	assert.Equal(t, "Turkey", location.Country)
+
+	location = parseLegacyLocation(hotelID, "Bagdat Cd. 5, Kadikoy, Istanbul, Turkey")
+	assert.Equal(t, "Bagdat Cd. 5", location.Street)
+	assert.Equal(t, "Kadikoy", location.District)
+	assert.Equal(t, "Istanbul", location.City)
+	assert.Equal(t, "Turkey", location.Country)
+}
+
+func hotelAt(title string, lat, lng float64) Hotel {
+	return Hotel{ID: uuid.New(), CompanyTitle: title, Location: &Location{Latitude: &lat, Longitude: &lng}}
+}
+
+func TestFindNearbyHotels(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+
+	// Taksim is the origin; Kadikoy is inside the radius, the box corner hotel is not
+	far := hotelAt("Corner", 41.1, 29.1)
+	near := hotelAt("Kadikoy", 40.99, 29.03)
+	closest := hotelAt("Taksim", 41.037, 28.985)
+	noCoordinates := Hotel{ID: uuid.New(), CompanyTitle: "Unknown"}
+
+	mockRepo.On("FetchHotelsInBoundingBox", boundingBoxAround(41.0369, 28.9850, 8)).
+		Return([]Hotel{far, near, noCoordinates, closest}, nil).Once()
+
+	results, err := service.FindNearbyHotels(41.0369, 28.9850, 8, 0)
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Equal(t, "Taksim", results[0].CompanyTitle)
+	assert.Equal(t, "Kadikoy", results[1].CompanyTitle)
+	assert.Less(t, results[0].DistanceKm, results[1].DistanceKm)
+	assert.LessOrEqual(t, results[1].DistanceKm, 8.0)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestFindNearbyHotels_InvalidCoordinates(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+
+	_, err := service.FindNearbyHotels(95, 28.9850, 8, 0)
+	assert.ErrorIs(t, err, ErrInvalidCoordinates)
+
+	_, err = service.FindNearbyHotels(41.0369, 28.9850, 0, 0)
+	assert.ErrorIs(t, err, ErrInvalidCoordinates)
+
+	_, err = service.FindNearbyHotels(41.0369, 28.9850, MaxRadiusKm+1, 0)
+	assert.ErrorIs(t, err, ErrSearchAreaTooLarge)
+
+	// A box crossing the antimeridian spans the degrees across it
+	_, err = service.FindHotelsInBoundingBox(BoundingBox{MinLat: 40, MinLng: 175, MaxLat: 42, MaxLng: -170}, 0)
+	assert.ErrorIs(t, err, ErrSearchAreaTooLarge)
+
+	mockRepo.AssertNotCalled(t, "FetchHotelsInBoundingBox", mock.Anything)
+}
+
+func TestFindHotelsInBoundingBox(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+
+	box := BoundingBox{MinLat: 40, MinLng: 28, MaxLat: 42, MaxLng: 30}
+	edge := hotelAt("Edge", 40.1, 28.1)
+	center := hotelAt("Center", 41.01, 29.01)
+
+	mockRepo.On("FetchHotelsInBoundingBox", box).Return([]Hotel{edge, center}, nil).Once()
+
+	results, err := service.FindHotelsInBoundingBox(box, 0)
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Equal(t, "Center", results[0].CompanyTitle)
+	assert.Equal(t, "Edge", results[1].CompanyTitle)
+
+	// The limit keeps the nearest hotels
+	mockRepo.On("FetchHotelsInBoundingBox", box).Return([]Hotel{edge, center}, nil).Once()
+	results, err = service.FindHotelsInBoundingBox(box, 1)
+	assert.NoError(t, err)
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, "Center", results[0].CompanyTitle)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSearchHotels(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+	mockRepo.On("RecordAudit", mock.Anything).Return(nil).Twice()
+
+	existing := Hotel{ID: uuid.New(), OwnerName: "Jane", OwnerSurname: "Smith", CompanyTitle: "Pera Palace"}
+
+	// The index is loaded once, on the first search
+	mockRepo.On("FetchAllHotels").Return([]Hotel{existing}, nil).Once()
+
+	results, err := service.SearchHotels("pera palas", 0)
+	assert.NoError(t, err)
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, existing.ID, results[0].ID)
+	}
+
+	// Hotels created afterwards are searchable without reloading
+	mockRepo.On("Save", mock.Anything).Return(nil).Once()
+	created, err := service.CreateHotel(context.Background(), "John", "Doe", "Palace Suites", nil)
+	assert.NoError(t, err)
+
+	results, err = service.SearchHotels("palace", 0)
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+
+	// Deleted hotels disappear from the results
+	mockRepo.On("GetHotelDetails", created.ID).Return(created, nil).Once()
+	mockRepo.On("Delete", created.ID, 0).Return(nil).Once()
+	assert.NoError(t, service.DeleteHotel(context.Background(), created.ID, 0))
+
+	results, err = service.SearchHotels("palace", 0)
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateHotel_InvalidContacts(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+
+	contacts := []ContactInfo{
+		{InfoType: ContactTypeEmail, InfoContent: "Desk@Example.COM"},
+		{InfoType: ContactTypeEmail, InfoContent: "not-an-email"},
+		{InfoType: "pager", InfoContent: "1234"},
+	}
+
+	mockRepo.On("ListContactTypes").Return(DefaultContactTypes, nil).Once()
+
+	_, err := service.CreateHotel(context.Background(), "John", "Doe", "Doe Ltd.", contacts)
+	assert.ErrorIs(t, err, ErrInvalidContact)
+
+	// Every invalid field is reported, valid contacts are normalized
+	var validationErr *ValidationError
+	if assert.ErrorAs(t, err, &validationErr) {
+		assert.Equal(t, []FieldError{
+			{Field: "contacts[1].info_content", Message: "must be an email address"},
+			{Field: "contacts[2].info_type", Message: "must be one of email, fax, location, phone, website"},
+		}, validationErr.Fields)
+	}
+	assert.Equal(t, "desk@example.com", contacts[0].InfoContent)
+
+	mockRepo.AssertNotCalled(t, "Save", mock.Anything)
+}
+
+func TestFetchLocationStats_CountedContactTypes(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+
+	aggregates := &LocationAggregates{HotelCount: 1, ContactCounts: map[string]int{ContactTypePhone: 1, "whatsapp": 1, ContactTypeFax: 1}}
+	contactTypes := append([]ContactType{{Name: "whatsapp", DisplayName: "WhatsApp", Kind: ContactKindPhone, CountsInStats: true}}, DefaultContactTypes...)
+
+	// Every type counted toward the stats is a phone type
+	mockRepo.On("ListContactTypes").Return(contactTypes, nil).Once()
+	mockRepo.On("FetchLocationAggregates", LocationFilter{City: "Istanbul"}, []string{ContactTypePhone, "whatsapp"}).Return(aggregates, nil).Once()
+	mockRepo.On("FetchRoomCapacity", LocationFilter{City: "Istanbul"}).Return(0, 0, nil).Once()
+	mockRepo.On("FetchNightlyPrices", LocationFilter{City: "Istanbul"}, mock.Anything).Return([]HotelPrice{}, nil).Once()
+
+	// Only the types that count toward the stats are counted
+	stats, err := service.FetchLocationStats(LocationFilter{City: "Istanbul"}, "")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, stats.HotelCount)
+	assert.Equal(t, 2, stats.PhoneCount)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateContactType(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+
+	contactType := &ContactType{Name: "whatsapp", DisplayName: "WhatsApp", Kind: ContactKindPhone}
+	mockRepo.On("CreateContactType", contactType).Return(nil).Once()
+
+	assert.NoError(t, service.CreateContactType(contactType))
+
+	// Invalid types never reach the repository
+	err := service.CreateContactType(&ContactType{Name: "WhatsApp", DisplayName: "WhatsApp", Kind: ContactKindPhone})
+	assert.ErrorIs(t, err, ErrInvalidContactType)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateContactType(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+
+	// The name comes from the path, not from the body
+	mockRepo.On("UpdateContactType", mock.MatchedBy(func(c *ContactType) bool {
+		return c.Name == "whatsapp" && c.UniquePerHotel
+	})).Return(ErrContactTypeNotFound).Once()
+
+	err := service.UpdateContactType("whatsapp", &ContactType{Name: "other", DisplayName: "WhatsApp", Kind: ContactKindPhone, UniquePerHotel: true})
+	assert.ErrorIs(t, err, ErrContactTypeNotFound)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRestoreHotel(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+	mockRepo.On("RecordAudit", mock.Anything).Return(nil).Once()
+
+	hotelID := uuid.New()
+	restored := &Hotel{ID: hotelID, CompanyTitle: "Bosphorus Inn", Version: 3}
+
+	mockRepo.On("Restore", hotelID).Return(nil).Once()
+	mockRepo.On("GetHotelDetails", hotelID).Return(restored, nil).Once()
+
+	hotel, err := service.RestoreHotel(context.Background(), hotelID)
+	assert.NoError(t, err)
+	assert.Equal(t, restored, hotel)
+
+	// Hotels that are not deleted cannot be restored
+	mockRepo.On("Restore", hotelID).Return(ErrHotelNotDeleted).Once()
+	_, err = service.RestoreHotel(context.Background(), hotelID)
+	assert.ErrorIs(t, err, ErrHotelNotDeleted)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPurgeDeletedHotels(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	blobs, err := storage.NewLocalStore(t.TempDir())
+	assert.NoError(t, err)
+	service := NewService(mockRepo, blobs)
+
+	// The media of purged hotels are removed from the blob store
+	media := Media{ID: uuid.New(), StorageKey: "hotels/1/media/2", HasThumbnail: true}
+	assert.NoError(t, blobs.Put(media.StorageKey, strings.NewReader("image")))
+	assert.NoError(t, blobs.Put(thumbnailKey(media.StorageKey), strings.NewReader("thumbnail")))
+
+	// Hotels deleted before now minus the retention period are purged
+	retention := 24 * time.Hour
+	expired := mock.MatchedBy(func(before time.Time) bool {
+		return time.Since(before) >= retention && time.Since(before) < retention+time.Minute
+	})
+	ids := []uuid.UUID{uuid.New(), uuid.New()}
+	mockRepo.On("LockDeletedHotels", expired).Return(ids, nil).Once()
+	mockRepo.On("ListPurgeableMedia", ids).Return([]Media{media}, nil).Once()
+	mockRepo.On("PurgeHotels", ids).Return(int64(2), nil).Once()
+
+	purged, err := service.PurgeDeletedHotels(retention)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), purged)
+
+	for _, key := range []string{media.StorageKey, thumbnailKey(media.StorageKey)} {
+		_, err := blobs.Open(key)
+		assert.ErrorIs(t, err, storage.ErrBlobNotFound, key)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPurgeDeletedHotels_Failed(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	blobs, err := storage.NewLocalStore(t.TempDir())
+	assert.NoError(t, err)
+	service := NewService(mockRepo, blobs)
+
+	media := Media{ID: uuid.New(), StorageKey: "hotels/1/media/2"}
+	assert.NoError(t, blobs.Put(media.StorageKey, strings.NewReader("image")))
+
+	ids := []uuid.UUID{uuid.New()}
+	mockRepo.On("LockDeletedHotels", mock.Anything).Return(ids, nil).Once()
+	mockRepo.On("ListPurgeableMedia", ids).Return([]Media{media}, nil).Once()
+	mockRepo.On("PurgeHotels", ids).Return(int64(0), fmt.Errorf("connection reset")).Once()
+
+	// The blobs of hotels that were not purged are kept
+	_, err = service.PurgeDeletedHotels(24 * time.Hour)
+	assert.Error(t, err)
+	_, err = blobs.Open(media.StorageKey)
+	assert.NoError(t, err)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateHotel_RecordsAudit(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+
+	hotelID := uuid.New()
+	title := "Doe Hotels Ltd."
+
+	mockRepo.On("GetHotelDetails", hotelID).Return(&Hotel{ID: hotelID, OwnerName: "John", OwnerSurname: "Doe", CompanyTitle: "Doe Ltd."}, nil).Once()
+	mockRepo.On("UpdateHotel", mock.Anything).Return(nil).Once()
+	mockRepo.On("RecordAudit", mock.MatchedBy(func(entry *AuditEntry) bool {
+		return entry.HotelID == hotelID && entry.EntityID == hotelID &&
+			entry.Entity == AuditEntityHotel && entry.Action == AuditActionUpdate &&
+			entry.Actor == "alice" && entry.RequestID == "req-1" && len(entry.Changes) == 1 &&
+			entry.Changes["company_title"] == AuditChange{Before: "Doe Ltd.", After: title}
+	})).Return(nil).Once()
+
+	ctx := WithAuditInfo(context.Background(), "alice", "req-1")
+	_, err := service.UpdateHotel(ctx, hotelID, HotelUpdate{CompanyTitle: &title}, 0)
+	assert.NoError(t, err)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRemoveContactInfo_AuditFailure(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+
+	hotelID, contactID := uuid.New(), uuid.New()
+	contact := &ContactInfo{ID: contactID, HotelID: hotelID, InfoType: ContactTypePhone, InfoContent: "+902125550100"}
+
+	mockRepo.On("GetContactInfo", hotelID, contactID).Return(contact, nil).Once()
+	mockRepo.On("RemoveContactInfo", hotelID, contactID, 0).Return(nil).Once()
+	mockRepo.On("RecordAudit", mock.MatchedBy(func(entry *AuditEntry) bool {
+		return entry.Action == AuditActionDelete && entry.Actor == SystemActor &&
+			entry.Changes["info_content"] == AuditChange{Before: "+902125550100", After: nil}
+	})).Return(fmt.Errorf("database is down")).Once()
+
+	// The removal shares the transaction of its audit entry, so it fails with it
+	err := service.RemoveContactInfo(context.Background(), hotelID, contactID, 0)
+	assert.ErrorContains(t, err, "database is down")
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestListAuditEntries(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+
+	hotelID := uuid.New()
+	entries := []AuditEntry{
+		{ID: uuid.New(), HotelID: hotelID, CreatedAt: time.Date(2026, 5, 2, 0, 0, 0, 0, time.UTC)},
+		{ID: uuid.New(), HotelID: hotelID, CreatedAt: time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	mockRepo.On("ListAuditEntries", mock.MatchedBy(func(filter AuditFilter) bool {
+		return filter.HotelID == hotelID && filter.Limit == 1 && filter.after == nil
+	})).Return(entries, nil).Once()
+
+	page, err := service.ListAuditEntries(AuditFilter{HotelID: hotelID, Limit: 1})
+	assert.NoError(t, err)
+	assert.Len(t, page.Items, 1)
+	assert.NotEmpty(t, page.NextCursor)
+
+	// The cursor continues after the last entry of the page
+	mockRepo.On("ListAuditEntries", mock.MatchedBy(func(filter AuditFilter) bool {
+		return filter.after != nil && filter.after.ID == entries[0].ID && filter.after.Value == entries[0].CreatedAt
+	})).Return(entries[1:], nil).Once()
+
+	page, err = service.ListAuditEntries(AuditFilter{HotelID: hotelID, Limit: 1, Cursor: page.NextCursor})
+	assert.NoError(t, err)
+	assert.Len(t, page.Items, 1)
+	assert.Empty(t, page.NextCursor)
+
+	// Empty time ranges are rejected before reaching the repository
+	_, err = service.ListAuditEntries(AuditFilter{Since: entries[0].CreatedAt, Until: entries[1].CreatedAt})
+	assert.ErrorIs(t, err, ErrInvalidAuditFilter)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestImportHotels_AllOrNothing(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+
+	rows := []ImportRow{
+		{Row: 2, OwnerName: "John", OwnerSurname: "Doe", CompanyTitle: "Doe Ltd.", Contacts: []ContactInfo{{InfoType: ContactTypePhone, InfoContent: "0212 555 01 00"}}},
+		{Row: 3, OwnerName: "Jane", OwnerSurname: "Smith", Contacts: []ContactInfo{{InfoType: ContactTypeEmail, InfoContent: "not-an-email"}}},
+	}
+	mockRepo.On("ListContactTypes").Return(DefaultContactTypes, nil)
+
+	// A single invalid row keeps every hotel out of the database
+	result, err := service.ImportHotels(context.Background(), rows, ImportOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.Total)
+	assert.Equal(t, 1, result.Valid)
+	assert.Equal(t, 0, result.Imported)
+	if assert.Len(t, result.Errors, 1) {
+		assert.Equal(t, 3, result.Errors[0].Row)
+		assert.Equal(t, ErrInvalidHotel.Error(), result.Errors[0].Error)
+	}
+
+	// Once the file is valid, it is stored in one transaction
+	rows = rows[:1]
+	mockRepo.On("ImportHotels", mock.MatchedBy(func(hotels []*Hotel) bool {
+		return len(hotels) == 1 && hotels[0].ContactInfos[0].InfoContent == "+902125550100"
+	})).Return(nil).Once()
+	mockRepo.On("RecordAudit", mock.Anything).Return(nil).Twice()
+
+	result, err = service.ImportHotels(context.Background(), rows, ImportOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Imported)
+	assert.Empty(t, result.Errors)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestImportHotels_AllOrNothing_Large(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+
+	rows := make([]ImportRow, importInsertBatch+1)
+	for i := range rows {
+		rows[i] = ImportRow{Row: i + 2, OwnerName: "John", OwnerSurname: "Doe", CompanyTitle: fmt.Sprintf("Doe %d Ltd.", i)}
+	}
+	mockRepo.On("ListContactTypes").Return(DefaultContactTypes, nil).Once()
+	mockRepo.On("ImportHotels", mock.MatchedBy(func(hotels []*Hotel) bool { return len(hotels) == importInsertBatch })).Return(nil).Once()
+	mockRepo.On("ImportHotels", mock.MatchedBy(func(hotels []*Hotel) bool { return len(hotels) == 1 })).Return(nil).Once()
+	mockRepo.On("RecordAudit", mock.Anything).Return(nil).Times(len(rows))
+
+	// The rows are stored batch by batch, still in one transaction
+	result, err := service.ImportHotels(context.Background(), rows, ImportOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, len(rows), result.Imported)
+	assert.Empty(t, result.Errors)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestImportHotels_Batches(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+
+	rows := []ImportRow{
+		{Row: 1, OwnerName: "A", OwnerSurname: "A", CompanyTitle: "A Ltd."},
+		{Row: 2, Err: fmt.Errorf("invalid character")},
+		{Row: 3, OwnerName: "B", OwnerSurname: "B", CompanyTitle: "B Ltd."},
+		{Row: 4, OwnerName: "C", OwnerSurname: "C", CompanyTitle: "C Ltd."},
+	}
+	mockRepo.On("ListContactTypes").Return(DefaultContactTypes, nil).Once()
+	mockRepo.On("ImportHotels", mock.MatchedBy(func(hotels []*Hotel) bool { return len(hotels) == 2 })).Return(nil).Once()
+	mockRepo.On("ImportHotels", mock.MatchedBy(func(hotels []*Hotel) bool { return len(hotels) == 1 })).Return(fmt.Errorf("duplicate key")).Once()
+	mockRepo.On("RecordAudit", mock.Anything).Return(nil).Twice()
+
+	// Invalid rows are skipped and a failed batch does not undo earlier ones
+	result, err := service.ImportHotels(context.Background(), rows, ImportOptions{BatchSize: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, result.Valid)
+	assert.Equal(t, 2, result.Imported)
+	assert.Equal(t, 2, result.Failed)
+	if assert.Len(t, result.Errors, 2) {
+		assert.Equal(t, ImportRowError{Row: 2, Error: "invalid character"}, result.Errors[0])
+		assert.Equal(t, ImportRowError{Row: 4, Error: "duplicate key"}, result.Errors[1])
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestImportHotels_DryRun(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+
+	rows := []ImportRow{{Row: 1, OwnerName: "John", OwnerSurname: "Doe", CompanyTitle: "Doe Ltd."}}
+	mockRepo.On("ListContactTypes").Return(DefaultContactTypes, nil).Once()
+
+	// Nothing is stored
+	result, err := service.ImportHotels(context.Background(), rows, ImportOptions{DryRun: true})
+	assert.NoError(t, err)
+	assert.True(t, result.DryRun)
+	assert.Equal(t, 1, result.Valid)
+	assert.Equal(t, 0, result.Imported)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestStartImportJob(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+
+	rows := []ImportRow{{Row: 1, OwnerName: "John", OwnerSurname: "Doe", CompanyTitle: "Doe Ltd."}}
+	finished := make(chan ImportJob, 1)
+
+	mockRepo.On("CreateImportJob", mock.MatchedBy(func(job *ImportJob) bool {
+		return job.Status == ImportInProgress && job.Total == 1
+	})).Return(nil).Once()
+	mockRepo.On("ListContactTypes").Return(DefaultContactTypes, nil).Once()
+	mockRepo.On("ImportHotels", mock.Anything).Return(nil).Once()
+	mockRepo.On("RecordAudit", mock.Anything).Return(nil).Once()
+	mockRepo.On("UpdateImportJob", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		if job := args.Get(0).(*ImportJob); job.FinishedAt != nil {
+			finished <- *job
+		}
+	}).Twice()
+
+	job, err := service.StartImportJob(context.Background(), ImportRows(rows), ImportOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, ImportInProgress, job.Status)
+
+	// The job reports the outcome once the import is done
+	select {
+	case done := <-finished:
+		assert.Equal(t, job.ID, done.ID)
+		assert.Equal(t, ImportCompleted, done.Status)
+		assert.Equal(t, 1, done.Imported)
+	case <-time.After(time.Second):
+		t.Fatal("import job did not finish")
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestStartImportJob_NegativeBatchSize(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+
+	rows := []ImportRow{{Row: 1, OwnerName: "John", OwnerSurname: "Doe", CompanyTitle: "Doe Ltd."}}
+
+	// No job is created for options the import would reject
+	_, err := service.StartImportJob(context.Background(), ImportRows(rows), ImportOptions{BatchSize: -1})
+	assert.ErrorIs(t, err, ErrInvalidImport)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestFailInterruptedImportJobs(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+
+	mockRepo.On("FailImportJobs", ErrImportInterrupted.Error(), mock.AnythingOfType("time.Time")).Return(int64(2), nil).Once()
+
+	failed, err := service.FailInterruptedImportJobs()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), failed)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestExportHotels(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+
+	hotels := []Hotel{
+		{ID: uuid.New(), OwnerName: "John", OwnerSurname: "Doe", CompanyTitle: "Doe Ltd.", ContactInfos: []ContactInfo{{InfoType: ContactTypePhone, InfoContent: "+902125550100"}}},
+	}
+	// Pagination does not apply to exports
+	opts := ListOptions{SortBy: "company_title", Location: LocationFilter{City: "Istanbul"}}
+	mockRepo.On("CountContactColumns", opts).Return(map[string]int{ContactTypePhone: 1}, nil).Once()
+	mockRepo.On("ExportHotels", opts).Return(hotels, nil).Once()
+
+	var buf strings.Builder
+	err := service.ExportHotels(&buf, ExportFormatCSV, ListOptions{Limit: 5, Offset: 10, SortBy: "company_title", Location: LocationFilter{City: "Istanbul"}})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(buf.String(), "\n"))
+	assert.Contains(t, buf.String(), ",contact:phone\n")
+	assert.Contains(t, buf.String(), ",+902125550100\n")
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestExportHotels_InvalidOptions(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+
+	var buf strings.Builder
+	err := service.ExportHotels(&buf, ExportFormatJSON, ListOptions{SortBy: "popularity"})
+	assert.ErrorIs(t, err, ErrInvalidSort)
+
+	err = service.ExportHotels(&buf, "xml", ListOptions{})
+	assert.ErrorIs(t, err, ErrInvalidExportFormat)
+
+	// Nothing is written before the options are validated
+	assert.Empty(t, buf.String())
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAddOfficial(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+	mockRepo.On("RecordAudit", mock.MatchedBy(func(entry *AuditEntry) bool {
+		return entry.Entity == AuditEntityOfficial && entry.Action == AuditActionCreate
+	})).Return(nil).Once()
+
+	hotelID := uuid.New()
+	official := &HotelOfficial{
+		Role:         "Sales Manager",
+		Name:         "Jane",
+		Surname:      "Smith",
+		ContactInfos: []OfficialContact{{ID: uuid.New(), InfoType: ContactTypePhone, InfoContent: "0212 555 01 00"}},
+	}
+
+	mockRepo.On("GetHotelDetails", hotelID).Return(&Hotel{ID: hotelID, CompanyTitle: "Doe Ltd."}, nil).Once()
+	mockRepo.On("ListContactTypes").Return(DefaultContactTypes, nil).Once()
+	mockRepo.On("AddOfficial", official, 3).Return(nil).Once()
+
+	err := service.AddOfficial(context.Background(), hotelID, official, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, hotelID, official.HotelID)
+	assert.Equal(t, RoleSalesManager, official.Role)
+	assert.Equal(t, "Doe Ltd.", official.CompanyTitle)
+	// Contacts are always stored as new contacts
+	assert.Equal(t, uuid.Nil, official.ContactInfos[0].ID)
+	assert.Equal(t, "+902125550100", official.ContactInfos[0].InfoContent)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAddOfficial_Invalid(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+
+	hotelID := uuid.New()
+	mockRepo.On("GetHotelDetails", hotelID).Return(&Hotel{ID: hotelID}, nil).Once()
+	mockRepo.On("ListContactTypes").Return(DefaultContactTypes, nil).Once()
+
+	err := service.AddOfficial(context.Background(), hotelID, &HotelOfficial{Role: RoleOwner, Name: "John"}, 0)
+	var validationErr *ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateOfficial(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+
+	hotelID, officialID := uuid.New(), uuid.New()
+	before := &HotelOfficial{ID: officialID, HotelID: hotelID, Role: RoleSalesManager, Name: "Jane", Surname: "Smith"}
+	official := &HotelOfficial{Role: RoleGeneralManager, Name: "Jane", Surname: "Smith"}
+
+	mockRepo.On("GetHotelDetails", hotelID).Return(&Hotel{ID: hotelID}, nil).Once()
+	mockRepo.On("GetOfficial", hotelID, officialID).Return(before, nil).Once()
+	mockRepo.On("ListContactTypes").Return(DefaultContactTypes, nil).Once()
+	mockRepo.On("UpdateOfficial", official, 0).Return(nil).Once()
+	mockRepo.On("RecordAudit", mock.MatchedBy(func(entry *AuditEntry) bool {
+		change, ok := entry.Changes["role"]
+		return ok && change.Before == RoleSalesManager && change.After == RoleGeneralManager
+	})).Return(nil).Once()
+
+	err := service.UpdateOfficial(context.Background(), hotelID, officialID, official, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, officialID, official.ID)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRemoveOfficial(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+	mockRepo.On("RecordAudit", mock.Anything).Return(nil).Once()
+
+	hotelID, officialID := uuid.New(), uuid.New()
+	mockRepo.On("GetOfficial", hotelID, officialID).Return(&HotelOfficial{ID: officialID, HotelID: hotelID}, nil).Once()
+	mockRepo.On("RemoveOfficial", hotelID, officialID, 0).Return(nil).Once()
+
+	err := service.RemoveOfficial(context.Background(), hotelID, officialID, 0)
+	assert.NoError(t, err)
+
+	// Unknown officials are reported without touching the hotel
+	unknownID := uuid.New()
+	mockRepo.On("GetOfficial", hotelID, unknownID).Return((*HotelOfficial)(nil), ErrOfficialNotFound).Once()
+	err = service.RemoveOfficial(context.Background(), hotelID, unknownID, 0)
+	assert.ErrorIs(t, err, ErrOfficialNotFound)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAddRoomType(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+	mockRepo.On("RecordAudit", mock.MatchedBy(func(entry *AuditEntry) bool {
+		return entry.Entity == AuditEntityRoomType && entry.Action == AuditActionCreate
+	})).Return(nil).Once()
+
+	hotelID := uuid.New()
+	roomType := &RoomType{Name: "Twin", Capacity: 2, RoomCount: 8, Beds: BedConfiguration{{Type: BedSingle, Count: 2}}}
+
+	mockRepo.On("GetHotelDetails", hotelID).Return(&Hotel{ID: hotelID}, nil).Once()
+	mockRepo.On("AddRoomType", roomType, 0).Return(nil).Once()
+
+	err := service.AddRoomType(context.Background(), hotelID, roomType, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, hotelID, roomType.HotelID)
+	assert.NotEqual(t, uuid.Nil, roomType.ID)
+	assert.Equal(t, 2, roomType.BedCount)
+
+	// Invalid room types are not stored
+	mockRepo.On("GetHotelDetails", hotelID).Return(&Hotel{ID: hotelID}, nil).Once()
+	err = service.AddRoomType(context.Background(), hotelID, &RoomType{Name: "Empty"}, 0)
+	var validationErr *ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateRoomType_NotFound(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+
+	hotelID, roomTypeID := uuid.New(), uuid.New()
+	mockRepo.On("GetRoomType", hotelID, roomTypeID).Return((*RoomType)(nil), ErrRoomTypeNotFound).Once()
+
+	err := service.UpdateRoomType(context.Background(), hotelID, roomTypeID, &RoomType{Name: "Twin"}, 0)
+	assert.ErrorIs(t, err, ErrRoomTypeNotFound)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestListRoomTypes(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+
+	hotelID := uuid.New()
+	mockRepo.On("GetHotelDetails", hotelID).Return(&Hotel{ID: hotelID}, nil).Once()
+	mockRepo.On("ListRoomTypes", hotelID).Return([]RoomType(nil), nil).Once()
+
+	roomTypes, err := service.ListRoomTypes(hotelID)
+	assert.NoError(t, err)
+	assert.NotNil(t, roomTypes)
+	assert.Empty(t, roomTypes)
+
+	// Unknown hotels are reported rather than listed as empty
+	unknownID := uuid.New()
+	mockRepo.On("GetHotelDetails", unknownID).Return((*Hotel)(nil), ErrHotelNotFound).Once()
+	_, err = service.ListRoomTypes(unknownID)
+	assert.ErrorIs(t, err, ErrHotelNotFound)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateReservation(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+	mockRepo.On("RecordAudit", mock.MatchedBy(func(entry *AuditEntry) bool {
+		return entry.Entity == AuditEntityReservation && entry.Action == AuditActionCreate
+	})).Return(nil).Once()
+
+	hotelID := uuid.New()
+	checkIn := day(time.Now().UTC()).AddDate(0, 0, 3)
+	reservation := &Reservation{RoomTypeID: uuid.New(), CheckIn: checkIn, CheckOut: checkIn.AddDate(0, 0, 2), Rooms: 2, Status: ReservationHold}
+
+	mockRepo.On("GetHotelDetails", hotelID).Return(&Hotel{ID: hotelID}, nil).Once()
+	mockRepo.On("CreateReservation", reservation, mock.Anything).Return(nil).Once()
+
+	err := service.CreateReservation(context.Background(), hotelID, reservation)
+	assert.NoError(t, err)
+	assert.Equal(t, hotelID, reservation.HotelID)
+	assert.NotEqual(t, uuid.Nil, reservation.ID)
+	if assert.NotNil(t, reservation.ExpiresAt) {
+		assert.WithinDuration(t, time.Now().Add(HoldDuration), *reservation.ExpiresAt, time.Minute)
+	}
+
+	// Overbooking is reported as is
+	booking := &Reservation{RoomTypeID: reservation.RoomTypeID, CheckIn: checkIn, CheckOut: checkIn.AddDate(0, 0, 1), Rooms: 1}
+	mockRepo.On("GetHotelDetails", hotelID).Return(&Hotel{ID: hotelID}, nil).Once()
+	mockRepo.On("CreateReservation", booking, mock.Anything).Return(ErrNotAvailable).Once()
+
+	err = service.CreateReservation(context.Background(), hotelID, booking)
+	assert.ErrorIs(t, err, ErrNotAvailable)
+	assert.Nil(t, booking.ExpiresAt)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetAvailability(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+
+	hotelID := uuid.New()
+	twin := RoomType{ID: uuid.New(), HotelID: hotelID, Name: "Twin", RoomCount: 4}
+	from := time.Date(2030, time.June, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 2)
+
+	mockRepo.On("GetHotelDetails", hotelID).Return(&Hotel{ID: hotelID}, nil).Once()
+	mockRepo.On("ListRoomTypes", hotelID).Return([]RoomType{twin}, nil).Once()
+	mockRepo.On("ListActiveReservations", []uuid.UUID{twin.ID}, from, to, mock.Anything).Return([]Reservation{
+		{RoomTypeID: twin.ID, CheckIn: from, CheckOut: from.AddDate(0, 0, 1), Rooms: 3},
+	}, nil).Once()
+
+	calendar, err := service.GetAvailability(hotelID, AvailabilityQuery{From: from, To: to})
+	assert.NoError(t, err)
+	assert.Equal(t, []RoomAvailability{{
+		RoomTypeID: twin.ID,
+		Name:       "Twin",
+		RoomCount:  4,
+		Dates:      []DateAvailability{{"2030-06-01", 1}, {"2030-06-02", 4}},
+	}}, calendar)
+
+	// Empty and reversed ranges are rejected
+	_, err = service.GetAvailability(hotelID, AvailabilityQuery{From: to, To: from})
+	assert.ErrorIs(t, err, ErrInvalidDateRange)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestConfirmAndCancelReservation(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+	mockRepo.On("RecordAudit", mock.MatchedBy(func(entry *AuditEntry) bool {
+		return entry.Entity == AuditEntityReservation && entry.Action == AuditActionUpdate
+	})).Return(nil).Twice()
+
+	hotelID, reservationID := uuid.New(), uuid.New()
+	expiresAt := time.Now().Add(HoldDuration)
+	hold := &Reservation{ID: reservationID, HotelID: hotelID, Status: ReservationHold, ExpiresAt: &expiresAt}
+
+	mockRepo.On("GetReservation", hotelID, reservationID).Return(hold, nil).Once()
+	mockRepo.On("ConfirmReservation", hotelID, reservationID, mock.Anything).Return(nil).Once()
+
+	confirmed, err := service.ConfirmReservation(context.Background(), hotelID, reservationID)
+	assert.NoError(t, err)
+	assert.Equal(t, ReservationBooked, confirmed.Status)
+	assert.Nil(t, confirmed.ExpiresAt)
+
+	mockRepo.On("GetReservation", hotelID, reservationID).Return(confirmed, nil).Once()
+	mockRepo.On("CancelReservation", hotelID, reservationID).Return(nil).Once()
+	assert.NoError(t, service.CancelReservation(context.Background(), hotelID, reservationID))
+
+	// Cancelling again changes nothing
+	cancelled := *confirmed
+	cancelled.Status = ReservationCancelled
+	mockRepo.On("GetReservation", hotelID, reservationID).Return(&cancelled, nil).Once()
+	assert.NoError(t, service.CancelReservation(context.Background(), hotelID, reservationID))
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAddRatePlan(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+	mockRepo.On("RecordAudit", mock.MatchedBy(func(entry *AuditEntry) bool {
+		return entry.Entity == AuditEntityRatePlan && entry.Action == AuditActionCreate
+	})).Return(nil).Once()
+
+	hotelID := uuid.New()
+	from := time.Date(2030, time.June, 1, 0, 0, 0, 0, time.UTC)
+	plan := &RatePlan{Name: "Summer", RoomTypeID: uuid.New(), ValidFrom: from, ValidTo: from.AddDate(0, 3, 0), BasePrice: money.MustParse("120"), Currency: "eur"}
+	mockRepo.On("AddRatePlan", plan, 3).Return(nil).Once()
+
+	assert.NoError(t, service.AddRatePlan(context.Background(), hotelID, plan, 3))
+	assert.Equal(t, hotelID, plan.HotelID)
+	assert.NotEqual(t, uuid.Nil, plan.ID)
+	assert.Equal(t, "EUR", plan.Currency)
+
+	// Overlapping plans are reported as is, invalid ones never reach the repository
+	overlapping := &RatePlan{Name: "Summer", RoomTypeID: plan.RoomTypeID, ValidFrom: from, ValidTo: from.AddDate(0, 1, 0), BasePrice: money.MustParse("90"), Currency: "EUR"}
+	mockRepo.On("AddRatePlan", overlapping, 4).Return(ErrRatePlanOverlap).Once()
+	assert.ErrorIs(t, service.AddRatePlan(context.Background(), hotelID, overlapping, 4), ErrRatePlanOverlap)
+
+	var validationErr *ValidationError
+	assert.ErrorAs(t, service.AddRatePlan(context.Background(), hotelID, &RatePlan{Name: "Empty"}, 4), &validationErr)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestQuoteStay(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+
+	hotelID, roomTypeID := uuid.New(), uuid.New()
+	checkIn := time.Date(2030, time.June, 1, 14, 0, 0, 0, time.UTC)
+	plan := RatePlan{ID: uuid.New(), RoomTypeID: roomTypeID, ValidFrom: day(checkIn), ValidTo: day(checkIn).AddDate(0, 1, 0),
+		BasePrice: money.MustParse("99.99"), Currency: "EUR", MealPlan: MealPlanBreakfast}
+
+	mockRepo.On("GetRoomType", hotelID, roomTypeID).Return(&RoomType{ID: roomTypeID, HotelID: hotelID}, nil).Once()
+	mockRepo.On("ListRatePlansForStay", hotelID, roomTypeID, MealPlanBreakfast, day(checkIn), day(checkIn).AddDate(0, 0, 3)).Return([]RatePlan{plan}, nil).Once()
+	// The stored rate is quoted the other way round and is looked up once
+	mockRepo.On("FindExchangeRate", "EUR", "TRY", mock.Anything).Return(&ExchangeRate{BaseCurrency: "EUR", QuoteCurrency: "TRY", Rate: money.MustParse("35.5")}, nil).Once()
+
+	quote, err := service.QuoteStay(hotelID, QuoteRequest{RoomTypeID: roomTypeID, CheckIn: checkIn, CheckOut: checkIn.AddDate(0, 0, 3), MealPlan: "Breakfast", Currency: "try"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, hotelID, quote.HotelID)
+		assert.Equal(t, 1, quote.Rooms)
+		assert.Equal(t, "TRY", quote.Currency)
+		assert.Len(t, quote.Nights, 3)
+		// 99.99 * 35.5 = 3549.645, rounded per night
+		assert.Equal(t, "3549.65 TRY", quote.Nights[0].Converted.String())
+		assert.Equal(t, "10648.95 TRY", quote.Total.String())
+	}
+
+	// Requests are validated before anything is looked up
+	_, err = service.QuoteStay(hotelID, QuoteRequest{RoomTypeID: roomTypeID, CheckIn: checkIn, CheckOut: checkIn})
+	assert.ErrorIs(t, err, ErrInvalidDateRange)
+	var validationErr *ValidationError
+	_, err = service.QuoteStay(hotelID, QuoteRequest{CheckIn: checkIn, CheckOut: checkIn.AddDate(0, 0, 1), Rooms: -1, MealPlan: "dinner", Currency: "lira"})
+	if assert.ErrorAs(t, err, &validationErr) {
+		assert.Len(t, validationErr.Fields, 4)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAddReview(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+	mockRepo.On("RecordAudit", mock.MatchedBy(func(entry *AuditEntry) bool {
+		return entry.Entity == AuditEntityReview && entry.Action == AuditActionCreate
+	})).Return(nil).Once()
+
+	hotelID := uuid.New()
+	review := &Review{Score: 9, Text: "Great breakfast", Author: "Jane", Status: ReviewApproved}
+
+	mockRepo.On("GetHotelDetails", hotelID).Return(&Hotel{ID: hotelID}, nil).Twice()
+	mockRepo.On("AddReview", review).Return(nil).Once()
+
+	// New reviews await moderation whatever status they are sent with
+	err := service.AddReview(context.Background(), hotelID, review)
+	assert.NoError(t, err)
+	assert.Equal(t, ReviewPending, review.Status)
+	assert.Equal(t, hotelID, review.HotelID)
+	assert.False(t, review.Date.IsZero())
+
+	var validationErr *ValidationError
+	assert.ErrorAs(t, service.AddReview(context.Background(), hotelID, &Review{Score: 0, Author: "Jane"}), &validationErr)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestModerateReview(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+	mockRepo.On("RecordAudit", mock.MatchedBy(func(entry *AuditEntry) bool {
+		return entry.Entity == AuditEntityReview && entry.Action == AuditActionUpdate &&
+			entry.Changes["status"] == AuditChange{Before: ReviewPending, After: ReviewApproved}
+	})).Return(nil).Once()
+
+	hotelID, reviewID := uuid.New(), uuid.New()
+	mockRepo.On("GetReview", hotelID, reviewID).Return(&Review{ID: reviewID, HotelID: hotelID, Score: 7, Status: ReviewPending}, nil).Once()
+	mockRepo.On("SetReviewStatus", hotelID, reviewID, ReviewApproved).Return(nil).Once()
+
+	review, err := service.ModerateReview(context.Background(), hotelID, reviewID, " Approved ")
+	assert.NoError(t, err)
+	assert.Equal(t, ReviewApproved, review.Status)
+
+	// Unknown statuses are rejected before the review is looked up
+	_, err = service.ModerateReview(context.Background(), hotelID, reviewID, "hidden")
+	var validationErr *ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestListReviews(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+
+	hotelID := uuid.New()
+	mockRepo.On("GetHotelDetails", hotelID).Return(&Hotel{ID: hotelID}, nil).Once()
+	mockRepo.On("ListReviews", ReviewFilter{HotelID: hotelID, Status: ReviewApproved, Limit: DefaultPageLimit}).Return([]Review(nil), nil).Once()
+
+	reviews, err := service.ListReviews(ReviewFilter{HotelID: hotelID})
+	assert.NoError(t, err)
+	assert.NotNil(t, reviews)
+
+	_, err = service.ListReviews(ReviewFilter{HotelID: hotelID, Status: "hidden"})
+	assert.ErrorIs(t, err, ErrInvalidReviewStatus)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSetHotelTags(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+	mockRepo.On("RecordAudit", mock.MatchedBy(func(entry *AuditEntry) bool {
+		return entry.Entity == AuditEntityHotel && entry.Action == AuditActionUpdate &&
+			entry.Changes["tags"] == AuditChange{Before: "wifi", After: "parking, pool"}
+	})).Return(nil).Once()
+
+	hotelID := uuid.New()
+	mockRepo.On("GetHotelDetails", hotelID).Return(&Hotel{ID: hotelID, Tags: []HotelTag{{HotelID: hotelID, TagName: "wifi"}}}, nil).Twice()
+	mockRepo.On("ListTags").Return(DefaultTags, nil).Twice()
+	mockRepo.On("SetHotelTags", hotelID, []string{"parking", "pool"}, 3).Return(nil).Once()
+
+	tags, err := service.SetHotelTags(context.Background(), hotelID, []string{"Pool", "parking", "pool"}, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"parking", "pool"}, tags)
+
+	// Tags outside the vocabulary are rejected by position
+	_, err = service.SetHotelTags(context.Background(), hotelID, []string{"pool", "casino"}, 3)
+	var validationErr *ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, []FieldError{{"tags[1]", `unknown tag "casino"`}}, validationErr.Fields)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateChain(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+
+	groupID, brandID := uuid.New(), uuid.New()
+	mockRepo.On("GetChain", groupID).Return(&Chain{ID: groupID, Name: "Hilton", Kind: ChainKindGroup}, nil)
+	mockRepo.On("GetChain", brandID).Return(&Chain{ID: brandID, Name: "Hampton", Kind: ChainKindBrand, ParentID: &groupID}, nil)
+	mockRepo.On("CreateChain", mock.AnythingOfType("*hotel.Chain")).Return(nil).Once()
+
+	chain := &Chain{Name: " Curio ", Kind: "brand", ParentID: &groupID}
+	assert.NoError(t, service.CreateChain(chain))
+	assert.NotEqual(t, uuid.Nil, chain.ID)
+	assert.Equal(t, "Curio", chain.Name)
+	assert.False(t, chain.CreatedAt.IsZero())
+
+	// Brands only belong to groups
+	var validationErr *ValidationError
+	assert.ErrorAs(t, service.CreateChain(&Chain{Name: "Sub", Kind: ChainKindBrand, ParentID: &brandID}), &validationErr)
+	assert.Equal(t, []FieldError{{"parent_id", "must be an existing group"}}, validationErr.Fields)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSetHotelChain(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+
+	hotelID, brandID, unknownID := uuid.New(), uuid.New(), uuid.New()
+	mockRepo.On("RecordAudit", mock.MatchedBy(func(entry *AuditEntry) bool {
+		return entry.Entity == AuditEntityHotel && entry.Action == AuditActionUpdate &&
+			entry.Changes["chain_id"] == AuditChange{Before: "", After: brandID.String()}
+	})).Return(nil).Once()
+	mockRepo.On("GetHotelDetails", hotelID).Return(&Hotel{ID: hotelID}, nil).Twice()
+	mockRepo.On("GetChain", brandID).Return(&Chain{ID: brandID, Kind: ChainKindBrand}, nil).Once()
+	mockRepo.On("GetChain", unknownID).Return(nil, ErrChainNotFound).Once()
+	mockRepo.On("SetHotelChain", hotelID, &brandID, 3).Return(nil).Once()
+
+	assert.NoError(t, service.SetHotelChain(context.Background(), hotelID, &brandID, 3))
+
+	var validationErr *ValidationError
+	assert.ErrorAs(t, service.SetHotelChain(context.Background(), hotelID, &unknownID, 4), &validationErr)
+	assert.Equal(t, []FieldError{{"chain_id", "unknown chain"}}, validationErr.Fields)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestFetchChainStats(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+
+	chainID := uuid.New()
+	filter := LocationFilter{ChainID: chainID}
+	mockRepo.On("GetChain", chainID).Return(&Chain{ID: chainID, Kind: ChainKindGroup}, nil).Once()
+	mockRepo.On("CountHotels", filter).Return(int64(4), nil).Once()
+	mockRepo.On("CountHotelsByCity", filter).Return([]LocationCount{{"Turkey", "Istanbul", 2}, {"Turkey", "Izmir", 1}}, nil).Once()
+	mockRepo.On("CountHotelsByContactType", filter).Return(map[string]int64{ContactTypePhone: 4, ContactTypeEmail: 1}, nil).Once()
+	mockRepo.On("ListContactTypes").Return(DefaultContactTypes, nil).Once()
+
+	stats, err := service.FetchChainStats(chainID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, int64(4), stats.HotelCount)
+		assert.Len(t, stats.Locations, 2)
+		assert.Equal(t, int64(1), stats.UnlocatedCount)
+		assert.Contains(t, stats.ContactCoverage, ContactCoverage{InfoType: ContactTypeEmail, HotelCount: 1, Share: 0.25})
+		assert.Contains(t, stats.ContactCoverage, ContactCoverage{InfoType: ContactTypePhone, HotelCount: 4, Share: 1})
+	}
+
+	// Stats of unknown chains are not counted
+	unknownID := uuid.New()
+	mockRepo.On("GetChain", unknownID).Return(nil, ErrChainNotFound).Once()
+	_, err = service.FetchChainStats(unknownID)
+	assert.ErrorIs(t, err, ErrChainNotFound)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestListHotels_Facets(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+
+	opts := ListOptions{Limit: DefaultPageLimit, SortBy: "id", Tags: []string{"pool"}, Facets: true}
+	facets := &Facets{
+		Tags:      []FacetCount{{"pool", 2}, {"wifi", 1}},
+		Countries: []FacetCount{{"Turkey", 2}},
+		Cities:    []CityFacetCount{{"Turkey", "Istanbul", 1}, {"Turkey", "Izmir", 1}},
+	}
+	mockRepo.On("ListHotels", opts).Return([]Hotel{{ID: uuid.New()}, {ID: uuid.New()}}, int64(2), nil).Once()
+	mockRepo.On("CountFacets", opts).Return(facets, nil).Once()
+
+	page, err := service.ListHotels(ListOptions{Tags: []string{" Pool "}, Facets: true})
+	assert.NoError(t, err)
+	assert.Len(t, page.Items, 2)
+	assert.Equal(t, facets, page.Facets)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAddMedia(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	root := t.TempDir()
+	blobs, err := storage.NewLocalStore(root)
+	assert.NoError(t, err)
+	service := NewService(mockRepo, blobs)
+	mockRepo.On("RecordAudit", mock.MatchedBy(func(entry *AuditEntry) bool {
+		return entry.Entity == AuditEntityMedia && entry.Action == AuditActionCreate
+	})).Return(nil).Once()
+
+	hotelID := uuid.New()
+	mockRepo.On("GetHotelDetails", hotelID).Return(&Hotel{ID: hotelID}, nil)
+	mockRepo.On("AddMedia", mock.AnythingOfType("*hotel.Media"), 2).Return(nil).Once()
+
+	// The image and its thumbnail are stored under the key of the media
+	media, err := service.AddMedia(context.Background(), hotelID, "lobby.png", testPNG(t, 640, 480, color.White), 2)
+	assert.NoError(t, err)
+	assert.Equal(t, mediaKey(hotelID, media.ID), media.StorageKey)
+	assert.True(t, media.HasThumbnail)
+	for _, key := range []string{media.StorageKey, thumbnailKey(media.StorageKey)} {
+		blob, err := blobs.Open(key)
+		assert.NoError(t, err, key)
+		blob.Close()
+	}
+
+	// Blobs of media that could not be recorded are removed again
+	mockRepo.On("AddMedia", mock.AnythingOfType("*hotel.Media"), 1).Return(ErrVersionConflict).Once()
+	_, err = service.AddMedia(context.Background(), hotelID, "lobby.png", testPNG(t, 10, 10, color.White), 1)
+	assert.ErrorIs(t, err, ErrVersionConflict)
+	entries, err := os.ReadDir(filepath.Join(root, "hotels", hotelID.String(), "media"))
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	_, err = service.AddMedia(context.Background(), hotelID, "notes.txt", []byte("plain text"), 0)
+	assert.ErrorIs(t, err, ErrUnsupportedMedia)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRemoveMedia(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	blobs, err := storage.NewLocalStore(t.TempDir())
+	assert.NoError(t, err)
+	service := NewService(mockRepo, blobs)
+	mockRepo.On("RecordAudit", mock.MatchedBy(func(entry *AuditEntry) bool {
+		return entry.Entity == AuditEntityMedia && entry.Action == AuditActionDelete
+	})).Return(nil).Once()
+
+	hotelID, mediaID := uuid.New(), uuid.New()
+	media := &Media{ID: mediaID, HotelID: hotelID, Kind: MediaKindDocument, StorageKey: mediaKey(hotelID, mediaID)}
+	assert.NoError(t, blobs.Put(media.StorageKey, strings.NewReader("%PDF-1.4")))
+	mockRepo.On("GetMedia", hotelID, mediaID).Return(media, nil)
+	mockRepo.On("RemoveMedia", hotelID, mediaID, 0).Return(nil).Once()
+
+	// Documents have no thumbnail to serve
+	_, _, err = service.OpenMedia(hotelID, mediaID, true)
+	assert.ErrorIs(t, err, ErrThumbnailNotFound)
+
+	assert.NoError(t, service.RemoveMedia(context.Background(), hotelID, mediaID, 0))
+	_, err = blobs.Open(media.StorageKey)
+	assert.ErrorIs(t, err, storage.ErrBlobNotFound)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSaveTranslation(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+
+	hotelID := uuid.New()
+	mockRepo.On("GetTranslation", hotelID, "pt-BR").Return(nil, ErrTranslationNotFound).Once()
+	mockRepo.On("SaveTranslation", mock.MatchedBy(func(translation *HotelTranslation) bool {
+		return translation.HotelID == hotelID && translation.Language == "pt-BR" && translation.CompanyTitle == "Palácio Pera"
+	}), 2).Return(nil).Once()
+	mockRepo.On("RecordAudit", mock.MatchedBy(func(entry *AuditEntry) bool {
+		return entry.Entity == AuditEntityTranslation && entry.Action == AuditActionCreate
+	})).Return(nil).Once()
+
+	err := service.SaveTranslation(context.Background(), hotelID, "pt-br", &HotelTranslation{CompanyTitle: " Palácio Pera "}, 2)
+	assert.NoError(t, err)
+
+	// Replacing a translation audits the changed fields
+	mockRepo.On("GetTranslation", hotelID, "de").Return(&HotelTranslation{HotelID: hotelID, Language: "de", CompanyTitle: "Pera Palast"}, nil).Once()
+	mockRepo.On("SaveTranslation", mock.Anything, 3).Return(nil).Once()
+	mockRepo.On("RecordAudit", mock.MatchedBy(func(entry *AuditEntry) bool {
+		return entry.Entity == AuditEntityTranslation && entry.Action == AuditActionUpdate &&
+			entry.Changes["description"] == AuditChange{Before: "", After: "Am Goldenen Horn"}
+	})).Return(nil).Once()
+
+	err = service.SaveTranslation(context.Background(), hotelID, "de", &HotelTranslation{CompanyTitle: "Pera Palast", Description: "Am Goldenen Horn"}, 3)
+	assert.NoError(t, err)
+
+	// Invalid languages never reach the repository
+	err = service.SaveTranslation(context.Background(), hotelID, "*", &HotelTranslation{CompanyTitle: "Pera"}, 3)
+	assert.ErrorIs(t, err, ErrInvalidLanguage)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestListMissingTranslations(t *testing.T) {
+	mockRepo := new(MockHotelRepository)
+	service := NewService(mockRepo, nil)
+
+	hotelID := uuid.New()
+	filter := MissingTranslationFilter{Languages: []string{"de", "tr"}, Limit: DefaultPageLimit}
+	mockRepo.On("ListMissingTranslations", filter).Return([]Hotel{{
+		ID:           hotelID,
+		CompanyTitle: "Pera Palace",
+		Translations: []HotelTranslation{{Language: "de", CompanyTitle: "Pera Palast", Description: "Am Goldenen Horn"}},
+	}}, int64(1), nil).Once()
 
-	hotelCount, phoneCount, err := service.FetchLocationStats(location)
+	page, err := service.ListMissingTranslations(MissingTranslationFilter{Languages: []string{"DE", "tr", "de"}})
 	assert.NoError(t, err)
-	assert.Equal(t, 0, hotelCount)
-	assert.Equal(t, 0, phoneCount)
+	assert.Equal(t, []string{"de", "tr"}, page.Languages)
+	assert.Equal(t, int64(1), page.Total)
+	assert.Equal(t, []MissingTranslations{{
+		HotelID:      hotelID,
+		CompanyTitle: "Pera Palace",
+		Missing: map[string][]string{
+			"de": {"marketing_text"},
+			"tr": {"company_title", "description", "marketing_text"},
+		},
+	}}, page.Items)
 
 	mockRepo.AssertExpectations(t)
 }
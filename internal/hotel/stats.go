@@ -0,0 +1,143 @@
+package hotel
+
+import (
+	"errors"
+	"fmt"
+	"hotel-guide/internal/money"
+	"strings"
+)
+
+// Levels of a location that hotels can be grouped by.
+const (
+	LocationLevelCountry  = "country"
+	LocationLevelCity     = "city"
+	LocationLevelDistrict = "district"
+)
+
+var ErrInvalidLocationLevel = errors.New("level must be country, city or district")
+
+// MaxStatsBatch limits the number of locations of FetchLocationStatsBatch.
+const MaxStatsBatch = 100
+
+// locationLevelColumns are the columns a location level groups by. Cities are
+// told apart by country and districts by city, so finer levels include the
+// coarser ones.
+var locationLevelColumns = map[string][]string{
+	LocationLevelCountry:  {"locations.country"},
+	LocationLevelCity:     {"locations.country", "locations.city"},
+	LocationLevelDistrict: {"locations.country", "locations.city", "locations.district"},
+}
+
+// locationStatsSorts are the sort fields accepted by ListLocationStats. Name
+// orders by the location itself.
+var locationStatsSorts = map[string]bool{"hotel_count": true, "phone_count": true, "name": true}
+
+// LocationStatsOptions controls which locations ListLocationStats returns and
+// in which order.
+type LocationStatsOptions struct {
+	// Level is the level hotels are grouped by, city by default.
+	Level string
+	// Location restricts the hotels that are counted, e.g. to the cities of a
+	// country or to the hotels of a chain.
+	Location LocationFilter
+	// MinHotels leaves out the locations with fewer hotels.
+	MinHotels int
+	// SortBy defaults to hotel_count in descending order, so that the first
+	// page lists the locations with the most hotels.
+	SortBy   string
+	SortDesc bool
+	Limit    int
+	Offset   int
+}
+
+// LocationSummary counts the hotels of a location and their phones. Only the
+// fields of the level the locations are grouped by are set.
+type LocationSummary struct {
+	Country    string `json:"country"`
+	City       string `json:"city,omitempty"`
+	District   string `json:"district,omitempty"`
+	HotelCount int    `json:"hotel_count"`
+	PhoneCount int    `json:"phone_count"`
+}
+
+// LocationStatsPage is one page of the locations with their hotel counts.
+type LocationStatsPage struct {
+	Items  []LocationSummary `json:"items"`
+	Level  string            `json:"level"`
+	Total  int64             `json:"total"`
+	Limit  int               `json:"limit"`
+	Offset int               `json:"offset"`
+}
+
+// normalize applies defaults and validates the options.
+func (o *LocationStatsOptions) normalize() error {
+	o.Level = strings.ToLower(strings.TrimSpace(o.Level))
+	if o.Level == "" {
+		o.Level = LocationLevelCity
+	}
+	if _, ok := locationLevelColumns[o.Level]; !ok {
+		return ErrInvalidLocationLevel
+	}
+
+	o.SortBy = strings.ToLower(strings.TrimSpace(o.SortBy))
+	if o.SortBy == "" {
+		o.SortBy, o.SortDesc = "hotel_count", true
+	}
+	if !locationStatsSorts[o.SortBy] {
+		return ErrInvalidSort
+	}
+
+	if o.Limit <= 0 {
+		o.Limit = DefaultPageLimit
+	}
+	if o.Limit > MaxPageLimit {
+		o.Limit = MaxPageLimit
+	}
+	if o.Offset < 0 {
+		o.Offset = 0
+	}
+	if o.MinHotels < 0 {
+		o.MinHotels = 0
+	}
+	return nil
+}
+
+// LocationStatsQuery asks for the stats of a location with the median nightly
+// price in Currency, DefaultCurrency when empty.
+type LocationStatsQuery struct {
+	Filter   LocationFilter
+	Currency string
+}
+
+// statsCurrency returns the normalized currency of location stats.
+func statsCurrency(currency string) (string, error) {
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	return money.NormalizeCurrency(currency)
+}
+
+// validateStatsBatch normalizes the currencies of a batch of stats queries and
+// returns a *ValidationError listing every invalid query.
+func validateStatsBatch(queries []LocationStatsQuery) error {
+	var fields []FieldError
+	if len(queries) == 0 || len(queries) > MaxStatsBatch {
+		fields = append(fields, FieldError{"locations", fmt.Sprintf("must list between 1 and %d locations", MaxStatsBatch)})
+	}
+	for i := range queries {
+		field := fmt.Sprintf("locations[%d]", i)
+		if queries[i].Filter.IsEmpty() {
+			fields = append(fields, FieldError{field, "requires a location, country, city, district or chain_id"})
+		}
+		if currency, err := statsCurrency(queries[i].Currency); err != nil {
+			fields = append(fields, FieldError{field + ".currency", "must be an ISO 4217 code such as EUR or TRY"})
+		} else {
+			queries[i].Currency = currency
+		}
+	}
+
+	if len(fields) > 0 {
+		return &ValidationError{Fields: fields}
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package hotel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocationStatsOptionsNormalize(t *testing.T) {
+	// Without options the cities with the most hotels come first
+	opts := LocationStatsOptions{MinHotels: -1, Limit: MaxPageLimit + 1}
+	assert.NoError(t, opts.normalize())
+	assert.Equal(t, LocationStatsOptions{Level: LocationLevelCity, SortBy: "hotel_count", SortDesc: true, Limit: MaxPageLimit}, opts)
+
+	opts = LocationStatsOptions{Level: " Country ", SortBy: "name"}
+	assert.NoError(t, opts.normalize())
+	assert.Equal(t, LocationLevelCountry, opts.Level)
+	assert.False(t, opts.SortDesc)
+
+	assert.ErrorIs(t, (&LocationStatsOptions{Level: "street"}).normalize(), ErrInvalidLocationLevel)
+	assert.ErrorIs(t, (&LocationStatsOptions{SortBy: "rating"}).normalize(), ErrInvalidSort)
+}
+
+func TestValidateStatsBatch(t *testing.T) {
+	queries := []LocationStatsQuery{{Filter: LocationFilter{City: "Istanbul"}}, {Filter: LocationFilter{Country: "Turkey"}, Currency: "try"}}
+	assert.NoError(t, validateStatsBatch(queries))
+	assert.Equal(t, DefaultCurrency, queries[0].Currency)
+	assert.Equal(t, "TRY", queries[1].Currency)
+
+	// A batch lists at least one and at most MaxStatsBatch locations
+	var validationErr *ValidationError
+	if assert.ErrorAs(t, validateStatsBatch(nil), &validationErr) {
+		assert.Equal(t, "locations", validationErr.Fields[0].Field)
+	}
+	assert.ErrorAs(t, validateStatsBatch(make([]LocationStatsQuery, MaxStatsBatch+1)), &validationErr)
+}
@@ -0,0 +1,113 @@
+package hotel
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Kinds of tags. Amenities are facilities of a hotel, such as a pool; other
+// tags describe the hotel, such as pet-friendly.
+const (
+	TagKindAmenity = "amenity"
+	TagKindTag     = "tag"
+)
+
+var (
+	ErrTagNotFound = errors.New("tag not found")
+	ErrTagExists   = errors.New("tag already exists")
+	ErrTagInUse    = errors.New("tag is used by hotels")
+	ErrInvalidTag  = errors.New("tag requires a lowercase name, a display name and a kind of amenity or tag")
+)
+
+// Tag is an entry of the tag vocabulary hotels are tagged from.
+type Tag struct {
+	// Name identifies the tag in hotel listings, e.g. "pet-friendly".
+	Name        string `gorm:"primaryKey" json:"name"`
+	DisplayName string `gorm:"not null" json:"display_name"`
+	Kind        string `gorm:"not null" json:"kind"`
+}
+
+// DefaultTags are the tags every vocabulary starts with.
+var DefaultTags = []Tag{
+	{Name: "pool", DisplayName: "Pool", Kind: TagKindAmenity},
+	{Name: "parking", DisplayName: "Parking", Kind: TagKindAmenity},
+	{Name: "wifi", DisplayName: "Wi-Fi", Kind: TagKindAmenity},
+	{Name: "spa", DisplayName: "Spa", Kind: TagKindAmenity},
+	{Name: "gym", DisplayName: "Gym", Kind: TagKindAmenity},
+	{Name: "restaurant", DisplayName: "Restaurant", Kind: TagKindAmenity},
+	{Name: "pet-friendly", DisplayName: "Pet-friendly", Kind: TagKindTag},
+	{Name: "family-friendly", DisplayName: "Family-friendly", Kind: TagKindTag},
+	{Name: "beachfront", DisplayName: "Beachfront", Kind: TagKindTag},
+}
+
+// HotelTag attaches a tag of the vocabulary to a hotel. It is encoded in JSON
+// as the name of the tag.
+type HotelTag struct {
+	HotelID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	TagName string    `gorm:"primaryKey;index"`
+}
+
+func (t HotelTag) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.TagName)
+}
+
+func (t *HotelTag) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &t.TagName)
+}
+
+var tagName = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,31}$`)
+
+// validateTag checks a vocabulary entry before it is stored.
+func validateTag(tag *Tag) error {
+	tag.Name = strings.TrimSpace(tag.Name)
+	tag.DisplayName = strings.TrimSpace(tag.DisplayName)
+	if !tagName.MatchString(tag.Name) || tag.DisplayName == "" {
+		return ErrInvalidTag
+	}
+	if tag.Kind != TagKindAmenity && tag.Kind != TagKindTag {
+		return ErrInvalidTag
+	}
+	return nil
+}
+
+// normalizeTags lower-cases tag names, drops empty and duplicate names and
+// sorts them.
+func normalizeTags(tags []string) []string {
+	seen := make(map[string]bool)
+	normalized := []string{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" && !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+	sort.Strings(normalized)
+	return normalized
+}
+
+// Facets count the hotels of a listing by tag and by location.
+type Facets struct {
+	Tags      []FacetCount     `json:"tags"`
+	Countries []FacetCount     `json:"countries"`
+	Cities    []CityFacetCount `json:"cities"`
+}
+
+// FacetCount is the number of hotels with a value of a facet.
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// CityFacetCount is the number of hotels in a city of a country. Cities are
+// counted per country, since city names such as Alexandria repeat.
+type CityFacetCount struct {
+	Country string `json:"country"`
+	Value   string `json:"value"`
+	Count   int64  `json:"count"`
+}
@@ -0,0 +1,43 @@
+package hotel
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateTag(t *testing.T) {
+	valid := Tag{Name: " pet-friendly ", DisplayName: " Pet-friendly ", Kind: TagKindTag}
+	assert.NoError(t, validateTag(&valid))
+	assert.Equal(t, "pet-friendly", valid.Name)
+	assert.Equal(t, "Pet-friendly", valid.DisplayName)
+
+	for _, tag := range []Tag{
+		{Name: "Pet Friendly", DisplayName: "Pet-friendly", Kind: TagKindTag},
+		{Name: "pool", Kind: TagKindAmenity},
+		{Name: "pool", DisplayName: "Pool", Kind: "feature"},
+	} {
+		assert.ErrorIs(t, validateTag(&tag), ErrInvalidTag, tag.Name)
+	}
+}
+
+func TestNormalizeTags(t *testing.T) {
+	assert.Equal(t, []string{"parking", "pool"}, normalizeTags([]string{" Pool", "parking", "", "pool"}))
+	assert.Equal(t, []string{}, normalizeTags(nil))
+}
+
+func TestHotelTag_JSON(t *testing.T) {
+	hotel := Hotel{ID: uuid.New(), Tags: []HotelTag{{TagName: "pool"}, {TagName: "wifi"}}}
+	data, err := json.Marshal(hotel)
+	assert.NoError(t, err)
+
+	var fields map[string]interface{}
+	assert.NoError(t, json.Unmarshal(data, &fields))
+	assert.Equal(t, []interface{}{"pool", "wifi"}, fields["tags"])
+
+	var decoded Hotel
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, hotel.Tags, decoded.Tags)
+}
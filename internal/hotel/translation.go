@@ -0,0 +1,214 @@
+package hotel
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/text/language"
+)
+
+// SupportedLanguages are the languages every hotel is expected to have a
+// translation for, see ListMissingTranslations.
+var SupportedLanguages = []string{"tr", "en", "de"}
+
+// DefaultLanguage ends every fallback chain, before the untranslated fields.
+const DefaultLanguage = "en"
+
+// Length limits of the translated fields in characters.
+const (
+	maxTitleLength         = 255
+	maxDescriptionLength   = 5000
+	maxMarketingTextLength = 2000
+)
+
+var (
+	ErrTranslationNotFound = errors.New("translation not found")
+	ErrInvalidLanguage     = errors.New("language must be a BCP-47 language tag such as de or pt-BR")
+)
+
+// HotelTranslation holds the content of a hotel in one language. Empty fields
+// fall back to the next language of the chain, see localizeHotel.
+type HotelTranslation struct {
+	HotelID uuid.UUID `gorm:"type:uuid;primaryKey" json:"hotel_id"`
+	// Language is a canonical BCP-47 tag, e.g. "de" or "pt-BR".
+	Language      string    `gorm:"primaryKey" json:"language"`
+	CompanyTitle  string    `json:"company_title"`
+	Description   string    `json:"description"`
+	MarketingText string    `json:"marketing_text"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// translatedFields names the fields a complete translation has.
+var translatedFields = []string{"company_title", "description", "marketing_text"}
+
+// field returns a translated field by its JSON name.
+func (t *HotelTranslation) field(name string) string {
+	switch name {
+	case "company_title":
+		return t.CompanyTitle
+	case "description":
+		return t.Description
+	case "marketing_text":
+		return t.MarketingText
+	}
+	return ""
+}
+
+// MissingTranslations lists, per language, the fields a hotel has no
+// translation for.
+type MissingTranslations struct {
+	HotelID      uuid.UUID           `json:"hotel_id"`
+	CompanyTitle string              `json:"company_title"`
+	Missing      map[string][]string `json:"missing"`
+}
+
+// MissingTranslationFilter selects the languages to check and a page of the
+// hotels missing any of their translations.
+type MissingTranslationFilter struct {
+	// Languages default to SupportedLanguages.
+	Languages []string
+	Limit     int
+	Offset    int
+}
+
+// MissingTranslationPage is a page of the hotels with missing translations.
+type MissingTranslationPage struct {
+	Items     []MissingTranslations `json:"items"`
+	Languages []string              `json:"languages"`
+	Total     int64                 `json:"total"`
+	Limit     int                   `json:"limit"`
+	Offset    int                   `json:"offset"`
+}
+
+// canonicalLanguage parses a BCP-47 tag and returns it in canonical form.
+func canonicalLanguage(tag string) (string, error) {
+	tag = strings.TrimSpace(tag)
+	if tag == "" || tag == "*" {
+		return "", ErrInvalidLanguage
+	}
+	parsed, err := language.Parse(tag)
+	if err != nil || parsed == language.Und {
+		return "", ErrInvalidLanguage
+	}
+	return parsed.String(), nil
+}
+
+// validateTranslation normalizes a translation and returns a *ValidationError
+// listing every invalid field.
+func validateTranslation(translation *HotelTranslation) error {
+	translation.CompanyTitle = strings.TrimSpace(translation.CompanyTitle)
+	translation.Description = strings.TrimSpace(translation.Description)
+	translation.MarketingText = strings.TrimSpace(translation.MarketingText)
+
+	var fields []FieldError
+	for _, limit := range []struct {
+		name  string
+		value string
+		max   int
+	}{
+		{"company_title", translation.CompanyTitle, maxTitleLength},
+		{"description", translation.Description, maxDescriptionLength},
+		{"marketing_text", translation.MarketingText, maxMarketingTextLength},
+	} {
+		if len([]rune(limit.value)) > limit.max {
+			fields = append(fields, FieldError{limit.name, fmt.Sprintf("must be at most %d characters", limit.max)})
+		}
+	}
+	if translation.CompanyTitle == "" && translation.Description == "" && translation.MarketingText == "" {
+		fields = append(fields, FieldError{"company_title", "a translation requires at least one translated field"})
+	}
+
+	if len(fields) > 0 {
+		return &ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+// LanguageChain returns the languages to try for an Accept-Language header,
+// most preferred first. Each accepted language is followed by its parents, so
+// "de-CH" falls back to "de", and DefaultLanguage comes last. Invalid headers
+// are ignored.
+func LanguageChain(acceptLanguage string) []string {
+	tags, _, _ := language.ParseAcceptLanguage(acceptLanguage)
+
+	seen := make(map[string]bool)
+	var chain []string
+	add := func(tag string) {
+		if !seen[tag] {
+			seen[tag] = true
+			chain = append(chain, tag)
+		}
+	}
+	for _, tag := range tags {
+		for ; tag != language.Und; tag = tag.Parent() {
+			add(tag.String())
+		}
+	}
+	add(DefaultLanguage)
+	return chain
+}
+
+// localizeHotel fills in the company title, description and marketing text of
+// a hotel from its translations, taking each field from the first language of
+// the chain that translates it. Fields no language translates keep their
+// untranslated value. Language is set to the first language of the chain the
+// hotel has a translation for. The languages of the chain the hotel has
+// translations for are returned; together they decide the localized fields.
+func localizeHotel(hotel *Hotel, chain []string) []string {
+	byLanguage := make(map[string]*HotelTranslation, len(hotel.Translations))
+	for i := range hotel.Translations {
+		byLanguage[hotel.Translations[i].Language] = &hotel.Translations[i]
+	}
+	var languages []string
+	for _, lang := range chain {
+		if _, ok := byLanguage[lang]; ok {
+			languages = append(languages, lang)
+		}
+	}
+
+	hotel.Language = ""
+	targets := map[string]*string{
+		"company_title":  &hotel.CompanyTitle,
+		"description":    &hotel.Description,
+		"marketing_text": &hotel.MarketingText,
+	}
+	for _, name := range translatedFields {
+		for _, lang := range chain {
+			translation, ok := byLanguage[lang]
+			if !ok {
+				continue
+			}
+			if hotel.Language == "" {
+				hotel.Language = lang
+			}
+			if value := translation.field(name); value != "" {
+				*targets[name] = value
+				break
+			}
+		}
+	}
+	return languages
+}
+
+// missingTranslations returns the fields each of the languages lacks in the
+// translations of a hotel. Languages translating every field are left out.
+func missingTranslations(translations []HotelTranslation, languages []string) map[string][]string {
+	byLanguage := make(map[string]*HotelTranslation, len(translations))
+	for i := range translations {
+		byLanguage[translations[i].Language] = &translations[i]
+	}
+
+	missing := make(map[string][]string)
+	for _, lang := range languages {
+		translation, ok := byLanguage[lang]
+		for _, name := range translatedFields {
+			if !ok || translation.field(name) == "" {
+				missing[lang] = append(missing[lang], name)
+			}
+		}
+	}
+	return missing
+}
@@ -0,0 +1,75 @@
+package hotel
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonicalLanguage(t *testing.T) {
+	for tag, want := range map[string]string{"de": "de", " TR ": "tr", "pt-br": "pt-BR", "en_GB": "en-GB"} {
+		got, err := canonicalLanguage(tag)
+		assert.NoError(t, err, tag)
+		assert.Equal(t, want, got, tag)
+	}
+	for _, tag := range []string{"", "*", "und", "not a language"} {
+		_, err := canonicalLanguage(tag)
+		assert.ErrorIs(t, err, ErrInvalidLanguage, tag)
+	}
+}
+
+func TestValidateTranslation(t *testing.T) {
+	translation := HotelTranslation{CompanyTitle: " Pera Palast "}
+	assert.NoError(t, validateTranslation(&translation))
+	assert.Equal(t, "Pera Palast", translation.CompanyTitle)
+
+	err := validateTranslation(&HotelTranslation{Description: strings.Repeat("ü", maxDescriptionLength+1)})
+	var validationErr *ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "description", validationErr.Fields[0].Field)
+
+	assert.ErrorAs(t, validateTranslation(&HotelTranslation{CompanyTitle: "  "}), &validationErr)
+}
+
+func TestLanguageChain(t *testing.T) {
+	assert.Equal(t, []string{"de-CH", "de", "en"}, LanguageChain("de-CH, en;q=0.5"))
+	assert.Equal(t, []string{"tr", "de", "en"}, LanguageChain("tr;q=0.9, de;q=0.8"))
+	assert.Equal(t, []string{"en"}, LanguageChain(""))
+	assert.Equal(t, []string{"en"}, LanguageChain(";;invalid"))
+}
+
+func TestLocalizeHotel(t *testing.T) {
+	hotel := Hotel{
+		CompanyTitle: "Pera Palace",
+		Translations: []HotelTranslation{
+			{Language: "de", Description: "Am Goldenen Horn"},
+			{Language: "en", CompanyTitle: "Pera Palace Hotel", MarketingText: "Since 1892"},
+		},
+	}
+	languages := localizeHotel(&hotel, []string{"de-AT", "de", "en"})
+	assert.Equal(t, []string{"de", "en"}, languages)
+	assert.Equal(t, "de", hotel.Language)
+	assert.Equal(t, "Pera Palace Hotel", hotel.CompanyTitle)
+	assert.Equal(t, "Am Goldenen Horn", hotel.Description)
+	assert.Equal(t, "Since 1892", hotel.MarketingText)
+
+	// Without a matching translation the hotel keeps its own fields
+	untranslated := Hotel{CompanyTitle: "Pera Palace", Translations: hotel.Translations[:1]}
+	languages = localizeHotel(&untranslated, []string{"tr", "en"})
+	assert.Empty(t, languages)
+	assert.Equal(t, "", untranslated.Language)
+	assert.Equal(t, "Pera Palace", untranslated.CompanyTitle)
+	assert.Equal(t, "", untranslated.Description)
+}
+
+func TestMissingTranslations(t *testing.T) {
+	translations := []HotelTranslation{
+		{Language: "en", CompanyTitle: "Pera Palace", Description: "Historic", MarketingText: "Since 1892"},
+		{Language: "de", CompanyTitle: "Pera Palast"},
+	}
+	assert.Equal(t, map[string][]string{
+		"tr": {"company_title", "description", "marketing_text"},
+		"de": {"description", "marketing_text"},
+	}, missingTranslations(translations, []string{"tr", "en", "de"}))
+}
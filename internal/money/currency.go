@@ -0,0 +1,52 @@
+package money
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+var ErrInvalidCurrency = errors.New("currency must be an ISO 4217 code such as EUR or TRY")
+
+var currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// minorUnits lists the currencies whose amounts do not have two fractional
+// digits.
+var minorUnits = map[string]int{
+	"BHD": 3, "CLP": 0, "ISK": 0, "JOD": 3, "JPY": 0, "KRW": 0, "KWD": 3, "OMR": 3, "TND": 3, "VND": 0,
+}
+
+// NormalizeCurrency upper-cases an ISO 4217 currency code and checks its form.
+func NormalizeCurrency(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if !currencyCode.MatchString(code) {
+		return "", ErrInvalidCurrency
+	}
+	return code, nil
+}
+
+// MinorUnits returns the number of fractional digits amounts of a currency are
+// rounded to.
+func MinorUnits(currency string) int {
+	if units, ok := minorUnits[currency]; ok {
+		return units
+	}
+	return 2
+}
+
+// Amount is a decimal in a currency.
+type Amount struct {
+	Value    Decimal `json:"amount"`
+	Currency string  `json:"currency"`
+}
+
+// Rounded returns the amount rounded to the minor units of its currency.
+func (a Amount) Rounded() Amount {
+	return Amount{Value: a.Value.Round(MinorUnits(a.Currency)), Currency: a.Currency}
+}
+
+// String formats the amount with the minor units of its currency, e.g.
+// "120.50 EUR".
+func (a Amount) String() string {
+	return a.Value.StringFixed(MinorUnits(a.Currency)) + " " + a.Currency
+}
@@ -0,0 +1,254 @@
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Scale is the number of fractional digits a Decimal keeps. Exchange rates
+// need more digits than prices, which are rounded to their currency.
+const Scale = 6
+
+var ErrInvalidDecimal = errors.New("decimal must be a number such as 120 or 99.95 with at most 6 fractional digits")
+
+var (
+	scaleFactor = big.NewInt(1_000_000)
+	decimalText = regexp.MustCompile(`^([+-]?)(\d+)(?:\.(\d*))?$`)
+)
+
+// Decimal is a fixed-point decimal number with Scale fractional digits, so
+// that prices add up without the rounding errors of float64. The zero value
+// is zero. Decimals are immutable; arithmetic returns new values.
+type Decimal struct {
+	// units is the number times 10^Scale, or nil for zero so that equal
+	// decimals compare equal with reflect.DeepEqual.
+	units *big.Int
+}
+
+// Zero is the decimal 0.
+var Zero = Decimal{}
+
+func fromUnits(units *big.Int) Decimal {
+	if units.Sign() == 0 {
+		return Decimal{}
+	}
+	return Decimal{units: units}
+}
+
+func (d Decimal) int() *big.Int {
+	if d.units == nil {
+		return new(big.Int)
+	}
+	return d.units
+}
+
+// NewFromInt returns the decimal of an integer.
+func NewFromInt(n int64) Decimal {
+	return fromUnits(new(big.Int).Mul(big.NewInt(n), scaleFactor))
+}
+
+// Parse reads a decimal written with a point, such as "99.95" or "-3".
+func Parse(s string) (Decimal, error) {
+	match := decimalText.FindStringSubmatch(strings.TrimSpace(s))
+	if match == nil || len(match[3]) > Scale {
+		return Decimal{}, ErrInvalidDecimal
+	}
+	digits := match[2] + match[3] + strings.Repeat("0", Scale-len(match[3]))
+	units, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return Decimal{}, ErrInvalidDecimal
+	}
+	if match[1] == "-" {
+		units.Neg(units)
+	}
+	return fromUnits(units), nil
+}
+
+// MustParse is like Parse but panics on invalid input. It is meant for
+// constants.
+func MustParse(s string) Decimal {
+	d, err := Parse(s)
+	if err != nil {
+		panic(fmt.Sprintf("money: %q: %v", s, err))
+	}
+	return d
+}
+
+func (d Decimal) Add(other Decimal) Decimal {
+	return fromUnits(new(big.Int).Add(d.int(), other.int()))
+}
+
+func (d Decimal) Sub(other Decimal) Decimal {
+	return fromUnits(new(big.Int).Sub(d.int(), other.int()))
+}
+
+// Mul returns the product rounded half away from zero to Scale digits.
+func (d Decimal) Mul(other Decimal) Decimal {
+	product := new(big.Int).Mul(d.int(), other.int())
+	return fromUnits(divRound(product, scaleFactor))
+}
+
+// MulInt returns the exact product with an integer.
+func (d Decimal) MulInt(n int64) Decimal {
+	return fromUnits(new(big.Int).Mul(d.int(), big.NewInt(n)))
+}
+
+// Div returns the quotient rounded half away from zero to Scale digits. It
+// panics when other is zero, like integer division.
+func (d Decimal) Div(other Decimal) Decimal {
+	if other.IsZero() {
+		panic("money: division by zero")
+	}
+	dividend := new(big.Int).Mul(d.int(), scaleFactor)
+	return fromUnits(divRound(dividend, other.int()))
+}
+
+// Round returns the decimal rounded half away from zero to places fractional
+// digits, between 0 and Scale.
+func (d Decimal) Round(places int) Decimal {
+	if places >= Scale {
+		return d
+	}
+	if places < 0 {
+		places = 0
+	}
+	step := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(Scale-places)), nil)
+	rounded := divRound(d.int(), step)
+	return fromUnits(rounded.Mul(rounded, step))
+}
+
+// divRound divides and rounds half away from zero.
+func divRound(x, y *big.Int) *big.Int {
+	quotient, remainder := new(big.Int).QuoRem(x, y, new(big.Int))
+	if remainder.Sign() == 0 {
+		return quotient
+	}
+	twice := new(big.Int).Abs(remainder)
+	twice.Lsh(twice, 1)
+	if twice.Cmp(new(big.Int).Abs(y)) >= 0 {
+		if (x.Sign() < 0) != (y.Sign() < 0) {
+			quotient.Sub(quotient, big.NewInt(1))
+		} else {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+	return quotient
+}
+
+// Cmp compares two decimals and returns -1, 0 or +1.
+func (d Decimal) Cmp(other Decimal) int {
+	return d.int().Cmp(other.int())
+}
+
+func (d Decimal) Sign() int {
+	return d.int().Sign()
+}
+
+func (d Decimal) IsZero() bool {
+	return d.units == nil || d.units.Sign() == 0
+}
+
+// StringFixed formats the decimal rounded to exactly places fractional digits,
+// e.g. "120.50".
+func (d Decimal) StringFixed(places int) string {
+	if places > Scale {
+		places = Scale
+	}
+	if places < 0 {
+		places = 0
+	}
+	units := d.Round(places).int()
+	digits := new(big.Int).Abs(units).String()
+	if len(digits) <= Scale {
+		digits = strings.Repeat("0", Scale-len(digits)+1) + digits
+	}
+	whole, fraction := digits[:len(digits)-Scale], digits[len(digits)-Scale:][:places]
+
+	sign := ""
+	if units.Sign() < 0 {
+		sign = "-"
+	}
+	if places == 0 {
+		return sign + whole
+	}
+	return sign + whole + "." + fraction
+}
+
+// String formats the decimal without trailing fractional zeros, e.g. "120.5".
+func (d Decimal) String() string {
+	s := d.StringFixed(Scale)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// MarshalJSON writes the decimal as a JSON string, so that clients do not read
+// it into a float.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + d.String() + `"`), nil
+}
+
+// UnmarshalJSON accepts a JSON string or number. Numbers are read from their
+// text, never through a float. Null leaves the decimal unchanged.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	text := strings.Trim(string(data), `"`)
+	parsed, err := Parse(text)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// Value stores the decimal as its text, which numeric columns read exactly.
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+func (d *Decimal) Scan(value interface{}) error {
+	var text string
+	switch v := value.(type) {
+	case nil:
+		*d = Decimal{}
+		return nil
+	case string:
+		text = v
+	case []byte:
+		text = string(v)
+	case int64:
+		*d = NewFromInt(v)
+		return nil
+	case float64:
+		text = fmt.Sprintf("%.*f", Scale, v)
+	default:
+		return fmt.Errorf("cannot scan %T into decimal", value)
+	}
+	parsed, err := Parse(text)
+	if err != nil {
+		return fmt.Errorf("cannot scan %q into decimal: %w", text, err)
+	}
+	*d = parsed
+	return nil
+}
+
+// Median returns the median of the values, the mean of the two middle values
+// for an even count, or zero for no values.
+func Median(values []Decimal) Decimal {
+	sorted := append([]Decimal(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Cmp(sorted[j]) < 0 })
+	n := len(sorted)
+	if n == 0 {
+		return Decimal{}
+	}
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return sorted[n/2-1].Add(sorted[n/2]).Div(NewFromInt(2))
+}
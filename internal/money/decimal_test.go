@@ -0,0 +1,94 @@
+package money
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	for text, want := range map[string]string{"120": "120", "99.950": "99.95", "-0.5": "-0.5", "+3.000001": "3.000001", "0.000": "0", "7.": "7"} {
+		d, err := Parse(text)
+		assert.NoError(t, err, text)
+		assert.Equal(t, want, d.String(), text)
+	}
+	for _, text := range []string{"", "abc", "1.2.3", "1e3", ".5", "0.0000001"} {
+		_, err := Parse(text)
+		assert.ErrorIs(t, err, ErrInvalidDecimal, text)
+	}
+	assert.Equal(t, Zero, MustParse("-0.00"))
+}
+
+func TestArithmetic(t *testing.T) {
+	// The sum float64 gets wrong
+	assert.Equal(t, "0.3", MustParse("0.1").Add(MustParse("0.2")).String())
+	assert.Equal(t, MustParse("0.3"), MustParse("0.1").Add(MustParse("0.2")))
+
+	assert.Equal(t, "-1.25", MustParse("1").Sub(MustParse("2.25")).String())
+	assert.Equal(t, "36.2", MustParse("120.5").Mul(MustParse("0.3004")).Round(2).String())
+	assert.Equal(t, "361.5", MustParse("120.5").MulInt(3).String())
+	assert.Equal(t, "0.333333", NewFromInt(1).Div(NewFromInt(3)).String())
+	assert.Equal(t, "0.666667", NewFromInt(2).Div(NewFromInt(3)).String())
+	assert.Equal(t, "-0.666667", NewFromInt(-2).Div(NewFromInt(3)).String())
+	assert.Equal(t, 1, MustParse("2.5").Cmp(MustParse("2.49")))
+	assert.Panics(t, func() { NewFromInt(1).Div(Zero) })
+}
+
+func TestRound(t *testing.T) {
+	assert.Equal(t, "10.13", MustParse("10.125").Round(2).String())
+	assert.Equal(t, "-10.13", MustParse("-10.125").Round(2).String())
+	assert.Equal(t, "10.12", MustParse("10.124999").Round(2).String())
+	assert.Equal(t, "11", MustParse("10.5").Round(0).String())
+	assert.Equal(t, "120.50", MustParse("120.5").StringFixed(2))
+	assert.Equal(t, "0.05", MustParse("0.049").StringFixed(2))
+	assert.Equal(t, "-0.01", MustParse("-0.005").StringFixed(2))
+	assert.Equal(t, "121", MustParse("120.5").StringFixed(0))
+}
+
+func TestDecimal_JSON(t *testing.T) {
+	var value struct {
+		Price Decimal `json:"price"`
+		Rate  Decimal `json:"rate"`
+	}
+	assert.NoError(t, json.Unmarshal([]byte(`{"price": "99.95", "rate": 0.028345}`), &value))
+	assert.Equal(t, MustParse("99.95"), value.Price)
+	assert.Equal(t, MustParse("0.028345"), value.Rate)
+
+	data, err := json.Marshal(value)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"price": "99.95", "rate": "0.028345"}`, string(data))
+
+	assert.Error(t, json.Unmarshal([]byte(`{"price": "cheap"}`), &value))
+}
+
+func TestDecimal_Scan(t *testing.T) {
+	var d Decimal
+	for _, tt := range []struct {
+		value interface{}
+		want  string
+	}{{"12.500000", "12.5"}, {[]byte("3.25"), "3.25"}, {int64(4), "4"}, {0.1, "0.1"}} {
+		assert.NoError(t, d.Scan(tt.value))
+		assert.Equal(t, tt.want, d.String())
+	}
+	assert.NoError(t, d.Scan(nil))
+	assert.True(t, d.IsZero())
+	assert.Error(t, d.Scan(true))
+}
+
+func TestMedian(t *testing.T) {
+	assert.Equal(t, Zero, Median(nil))
+	assert.Equal(t, "100", Median([]Decimal{MustParse("300"), MustParse("100"), MustParse("50")}).String())
+	assert.Equal(t, "125.25", Median([]Decimal{MustParse("300"), MustParse("100"), MustParse("50"), MustParse("150.5")}).String())
+}
+
+func TestCurrency(t *testing.T) {
+	code, err := NormalizeCurrency(" try ")
+	assert.NoError(t, err)
+	assert.Equal(t, "TRY", code)
+	_, err = NormalizeCurrency("EURO")
+	assert.ErrorIs(t, err, ErrInvalidCurrency)
+
+	assert.Equal(t, "1500 JPY", Amount{MustParse("1499.6"), "JPY"}.String())
+	assert.Equal(t, Amount{MustParse("10.13"), "EUR"}, Amount{MustParse("10.125"), "EUR"}.Rounded())
+}
@@ -0,0 +1,47 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	id := uuid.New()
+	raw := Encode(Cursor{Sort: "-version", Value: 3, ID: id})
+
+	cursor, err := Decode(raw, "-version")
+	assert.NoError(t, err)
+	assert.Equal(t, id, cursor.ID)
+	assert.Equal(t, json.Number("3"), cursor.Value)
+}
+
+func TestDecode_WrongSort(t *testing.T) {
+	raw := Encode(Cursor{Sort: "id", Value: "x", ID: uuid.New()})
+
+	_, err := Decode(raw, "-id")
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+func TestDecode_Tampered(t *testing.T) {
+	raw := Encode(Cursor{Sort: "company_title", Value: "Alpha Inn", ID: uuid.New()})
+	payload, signature, _ := strings.Cut(raw, ".")
+
+	// Re-encode a different position with the original signature
+	forged, _ := json.Marshal(Cursor{Sort: "company_title", Value: "Zeta Inn", ID: uuid.New()})
+
+	for _, candidate := range []string{
+		"",
+		"garbage",
+		payload,
+		payload + ".",
+		base64.RawURLEncoding.EncodeToString(forged) + "." + signature,
+	} {
+		_, err := Decode(candidate, "company_title")
+		assert.ErrorIs(t, err, ErrInvalidCursor, candidate)
+	}
+}
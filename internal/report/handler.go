@@ -2,8 +2,12 @@ package report
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"hotel-guide/internal/money"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
@@ -27,6 +31,7 @@ func (h *ReportHandler) RegisterRoutes(r *mux.Router) {
 	r.HandleFunc("/reports", h.ListReports).Methods(http.MethodGet)
 	r.HandleFunc("/reports/{id}", h.GetReportByID).Methods(http.MethodGet)
 	r.HandleFunc("/reports", h.RequestReportGeneration).Methods(http.MethodPost)
+	r.HandleFunc("/reports:batch", h.RequestBulkReportGeneration).Methods(http.MethodPost)
 }
 
 // sendJSONResponse sends JSON response with proper Content-Type and status code
@@ -41,9 +46,7 @@ func sendJSONResponse(w http.ResponseWriter, statusCode int, response interface{
 
 // RequestReportGeneration handles the creation of a new report
 func (h *ReportHandler) RequestReportGeneration(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		Location string `json:"location"`
-	}
+	var req LocationFilter
 
 	// Parse the request body
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -52,13 +55,13 @@ func (h *ReportHandler) RequestReportGeneration(w http.ResponseWriter, r *http.R
 	}
 
 	// Validate location
-	if req.Location == "" {
-		http.Error(w, "Location must not be empty", http.StatusBadRequest)
+	if err := validateReportRequest(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
 	// Call the service to request a new report generation
-	report, err := h.reportService.RequestReportGeneration(req.Location)
+	report, err := h.reportService.RequestReportGeneration(req)
 	if err != nil {
 		log.Error().Err(err).Msg("Error creating report")
 		http.Error(w, fmt.Sprintf("Error creating report: %v", err), http.StatusInternalServerError)
@@ -69,17 +72,97 @@ func (h *ReportHandler) RequestReportGeneration(w http.ResponseWriter, r *http.R
 	sendJSONResponse(w, http.StatusCreated, report)
 }
 
-// ListReports handles fetching all reports
+// RequestBulkReportGeneration handles the creation of a report for each of
+// several locations
+func (h *ReportHandler) RequestBulkReportGeneration(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Locations []LocationFilter `json:"locations"`
+	}
+
+	// Parse the request body
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	// Validate the locations
+	if len(req.Locations) == 0 || len(req.Locations) > maxStatsBatch {
+		http.Error(w, fmt.Sprintf("locations must list between 1 and %d locations", maxStatsBatch), http.StatusBadRequest)
+		return
+	}
+	for i := range req.Locations {
+		if err := validateReportRequest(&req.Locations[i]); err != nil {
+			http.Error(w, fmt.Sprintf("locations[%d]: %v", i, err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Call the service to request the reports at once
+	reports, err := h.reportService.RequestBulkReportGeneration(req.Locations)
+	if err != nil {
+		log.Error().Err(err).Msg("Error creating reports")
+		http.Error(w, fmt.Sprintf("Error creating reports: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// Return the created reports
+	sendJSONResponse(w, http.StatusCreated, map[string][]Report{"items": reports})
+}
+
+// validateReportRequest checks the location of a report request and normalizes
+// its currency
+func validateReportRequest(filter *LocationFilter) error {
+	if filter.IsEmpty() {
+		return errors.New("Location or chain_id must not be empty")
+	}
+	if filter.Currency != "" {
+		currency, err := money.NormalizeCurrency(filter.Currency)
+		if err != nil {
+			return err
+		}
+		filter.Currency = currency
+	}
+	return nil
+}
+
+// ListReports handles fetching a page of reports
 func (h *ReportHandler) ListReports(w http.ResponseWriter, r *http.Request) {
-	reports, err := h.reportService.ListReports()
+	query := r.URL.Query()
+	opts := ListOptions{Cursor: query.Get("cursor"), SortBy: query.Get("sort")}
+	if strings.HasPrefix(opts.SortBy, "-") {
+		opts.SortBy, opts.SortDesc = opts.SortBy[1:], true
+	}
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 0 {
+			http.Error(w, "limit parameter must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+		opts.Limit = limit
+	}
+
+	page, err := h.reportService.ListReports(opts)
+	if errors.Is(err, ErrInvalidCursor) || errors.Is(err, ErrInvalidSort) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		log.Error().Err(err).Msg("Error fetching reports")
 		http.Error(w, fmt.Sprintf("Error fetching reports: %v", err), http.StatusInternalServerError)
 		return
 	}
 
-	// Return the list of reports
-	sendJSONResponse(w, http.StatusOK, reports)
+	page.Links.Self = r.URL.RequestURI()
+	if page.NextCursor != "" {
+		next := *r.URL
+		nextQuery := next.Query()
+		nextQuery.Set("cursor", page.NextCursor)
+		next.RawQuery = nextQuery.Encode()
+		page.Links.Next = next.RequestURI()
+	}
+
+	// Return the page of reports
+	sendJSONResponse(w, http.StatusOK, page)
 }
 
 // GetReportByID handles fetching a specific report by ID
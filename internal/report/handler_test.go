@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/google/uuid"
@@ -25,9 +26,9 @@ func (m *MockReportService) CreateReport(location string, hotelCount, phoneCount
 }
 
 // ListReports mocks the ListReports method
-func (m *MockReportService) ListReports() ([]Report, error) {
-	args := m.Called()
-	return args.Get(0).([]Report), args.Error(1)
+func (m *MockReportService) ListReports(opts ListOptions) (*ReportPage, error) {
+	args := m.Called(opts)
+	return args.Get(0).(*ReportPage), args.Error(1)
 }
 
 // GetReportByID mocks the GetReportByID method
@@ -37,11 +38,20 @@ func (m *MockReportService) GetReportByID(id uuid.UUID) (*Report, error) {
 }
 
 // RequestReportGeneration mocks the RequestReportGeneration method
-func (m *MockReportService) RequestReportGeneration(location string) (*Report, error) {
-	args := m.Called(location)
+func (m *MockReportService) RequestReportGeneration(filter LocationFilter) (*Report, error) {
+	args := m.Called(filter)
 	return args.Get(0).(*Report), args.Error(1)
 }
 
+// RequestBulkReportGeneration mocks the RequestBulkReportGeneration method
+func (m *MockReportService) RequestBulkReportGeneration(filters []LocationFilter) ([]Report, error) {
+	args := m.Called(filters)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]Report), args.Error(1)
+}
+
 // UpdateReportStatus mocks the UpdateReportStatus method
 func (m *MockReportService) UpdateReportStatus(id uuid.UUID, status ReportStatus) error {
 	args := m.Called(id, status)
@@ -54,9 +64,9 @@ func (m *MockReportService) StartReportConsumer() {
 }
 
 // fetchLocationStats mocks the fetchLocationStats method
-func (m *MockReportService) fetchLocationStats(location string) (int, int, error) {
-	args := m.Called(location)
-	return args.Int(0), args.Int(1), args.Error(2)
+func (m *MockReportService) fetchLocationStats(filter LocationFilter) (*LocationStats, error) {
+	args := m.Called(filter)
+	return args.Get(0).(*LocationStats), args.Error(1)
 }
 
 // Test RequestReportGeneration
@@ -72,7 +82,7 @@ func TestRequestReportGeneration_Handler(t *testing.T) {
 		Status:   Pending,
 	}
 
-	mockService.On("RequestReportGeneration", "Paris").Return(report, nil)
+	mockService.On("RequestReportGeneration", LocationFilter{Location: "Paris"}).Return(report, nil)
 
 	// Prepare the request
 	requestBody := `{"location": "Paris"}`
@@ -95,6 +105,113 @@ func TestRequestReportGeneration_Handler(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
+// Test RequestReportGeneration_Handler_StructuredLocation
+func TestRequestReportGeneration_Handler_StructuredLocation(t *testing.T) {
+	mockService := new(MockReportService)
+	handler := NewHandler(mockService)
+
+	// Test data
+	filter := LocationFilter{Country: "Turkey", City: "Istanbul", District: "Kadikoy"}
+	report := &Report{
+		ID:       uuid.New(),
+		Country:  "Turkey",
+		City:     "Istanbul",
+		District: "Kadikoy",
+		Status:   Pending,
+	}
+
+	mockService.On("RequestReportGeneration", filter).Return(report, nil)
+
+	// Prepare the request
+	requestBody := `{"country": "Turkey", "city": "Istanbul", "district": "Kadikoy"}`
+	req := httptest.NewRequest(http.MethodPost, "/reports", bytes.NewBufferString(requestBody))
+	rr := httptest.NewRecorder()
+
+	// Register routes and handle request
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+	r.ServeHTTP(rr, req)
+
+	// Assert status code and response body
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	var response Report
+	err := json.NewDecoder(rr.Body).Decode(&response)
+	assert.NoError(t, err)
+	assert.Equal(t, "Kadikoy", response.District)
+	mockService.AssertExpectations(t)
+}
+
+func TestRequestReportGeneration_Handler_Currency(t *testing.T) {
+	mockService := new(MockReportService)
+	handler := NewHandler(mockService)
+
+	// The currency is normalized before the report is requested
+	filter := LocationFilter{City: "Istanbul", Currency: "TRY"}
+	mockService.On("RequestReportGeneration", filter).Return(&Report{ID: uuid.New(), City: "Istanbul", Currency: "TRY", Status: Pending}, nil)
+
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+
+	tests := []struct {
+		body   string
+		status int
+	}{
+		{`{"city": "Istanbul", "currency": "try"}`, http.StatusCreated},
+		{`{"city": "Istanbul", "currency": "lira"}`, http.StatusBadRequest},
+		{`{"currency": "TRY"}`, http.StatusBadRequest},
+		{`{"chain_id": "hilton"}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/reports", bytes.NewBufferString(tt.body)))
+		assert.Equal(t, tt.status, rr.Code, tt.body)
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestRequestBulkReportGeneration_Handler(t *testing.T) {
+	mockService := new(MockReportService)
+	handler := NewHandler(mockService)
+
+	// Every location is validated and its currency normalized
+	filters := []LocationFilter{{City: "Istanbul", Currency: "TRY"}, {Country: "Turkey"}}
+	reports := []Report{
+		{ID: uuid.New(), City: "Istanbul", Currency: "TRY", Status: Pending},
+		{ID: uuid.New(), Country: "Turkey", Status: Pending},
+	}
+	mockService.On("RequestBulkReportGeneration", filters).Return(reports, nil).Once()
+
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+
+	rr := httptest.NewRecorder()
+	body := `{"locations": [{"city": "Istanbul", "currency": "try"}, {"country": "Turkey"}]}`
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/reports:batch", bytes.NewBufferString(body)))
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	var response struct {
+		Items []Report `json:"items"`
+	}
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	assert.Equal(t, []uuid.UUID{reports[0].ID, reports[1].ID}, []uuid.UUID{response.Items[0].ID, response.Items[1].ID})
+
+	tooMany := `{"locations": [` + strings.Repeat(`{"city": "Istanbul"},`, maxStatsBatch) + `{"city": "Istanbul"}]}`
+	for _, tt := range []struct {
+		body   string
+		status int
+	}{
+		{`{"locations": []}`, http.StatusBadRequest},
+		{tooMany, http.StatusBadRequest},
+		{`{"locations": [{"city": "Istanbul"}, {}]}`, http.StatusBadRequest},
+		{`{"locations": [{"city": "Istanbul", "currency": "lira"}]}`, http.StatusBadRequest},
+		{`{"locations":`, http.StatusBadRequest},
+	} {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/reports:batch", bytes.NewBufferString(tt.body)))
+		assert.Equal(t, tt.status, rr.Code, tt.body)
+	}
+	mockService.AssertExpectations(t)
+}
+
 // Test ListReports
 func TestListReports_Handler(t *testing.T) {
 	mockService := new(MockReportService)
@@ -107,10 +224,11 @@ func TestListReports_Handler(t *testing.T) {
 		Status:   "Completed",
 	}
 
-	mockService.On("ListReports").Return([]Report{report}, nil)
+	page := &ReportPage{Items: []Report{report}, Limit: 1, NextCursor: "abc"}
+	mockService.On("ListReports", ListOptions{Limit: 1, SortBy: "location", SortDesc: true}).Return(page, nil)
 
 	// Prepare the request
-	req := httptest.NewRequest(http.MethodGet, "/reports", nil)
+	req := httptest.NewRequest(http.MethodGet, "/reports?limit=1&sort=-location", nil)
 	rr := httptest.NewRecorder()
 
 	// Register routes and handle request
@@ -120,11 +238,33 @@ func TestListReports_Handler(t *testing.T) {
 
 	// Assert status code and response body
 	assert.Equal(t, http.StatusOK, rr.Code)
-	var reports []Report
-	err := json.NewDecoder(rr.Body).Decode(&reports)
+	var response ReportPage
+	err := json.NewDecoder(rr.Body).Decode(&response)
 	assert.NoError(t, err)
-	assert.Len(t, reports, 1)
-	assert.Equal(t, "Paris", reports[0].Location)
+	assert.Len(t, response.Items, 1)
+	assert.Equal(t, "Paris", response.Items[0].Location)
+	assert.Equal(t, "/reports?cursor=abc&limit=1&sort=-location", response.Links.Next)
+	mockService.AssertExpectations(t)
+}
+
+// Test ListReports with an invalid cursor
+func TestListReports_Handler_InvalidCursor(t *testing.T) {
+	mockService := new(MockReportService)
+	handler := NewHandler(mockService)
+
+	mockService.On("ListReports", ListOptions{Cursor: "forged"}).Return((*ReportPage)(nil), ErrInvalidCursor)
+
+	// Prepare the request
+	req := httptest.NewRequest(http.MethodGet, "/reports?cursor=forged", nil)
+	rr := httptest.NewRecorder()
+
+	// Register routes and handle request
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+	r.ServeHTTP(rr, req)
+
+	// Assert status code
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
 	mockService.AssertExpectations(t)
 }
 
@@ -0,0 +1,113 @@
+package report
+
+import (
+	"errors"
+	"hotel-guide/internal/pagination"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+var (
+	ErrInvalidCursor = pagination.ErrInvalidCursor
+	ErrInvalidSort   = errors.New("invalid sort field")
+)
+
+// sortableColumns maps the sort fields accepted by ListReports to their columns.
+var sortableColumns = map[string]string{
+	"requested_at": "requested_at",
+	"id":           "id",
+	"location":     "location",
+	"status":       "status",
+}
+
+// ListOptions controls which page of reports ListReports returns. Reports are
+// ordered by the sort field and then by id, so walking the pages with cursors
+// never skips or repeats a report.
+type ListOptions struct {
+	Limit    int
+	Cursor   string
+	SortBy   string
+	SortDesc bool
+
+	after *pagination.Cursor
+}
+
+// ReportPage is one page of the report listing.
+type ReportPage struct {
+	Items      []Report  `json:"items"`
+	Limit      int       `json:"limit"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+	Links      PageLinks `json:"links"`
+}
+
+// PageLinks holds the URLs of the current and the next page.
+type PageLinks struct {
+	Self string `json:"self"`
+	Next string `json:"next,omitempty"`
+}
+
+// normalize applies defaults and validates the options.
+func (o *ListOptions) normalize() error {
+	if o.Limit <= 0 {
+		o.Limit = DefaultPageLimit
+	}
+	if o.Limit > MaxPageLimit {
+		o.Limit = MaxPageLimit
+	}
+
+	o.SortBy = strings.ToLower(strings.TrimSpace(o.SortBy))
+	if o.SortBy == "" {
+		o.SortBy = "requested_at"
+	}
+	if _, ok := sortableColumns[o.SortBy]; !ok {
+		return ErrInvalidSort
+	}
+
+	o.after = nil
+	if o.Cursor != "" {
+		cursor, err := pagination.Decode(o.Cursor, o.sortKey())
+		if err != nil {
+			return err
+		}
+		if o.SortBy == "requested_at" {
+			value, ok := cursor.Value.(string)
+			if !ok {
+				return ErrInvalidCursor
+			}
+			if cursor.Value, err = time.Parse(time.RFC3339Nano, value); err != nil {
+				return ErrInvalidCursor
+			}
+		}
+		o.after = cursor
+	}
+	return nil
+}
+
+// sortKey identifies the ordering a cursor was issued for.
+func (o *ListOptions) sortKey() string {
+	if o.SortDesc {
+		return "-" + o.SortBy
+	}
+	return o.SortBy
+}
+
+// nextCursor returns the cursor that continues the listing after report.
+func (o *ListOptions) nextCursor(report Report) string {
+	var value interface{}
+	switch o.SortBy {
+	case "requested_at":
+		value = report.RequestedAt
+	case "id":
+		value = report.ID.String()
+	case "location":
+		value = report.Location
+	case "status":
+		value = report.Status
+	}
+	return pagination.Encode(pagination.Cursor{Sort: o.sortKey(), Value: value, ID: report.ID})
+}
@@ -1,6 +1,7 @@
 package report
 
 import (
+	"hotel-guide/internal/money"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,12 +15,78 @@ const (
 )
 
 type Report struct {
-	ID          uuid.UUID    `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
-	Location    string       `json:"location"`
-	HotelCount  int          `json:"hotel_count"`
-	PhoneCount  int          `json:"phone_count"`
-	RequestedAt time.Time    `json:"requested_at"`
-	Status      ReportStatus `json:"status"`
+	ID       uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	Location string    `json:"location"`
+	Country  string    `json:"country,omitempty"`
+	City     string    `json:"city,omitempty"`
+	District string    `json:"district,omitempty"`
+	// ChainID restricts the report to the hotels of a chain and, for a group,
+	// of its brands.
+	ChainID    *uuid.UUID `gorm:"type:uuid" json:"chain_id,omitempty"`
+	HotelCount int        `json:"hotel_count"`
+	PhoneCount int        `json:"phone_count"`
+	// The contact and official counts are those of LocationStats.
+	HotelsWithoutPhone int     `json:"hotels_without_phone"`
+	EmailCount         int     `json:"email_count"`
+	FaxCount           int     `json:"fax_count"`
+	WebsiteCount       int     `json:"website_count"`
+	AverageContacts    float64 `json:"average_contacts"`
+	OfficialCount      int     `json:"official_count"`
+	RoomCount          int     `json:"room_count"`
+	BedCount           int     `json:"bed_count"`
+	// AverageRating is the average review score of the hotels, or zero when
+	// they have no approved reviews.
+	AverageRating float64 `json:"average_rating"`
+	// MedianNightlyPrice is the median base price of a night at the hotels in
+	// Currency, or nil when none of them has a rate plan for the day the
+	// report was generated.
+	MedianNightlyPrice *money.Decimal `gorm:"type:numeric(18,6)" json:"median_nightly_price,omitempty"`
+	Currency           string         `gorm:"type:char(3)" json:"currency,omitempty"`
+	RequestedAt        time.Time      `json:"requested_at"`
+	Status             ReportStatus   `json:"status"`
+}
+
+// LocationFilter selects the hotels a report covers. Location matches any of
+// country, city or district; the other fields must all match when set.
+// ChainID selects the hotels of a chain, alone or within a location.
+// Currency is the currency of the median nightly price, EUR by default.
+type LocationFilter struct {
+	Location string     `json:"location"`
+	Country  string     `json:"country,omitempty"`
+	City     string     `json:"city,omitempty"`
+	District string     `json:"district,omitempty"`
+	ChainID  *uuid.UUID `json:"chain_id,omitempty"`
+	Currency string     `json:"currency,omitempty"`
+}
+
+// IsEmpty reports whether the filter has no criteria.
+func (f LocationFilter) IsEmpty() bool {
+	return f.Location == "" && f.Country == "" && f.City == "" && f.District == "" && f.ChainID == nil
+}
+
+// LocationStats summarizes the hotels of a location as reported by the
+// hotel-service.
+type LocationStats struct {
+	HotelCount int `json:"hotel_count"`
+	PhoneCount int `json:"phone_count"`
+	// HotelsWithoutPhone is the number of hotels without a phone, and the
+	// email, fax and website counts the number of contacts of those types.
+	HotelsWithoutPhone int `json:"hotels_without_phone"`
+	EmailCount         int `json:"email_count"`
+	FaxCount           int `json:"fax_count"`
+	WebsiteCount       int `json:"website_count"`
+	// AverageContacts is the number of contacts per hotel and OfficialCount
+	// the number of distinct officials of the hotels.
+	AverageContacts float64 `json:"average_contacts"`
+	OfficialCount   int     `json:"official_count"`
+	RoomCount       int     `json:"room_count"`
+	BedCount        int     `json:"bed_count"`
+	// AverageRating is the average review score of the hotels.
+	AverageRating float64 `json:"average_rating"`
+	// MedianNightlyPrice is the median nightly price in Currency, absent when
+	// no hotel of the location is priced.
+	MedianNightlyPrice *money.Decimal `json:"median_nightly_price,omitempty"`
+	Currency           string         `json:"currency,omitempty"`
 }
 
 func NewReport(location string, hotelCount, phoneCount int) *Report {
@@ -32,3 +99,15 @@ func NewReport(location string, hotelCount, phoneCount int) *Report {
 		Status:      Pending,
 	}
 }
+
+// Filter returns the location filter the report was requested for.
+func (r *Report) Filter() LocationFilter {
+	return LocationFilter{
+		Location: r.Location,
+		Country:  r.Country,
+		City:     r.City,
+		District: r.District,
+		ChainID:  r.ChainID,
+		Currency: r.Currency,
+	}
+}
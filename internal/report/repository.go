@@ -1,13 +1,16 @@
 package report
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"net/url"
 	"os"
+	"strings"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -16,12 +19,19 @@ import (
 // ReportRepository defines report database operations
 type ReportRepository interface {
 	Save(report *Report) error
-	ListReports() ([]Report, error)
+	SaveAll(reports []Report) error
+	ListReports(opts ListOptions) ([]Report, error)
 	GetReportByID(id uuid.UUID) (*Report, error)
 	UpdateReportStatus(id uuid.UUID, status ReportStatus) error
-	UpdateReportStats(reportID uuid.UUID, hotelCount, phoneCount int, status ReportStatus) error
-	FetchHotelAndPhoneCounts(location string) (int, int, error)
+	UpdateReportStats(reportID uuid.UUID, stats LocationStats, status ReportStatus) error
+	FetchLocationStats(filter LocationFilter) (*LocationStats, error)
+	FetchLocationStatsBatch(filters []LocationFilter) ([]LocationStats, error)
 }
+
+// maxStatsBatch is the largest number of locations hotel-service accepts in
+// one stats batch.
+const maxStatsBatch = 100
+
 type reportRepository struct {
 	db *gorm.DB
 }
@@ -35,10 +45,34 @@ func (r *reportRepository) Save(report *Report) error {
 	return r.db.Create(report).Error
 }
 
-// ListReports lists all reports
-func (r *reportRepository) ListReports() ([]Report, error) {
+// SaveAll saves new reports in one statement
+func (r *reportRepository) SaveAll(reports []Report) error {
+	return r.db.Create(&reports).Error
+}
+
+// ListReports lists the reports after the cursor of the options in keyset order.
+// It fetches one report more than the limit to tell whether another page exists.
+func (r *reportRepository) ListReports(opts ListOptions) ([]Report, error) {
+	column := sortableColumns[opts.SortBy]
+	direction, comparison := "ASC", ">"
+	if opts.SortDesc {
+		direction, comparison = "DESC", "<"
+	}
+
+	order := fmt.Sprintf("%s %s", column, direction)
+	query := r.db.Model(&Report{})
+	if column != "id" {
+		order += ", id " + direction
+		if opts.after != nil {
+			query = query.Where(fmt.Sprintf("%s %s ? OR (%s = ? AND id %s ?)", column, comparison, column, comparison),
+				opts.after.Value, opts.after.Value, opts.after.ID)
+		}
+	} else if opts.after != nil {
+		query = query.Where("id "+comparison+" ?", opts.after.ID)
+	}
+
 	var reports []Report
-	err := r.db.Find(&reports).Error
+	err := query.Order(order).Limit(opts.Limit + 1).Find(&reports).Error
 	return reports, err
 }
 
@@ -57,36 +91,110 @@ func (r *reportRepository) UpdateReportStatus(id uuid.UUID, status ReportStatus)
 	return r.db.Model(&Report{}).Where("id = ?", id).Update("status", status).Error
 }
 
-// UpdateReportStats updates the hotel count, phone count, and status of a report
-func (r *reportRepository) UpdateReportStats(reportID uuid.UUID, hotelCount, phoneCount int, status ReportStatus) error {
+// UpdateReportStats updates the location stats and status of a report
+func (r *reportRepository) UpdateReportStats(reportID uuid.UUID, stats LocationStats, status ReportStatus) error {
+	updates := map[string]interface{}{
+		"hotel_count":          stats.HotelCount,
+		"phone_count":          stats.PhoneCount,
+		"hotels_without_phone": stats.HotelsWithoutPhone,
+		"email_count":          stats.EmailCount,
+		"fax_count":            stats.FaxCount,
+		"website_count":        stats.WebsiteCount,
+		"average_contacts":     stats.AverageContacts,
+		"official_count":       stats.OfficialCount,
+		"room_count":           stats.RoomCount,
+		"bed_count":            stats.BedCount,
+		"average_rating":       stats.AverageRating,
+		"median_nightly_price": stats.MedianNightlyPrice,
+		"status":               status,
+	}
+	// Without a median the report keeps the currency it was requested in
+	if stats.Currency != "" {
+		updates["currency"] = stats.Currency
+	}
 	return r.db.Model(&Report{}).
 		Where("id = ?", reportID).
-		Updates(map[string]interface{}{
-			"hotel_count": hotelCount,
-			"phone_count": phoneCount,
-			"status":      status,
-		}).Error
+		Updates(updates).Error
 }
 
-// FetchHotelAndPhoneCounts fetches hotel and phone counts by location from hotel-service
-func (r *reportRepository) FetchHotelAndPhoneCounts(location string) (int, int, error) {
+// FetchLocationStats fetches the hotel, contact, official, room and bed counts, the average rating and the median nightly price of a location from hotel-service
+func (r *reportRepository) FetchLocationStats(filter LocationFilter) (*LocationStats, error) {
 	var hotelServiceURL = os.Getenv("HOTEL_SERVICE_URL")
-	location = url.QueryEscape(location)
-	url := fmt.Sprintf("%s/hotels/stats?location=%s", hotelServiceURL, location)
+	params := url.Values{}
+	for key, value := range map[string]string{
+		"location": filter.Location,
+		"country":  filter.Country,
+		"city":     filter.City,
+		"district": filter.District,
+		"currency": filter.Currency,
+	} {
+		if value != "" {
+			params.Set(key, value)
+		}
+	}
+	if filter.ChainID != nil {
+		params.Set("chain_id", filter.ChainID.String())
+	}
+	url := fmt.Sprintf("%s/hotels/stats?%s", hotelServiceURL, params.Encode())
 	resp, err := http.Get(url)
 	if err != nil {
-		return 0, 0, fmt.Errorf("failed to fetch hotel and phone counts from hotel-service: %w", err)
+		return nil, fmt.Errorf("failed to fetch location stats from hotel-service: %w", err)
 	}
 	defer resp.Body.Close()
 
-	var result struct {
-		HotelCount int `json:"hotel_count"`
-		PhoneCount int `json:"phone_count"`
+	var stats LocationStats
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		log.Printf("Failed to decode JSON response: %v", err)
+		return nil, fmt.Errorf("failed to decode location stats response: %w", err)
+	}
+	return &stats, nil
+}
+
+// FetchLocationStatsBatch fetches the stats of many locations from hotel-service, in the order of the filters, with one request per maxStatsBatch locations
+func (r *reportRepository) FetchLocationStatsBatch(filters []LocationFilter) ([]LocationStats, error) {
+	stats := make([]LocationStats, 0, len(filters))
+	for start := 0; start < len(filters); start += maxStatsBatch {
+		end := start + maxStatsBatch
+		if end > len(filters) {
+			end = len(filters)
+		}
+		batch, err := fetchLocationStatsBatch(filters[start:end])
+		if err != nil {
+			return nil, err
+		}
+		stats = append(stats, batch...)
 	}
+	return stats, nil
+}
 
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		log.Printf("Failed to decode JSON response: %v", err)
-		return 0, 0, fmt.Errorf("failed to decode hotel and phone counts response: %w", err)
+// fetchLocationStatsBatch posts one batch of locations to hotel-service.
+func fetchLocationStatsBatch(filters []LocationFilter) ([]LocationStats, error) {
+	body, err := json.Marshal(struct {
+		Locations []LocationFilter `json:"locations"`
+	}{filters})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal location stats batch: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/hotels/stats:batch", os.Getenv("HOTEL_SERVICE_URL"))
+	resp, err := http.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch location stats batch from hotel-service: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("hotel-service rejected location stats batch with %s: %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+
+	var response struct {
+		Items []LocationStats `json:"items"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode location stats batch response: %w", err)
+	}
+	if len(response.Items) != len(filters) {
+		return nil, fmt.Errorf("hotel-service returned stats for %d of %d locations", len(response.Items), len(filters))
 	}
-	return result.HotelCount, result.PhoneCount, nil
+	return response.Items, nil
 }
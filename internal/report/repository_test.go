@@ -1,7 +1,9 @@
 package report
 
 import (
+	"encoding/json"
 	"fmt"
+	"hotel-guide/internal/pagination"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -53,8 +55,23 @@ func TestSaveReport(t *testing.T) {
 	mock.ExpectExec("INSERT INTO `reports`").
 		WithArgs(
 			report.Location,
+			report.Country,
+			report.City,
+			report.District,
+			report.ChainID,
 			report.HotelCount,
 			report.PhoneCount,
+			report.HotelsWithoutPhone,
+			report.EmailCount,
+			report.FaxCount,
+			report.WebsiteCount,
+			report.AverageContacts,
+			report.OfficialCount,
+			report.RoomCount,
+			report.BedCount,
+			report.AverageRating,
+			report.MedianNightlyPrice,
+			report.Currency,
 			expectedTime,
 			report.Status,
 			report.ID,
@@ -72,6 +89,82 @@ func TestSaveReport(t *testing.T) {
 	}
 }
 
+func TestSaveAllReports(t *testing.T) {
+	// Mock setup
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to open mock database connection: %v", err)
+	}
+	defer db.Close()
+
+	// Mock SQLite version query
+	mock.ExpectQuery(`(?i)^SELECT sqlite_version\(\)$`).
+		WillReturnRows(sqlmock.NewRows([]string{"sqlite_version"}).AddRow("3.32.3"))
+
+	// GORM setup
+	gormDB, err := gorm.Open(sqlite.New(sqlite.Config{Conn: db}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to initialize GORM: %v", err)
+	}
+
+	// Repository setup
+	repo := NewRepository(gormDB)
+
+	// Mock expectations: the reports are inserted with one statement
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `reports` .* VALUES \\(.*\\),\\(.*\\)$").
+		WillReturnResult(sqlmock.NewResult(2, 2))
+	mock.ExpectCommit()
+
+	err = repo.SaveAll([]Report{*NewReport("Istanbul", 0, 0), *NewReport("Ankara", 0, 0)})
+	assert.NoError(t, err)
+
+	// Ensure all expectations were met
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestUpdateReportStats_Repository(t *testing.T) {
+	// Mock setup
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to open mock database connection: %v", err)
+	}
+	defer db.Close()
+
+	// Mock SQLite version query
+	mock.ExpectQuery(`(?i)^SELECT sqlite_version\(\)$`).
+		WillReturnRows(sqlmock.NewRows([]string{"sqlite_version"}).AddRow("3.32.3"))
+
+	// GORM setup
+	gormDB, err := gorm.Open(sqlite.New(sqlite.Config{Conn: db}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to initialize GORM: %v", err)
+	}
+	repo := NewRepository(gormDB)
+
+	reportID := uuid.New()
+	stats := LocationStats{
+		HotelCount: 5, PhoneCount: 9, HotelsWithoutPhone: 1, EmailCount: 4, FaxCount: 2, WebsiteCount: 3,
+		AverageContacts: 3.6, OfficialCount: 6, RoomCount: 120, BedCount: 180, AverageRating: 7.5,
+	}
+
+	// Every stat is stored; the columns are set in alphabetical order
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `reports` SET").
+		WithArgs(3.6, 7.5, 180, 4, 2, 5, 1, nil, 6, 9, 120, Completed, 3, reportID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, repo.UpdateReportStats(reportID, stats, Completed))
+
+	// Ensure all expectations were met
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There were unfulfilled expectations: %s", err)
+	}
+}
+
 func TestListReports_Repository(t *testing.T) {
 	// Mock database setup
 	db, mock, err := sqlmock.New()
@@ -116,11 +209,11 @@ func TestListReports_Repository(t *testing.T) {
 		AddRow(mockReports[0].ID.String(), mockReports[0].Location, mockReports[0].HotelCount, mockReports[0].PhoneCount, mockReports[0].Status).
 		AddRow(mockReports[1].ID.String(), mockReports[1].Location, mockReports[1].HotelCount, mockReports[1].PhoneCount, mockReports[1].Status)
 
-	mock.ExpectQuery(`SELECT \* FROM ` + "`reports`").
+	mock.ExpectQuery(`SELECT \* FROM ` + "`reports` ORDER BY requested_at ASC, id ASC LIMIT 21").
 		WillReturnRows(rows)
 
 	// Call ListReports method
-	reports, err := repo.ListReports()
+	reports, err := repo.ListReports(ListOptions{Limit: DefaultPageLimit, SortBy: "requested_at"})
 	assert.NoError(t, err)
 	assert.Equal(t, len(mockReports), len(reports))
 
@@ -139,7 +232,7 @@ func TestListReports_Repository(t *testing.T) {
 	}
 }
 
-func TestFetchHotelAndPhoneCounts(t *testing.T) {
+func TestFetchLocationStats_Repository(t *testing.T) {
 	// Set up environment variable for hotel service URL
 	hotelServiceURL := "http://localhost:8080" // Ensure it points to localhost for mock server
 	os.Setenv("HOTEL_SERVICE_URL", hotelServiceURL)
@@ -154,7 +247,8 @@ func TestFetchHotelAndPhoneCounts(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, fmt.Sprintf("/hotels/stats?location=%s", url.QueryEscape(mockLocation)), r.URL.String())
 		w.WriteHeader(http.StatusOK)
-		fmt.Fprintf(w, `{"hotel_count": %d, "phone_count": %d}`, mockHotelCount, mockPhoneCount)
+		fmt.Fprintf(w, `{"hotel_count": %d, "phone_count": %d, "hotels_without_phone": 1, "email_count": 4, "fax_count": 2, "website_count": 3,
+			"average_contacts": 3.8, "official_count": 6, "room_count": 40, "bed_count": 65, "average_rating": 8.25}`, mockHotelCount, mockPhoneCount)
 	}))
 	defer server.Close()
 
@@ -165,9 +259,196 @@ func TestFetchHotelAndPhoneCounts(t *testing.T) {
 	gormDB, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{}) // Using in-memory SQLite for simplicity
 	repo := NewRepository(gormDB)
 
-	// Call FetchHotelAndPhoneCounts
-	hotelCount, phoneCount, err := repo.FetchHotelAndPhoneCounts(mockLocation)
+	// Call FetchLocationStats
+	stats, err := repo.FetchLocationStats(LocationFilter{Location: mockLocation})
+	assert.NoError(t, err)
+	assert.Equal(t, &LocationStats{
+		HotelCount: mockHotelCount, PhoneCount: mockPhoneCount, HotelsWithoutPhone: 1, EmailCount: 4, FaxCount: 2, WebsiteCount: 3,
+		AverageContacts: 3.8, OfficialCount: 6, RoomCount: 40, BedCount: 65, AverageRating: 8.25,
+	}, stats)
+}
+
+func TestFetchLocationStats_Repository_StructuredLocation(t *testing.T) {
+	// Start a mock HTTP server that expects every location level as a query parameter
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/hotels/stats?city=Istanbul&country=Turkey&district=Kadikoy", r.URL.String())
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, `{"hotel_count": 3, "phone_count": 4}`)
+	}))
+	defer server.Close()
+
+	os.Setenv("HOTEL_SERVICE_URL", server.URL)
+	defer os.Unsetenv("HOTEL_SERVICE_URL")
+
+	// Initialize repository with a dummy DB (not used in this test)
+	gormDB, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	repo := NewRepository(gormDB)
+
+	stats, err := repo.FetchLocationStats(LocationFilter{Country: "Turkey", City: "Istanbul", District: "Kadikoy"})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, stats.HotelCount)
+	assert.Equal(t, 4, stats.PhoneCount)
+}
+
+func TestFetchLocationStats_Repository_Currency(t *testing.T) {
+	// Start a mock HTTP server that prices the location in the requested currency
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/hotels/stats?city=Istanbul&currency=TRY", r.URL.String())
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, `{"hotel_count": 3, "median_nightly_price": "3549.65", "currency": "TRY"}`)
+	}))
+	defer server.Close()
+
+	os.Setenv("HOTEL_SERVICE_URL", server.URL)
+	defer os.Unsetenv("HOTEL_SERVICE_URL")
+
+	// Initialize repository with a dummy DB (not used in this test)
+	gormDB, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	repo := NewRepository(gormDB)
+
+	stats, err := repo.FetchLocationStats(LocationFilter{City: "Istanbul", Currency: "TRY"})
+	assert.NoError(t, err)
+	if assert.NotNil(t, stats.MedianNightlyPrice) {
+		assert.Equal(t, "3549.65", stats.MedianNightlyPrice.String())
+	}
+	assert.Equal(t, "TRY", stats.Currency)
+}
+
+func TestFetchLocationStats_Repository_Chain(t *testing.T) {
+	chainID := uuid.New()
+
+	// Start a mock HTTP server that expects the chain as a query parameter
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/hotels/stats?chain_id="+chainID.String(), r.URL.String())
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, `{"hotel_count": 12, "phone_count": 20}`)
+	}))
+	defer server.Close()
+
+	os.Setenv("HOTEL_SERVICE_URL", server.URL)
+	defer os.Unsetenv("HOTEL_SERVICE_URL")
+
+	// Initialize repository with a dummy DB (not used in this test)
+	gormDB, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	repo := NewRepository(gormDB)
+
+	stats, err := repo.FetchLocationStats(LocationFilter{ChainID: &chainID})
+	assert.NoError(t, err)
+	assert.Equal(t, 12, stats.HotelCount)
+}
+
+func TestFetchLocationStatsBatch_Repository(t *testing.T) {
+	chainID := uuid.New()
+
+	// Start a mock HTTP server that answers every batch with stats in the order of its locations
+	var batchSizes []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/hotels/stats:batch", r.URL.Path)
+		var request struct {
+			Locations []LocationFilter `json:"locations"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		batchSizes = append(batchSizes, len(request.Locations))
+
+		items := make([]LocationStats, len(request.Locations))
+		for i, location := range request.Locations {
+			if location.ChainID != nil && *location.ChainID == chainID {
+				items[i] = LocationStats{HotelCount: 12, Currency: location.Currency}
+			} else {
+				items[i] = LocationStats{HotelCount: len(location.City)}
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{"items": items})
+	}))
+	defer server.Close()
+
+	os.Setenv("HOTEL_SERVICE_URL", server.URL)
+	defer os.Unsetenv("HOTEL_SERVICE_URL")
+
+	// Initialize repository with a dummy DB (not used in this test)
+	gormDB, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	repo := NewRepository(gormDB)
+
+	stats, err := repo.FetchLocationStatsBatch([]LocationFilter{{City: "Istanbul"}, {ChainID: &chainID, Currency: "TRY"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []LocationStats{{HotelCount: 8}, {HotelCount: 12, Currency: "TRY"}}, stats)
+	assert.Equal(t, []int{2}, batchSizes)
+
+	// Locations beyond the limit of one batch are fetched in another request
+	filters := make([]LocationFilter, maxStatsBatch+1)
+	for i := range filters {
+		filters[i] = LocationFilter{City: "Rome"}
+	}
+	batchSizes = nil
+	stats, err = repo.FetchLocationStatsBatch(filters)
+	assert.NoError(t, err)
+	assert.Len(t, stats, maxStatsBatch+1)
+	assert.Equal(t, []int{maxStatsBatch, 1}, batchSizes)
+}
+
+func TestFetchLocationStatsBatch_Repository_Rejected(t *testing.T) {
+	// Start a mock HTTP server that rejects the batch
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "no exchange rate is in effect between the currencies", http.StatusUnprocessableEntity)
+	}))
+	defer server.Close()
+
+	os.Setenv("HOTEL_SERVICE_URL", server.URL)
+	defer os.Unsetenv("HOTEL_SERVICE_URL")
+
+	// Initialize repository with a dummy DB (not used in this test)
+	gormDB, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	repo := NewRepository(gormDB)
+
+	_, err := repo.FetchLocationStatsBatch([]LocationFilter{{City: "Istanbul", Currency: "XAU"}})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "422")
+		assert.Contains(t, err.Error(), "no exchange rate")
+	}
+}
+
+func TestListReports_Repository_Cursor(t *testing.T) {
+	// Mock database setup
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to open mock database connection: %v", err)
+	}
+	defer db.Close()
+
+	// Mock SQLite version query
+	mock.ExpectQuery(`(?i)^SELECT sqlite_version\(\)$`).
+		WillReturnRows(sqlmock.NewRows([]string{"sqlite_version"}).AddRow("3.32.3"))
+
+	// Initialize GORM DB
+	gormDB, err := gorm.Open(sqlite.New(sqlite.Config{Conn: db}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to initialize GORM: %v", err)
+	}
+
+	// Create repository instance
+	repo := NewRepository(gormDB)
+
+	// The page continues after the last report of the previous page
+	lastID := uuid.New()
+	opts := ListOptions{
+		Limit:    10,
+		SortBy:   "location",
+		SortDesc: true,
+		after:    &pagination.Cursor{Sort: "-location", Value: "Paris", ID: lastID},
+	}
+
+	mock.ExpectQuery(`SELECT \* FROM `+"`reports`"+` WHERE location < \? OR \(location = \? AND id < \?\) ORDER BY location DESC, id DESC LIMIT 11`).
+		WithArgs("Paris", "Paris", lastID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "location"}))
+
+	reports, err := repo.ListReports(opts)
 	assert.NoError(t, err)
-	assert.Equal(t, mockHotelCount, hotelCount)
-	assert.Equal(t, mockPhoneCount, phoneCount)
+	assert.Empty(t, reports)
+
+	// Ensure all expectations were met
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There were unfulfilled expectations: %s", err)
+	}
 }
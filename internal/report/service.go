@@ -1,23 +1,27 @@
 package report
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"hotel-guide/internal/money"
 	"hotel-guide/internal/mq"
 	"log"
 
 	"github.com/google/uuid"
+	"github.com/streadway/amqp"
 )
 
 // ReportService interface defines the methods for report-related operations
 type ReportService interface {
 	CreateReport(location string, hotelCount, phoneCount int) (*Report, error)
-	ListReports() ([]Report, error)
+	ListReports(opts ListOptions) (*ReportPage, error)
 	GetReportByID(id uuid.UUID) (*Report, error)
-	RequestReportGeneration(location string) (*Report, error)
+	RequestReportGeneration(filter LocationFilter) (*Report, error)
+	RequestBulkReportGeneration(filters []LocationFilter) ([]Report, error)
 	UpdateReportStatus(id uuid.UUID, status ReportStatus) error
 	StartReportConsumer()
-	fetchLocationStats(location string) (int, int, error)
+	fetchLocationStats(filter LocationFilter) (*LocationStats, error)
 }
 
 // reportService struct implements the ReportService interface
@@ -44,9 +48,26 @@ func (s *reportService) CreateReport(location string, hotelCount, phoneCount int
 	return report, nil
 }
 
-// ListReports retrieves a list of all reports
-func (s *reportService) ListReports() ([]Report, error) {
-	return s.reportRepo.ListReports()
+// ListReports retrieves a page of reports and the cursor of the next page
+func (s *reportService) ListReports(opts ListOptions) (*ReportPage, error) {
+	if err := opts.normalize(); err != nil {
+		return nil, err
+	}
+
+	reports, err := s.reportRepo.ListReports(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reports: %w", err)
+	}
+
+	page := &ReportPage{Items: reports, Limit: opts.Limit}
+	if len(reports) > opts.Limit {
+		page.Items = reports[:opts.Limit]
+		page.NextCursor = opts.nextCursor(page.Items[opts.Limit-1])
+	}
+	if page.Items == nil {
+		page.Items = []Report{}
+	}
+	return page, nil
 }
 
 // GetReportByID retrieves the details of a report by its ID
@@ -54,26 +75,29 @@ func (s *reportService) GetReportByID(id uuid.UUID) (*Report, error) {
 	return s.reportRepo.GetReportByID(id)
 }
 
+// newRequestedReport creates the "Pending" report of a location filter
+func newRequestedReport(filter LocationFilter) *Report {
+	report := NewReport(filter.Location, 0, 0) // Initial counts set to 0
+	report.Country = filter.Country
+	report.City = filter.City
+	report.District = filter.District
+	report.ChainID = filter.ChainID
+	report.Currency = filter.Currency
+	report.Status = Pending
+	return report
+}
+
 // RequestReportGeneration handles the creation of a new report and sends it to the RabbitMQ queue
-func (s *reportService) RequestReportGeneration(location string) (*Report, error) {
+func (s *reportService) RequestReportGeneration(filter LocationFilter) (*Report, error) {
 	// Create a new report with "Pending" status
-	report := NewReport(location, 0, 0) // Initial counts set to 0
-	report.Status = Pending
+	report := newRequestedReport(filter)
 	err := s.reportRepo.Save(report)
 	if err != nil {
 		return nil, fmt.Errorf("failed to save report: %w", err)
 	}
 
 	// Marshal the report ID and location to JSON
-	reportRequest := struct {
-		ID       uuid.UUID `json:"id"`
-		Location string    `json:"location"`
-	}{
-		ID:       report.ID,
-		Location: location,
-	}
-
-	reportJSON, err := json.Marshal(reportRequest)
+	reportJSON, err := json.Marshal(reportRequest{ID: report.ID, LocationFilter: filter})
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal report request to JSON: %w", err)
 	}
@@ -87,6 +111,30 @@ func (s *reportService) RequestReportGeneration(location string) (*Report, error
 	return report, nil
 }
 
+// RequestBulkReportGeneration creates a report for each location and sends
+// them to the RabbitMQ queue in one message, so that the stats of all of them
+// are fetched with a single request to hotel-service
+func (s *reportService) RequestBulkReportGeneration(filters []LocationFilter) ([]Report, error) {
+	reports := make([]Report, len(filters))
+	requests := make([]reportRequest, len(filters))
+	for i, filter := range filters {
+		reports[i] = *newRequestedReport(filter)
+		requests[i] = reportRequest{ID: reports[i].ID, LocationFilter: filter}
+	}
+	if err := s.reportRepo.SaveAll(reports); err != nil {
+		return nil, fmt.Errorf("failed to save reports: %w", err)
+	}
+
+	requestsJSON, err := json.Marshal(requests)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal report requests to JSON: %w", err)
+	}
+	if err := s.messageQueue.Publish("reportQueue", requestsJSON); err != nil {
+		return nil, fmt.Errorf("failed to publish report generation requests: %w", err)
+	}
+	return reports, nil
+}
+
 // UpdateReportStatus updates the status of an existing report
 func (s *reportService) UpdateReportStatus(id uuid.UUID, status ReportStatus) error {
 	return s.reportRepo.UpdateReportStatus(id, status)
@@ -101,40 +149,109 @@ func (s *reportService) StartReportConsumer() {
 
 	go func() {
 		for msg := range messages {
-			var request struct {
-				ID       uuid.UUID `json:"id"`
-				Location string    `json:"location"`
-			}
-			err := json.Unmarshal(msg.Body, &request)
-			if err != nil {
-				log.Printf("Invalid report request in message: %v", err)
-				continue
+			// Reports requested together are already waiting in the queue;
+			// take them along so that their stats are fetched at once
+			batch := []amqp.Delivery{msg}
+		drain:
+			for len(batch) < maxStatsBatch {
+				select {
+				case next, ok := <-messages:
+					if !ok {
+						break drain
+					}
+					batch = append(batch, next)
+				default:
+					break drain
+				}
 			}
+			s.processReportRequests(batch)
+		}
+	}()
+}
 
-			// Fetch hotel and phone counts for the specified location
-			hotelCount, phoneCount, err := s.fetchLocationStats(request.Location)
-			if err != nil {
-				log.Printf("Failed to fetch location stats for %s: %v", request.Location, err)
-				continue
-			}
+// reportRequest is the message published for a report to be generated. A
+// bulk request publishes an array of them.
+type reportRequest struct {
+	ID uuid.UUID `json:"id"`
+	LocationFilter
+}
+
+// decodeReportRequests reads the report requests of a message.
+func decodeReportRequests(body []byte) ([]reportRequest, error) {
+	if body = bytes.TrimSpace(body); len(body) > 0 && body[0] == '[' {
+		var requests []reportRequest
+		if err := json.Unmarshal(body, &requests); err != nil {
+			return nil, err
+		}
+		return requests, nil
+	}
+	var request reportRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		return nil, err
+	}
+	return []reportRequest{request}, nil
+}
+
+// processReportRequests fetches the stats of a batch of report requests and
+// completes their reports. A batch of several reports takes a single request
+// to hotel-service; when it fails, every report is fetched on its own so that
+// one failing location does not hold up the others.
+func (s *reportService) processReportRequests(messages []amqp.Delivery) {
+	requests := make([]reportRequest, 0, len(messages))
+	for _, msg := range messages {
+		decoded, err := decodeReportRequests(msg.Body)
+		if err != nil {
+			log.Printf("Invalid report request in message: %v", err)
+			continue
+		}
+		requests = append(requests, decoded...)
+	}
 
-			// Update the report with the fetched stats and set status to Completed
-			err = s.reportRepo.UpdateReportStats(request.ID, hotelCount, phoneCount, Completed)
-			if err != nil {
-				log.Printf("Failed to update report status for report ID %s: %v", request.ID, err)
-				continue
+	if len(requests) > 1 {
+		filters := make([]LocationFilter, len(requests))
+		for i, request := range requests {
+			filters[i] = request.LocationFilter
+		}
+		stats, err := s.reportRepo.FetchLocationStatsBatch(filters)
+		if err == nil {
+			for i, request := range requests {
+				s.completeReport(request.ID, stats[i])
 			}
+			return
+		}
+		log.Printf("Failed to fetch location stats for %d reports at once, fetching them one by one: %v", len(requests), err)
+	}
 
-			log.Printf("Report %s has been successfully processed with %d hotels and %d phones", request.ID, hotelCount, phoneCount)
+	for _, request := range requests {
+		stats, err := s.fetchLocationStats(request.LocationFilter)
+		if err != nil {
+			log.Printf("Failed to fetch location stats for %+v: %v", request.LocationFilter, err)
+			continue
 		}
-	}()
+		s.completeReport(request.ID, *stats)
+	}
+}
+
+// completeReport stores the stats of a report and marks it completed.
+func (s *reportService) completeReport(id uuid.UUID, stats LocationStats) {
+	if err := s.reportRepo.UpdateReportStats(id, stats, Completed); err != nil {
+		log.Printf("Failed to update report status for report ID %s: %v", id, err)
+		return
+	}
+
+	medianNightlyPrice := "no"
+	if stats.MedianNightlyPrice != nil {
+		medianNightlyPrice = money.Amount{Value: *stats.MedianNightlyPrice, Currency: stats.Currency}.String()
+	}
+	log.Printf("Report %s has been successfully processed with %d hotels, %d phones, %d rooms, %d beds, an average rating of %.2f and %s median nightly price",
+		id, stats.HotelCount, stats.PhoneCount, stats.RoomCount, stats.BedCount, stats.AverageRating, medianNightlyPrice)
 }
 
-// fetchLocationStats fetches hotel and phone counts for a given location.
-func (s *reportService) fetchLocationStats(location string) (int, int, error) {
-	hotelCount, phoneCount, err := s.reportRepo.FetchHotelAndPhoneCounts(location)
+// fetchLocationStats fetches the hotel, contact, official, room and bed counts and the average rating for a given location.
+func (s *reportService) fetchLocationStats(filter LocationFilter) (*LocationStats, error) {
+	stats, err := s.reportRepo.FetchLocationStats(filter)
 	if err != nil {
-		return 0, 0, fmt.Errorf("failed to fetch hotel and phone counts for location %s: %w", location, err)
+		return nil, fmt.Errorf("failed to fetch location stats for location %+v: %w", filter, err)
 	}
-	return hotelCount, phoneCount, nil
+	return stats, nil
 }
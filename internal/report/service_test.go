@@ -1,6 +1,8 @@
 package report
 
 import (
+	"encoding/json"
+	"fmt"
 	"testing"
 	"time"
 
@@ -20,8 +22,13 @@ func (m *MockReportRepository) Save(report *Report) error {
 	return args.Error(0)
 }
 
-func (m *MockReportRepository) ListReports() ([]Report, error) {
-	args := m.Called()
+func (m *MockReportRepository) SaveAll(reports []Report) error {
+	args := m.Called(reports)
+	return args.Error(0)
+}
+
+func (m *MockReportRepository) ListReports(opts ListOptions) ([]Report, error) {
+	args := m.Called(opts)
 	return args.Get(0).([]Report), args.Error(1)
 }
 
@@ -35,13 +42,21 @@ func (m *MockReportRepository) UpdateReportStatus(id uuid.UUID, status ReportSta
 	return args.Error(0)
 }
 
-func (m *MockReportRepository) FetchHotelAndPhoneCounts(location string) (int, int, error) {
-	args := m.Called(location)
-	return args.Int(0), args.Int(1), args.Error(2)
+func (m *MockReportRepository) FetchLocationStats(filter LocationFilter) (*LocationStats, error) {
+	args := m.Called(filter)
+	return args.Get(0).(*LocationStats), args.Error(1)
+}
+
+func (m *MockReportRepository) FetchLocationStatsBatch(filters []LocationFilter) ([]LocationStats, error) {
+	args := m.Called(filters)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]LocationStats), args.Error(1)
 }
 
-func (m *MockReportRepository) UpdateReportStats(id uuid.UUID, hotelCount, phoneCount int, status ReportStatus) error {
-	args := m.Called(id, hotelCount, phoneCount, status)
+func (m *MockReportRepository) UpdateReportStats(id uuid.UUID, stats LocationStats, status ReportStatus) error {
+	args := m.Called(id, stats, status)
 	return args.Error(0)
 }
 
@@ -130,7 +145,7 @@ func TestRequestReportGeneration(t *testing.T) {
 	mockQueue.On("Publish", "reportQueue", mock.AnythingOfType("[]uint8")).Return(nil)
 
 	// Call the method under test
-	result, err := service.RequestReportGeneration("Test Location")
+	result, err := service.RequestReportGeneration(LocationFilter{Location: "Test Location"})
 
 	// Assert results
 	assert.NoError(t, err)
@@ -142,6 +157,96 @@ func TestRequestReportGeneration(t *testing.T) {
 	mockQueue.AssertExpectations(t)
 }
 
+func TestRequestBulkReportGeneration(t *testing.T) {
+	mockRepo := new(MockReportRepository)
+	mockQueue := new(MockMessageQueue)
+	service := NewService(mockRepo, mockQueue)
+
+	chainID := uuid.New()
+	filters := []LocationFilter{{City: "Istanbul", Currency: "TRY"}, {ChainID: &chainID}}
+
+	// The reports are saved together and published in one message
+	mockRepo.On("SaveAll", mock.MatchedBy(func(reports []Report) bool {
+		return len(reports) == 2 && reports[0].City == "Istanbul" && reports[0].Currency == "TRY" &&
+			*reports[1].ChainID == chainID && reports[0].Status == Pending && reports[1].Status == Pending
+	})).Return(nil).Once()
+	var published []reportRequest
+	mockQueue.On("Publish", "reportQueue", mock.AnythingOfType("[]uint8")).Return(nil).Run(func(args mock.Arguments) {
+		published, _ = decodeReportRequests(args.Get(1).([]byte))
+	}).Once()
+
+	reports, err := service.RequestBulkReportGeneration(filters)
+	assert.NoError(t, err)
+	if assert.Len(t, reports, 2) && assert.Len(t, published, 2) {
+		assert.Equal(t, reportRequest{ID: reports[0].ID, LocationFilter: filters[0]}, published[0])
+		assert.Equal(t, reportRequest{ID: reports[1].ID, LocationFilter: filters[1]}, published[1])
+	}
+
+	mockRepo.AssertExpectations(t)
+	mockQueue.AssertExpectations(t)
+}
+
+// TestRequestReportGeneration_StructuredLocation tests that the location levels reach the report and the queue
+func TestRequestReportGeneration_StructuredLocation(t *testing.T) {
+	// Initialize mocks
+	mockRepo := new(MockReportRepository)
+	mockQueue := new(MockMessageQueue)
+	service := NewService(mockRepo, mockQueue)
+
+	filter := LocationFilter{Country: "Turkey", City: "Istanbul"}
+
+	// Set up expectations
+	mockRepo.On("Save", mock.MatchedBy(func(r *Report) bool {
+		return r.Country == "Turkey" && r.City == "Istanbul" && r.District == ""
+	})).Return(nil)
+	mockQueue.On("Publish", "reportQueue", mock.MatchedBy(func(body []byte) bool {
+		var message LocationFilter
+		return json.Unmarshal(body, &message) == nil && message == filter
+	})).Return(nil)
+
+	// Call the method under test
+	result, err := service.RequestReportGeneration(filter)
+
+	// Assert results
+	assert.NoError(t, err)
+	assert.Equal(t, filter, result.Filter())
+
+	// Verify all expectations were met
+	mockRepo.AssertExpectations(t)
+	mockQueue.AssertExpectations(t)
+}
+
+// TestRequestReportGeneration_Chain tests that a chain filter reaches the report and the queue
+func TestRequestReportGeneration_Chain(t *testing.T) {
+	// Initialize mocks
+	mockRepo := new(MockReportRepository)
+	mockQueue := new(MockMessageQueue)
+	service := NewService(mockRepo, mockQueue)
+
+	chainID := uuid.New()
+	filter := LocationFilter{City: "Istanbul", ChainID: &chainID}
+
+	// Set up expectations
+	mockRepo.On("Save", mock.MatchedBy(func(r *Report) bool {
+		return r.City == "Istanbul" && r.ChainID != nil && *r.ChainID == chainID
+	})).Return(nil)
+	mockQueue.On("Publish", "reportQueue", mock.MatchedBy(func(body []byte) bool {
+		var message LocationFilter
+		return json.Unmarshal(body, &message) == nil && message.ChainID != nil && *message.ChainID == chainID
+	})).Return(nil)
+
+	// Call the method under test
+	result, err := service.RequestReportGeneration(filter)
+
+	// Assert results
+	assert.NoError(t, err)
+	assert.Equal(t, filter, result.Filter())
+
+	// Verify all expectations were met
+	mockRepo.AssertExpectations(t)
+	mockQueue.AssertExpectations(t)
+}
+
 // TestStartReportConsumer tests the StartReportConsumer method of reportService
 func TestStartReportConsumer(t *testing.T) {
 	mockRepo := new(MockReportRepository)
@@ -153,9 +258,8 @@ func TestStartReportConsumer(t *testing.T) {
 	mockRabbitMQ.On("Consume", "reportQueue").Return((<-chan amqp.Delivery)(mockMessages), nil).Once() // Cast to <-chan
 
 	location := "Test Location"
-	expectedHotelCount := 5
-	expectedPhoneCount := 10
-	mockRepo.On("FetchHotelAndPhoneCounts", location).Return(expectedHotelCount, expectedPhoneCount, nil)
+	expectedStats := LocationStats{HotelCount: 5, PhoneCount: 10, RoomCount: 120, BedCount: 180, AverageRating: 7.5}
+	mockRepo.On("FetchLocationStats", LocationFilter{Location: location}).Return(&expectedStats, nil)
 
 	// Start the consumer in a goroutine
 	go service.StartReportConsumer()
@@ -168,7 +272,7 @@ func TestStartReportConsumer(t *testing.T) {
 
 	status := Completed
 	// Set up expectations for UpdateReportStats
-	mockRepo.On("UpdateReportStats", reportID, expectedHotelCount, expectedPhoneCount, status).Return(nil)
+	mockRepo.On("UpdateReportStats", reportID, expectedStats, status).Return(nil)
 
 	mockMessages <- message
 
@@ -179,6 +283,108 @@ func TestStartReportConsumer(t *testing.T) {
 	mockRabbitMQ.AssertExpectations(t)
 }
 
+func TestStartReportConsumer_Batch(t *testing.T) {
+	mockRepo := new(MockReportRepository)
+	mockRabbitMQ := new(MockMessageQueue)
+	service := NewService(mockRepo, mockRabbitMQ)
+
+	// Reports requested together are waiting in the queue when the consumer starts
+	istanbulID, ankaraID := uuid.New(), uuid.New()
+	mockMessages := make(chan amqp.Delivery, 3)
+	mockMessages <- amqp.Delivery{Body: []byte(`{"id":"` + istanbulID.String() + `", "city":"Istanbul"}`)}
+	mockMessages <- amqp.Delivery{Body: []byte(`not a report request`)}
+	mockMessages <- amqp.Delivery{Body: []byte(`{"id":"` + ankaraID.String() + `", "city":"Ankara", "currency":"TRY"}`)}
+	mockRabbitMQ.On("Consume", "reportQueue").Return((<-chan amqp.Delivery)(mockMessages), nil).Once()
+
+	// Their stats are fetched with a single request
+	istanbulStats, ankaraStats := LocationStats{HotelCount: 12, PhoneCount: 30}, LocationStats{HotelCount: 4, PhoneCount: 7}
+	mockRepo.On("FetchLocationStatsBatch", []LocationFilter{{City: "Istanbul"}, {City: "Ankara", Currency: "TRY"}}).
+		Return([]LocationStats{istanbulStats, ankaraStats}, nil).Once()
+
+	completed := make(chan uuid.UUID, 2)
+	notify := func(args mock.Arguments) { completed <- args.Get(0).(uuid.UUID) }
+	mockRepo.On("UpdateReportStats", istanbulID, istanbulStats, Completed).Run(notify).Return(nil).Once()
+	mockRepo.On("UpdateReportStats", ankaraID, ankaraStats, Completed).Run(notify).Return(nil).Once()
+
+	service.StartReportConsumer()
+	for i := 0; i < 2; i++ {
+		select {
+		case <-completed:
+		case <-time.After(time.Second):
+			t.Fatal("reports were not completed")
+		}
+	}
+
+	mockRepo.AssertExpectations(t)
+	mockRabbitMQ.AssertExpectations(t)
+}
+
+func TestStartReportConsumer_BulkRequest(t *testing.T) {
+	mockRepo := new(MockReportRepository)
+	mockRabbitMQ := new(MockMessageQueue)
+	service := NewService(mockRepo, mockRabbitMQ)
+
+	// A bulk request arrives as one message
+	istanbulID, ankaraID := uuid.New(), uuid.New()
+	mockMessages := make(chan amqp.Delivery, 1)
+	mockMessages <- amqp.Delivery{Body: []byte(`[{"id":"` + istanbulID.String() + `", "city":"Istanbul"}, {"id":"` + ankaraID.String() + `", "city":"Ankara"}]`)}
+	mockRabbitMQ.On("Consume", "reportQueue").Return((<-chan amqp.Delivery)(mockMessages), nil).Once()
+
+	// Its stats are fetched with a single request
+	istanbulStats, ankaraStats := LocationStats{HotelCount: 12, PhoneCount: 30}, LocationStats{HotelCount: 4, PhoneCount: 7}
+	mockRepo.On("FetchLocationStatsBatch", []LocationFilter{{City: "Istanbul"}, {City: "Ankara"}}).
+		Return([]LocationStats{istanbulStats, ankaraStats}, nil).Once()
+
+	completed := make(chan uuid.UUID, 2)
+	notify := func(args mock.Arguments) { completed <- args.Get(0).(uuid.UUID) }
+	mockRepo.On("UpdateReportStats", istanbulID, istanbulStats, Completed).Run(notify).Return(nil).Once()
+	mockRepo.On("UpdateReportStats", ankaraID, ankaraStats, Completed).Run(notify).Return(nil).Once()
+
+	service.StartReportConsumer()
+	for i := 0; i < 2; i++ {
+		select {
+		case <-completed:
+		case <-time.After(time.Second):
+			t.Fatal("reports were not completed")
+		}
+	}
+
+	mockRepo.AssertExpectations(t)
+	mockRabbitMQ.AssertExpectations(t)
+}
+
+func TestStartReportConsumer_BatchFallback(t *testing.T) {
+	mockRepo := new(MockReportRepository)
+	mockRabbitMQ := new(MockMessageQueue)
+	service := NewService(mockRepo, mockRabbitMQ)
+
+	ankaraID, istanbulID := uuid.New(), uuid.New()
+	mockMessages := make(chan amqp.Delivery, 2)
+	mockMessages <- amqp.Delivery{Body: []byte(`{"id":"` + ankaraID.String() + `", "city":"Ankara", "currency":"XAU"}`)}
+	mockMessages <- amqp.Delivery{Body: []byte(`{"id":"` + istanbulID.String() + `", "city":"Istanbul"}`)}
+	mockRabbitMQ.On("Consume", "reportQueue").Return((<-chan amqp.Delivery)(mockMessages), nil).Once()
+
+	// One failing location fails the batch, so every report is fetched on its own
+	mockRepo.On("FetchLocationStatsBatch", mock.Anything).Return(nil, fmt.Errorf("hotel-service rejected location stats batch")).Once()
+	mockRepo.On("FetchLocationStats", LocationFilter{City: "Ankara", Currency: "XAU"}).Return((*LocationStats)(nil), fmt.Errorf("no exchange rate")).Once()
+	istanbulStats := LocationStats{HotelCount: 12}
+	mockRepo.On("FetchLocationStats", LocationFilter{City: "Istanbul"}).Return(&istanbulStats, nil).Once()
+
+	completed := make(chan uuid.UUID, 1)
+	mockRepo.On("UpdateReportStats", istanbulID, istanbulStats, Completed).
+		Run(func(args mock.Arguments) { completed <- args.Get(0).(uuid.UUID) }).Return(nil).Once()
+
+	service.StartReportConsumer()
+	select {
+	case id := <-completed:
+		assert.Equal(t, istanbulID, id)
+	case <-time.After(time.Second):
+		t.Fatal("report was not completed")
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
 // TestListReports tests the ListReports method of reportService
 func TestListReports(t *testing.T) {
 	mockRepo := new(MockReportRepository)
@@ -192,21 +398,59 @@ func TestListReports(t *testing.T) {
 	}
 
 	// Set up expectations for ListReports
-	mockRepo.On("ListReports").Return(expectedReports, nil)
+	mockRepo.On("ListReports", ListOptions{Limit: DefaultPageLimit, SortBy: "requested_at"}).Return(expectedReports, nil)
 
 	// Call the method under test
-	reports, err := service.ListReports()
+	page, err := service.ListReports(ListOptions{})
 
 	// Assert results
 	assert.NoError(t, err)
-	assert.Len(t, reports, 2)
-	assert.Equal(t, "Location 1", reports[0].Location)
-	assert.Equal(t, "Location 2", reports[1].Location)
+	assert.Len(t, page.Items, 2)
+	assert.Equal(t, "Location 1", page.Items[0].Location)
+	assert.Equal(t, "Location 2", page.Items[1].Location)
+	assert.Empty(t, page.NextCursor)
 
 	// Verify expectations
 	mockRepo.AssertExpectations(t)
 }
 
+// TestListReports_Cursor tests that ListReports continues after the cursor of the previous page
+func TestListReports_Cursor(t *testing.T) {
+	mockRepo := new(MockReportRepository)
+	mockRabbitMQ := new(MockMessageQueue)
+	service := NewService(mockRepo, mockRabbitMQ)
+
+	// The repository returns one report more than the limit when another page exists
+	requestedAt := time.Date(2024, 5, 1, 10, 30, 0, 123456000, time.UTC)
+	reports := []Report{
+		{ID: uuid.New(), Location: "Location 1", RequestedAt: requestedAt.Add(time.Minute)},
+		{ID: uuid.New(), Location: "Location 2", RequestedAt: requestedAt},
+	}
+	mockRepo.On("ListReports", ListOptions{Limit: 1, SortBy: "requested_at", SortDesc: true}).Return(reports, nil).Once()
+
+	page, err := service.ListReports(ListOptions{Limit: 1, SortBy: "requested_at", SortDesc: true})
+	assert.NoError(t, err)
+	assert.Len(t, page.Items, 1)
+	assert.NotEmpty(t, page.NextCursor)
+	cursor := page.NextCursor
+
+	// The cursor carries the position of the last report of the page
+	mockRepo.On("ListReports", mock.MatchedBy(func(opts ListOptions) bool {
+		return opts.after != nil && opts.after.ID == reports[0].ID && opts.after.Value == reports[0].RequestedAt
+	})).Return(reports[1:], nil).Once()
+
+	page, err = service.ListReports(ListOptions{Limit: 1, SortBy: "requested_at", SortDesc: true, Cursor: cursor})
+	assert.NoError(t, err)
+	assert.Len(t, page.Items, 1)
+	assert.Empty(t, page.NextCursor)
+
+	// A cursor issued for another ordering is rejected
+	_, err = service.ListReports(ListOptions{SortBy: "location", Cursor: cursor})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	mockRepo.AssertExpectations(t)
+}
+
 // TestGetReportByID tests the GetReportByID method of reportService
 func TestGetReportByID(t *testing.T) {
 	mockRepo := new(MockReportRepository)
@@ -269,20 +513,18 @@ func TestFetchLocationStats(t *testing.T) {
 
 	// Mock data for the location
 	location := "Test Location"
-	expectedHotelCount := 5
-	expectedPhoneCount := 10
+	expectedStats := &LocationStats{HotelCount: 5, PhoneCount: 10, RoomCount: 120, BedCount: 180}
 
-	// Set up expectations for FetchHotelAndPhoneCounts
-	// This mocks the method FetchHotelAndPhoneCounts and specifies the return values
-	mockRepo.On("FetchHotelAndPhoneCounts", location).Return(expectedHotelCount, expectedPhoneCount, nil)
+	// Set up expectations for FetchLocationStats
+	// This mocks the method FetchLocationStats and specifies the return values
+	mockRepo.On("FetchLocationStats", LocationFilter{Location: location}).Return(expectedStats, nil)
 
 	// Call the method under test
-	hotelCount, phoneCount, err := service.fetchLocationStats(location)
+	stats, err := service.fetchLocationStats(LocationFilter{Location: location})
 
 	// Assert results
-	assert.NoError(t, err)                          // Ensure no error was returned
-	assert.Equal(t, expectedHotelCount, hotelCount) // Ensure hotel count is correct
-	assert.Equal(t, expectedPhoneCount, phoneCount) // Ensure phone count is correct
+	assert.NoError(t, err)                // Ensure no error was returned
+	assert.Equal(t, expectedStats, stats) // Ensure the stats are passed on
 
 	// Verify expectations were met (that the method was called as expected)
 	mockRepo.AssertExpectations(t)
@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalStore(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "blobs")
+	store, err := NewLocalStore(root)
+	assert.NoError(t, err)
+
+	assert.NoError(t, store.Put("hotels/1/media/a.jpg", strings.NewReader("image")))
+	blob, err := store.Open("hotels/1/media/a.jpg")
+	assert.NoError(t, err)
+	data, err := io.ReadAll(blob)
+	blob.Close()
+	assert.NoError(t, err)
+	assert.Equal(t, "image", string(data))
+
+	// No temporary files are left behind
+	entries, err := os.ReadDir(filepath.Join(root, "hotels", "1", "media"))
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	assert.NoError(t, store.Delete("hotels/1/media/a.jpg"))
+	assert.NoError(t, store.Delete("hotels/1/media/a.jpg"))
+	_, err = store.Open("hotels/1/media/a.jpg")
+	assert.ErrorIs(t, err, ErrBlobNotFound)
+}
+
+func TestLocalStore_InvalidKey(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	assert.NoError(t, err)
+
+	for _, key := range []string{"", "../secret", "hotels/../../secret", "/etc/passwd", "hotels//a", ".hidden", `hotels\a`} {
+		assert.ErrorIs(t, store.Put(key, strings.NewReader("x")), ErrInvalidBlobKey, key)
+		_, err := store.Open(key)
+		assert.ErrorIs(t, err, ErrInvalidBlobKey, key)
+	}
+}